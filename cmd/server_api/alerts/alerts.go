@@ -0,0 +1,178 @@
+// Package alerts posts operational alerts to the Slack and Discord incoming
+// webhooks and generic JSON endpoints admins have configured.
+package alerts
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventLowStock = "low_stock"
+	// EventTest is only sent by the test endpoint, whatever the webhook's
+	// events.
+	EventTest = "test"
+)
+
+const (
+	webhookTimeout = 10 * time.Second
+	// discordContentLimit is the longest message content Discord accepts.
+	discordContentLimit = 2000
+)
+
+func EventTypes() []string {
+	return []string{
+		EventLowStock,
+	}
+}
+
+// Alert is one message. Text may span several lines.
+type Alert struct {
+	Event string
+	Title string
+	Text  string
+}
+
+type Alerter struct {
+	webhookRepo *repositories.AlertWebhookRepository
+	client      *http.Client
+}
+
+func NewAlerter(webhookRepo *repositories.AlertWebhookRepository) *Alerter {
+	return &Alerter{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+}
+
+// Send posts the alert to every enabled webhook subscribed to its event. It
+// returns how many webhooks received it and the failed deliveries joined
+// together.
+func (a *Alerter) Send(ctx context.Context, alert Alert) (int, error) {
+	webhooks, err := a.webhookRepo.GetEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	var errs []error
+	for i := range webhooks {
+		if !slices.Contains(SplitEvents(webhooks[i].Events), alert.Event) {
+			continue
+		}
+		if err := a.SendTo(ctx, &webhooks[i], alert); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", webhooks[i].Name, err))
+			continue
+		}
+		delivered++
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// SendTo posts the alert to one webhook, enabled or not, and records the
+// outcome on it.
+func (a *Alerter) SendTo(ctx context.Context, webhook *models.AlertWebhook, alert Alert) error {
+	deliveredAt := time.Now().UTC()
+	err := a.post(ctx, webhook, alert)
+	if recordErr := a.webhookRepo.RecordDelivery(ctx, webhook.ID, deliveredAt, err); recordErr != nil {
+		return errors.Join(err, recordErr)
+	}
+	if err == nil {
+		webhook.LastDeliveredAt = &deliveredAt
+		webhook.LastError = nil
+	} else {
+		message := err.Error()
+		webhook.LastError = &message
+	}
+	return err
+}
+
+// post signs the delivery when the webhook has a signing secret. Slack and
+// Discord ignore the signature headers.
+func (a *Alerter) post(ctx context.Context, endpoint *models.AlertWebhook, alert Alert) error {
+	deliveryID := uuid.New().String()
+	sentAt := time.Now().UTC()
+	body, err := json.Marshal(payload(endpoint.Provider, deliveryID, sentAt, alert))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if endpoint.SigningSecret != nil {
+		webhook.SetHeaders(req.Header, *endpoint.SigningSecret, deliveryID, sentAt, body)
+	}
+	res, err := a.client.Do(req)
+	if err != nil {
+		// The error quotes the URL, which holds the webhook's credentials.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("POST failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 256))
+		return fmt.Errorf("POST returned %s: %s", res.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// payload builds the provider's incoming webhook body. Alert text is escaped
+// so it can't ping users or channels.
+func payload(provider, deliveryID string, sentAt time.Time, alert Alert) any {
+	if provider == models.AlertProviderGeneric {
+		return map[string]any{
+			"id":      deliveryID,
+			"event":   alert.Event,
+			"title":   alert.Title,
+			"text":    alert.Text,
+			"sent_at": sentAt,
+		}
+	}
+	if provider == models.AlertProviderDiscord {
+		content := "**" + alert.Title + "**\n" + alert.Text
+		if runes := []rune(content); len(runes) > discordContentLimit {
+			content = string(runes[:discordContentLimit-3]) + "..."
+		}
+		return map[string]any{
+			"content": content,
+			"allowed_mentions": map[string]any{
+				"parse": []string{},
+			},
+		}
+	}
+	escaper := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return map[string]any{
+		"text": "*" + escaper.Replace(alert.Title) + "*\n" + escaper.Replace(alert.Text),
+	}
+}
+
+func SplitEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+func JoinEvents(events []string) string {
+	return strings.Join(events, ",")
+}
@@ -0,0 +1,324 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/alerts"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/webhook"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// AlertWebhookAPI manages the Slack and Discord webhooks and generic
+// endpoints that receive operational alerts.
+type AlertWebhookAPI struct {
+	webhookRepo *repositories.AlertWebhookRepository
+	alerter     *alerts.Alerter
+}
+
+// CreateAlertWebhookRequest takes the webhook URL Slack or Discord issued,
+// or the URL of an endpoint taking JSON. Enabled defaults to true.
+type CreateAlertWebhookRequest struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	Provider string   `json:"provider" validate:"required,oneof=slack discord generic"`
+	URL      string   `json:"url" validate:"required"`
+	Events   []string `json:"events" validate:"required,min=1,dive,oneof=low_stock"`
+	Enabled  *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateAlertWebhookRequest replaces events when given.
+type UpdateAlertWebhookRequest struct {
+	Name     *string  `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Provider *string  `json:"provider,omitempty" validate:"omitempty,oneof=slack discord generic"`
+	URL      *string  `json:"url,omitempty"`
+	Events   []string `json:"events,omitempty" validate:"omitempty,min=1,dive,oneof=low_stock"`
+	Enabled  *bool    `json:"enabled,omitempty"`
+}
+
+// AlertWebhookDetail shows only the scheme and host of the URL, since the
+// rest of it is the webhook's secret. SigningSecret is only returned when it
+// is created or rotated.
+type AlertWebhookDetail struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Provider        string     `json:"provider"`
+	URL             string     `json:"url"`
+	Events          []string   `json:"events"`
+	Enabled         bool       `json:"enabled"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at"`
+	LastError       *string    `json:"last_error"`
+	Signed          bool       `json:"signed"`
+	SigningSecret   string     `json:"signing_secret,omitempty"`
+	CreatedDate     time.Time  `json:"created_date"`
+	UpdatedDate     time.Time  `json:"updated_date"`
+}
+
+func NewAlertWebhookAPI(webhookRepo *repositories.AlertWebhookRepository, alerter *alerts.Alerter) *AlertWebhookAPI {
+	return &AlertWebhookAPI{
+		webhookRepo: webhookRepo,
+		alerter:     alerter,
+	}
+}
+
+func (api *AlertWebhookAPI) Setup(group *echo.Group) {
+	group.POST("", api.createWebhook)
+	group.GET("", api.getWebhooks)
+	group.GET("/:id", api.getWebhook)
+	group.PUT("/:id", api.updateWebhook)
+	group.DELETE("/:id", api.deleteWebhook)
+	group.POST("/:id/test", api.testWebhook)
+	group.POST("/:id/rotate-secret", api.rotateSecret)
+}
+
+func (api *AlertWebhookAPI) createWebhook(c echo.Context) error {
+	var req CreateAlertWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if !isWebhookURL(req.URL) {
+		return fieldValidationError(c, "url", "https_url", "must be an https URL")
+	}
+	exists, err := api.webhookRepo.NameExists(c.Request().Context(), req.Name, "")
+	if err != nil || exists {
+		return alertWebhookNameError(c, err)
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating alert webhook",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	endpoint := &models.AlertWebhook{
+		ID:            uuid.New().String(),
+		Name:          req.Name,
+		Provider:      req.Provider,
+		URL:           req.URL,
+		Events:        alerts.JoinEvents(req.Events),
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		SigningSecret: &secret,
+	}
+	if err := api.webhookRepo.Create(c.Request().Context(), endpoint); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating alert webhook",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "alert_webhook", endpoint.ID, nil, toAlertWebhookDetail(endpoint, time.UTC))
+	detail := toAlertWebhookDetail(endpoint, userLocation(c))
+	detail.SigningSecret = secret
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    detail,
+		Message: "Alert webhook created successfully",
+	})
+}
+
+func (api *AlertWebhookAPI) getWebhooks(c echo.Context) error {
+	webhooks, err := api.webhookRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving alert webhooks",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]AlertWebhookDetail, len(webhooks))
+	for i := range webhooks {
+		details[i] = toAlertWebhookDetail(&webhooks[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details,
+		Message: "Alert webhooks retrieved successfully",
+	})
+}
+
+func (api *AlertWebhookAPI) getWebhook(c echo.Context) error {
+	webhook, err := api.webhookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return alertWebhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toAlertWebhookDetail(webhook, userLocation(c)),
+		Message: "Alert webhook retrieved successfully",
+	})
+}
+
+func (api *AlertWebhookAPI) updateWebhook(c echo.Context) error {
+	var req UpdateAlertWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.URL != nil && !isWebhookURL(*req.URL) {
+		return fieldValidationError(c, "url", "https_url", "must be an https URL")
+	}
+	webhook, err := api.webhookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return alertWebhookError(c, err)
+	}
+	before := toAlertWebhookDetail(webhook, time.UTC)
+	if req.Name != nil && *req.Name != webhook.Name {
+		exists, err := api.webhookRepo.NameExists(c.Request().Context(), *req.Name, webhook.ID)
+		if err != nil || exists {
+			return alertWebhookNameError(c, err)
+		}
+		webhook.Name = *req.Name
+	}
+	if req.Provider != nil {
+		webhook.Provider = *req.Provider
+	}
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = alerts.JoinEvents(req.Events)
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	if err := api.webhookRepo.Update(c.Request().Context(), webhook); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating alert webhook",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "alert_webhook", webhook.ID, before, toAlertWebhookDetail(webhook, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toAlertWebhookDetail(webhook, userLocation(c)),
+		Message: "Alert webhook updated successfully",
+	})
+}
+
+func (api *AlertWebhookAPI) deleteWebhook(c echo.Context) error {
+	webhook, err := api.webhookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return alertWebhookError(c, err)
+	}
+	if err := api.webhookRepo.Delete(c.Request().Context(), webhook.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting alert webhook",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "alert_webhook", webhook.ID, toAlertWebhookDetail(webhook, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Alert webhook deleted successfully",
+	})
+}
+
+// testWebhook posts a test alert to the webhook, even when it is disabled,
+// and answers with the webhook and its recorded delivery outcome.
+func (api *AlertWebhookAPI) testWebhook(c echo.Context) error {
+	webhook, err := api.webhookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return alertWebhookError(c, err)
+	}
+	err = api.alerter.SendTo(c.Request().Context(), webhook, alerts.Alert{
+		Event: alerts.EventTest,
+		Title: "Test alert",
+		Text:  "Alerts from the book management system will be posted here.",
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, models.Response{
+			Data:      toAlertWebhookDetail(webhook, userLocation(c)),
+			Message:   "Alert webhook delivery failed",
+			ErrorCode: models.ErrCodeAlertDeliveryFailed,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toAlertWebhookDetail(webhook, userLocation(c)),
+		Message: "Test alert delivered",
+	})
+}
+
+// rotateSecret replaces the webhook's signing secret, or gives it one if it
+// predates signing, and returns the new secret. Deliveries are signed with
+// the new secret from then on.
+func (api *AlertWebhookAPI) rotateSecret(c echo.Context) error {
+	endpoint, err := api.webhookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return alertWebhookError(c, err)
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error rotating signing secret",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	before := toAlertWebhookDetail(endpoint, time.UTC)
+	endpoint.SigningSecret = &secret
+	if err := api.webhookRepo.Update(c.Request().Context(), endpoint); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error rotating signing secret",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "alert_webhook", endpoint.ID, before, toAlertWebhookDetail(endpoint, time.UTC))
+	detail := toAlertWebhookDetail(endpoint, userLocation(c))
+	detail.SigningSecret = secret
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Signing secret rotated successfully",
+	})
+}
+
+func isWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+func alertWebhookError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Alert webhook not found",
+			ErrorCode: models.ErrCodeAlertWebhookNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving alert webhook",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func alertWebhookNameError(c echo.Context, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking alert webhook name",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "An alert webhook with this name already exists",
+		ErrorCode: models.ErrCodeAlertWebhookExists,
+	})
+}
+
+func toAlertWebhookDetail(webhook *models.AlertWebhook, location *time.Location) AlertWebhookDetail {
+	redacted := ""
+	if u, err := url.Parse(webhook.URL); err == nil {
+		redacted = u.Scheme + "://" + u.Host + "/..."
+	}
+	return AlertWebhookDetail{
+		ID:              webhook.ID,
+		Name:            webhook.Name,
+		Provider:        webhook.Provider,
+		URL:             redacted,
+		Events:          alerts.SplitEvents(webhook.Events),
+		Enabled:         webhook.Enabled,
+		LastDeliveredAt: timeIn(webhook.LastDeliveredAt, location),
+		LastError:       webhook.LastError,
+		Signed:          webhook.SigningSecret != nil,
+		CreatedDate:     webhook.CreatedDate.In(location),
+		UpdatedDate:     webhook.UpdatedDate.In(location),
+	}
+}
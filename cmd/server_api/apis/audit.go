@@ -0,0 +1,278 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"book-management-system/pkg/logging"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	auditChangeKey  = "audit_change"
+	auditActionList = "create, update, delete"
+)
+
+// auditChange describes the entity a handler changed. A nil before means it
+// was created, a nil after that it was deleted.
+type auditChange struct {
+	entityType string
+	entityID   string
+	before     any
+	after      any
+}
+
+type AuditAPI struct {
+	auditRepo *repositories.AuditLogRepository
+	branches  *BranchAccess
+}
+
+type AuditLogDetail struct {
+	ID          string          `json:"id"`
+	ActorID     string          `json:"actor_id"`
+	ActorEmail  string          `json:"actor_email"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    *string         `json:"entity_id"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	StatusCode  int             `json:"status_code"`
+	IPAddress   string          `json:"ip_address"`
+	Before      json.RawMessage `json:"before"`
+	After       json.RawMessage `json:"after"`
+	CreatedDate time.Time       `json:"created_date"`
+}
+
+type AuditLogListResponse struct {
+	Entries []AuditLogDetail `json:"entries"`
+	Total   int64            `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+// auditRecord attaches the changed entity to the request's audit entry.
+// Snapshots should be response details rather than models, so secrets such
+// as password hashes never reach the audit log.
+func auditRecord(c echo.Context, entityType, entityID string, before, after any) {
+	c.Set(auditChangeKey, &auditChange{
+		entityType: entityType,
+		entityID:   entityID,
+		before:     before,
+		after:      after,
+	})
+}
+
+// Audit records every successful POST, PUT, PATCH or DELETE made by an admin.
+// Handlers describe what they changed with auditRecord; other requests are
+// recorded against the route with no before/after data. Replayed idempotent
+// responses are not recorded again.
+func Audit(auditRepo *repositories.AuditLogRepository, authMw *auth.Middleware) echo.MiddlewareFunc {
+	logger := logging.Module("audit")
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			req := c.Request()
+			switch req.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return err
+			}
+			claims := authMw.GetUserFromContext(c)
+			res := c.Response()
+			if err != nil || claims == nil || claims.Role != "admin" || !res.Committed ||
+				res.Status >= http.StatusBadRequest || res.Header().Get(IdempotentReplayedHeader) != "" {
+				return err
+			}
+			change, _ := c.Get(auditChangeKey).(*auditChange)
+			if change == nil {
+				change = &auditChange{
+					entityType: routeEntity(c.Path()),
+					entityID:   c.Param("id"),
+				}
+			}
+			entry := &models.AuditLog{
+				ID:         uuid.New().String(),
+				ActorID:    claims.UserID,
+				ActorEmail: claims.Email,
+				Action:     auditAction(req.Method, change),
+				EntityType: change.entityType,
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				StatusCode: res.Status,
+				IPAddress:  c.RealIP(),
+				BeforeData: auditJSON(logger, change.before),
+				AfterData:  auditJSON(logger, change.after),
+			}
+			if change.entityID != "" {
+				entry.EntityID = &change.entityID
+			}
+			// The response is already sent, so a client disconnect must not
+			// cancel the write.
+			ctx := context.WithoutCancel(req.Context())
+			if err := auditRepo.Create(ctx, entry); err != nil {
+				logger.ErrorContext(ctx, "Failed to write audit log",
+					"error", err,
+					"actor_id", entry.ActorID,
+					"method", entry.Method,
+					"path", entry.Path,
+				)
+			}
+			return nil
+		}
+	}
+}
+
+func auditAction(method string, change *auditChange) string {
+	switch {
+	case change.before == nil && change.after != nil:
+		return models.AuditActionCreate
+	case change.before != nil && change.after == nil:
+		return models.AuditActionDelete
+	case change.before != nil && change.after != nil:
+		return models.AuditActionUpdate
+	}
+	switch method {
+	case http.MethodPost:
+		return models.AuditActionCreate
+	case http.MethodDelete:
+		return models.AuditActionDelete
+	}
+	return models.AuditActionUpdate
+}
+
+func auditJSON(logger *slog.Logger, value any) *string {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Error("Failed to encode audit snapshot",
+			"error", err,
+		)
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// routeEntity names the entity of an unannotated route after its last static
+// segment, for example "inactive-accounts" for /admin/jobs/inactive-accounts.
+func routeEntity(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] != "" && !strings.HasPrefix(segments[i], ":") {
+			return segments[i]
+		}
+	}
+	return path
+}
+
+func NewAuditAPI(auditRepo *repositories.AuditLogRepository, branches *BranchAccess) *AuditAPI {
+	return &AuditAPI{
+		auditRepo: auditRepo,
+		branches:  branches,
+	}
+}
+
+func (api *AuditAPI) Setup(group *echo.Group) {
+	group.GET("", api.getAuditLogs)
+}
+
+func (api *AuditAPI) getAuditLogs(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	location := userLocation(c)
+	filter := repositories.AuditLogFilter{
+		ActorID:    c.QueryParam("actor_id"),
+		EntityType: c.QueryParam("entity_type"),
+		EntityID:   c.QueryParam("entity_id"),
+		Action:     c.QueryParam("action"),
+	}
+	var fieldErrors []models.FieldError
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete:
+	default:
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "action",
+			Message: "must be one of: " + auditActionList,
+		})
+	}
+	if from, ok := reportDateParam(c, "from", location, &fieldErrors); ok {
+		filter.From = &from
+	}
+	if to, ok := reportDateParam(c, "to", location, &fieldErrors); ok {
+		// to is an inclusive day.
+		end := to.AddDate(0, 0, 1)
+		filter.To = &end
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	entries, err := api.auditRepo.GetAll(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving audit log",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.auditRepo.Count(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting audit log entries",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]AuditLogDetail, len(entries))
+	for i := range entries {
+		details[i] = toAuditLogDetail(&entries[i], location)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: AuditLogListResponse{
+			Entries: details,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		},
+		Message: "Audit log retrieved successfully",
+	})
+}
+
+func toAuditLogDetail(entry *models.AuditLog, location *time.Location) AuditLogDetail {
+	detail := AuditLogDetail{
+		ID:          entry.ID,
+		ActorID:     entry.ActorID,
+		ActorEmail:  entry.ActorEmail,
+		Action:      entry.Action,
+		EntityType:  entry.EntityType,
+		EntityID:    entry.EntityID,
+		Method:      entry.Method,
+		Path:        entry.Path,
+		StatusCode:  entry.StatusCode,
+		IPAddress:   entry.IPAddress,
+		CreatedDate: entry.CreatedDate.In(location),
+	}
+	if entry.BeforeData != nil {
+		detail.Before = json.RawMessage(*entry.BeforeData)
+	}
+	if entry.AfterData != nil {
+		detail.After = json.RawMessage(*entry.AfterData)
+	}
+	return detail
+}
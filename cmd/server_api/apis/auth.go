@@ -1,21 +1,29 @@
 package apis
 
 import (
+	"book-management-system/cmd/server_api/librarycard"
 	"book-management-system/cmd/server_api/models"
 	"book-management-system/cmd/server_api/repositories"
 	"book-management-system/pkg/auth"
+	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthAPI struct {
-	userRepo *repositories.UserRepository
-	jwt      *auth.JWT
-	authMw   *auth.Middleware
+	userRepo    repositories.UserRepo
+	planRepo    *repositories.MembershipPlanRepository
+	loginRepo   *repositories.LoginEventRepository
+	branchRepo  *repositories.BranchRepository
+	jwt         *auth.JWT
+	authMw      *auth.Middleware
+	idempotency *Idempotency
 }
 
 type RegisterRequest struct {
@@ -23,6 +31,14 @@ type RegisterRequest struct {
 	Password  string `json:"password" validate:"required,min=8"`
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
+	BranchID  string `json:"branch_id,omitempty"`
+	Timezone  string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+}
+
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=1"`
+	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=1"`
+	Timezone  *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
 type LoginRequest struct {
@@ -35,149 +51,205 @@ type RefreshRequest struct {
 }
 
 type AuthResponse struct {
-	User         *UserProfile     `json:"user"`
-	AccessToken  string           `json:"access_token"`
-	RefreshToken string           `json:"refresh_token"`
-	ExpiresAt    time.Time        `json:"expires_at"`
+	User             *UserProfile `json:"user"`
+	AccessToken      string       `json:"access_token"`
+	RefreshToken     string       `json:"refresh_token"`
+	TokenType        string       `json:"token_type"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
 }
 
 type UserProfile struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Role      string `json:"role"`
-	Status    string `json:"status"`
+	ID         string  `json:"id"`
+	Email      string  `json:"email"`
+	FirstName  string  `json:"first_name"`
+	LastName   string  `json:"last_name"`
+	Role       string  `json:"role"`
+	Status     string  `json:"status"`
+	BranchID   *string `json:"branch_id"`
+	CardNumber string  `json:"card_number"`
+	Timezone   string  `json:"timezone"`
 }
 
-func NewAuthAPI(userRepo *repositories.UserRepository, jwt *auth.JWT) *AuthAPI {
+func NewAuthAPI(userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository, loginRepo *repositories.LoginEventRepository, branchRepo *repositories.BranchRepository, jwt *auth.JWT, idempotency *Idempotency) *AuthAPI {
 	return &AuthAPI{
-		userRepo: userRepo,
-		jwt:      jwt,
-		authMw:   auth.NewMiddleware(jwt),
+		userRepo:    userRepo,
+		planRepo:    planRepo,
+		loginRepo:   loginRepo,
+		branchRepo:  branchRepo,
+		jwt:         jwt,
+		authMw:      auth.NewMiddleware(jwt),
+		idempotency: idempotency,
 	}
 }
 
 func (api *AuthAPI) Setup(group *echo.Group) {
-	group.POST("/register", api.register)
+	group.POST("/register", api.register, api.idempotency.ReplayMiddleware(api.replayRegister))
 	group.POST("/login", api.login)
 	group.POST("/refresh", api.refresh)
 	group.GET("/profile", api.profile, api.authMw.RequireAuth())
+	group.PUT("/profile", api.updateProfile, api.authMw.RequireAuth())
 }
 
 func (api *AuthAPI) register(c echo.Context) error {
 	var req RegisterRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request format",
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Email = normalizeEmail(req.Email)
+	plan, err := api.planRepo.GetByCode(defaultMembershipPlanCode)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error resolving membership plan",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
-	exists, err := api.userRepo.EmailExists(req.Email)
+	var branchID *string
+	if req.BranchID != "" {
+		branch, err := api.branchRepo.GetByID(c.Request().Context(), req.BranchID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fieldValidationError(c, "branch_id", "exists", "Branch not found")
+			}
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error resolving branch",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		branchID = &branch.ID
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error processing password",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	cardNumber, err := librarycard.Issue(c.Request().Context(), api.userRepo.CardNumberExists)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error checking email availability",
+			Message:   "Error issuing library card number",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
-	if exists {
+	user := &models.User{
+		ID:                    uuid.NewString(),
+		Email:                 req.Email,
+		PasswordHash:          string(hashedPassword),
+		FirstName:             req.FirstName,
+		LastName:              req.LastName,
+		Role:                  "member",
+		Status:                "active",
+		MembershipPlanID:      plan.ID,
+		BranchID:              branchID,
+		CardNumber:            cardNumber,
+		Timezone:              req.Timezone,
+		ReadingHistoryEnabled: true,
+	}
+	err = api.userRepo.Create(c.Request().Context(), user)
+	if errors.Is(err, repositories.ErrEmailExists) {
 		return c.JSON(http.StatusConflict, models.Response{
-			Message: "Email already registered",
+			Message:   "Email already registered",
+			ErrorCode: models.ErrCodeEmailExists,
 		})
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error processing password",
+			Message:   "Error creating user account",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
-	user := &models.User{
-		ID:           generateID(),
-		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		Role:         "member",
-		Status:       "active",
-	}
-	err = api.userRepo.Create(user)
+	setIdempotentResource(c, user.ID)
+	return api.registerResponse(c, http.StatusCreated, user)
+}
+
+// replayRegister answers a retried registration with fresh tokens for the
+// user the first request created, since tokens are never stored.
+func (api *AuthAPI) replayRegister(c echo.Context, status int, userID string) error {
+	user, err := api.userRepo.GetByID(c.Request().Context(), userID)
 	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error creating user account",
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if user.Status != "active" {
+		return c.JSON(http.StatusUnauthorized, models.Response{
+			Message:   "Account is not active",
+			ErrorCode: models.ErrCodeAccountInactive,
 		})
 	}
+	return api.registerResponse(c, status, user)
+}
+
+func (api *AuthAPI) registerResponse(c echo.Context, status int, user *models.User) error {
 	tokens, err := api.jwt.GenerateTokenPair(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error generating authentication tokens",
+			Message:   "Error generating authentication tokens",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 	response := models.Response{
-		Data: AuthResponse{
-			User: &UserProfile{
-				ID:        user.ID,
-				Email:     user.Email,
-				FirstName: user.FirstName,
-				LastName:  user.LastName,
-				Role:      user.Role,
-				Status:    user.Status,
-			},
-			AccessToken:  tokens.AccessToken,
-			RefreshToken: tokens.RefreshToken,
-			ExpiresAt:    time.Now().Add(time.Hour * 24),
-		},
+		Data:    newAuthResponse(user, tokens),
 		Message: "Account created successfully",
 	}
-	return c.JSON(http.StatusCreated, response)
+	return c.JSON(status, response)
 }
 
 func (api *AuthAPI) login(c echo.Context) error {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request format",
-		})
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
-	user, err := api.userRepo.GetByEmail(req.Email)
+	user, err := api.userRepo.GetByEmail(c.Request().Context(), normalizeEmail(req.Email))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.JSON(http.StatusUnauthorized, models.Response{
-				Message: "Invalid email or password",
+				Message:   "Invalid email or password",
+				ErrorCode: models.ErrCodeInvalidCredentials,
 			})
 		}
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error during authentication",
+			Message:   "Error during authentication",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 	if user.Status != "active" {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "Account is not active",
+			Message:   "Account is not active",
+			ErrorCode: models.ErrCodeAccountInactive,
 		})
 	}
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "Invalid email or password",
+			Message:   "Invalid email or password",
+			ErrorCode: models.ErrCodeInvalidCredentials,
 		})
 	}
 	tokens, err := api.jwt.GenerateTokenPair(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error generating authentication tokens",
+			Message:   "Error generating authentication tokens",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	api.recordLogin(c, user)
 	response := models.Response{
-		Data: AuthResponse{
-			User: &UserProfile{
-				ID:        user.ID,
-				Email:     user.Email,
-				FirstName: user.FirstName,
-				LastName:  user.LastName,
-				Role:      user.Role,
-				Status:    user.Status,
-			},
-			AccessToken:  tokens.AccessToken,
-			RefreshToken: tokens.RefreshToken,
-			ExpiresAt:    time.Now().Add(time.Hour * 24),
-		},
+		Data:    newAuthResponse(user, tokens),
 		Message: "Login successful",
 	}
 	return c.JSON(http.StatusOK, response)
@@ -186,47 +258,40 @@ func (api *AuthAPI) login(c echo.Context) error {
 func (api *AuthAPI) refresh(c echo.Context) error {
 	var req RefreshRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request format",
-		})
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
 	userID, err := api.jwt.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "Invalid refresh token",
+			Message:   "Invalid refresh token",
+			ErrorCode: models.ErrCodeInvalidToken,
 		})
 	}
-	user, err := api.userRepo.GetByID(userID)
+	user, err := api.userRepo.GetByID(c.Request().Context(), userID)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "User not found",
+			Message:   "User not found",
+			ErrorCode: models.ErrCodeUserNotFound,
 		})
 	}
 	if user.Status != "active" {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "Account is not active",
+			Message:   "Account is not active",
+			ErrorCode: models.ErrCodeAccountInactive,
 		})
 	}
 	tokens, err := api.jwt.GenerateTokenPair(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error generating authentication tokens",
+			Message:   "Error generating authentication tokens",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 	response := models.Response{
-		Data: AuthResponse{
-			User: &UserProfile{
-				ID:        user.ID,
-				Email:     user.Email,
-				FirstName: user.FirstName,
-				LastName:  user.LastName,
-				Role:      user.Role,
-				Status:    user.Status,
-			},
-			AccessToken:  tokens.AccessToken,
-			RefreshToken: tokens.RefreshToken,
-			ExpiresAt:    time.Now().Add(time.Hour * 24),
-		},
+		Data:    newAuthResponse(user, tokens),
 		Message: "Tokens refreshed successfully",
 	}
 	return c.JSON(http.StatusOK, response)
@@ -236,29 +301,119 @@ func (api *AuthAPI) profile(c echo.Context) error {
 	claims := api.authMw.GetUserFromContext(c)
 	if claims == nil {
 		return c.JSON(http.StatusUnauthorized, models.Response{
-			Message: "Authentication required",
+			Message:   "Authentication required",
+			ErrorCode: models.ErrCodeAuthenticationRequired,
 		})
 	}
-	user, err := api.userRepo.GetByID(claims.UserID)
+	user, err := api.userRepo.GetByID(c.Request().Context(), claims.UserID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, models.Response{
-			Message: "User not found",
+			Message:   "User not found",
+			ErrorCode: models.ErrCodeUserNotFound,
 		})
 	}
 	response := models.Response{
-		Data: UserProfile{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Role:      user.Role,
-			Status:    user.Status,
-		},
+		Data:    toUserProfile(user),
 		Message: "User profile retrieved successfully",
 	}
 	return c.JSON(http.StatusOK, response)
 }
 
-func generateID() string {
-	return time.Now().Format("20060102150405") + "-" + time.Now().Format("000000")
-}
\ No newline at end of file
+func (api *AuthAPI) updateProfile(c echo.Context) error {
+	var req UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	claims := api.authMw.GetUserFromContext(c)
+	user, err := api.userRepo.GetByID(c.Request().Context(), claims.UserID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if req.FirstName != nil {
+		user.FirstName = *req.FirstName
+	}
+	if req.LastName != nil {
+		user.LastName = *req.LastName
+	}
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+	err = api.userRepo.Update(c.Request().Context(), user)
+	if err == repositories.ErrVersionConflict {
+		return userVersionConflict(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toUserProfile(user),
+		Message: "Profile updated successfully",
+	})
+}
+
+func (api *AuthAPI) recordLogin(c echo.Context, user *models.User) {
+	ctx := c.Request().Context()
+	now := time.Now().UTC()
+	err := api.userRepo.UpdateLastLogin(c.Request().Context(), user.ID, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update last login",
+			"user_id", user.ID,
+			"error", err,
+		)
+	}
+	err = api.loginRepo.Create(&models.LoginEvent{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record login event",
+			"user_id", user.ID,
+			"error", err,
+		)
+	}
+	user.LastLoginAt = &now
+}
+
+// newAuthResponse reports the expiries written into the tokens, in the user's
+// time zone.
+func newAuthResponse(user *models.User, tokens *auth.TokenPair) AuthResponse {
+	return AuthResponse{
+		User:             toUserProfile(user),
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		TokenType:        tokens.TokenType,
+		ExpiresAt:        tokens.ExpiresAt.In(user.Location()),
+		RefreshExpiresAt: tokens.RefreshExpiresAt.In(user.Location()),
+	}
+}
+
+func toUserProfile(user *models.User) *UserProfile {
+	return &UserProfile{
+		ID:         user.ID,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Role:       user.Role,
+		Status:     user.Status,
+		BranchID:   user.BranchID,
+		CardNumber: user.CardNumber,
+		Timezone:   user.Timezone,
+	}
+}
@@ -0,0 +1,76 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/jobs"
+	"book-management-system/cmd/server_api/models"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BackupAPI takes and lists backups. They hold every member's password hash,
+// so only system admins may use it. Restoring is done from the command line.
+type BackupAPI struct {
+	backupJob *jobs.BackupJob
+	branches  *BranchAccess
+}
+
+// NewBackupAPI takes a nil backupJob when backups are disabled.
+func NewBackupAPI(backupJob *jobs.BackupJob, branches *BranchAccess) *BackupAPI {
+	return &BackupAPI{
+		backupJob: backupJob,
+		branches:  branches,
+	}
+}
+
+func (api *BackupAPI) Setup(group *echo.Group) {
+	group.POST("", api.runBackup)
+	group.GET("", api.getBackups)
+}
+
+func (api *BackupAPI) runBackup(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	if api.backupJob == nil {
+		return backupsDisabledError(c)
+	}
+	report, err := api.backupJob.Run(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running backup",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Backup completed successfully",
+	})
+}
+
+func (api *BackupAPI) getBackups(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	if api.backupJob == nil {
+		return backupsDisabledError(c)
+	}
+	backups, err := api.backupJob.List(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error listing backups",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    backups,
+		Message: "Backups retrieved successfully",
+	})
+}
+
+func backupsDisabledError(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, models.Response{
+		Message:   "Backups are not enabled",
+		ErrorCode: models.ErrCodeServiceUnavailable,
+	})
+}
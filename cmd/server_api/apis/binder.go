@@ -0,0 +1,89 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StrictJSONSerializer decodes request bodies with unknown fields and trailing
+// data rejected, so typos in field names fail loudly instead of being dropped,
+// and localizes response messages.
+type StrictJSONSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+func NewStrictJSONSerializer() *StrictJSONSerializer {
+	return &StrictJSONSerializer{}
+}
+
+// Serialize translates response messages into the negotiated language before
+// encoding; see Localize.
+func (s *StrictJSONSerializer) Serialize(c echo.Context, i any, indent string) error {
+	return s.DefaultJSONSerializer.Serialize(c, localize(c, i), indent)
+}
+
+func (s *StrictJSONSerializer) Deserialize(c echo.Context, i any) error {
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(i); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return errors.New("request body must contain a single JSON value")
+	}
+	return nil
+}
+
+func bindError(c echo.Context, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return c.JSON(http.StatusRequestEntityTooLarge, models.Response{
+			Message:   fmt.Sprintf("Request body exceeds %d bytes", maxBytesErr.Limit),
+			ErrorCode: models.ErrCodePayloadTooLarge,
+		})
+	}
+	response := models.Response{
+		Message:   "Invalid request format",
+		ErrorCode: models.ErrCodeInvalidRequest,
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Internal != nil {
+		err = httpErr.Internal
+	}
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		response.Errors = []models.FieldError{
+			{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: fmt.Sprintf("must be of type %s", typeErr.Type.String()),
+			},
+		}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		response.Errors = []models.FieldError{
+			{
+				Field:   strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`),
+				Rule:    "unknown",
+				Message: "is not a recognised field",
+			},
+		}
+	case errors.As(err, &syntaxErr):
+		response.Message = fmt.Sprintf("Malformed JSON at offset %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		response.Message = "Malformed JSON: unexpected end of body"
+	case err.Error() == "request body must contain a single JSON value":
+		response.Message = "Request body must contain a single JSON value"
+	case errors.Is(err, errMergePatchNotObject):
+		response.Message = "Merge patch must be a JSON object"
+	}
+	return c.JSON(http.StatusBadRequest, response)
+}
@@ -4,78 +4,104 @@ import (
 	"book-management-system/cmd/server_api/models"
 	"book-management-system/cmd/server_api/repositories"
 	"book-management-system/pkg/auth"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSearchTermLength = 200
+	// defaultNewArrivalDays and maxNewArrivalDays bound how far back
+	// new arrivals look.
+	defaultNewArrivalDays = 30
+	maxNewArrivalDays     = 365
 )
 
 type BookAPI struct {
-	bookRepo *repositories.BookRepository
-	authMw   *auth.Middleware
+	bookRepo       repositories.BookRepo
+	searchTermRepo *repositories.SearchTermRepository
+	authMw         *auth.Middleware
+	idempotency    *Idempotency
+	branches       *BranchAccess
 }
 
-func NewBookAPI(bookRepo *repositories.BookRepository, authMw *auth.Middleware) *BookAPI {
+func NewBookAPI(bookRepo repositories.BookRepo, searchTermRepo *repositories.SearchTermRepository, authMw *auth.Middleware, idempotency *Idempotency, branches *BranchAccess) *BookAPI {
 	return &BookAPI{
-		bookRepo: bookRepo,
-		authMw:   authMw,
+		bookRepo:       bookRepo,
+		searchTermRepo: searchTermRepo,
+		authMw:         authMw,
+		idempotency:    idempotency,
+		branches:       branches,
 	}
 }
 
 func (api *BookAPI) Setup(group *echo.Group) {
-	group.POST("", api.createBook, api.authMw.RequireAdmin())
+	group.POST("", api.createBook, api.authMw.RequireAuth(), api.authMw.RequireAdmin(), api.idempotency.Middleware())
 	group.GET("", api.getBooks)
 	group.GET("/:id", api.getBook)
 	group.GET("/search", api.searchBooks)
 	group.GET("/available", api.getAvailableBooks)
-	group.PUT("/:id", api.updateBook, api.authMw.RequireAdmin())
-	group.DELETE("/:id", api.deleteBook, api.authMw.RequireAdmin())
-	group.PUT("/:id/quantity", api.updateQuantity, api.authMw.RequireAdmin())
+	group.PUT("/:id", api.updateBook, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.PATCH("/:id", api.patchBook, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.DELETE("/:id", api.deleteBook, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.PUT("/:id/quantity", api.updateQuantity, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.POST("/import", api.importBooks, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+}
+
+// SetupPublic registers the read-only catalog routes the public OPAC uses.
+func (api *BookAPI) SetupPublic(group *echo.Group) {
+	group.GET("/search", api.searchBooks)
+	group.GET("/available", api.getAvailableBooks)
+	group.GET("/new", api.getNewArrivals)
+	group.GET("/:id", api.getBook)
+}
+
+// SetupKiosk registers catalog search under /kiosk/books for device tokens
+// with the search scope.
+func (api *BookAPI) SetupKiosk(group *echo.Group) {
+	group.GET("/search", api.searchBooks)
+	group.GET("/available", api.getAvailableBooks)
+	group.GET("/:id", api.getBook)
 }
 
 func (api *BookAPI) createBook(c echo.Context) error {
 	var req struct {
-		Title             string   `json:"title"`
-		Author            string   `json:"author"`
+		Title             string   `json:"title" validate:"required"`
+		Author            string   `json:"author" validate:"required"`
 		ISBN              *string  `json:"isbn"`
 		Publisher         *string  `json:"publisher"`
 		PublicationYear   *int     `json:"publication_year"`
 		Genre             *string  `json:"genre"`
 		Description       *string  `json:"description"`
 		Pages             *int     `json:"pages"`
-		Language          string   `json:"language"`
-		Price             *float64 `json:"price"`
-		Quantity          int      `json:"quantity"`
-		AvailableQuantity int      `json:"available_quantity"`
+		Language          string   `json:"language" validate:"required"`
+		Price             *float64 `json:"price" validate:"omitempty,min=0"`
+		Quantity          int      `json:"quantity" validate:"min=0"`
+		AvailableQuantity int      `json:"available_quantity" validate:"min=0"`
 		Location          *string  `json:"location"`
-		Status            string   `json:"status"`
+		CoverURL          *string  `json:"cover_url" validate:"omitempty,url"`
+		Status            string   `json:"status" validate:"required,oneof=active inactive on_order"`
+		LoanPolicy        string   `json:"loan_policy" validate:"omitempty,oneof=standard high_demand reference_only"`
+		MaxLoanDays       *int     `json:"max_loan_days" validate:"omitempty,min=1"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request body",
-		})
+		return bindError(c, err)
 	}
 
-	if req.Title == "" || req.Author == "" || req.Language == "" || req.Status == "" {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Title, author, language, and status are required",
-		})
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
 
-	if req.ISBN != nil && *req.ISBN != "" {
-		exists, err := api.bookRepo.ISBNExists(*req.ISBN)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, models.Response{
-				Message: "Failed to check ISBN existence",
-			})
-		}
-		if exists {
-			return c.JSON(http.StatusConflict, models.Response{
-				Message: "Book with this ISBN already exists",
-			})
-		}
+	if req.AvailableQuantity > req.Quantity {
+		return fieldValidationError(c, "available_quantity", "ltefield", "must not exceed quantity")
 	}
 
 	book := &models.Book{
@@ -93,15 +119,28 @@ func (api *BookAPI) createBook(c echo.Context) error {
 		Quantity:          req.Quantity,
 		AvailableQuantity: req.AvailableQuantity,
 		Location:          req.Location,
+		CoverURL:          req.CoverURL,
 		Status:            req.Status,
+		DigitalLoanLimit:  models.DefaultDigitalLoanLimit,
+		LoanPolicy:        req.LoanPolicy,
+		MaxLoanDays:       req.MaxLoanDays,
+	}
+	if book.LoanPolicy == "" {
+		book.LoanPolicy = models.BookLoanPolicyStandard
 	}
 
-	if err := api.bookRepo.Create(book); err != nil {
+	err := api.bookRepo.Create(c.Request().Context(), book)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return isbnExistsError(c)
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to create book",
+			Message:   "Failed to create book",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
+	auditRecord(c, "book", book.ID, nil, book)
 	return c.JSON(http.StatusCreated, models.Response{
 		Data:    book,
 		Message: "Book created successfully",
@@ -109,54 +148,56 @@ func (api *BookAPI) createBook(c echo.Context) error {
 }
 
 func (api *BookAPI) getBooks(c echo.Context) error {
-	limitStr := c.QueryParam("limit")
-	offsetStr := c.QueryParam("offset")
 	status := c.QueryParam("status")
 	genre := c.QueryParam("genre")
 	author := c.QueryParam("author")
-
-	limit := 20
-	offset := 0
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	minRating, ok := parseMinRating(c.QueryParam("min_rating"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "min_rating must be a number between 0 and 5",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "min_rating",
+					Message: "min_rating must be a number between 0 and 5",
+				},
+			},
+		})
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
 	}
 
-	var books []models.Book
-	var err error
-
-	if status != "" {
-		books, err = api.bookRepo.GetByStatus(status, limit, offset)
-	} else if genre != "" {
-		books, err = api.bookRepo.GetByGenre(genre, limit, offset)
-	} else if author != "" {
-		books, err = api.bookRepo.GetByAuthor(author, limit, offset)
-	} else {
-		books, err = api.bookRepo.GetAll(limit, offset)
+	bookRepo, err := api.branchRepo(c, false)
+	if err != nil {
+		return branchLookupError(c, err)
 	}
 
+	filter := repositories.BookFilter{
+		Status:    status,
+		Genre:     genre,
+		Author:    author,
+		MinRating: minRating,
+	}
+	books, err := bookRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to retrieve books",
+			Message:   "Failed to retrieve books",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
-	total, err := api.bookRepo.Count()
+	total, err := bookRepo.CountFiltered(c.Request().Context(), filter)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get book count",
+			Message:   "Failed to get book count",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
-	return c.JSON(http.StatusOK, models.Response{
+	return bookListResponse(c, books, models.Response{
 		Data: map[string]any{
 			"books":  books,
 			"total":  total,
@@ -171,18 +212,26 @@ func (api *BookAPI) getBook(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
 		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Book ID is required",
+			Message:   "Book ID is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "id",
+					Message: "Book ID is required",
+				},
+			},
 		})
 	}
 
-	book, err := api.bookRepo.GetByID(id)
+	book, err := api.bookRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, models.Response{
-			Message: "Book not found",
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
 		})
 	}
 
-	return c.JSON(http.StatusOK, models.Response{
+	return jsonWithETag(c, models.Response{
 		Data:    book,
 		Message: "Book retrieved successfully",
 	})
@@ -191,91 +240,106 @@ func (api *BookAPI) getBook(c echo.Context) error {
 func (api *BookAPI) searchBooks(c echo.Context) error {
 	query := c.QueryParam("q")
 	title := c.QueryParam("title")
-	limitStr := c.QueryParam("limit")
-	offsetStr := c.QueryParam("offset")
 
 	if query == "" && title == "" {
 		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Search query (q) or title parameter is required",
+			Message:   "Search query (q) or title parameter is required",
+			ErrorCode: models.ErrCodeValidation,
 		})
 	}
 
-	limit := 20
-	offset := 0
+	minRating, ok := parseMinRating(c.QueryParam("min_rating"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "min_rating must be a number between 0 and 5",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "min_rating",
+					Message: "min_rating must be a number between 0 and 5",
+				},
+			},
+		})
+	}
 
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	bookRepo, err := api.branchRepo(c, false)
+	if err != nil {
+		return branchLookupError(c, err)
 	}
 
 	var books []models.Book
-	var err error
 
 	if title != "" {
-		books, err = api.bookRepo.SearchByTitle(title, limit, offset)
+		books, err = bookRepo.SearchByTitle(c.Request().Context(), title, minRating, limit, offset)
 	} else {
-		books, err = api.bookRepo.SearchBooks(query, limit, offset)
+		books, err = bookRepo.SearchBooks(c.Request().Context(), query, minRating, limit, offset)
 	}
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to search books",
+			Message:   "Failed to search books",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	// Later pages repeat a search rather than make a new one. Only an
+	// empty search with no rating or branch filter shows that the catalog
+	// itself lacks the title.
+	if offset == 0 {
+		zeroResult := len(books) == 0 && minRating == 0 && c.QueryParam("branch_id") == ""
+		if title != "" {
+			api.recordSearch(c, title, zeroResult)
+		} else {
+			api.recordSearch(c, query, zeroResult)
+		}
+	}
 
-	return c.JSON(http.StatusOK, models.Response{
+	return bookListResponse(c, books, models.Response{
 		Data: map[string]any{
-			"books":  books,
-			"query":  query,
-			"title":  title,
-			"limit":  limit,
-			"offset": offset,
+			"books":      books,
+			"query":      query,
+			"title":      title,
+			"min_rating": minRating,
+			"limit":      limit,
+			"offset":     offset,
 		},
 		Message: "Books search completed successfully",
 	})
 }
 
 func (api *BookAPI) getAvailableBooks(c echo.Context) error {
-	limitStr := c.QueryParam("limit")
-	offsetStr := c.QueryParam("offset")
 
-	limit := 20
-	offset := 0
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	bookRepo, err := api.branchRepo(c, true)
+	if err != nil {
+		return branchLookupError(c, err)
 	}
 
-	books, err := api.bookRepo.GetAvailable(limit, offset)
+	books, err := bookRepo.GetAvailable(c.Request().Context(), limit, offset)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to retrieve available books",
+			Message:   "Failed to retrieve available books",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
-	count, err := api.bookRepo.CountAvailable()
+	count, err := bookRepo.CountAvailable(c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to get available book count",
+			Message:   "Failed to get available book count",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
-	return c.JSON(http.StatusOK, models.Response{
+	return bookListResponse(c, books, models.Response{
 		Data: map[string]any{
 			"books":  books,
 			"total":  count,
@@ -286,20 +350,92 @@ func (api *BookAPI) getAvailableBooks(c echo.Context) error {
 	})
 }
 
+// getNewArrivals lists the books added to the catalog in the last days days,
+// newest first.
+func (api *BookAPI) getNewArrivals(c echo.Context) error {
+	days := defaultNewArrivalDays
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxNewArrivalDays {
+			return c.JSON(http.StatusBadRequest, models.Response{
+				Message:   "days must be a whole number between 1 and 365",
+				ErrorCode: models.ErrCodeValidation,
+				Errors: []models.FieldError{
+					{
+						Field:   "days",
+						Message: "days must be a whole number between 1 and 365",
+					},
+				},
+			})
+		}
+		days = parsed
+	}
+
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+
+	bookRepo, err := api.branchRepo(c, false)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+
+	filter := repositories.BookFilter{
+		Genre:      c.QueryParam("genre"),
+		AddedSince: time.Now().UTC().AddDate(0, 0, -days),
+	}
+	books, err := bookRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to retrieve books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	total, err := bookRepo.CountFiltered(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to get book count",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	return bookListResponse(c, books, models.Response{
+		Data: map[string]any{
+			"books":  books,
+			"total":  total,
+			"days":   days,
+			"limit":  limit,
+			"offset": offset,
+		},
+		Message: "New arrivals retrieved successfully",
+	})
+}
+
 func (api *BookAPI) updateBook(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
 		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Book ID is required",
+			Message:   "Book ID is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "id",
+					Message: "Book ID is required",
+				},
+			},
 		})
 	}
 
-	book, err := api.bookRepo.GetByID(id)
+	book, err := api.bookRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, models.Response{
-			Message: "Book not found",
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
 		})
 	}
+	before := *book
 
 	var req struct {
 		Title             *string  `json:"title"`
@@ -311,31 +447,28 @@ func (api *BookAPI) updateBook(c echo.Context) error {
 		Description       *string  `json:"description"`
 		Pages             *int     `json:"pages"`
 		Language          *string  `json:"language"`
-		Price             *float64 `json:"price"`
-		Quantity          *int     `json:"quantity"`
-		AvailableQuantity *int     `json:"available_quantity"`
+		Price             *float64 `json:"price" validate:"omitempty,min=0"`
+		Quantity          *int     `json:"quantity" validate:"omitempty,min=0"`
+		AvailableQuantity *int     `json:"available_quantity" validate:"omitempty,min=0"`
 		Location          *string  `json:"location"`
-		Status            *string  `json:"status"`
+		CoverURL          *string  `json:"cover_url" validate:"omitempty,url"`
+		Status            *string  `json:"status" validate:"omitempty,oneof=active inactive on_order"`
+		DigitalLoanLimit  *int     `json:"digital_loan_limit" validate:"omitempty,min=0"`
+		LoanPolicy        *string  `json:"loan_policy" validate:"omitempty,oneof=standard high_demand reference_only"`
+		MaxLoanDays       *int     `json:"max_loan_days" validate:"omitempty,min=1"`
+		Version           *int     `json:"version"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request body",
-		})
+		return bindError(c, err)
 	}
 
-	if req.ISBN != nil && *req.ISBN != "" && *req.ISBN != *book.ISBN {
-		exists, err := api.bookRepo.ISBNExists(*req.ISBN)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"message": "Failed to check ISBN existence",
-			})
-		}
-		if exists {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"message": "Book with this ISBN already exists",
-			})
-		}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+
+	if req.Version != nil && *req.Version != book.Version {
+		return bookVersionConflict(c)
 	}
 
 	if req.Title != nil {
@@ -377,16 +510,146 @@ func (api *BookAPI) updateBook(c echo.Context) error {
 	if req.Location != nil {
 		book.Location = req.Location
 	}
+	if req.CoverURL != nil {
+		book.CoverURL = req.CoverURL
+	}
 	if req.Status != nil {
 		book.Status = *req.Status
 	}
+	if req.DigitalLoanLimit != nil {
+		book.DigitalLoanLimit = *req.DigitalLoanLimit
+	}
+	if req.LoanPolicy != nil {
+		book.LoanPolicy = *req.LoanPolicy
+	}
+	if req.MaxLoanDays != nil {
+		book.MaxLoanDays = req.MaxLoanDays
+	}
+
+	return api.saveBook(c, before, book)
+}
+
+// bookDocument is the editable part of a book, which PATCH /books/:id merges
+// the request into.
+type bookDocument struct {
+	Title             string   `json:"title" validate:"required"`
+	Author            string   `json:"author" validate:"required"`
+	ISBN              *string  `json:"isbn"`
+	Publisher         *string  `json:"publisher"`
+	PublicationYear   *int     `json:"publication_year"`
+	Genre             *string  `json:"genre"`
+	Description       *string  `json:"description"`
+	Pages             *int     `json:"pages"`
+	Language          string   `json:"language" validate:"required"`
+	Price             *float64 `json:"price" validate:"omitempty,min=0"`
+	Quantity          *int     `json:"quantity" validate:"required,min=0"`
+	AvailableQuantity *int     `json:"available_quantity" validate:"required,min=0"`
+	Location          *string  `json:"location"`
+	CoverURL          *string  `json:"cover_url" validate:"omitempty,url"`
+	Status            string   `json:"status" validate:"required,oneof=active inactive on_order"`
+	DigitalLoanLimit  *int     `json:"digital_loan_limit" validate:"required,min=0"`
+	LoanPolicy        string   `json:"loan_policy" validate:"required,oneof=standard high_demand reference_only"`
+	MaxLoanDays       *int     `json:"max_loan_days" validate:"omitempty,min=1"`
+	// Version is never part of the stored document; a patch that sets it
+	// must match the book's current version.
+	Version *int `json:"version,omitempty"`
+}
+
+// patchBook applies a JSON Merge Patch. Unlike updateBook it can clear an
+// optional field by patching it to null.
+func (api *BookAPI) patchBook(c echo.Context) error {
+	book, err := api.bookRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
+		})
+	}
+	before := *book
+
+	current := bookDocument{
+		Title:             book.Title,
+		Author:            book.Author,
+		ISBN:              book.ISBN,
+		Publisher:         book.Publisher,
+		PublicationYear:   book.PublicationYear,
+		Genre:             book.Genre,
+		Description:       book.Description,
+		Pages:             book.Pages,
+		Language:          book.Language,
+		Price:             book.Price,
+		Quantity:          &book.Quantity,
+		AvailableQuantity: &book.AvailableQuantity,
+		Location:          book.Location,
+		CoverURL:          book.CoverURL,
+		Status:            book.Status,
+		DigitalLoanLimit:  &book.DigitalLoanLimit,
+		LoanPolicy:        book.LoanPolicy,
+		MaxLoanDays:       book.MaxLoanDays,
+	}
+	var doc bookDocument
+	if err := bindMergePatch(c, current, &doc); err != nil {
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return err
+		}
+		return bindError(c, err)
+	}
 
-	if err := api.bookRepo.Update(book); err != nil {
+	if err := c.Validate(&doc); err != nil {
+		return validationError(c, err)
+	}
+
+	if doc.Version != nil && *doc.Version != book.Version {
+		return bookVersionConflict(c)
+	}
+
+	book.Title = doc.Title
+	book.Author = doc.Author
+	book.ISBN = doc.ISBN
+	book.Publisher = doc.Publisher
+	book.PublicationYear = doc.PublicationYear
+	book.Genre = doc.Genre
+	book.Description = doc.Description
+	book.Pages = doc.Pages
+	book.Language = doc.Language
+	book.Price = doc.Price
+	book.Quantity = *doc.Quantity
+	book.AvailableQuantity = *doc.AvailableQuantity
+	book.Location = doc.Location
+	book.CoverURL = doc.CoverURL
+	book.Status = doc.Status
+	book.DigitalLoanLimit = *doc.DigitalLoanLimit
+	book.LoanPolicy = doc.LoanPolicy
+	book.MaxLoanDays = doc.MaxLoanDays
+
+	return api.saveBook(c, before, book)
+}
+
+// saveBook saves a book edited by PUT or PATCH. The quantities are checked
+// here, once the edit is applied, since either may come from the request or
+// the stored book.
+func (api *BookAPI) saveBook(c echo.Context, before models.Book, book *models.Book) error {
+	if book.AvailableQuantity > book.Quantity {
+		return fieldValidationError(c, "available_quantity", "ltefield", "must not exceed quantity")
+	}
+	err := api.bookRepo.Update(c.Request().Context(), book)
+	if err == repositories.ErrVersionConflict {
+		return bookVersionConflict(c)
+	}
+	if err == repositories.ErrBookHasHoldings {
+		return bookHasHoldingsError(c)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return isbnExistsError(c)
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to update book",
+			Message:   "Failed to update book",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
+	auditRecord(c, "book", book.ID, before, book)
 	return c.JSON(http.StatusOK, models.Response{
 		Data:    book,
 		Message: "Book updated successfully",
@@ -397,23 +660,33 @@ func (api *BookAPI) deleteBook(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
 		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Book ID is required",
+			Message:   "Book ID is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "id",
+					Message: "Book ID is required",
+				},
+			},
 		})
 	}
 
-	_, err := api.bookRepo.GetByID(id)
+	book, err := api.bookRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, models.Response{
-			Message: "Book not found",
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
 		})
 	}
 
-	if err := api.bookRepo.Delete(id); err != nil {
+	if err := api.bookRepo.Delete(c.Request().Context(), id); err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to delete book",
+			Message:   "Failed to delete book",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
+	auditRecord(c, "book", id, book, nil)
 	return c.JSON(http.StatusOK, models.Response{
 		Data:    map[string]string{"id": id},
 		Message: "Book deleted successfully",
@@ -424,55 +697,136 @@ func (api *BookAPI) updateQuantity(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
 		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Book ID is required",
+			Message:   "Book ID is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "id",
+					Message: "Book ID is required",
+				},
+			},
 		})
 	}
 
 	var req struct {
-		Quantity          int `json:"quantity"`
-		AvailableQuantity int `json:"available_quantity"`
+		Quantity          int `json:"quantity" validate:"min=0"`
+		AvailableQuantity int `json:"available_quantity" validate:"min=0"`
 	}
 
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request body",
-		})
+		return bindError(c, err)
 	}
 
-	if req.Quantity < 0 || req.AvailableQuantity < 0 {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Quantities cannot be negative",
-		})
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
 
 	if req.AvailableQuantity > req.Quantity {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Available quantity cannot exceed total quantity",
-		})
+		return fieldValidationError(c, "available_quantity", "ltefield", "must not exceed quantity")
 	}
 
-	_, err := api.bookRepo.GetByID(id)
+	before, err := api.bookRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, models.Response{
-			Message: "Book not found",
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
 		})
 	}
 
-	if err := api.bookRepo.UpdateQuantity(id, req.Quantity, req.AvailableQuantity); err != nil {
+	err = api.bookRepo.UpdateQuantity(c.Request().Context(), id, req.Quantity, req.AvailableQuantity)
+	if err == repositories.ErrBookHasHoldings {
+		return bookHasHoldingsError(c)
+	}
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
+		})
+	}
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to update book quantity",
+			Message:   "Failed to update book quantity",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
-	book, err := api.bookRepo.GetByID(id)
+	book, err := api.bookRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Failed to retrieve updated book",
+			Message:   "Failed to retrieve updated book",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 
+	auditRecord(c, "book", id, before, book)
 	return c.JSON(http.StatusOK, models.Response{
 		Data:    book,
 		Message: "Book quantity updated successfully",
 	})
 }
+
+func isbnExistsError(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "Book with this ISBN already exists",
+		ErrorCode: models.ErrCodeISBNExists,
+	})
+}
+
+func bookVersionConflict(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "Book was changed by another request; reload it and try again",
+		ErrorCode: models.ErrCodeVersionConflict,
+	})
+}
+
+func bookHasHoldingsError(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "Book quantity is managed per branch; update its holdings instead",
+		ErrorCode: models.ErrCodeBookHasHoldings,
+	})
+}
+
+// recordSearch counts a search towards the search term and demand
+// reports. Only the normalized query is kept, never who searched. Failing to
+// record it does not fail the search.
+func (api *BookAPI) recordSearch(c echo.Context, query string, zeroResult bool) {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if runes := []rune(query); len(runes) > maxSearchTermLength {
+		query = string(runes[:maxSearchTermLength])
+	}
+	if query == "" {
+		return
+	}
+	if err := api.searchTermRepo.Record(c.Request().Context(), query, zeroResult); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to record search term",
+			"error", err,
+		)
+	}
+}
+
+// branchRepo narrows book listings to the branch_id query parameter, if
+// given. Local branches the caller cannot see are reported as not found.
+func (api *BookAPI) branchRepo(c echo.Context, available bool) (repositories.BookRepo, error) {
+	branchID := c.QueryParam("branch_id")
+	if branchID == "" {
+		return api.bookRepo, nil
+	}
+	if _, err := api.branches.VisibleBranch(c, branchID); err != nil {
+		return nil, err
+	}
+	if available {
+		return api.bookRepo.AvailableAtBranch(branchID), nil
+	}
+	return api.bookRepo.AtBranch(branchID), nil
+}
+
+func parseMinRating(value string) (float64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	minRating, err := strconv.ParseFloat(value, 64)
+	if err != nil || minRating < 0 || minRating > 5 {
+		return 0, false
+	}
+	return minRating, true
+}
@@ -0,0 +1,223 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/jobs"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/objectstore"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// BookCoverAPI serves book covers and imports them in bulk from a CSV of
+// ISBNs and image URLs, for onboarding a catalog.
+type BookCoverAPI struct {
+	coverRepo *repositories.BookCoverRepository
+	bookRepo  repositories.BookRepo
+	store     objectstore.Store
+	importJob *jobs.CoverImportJob
+}
+
+// NewBookCoverAPI takes a nil store and importJob when cover storage is
+// disabled; covers are then always served from the book's cover_url.
+func NewBookCoverAPI(coverRepo *repositories.BookCoverRepository, bookRepo repositories.BookRepo, store objectstore.Store, importJob *jobs.CoverImportJob) *BookCoverAPI {
+	return &BookCoverAPI{
+		coverRepo: coverRepo,
+		bookRepo:  bookRepo,
+		store:     store,
+		importJob: importJob,
+	}
+}
+
+// SetupCover registers a book's cover image under /books.
+func (api *BookCoverAPI) SetupCover(group *echo.Group) {
+	group.GET("/:id/cover", api.getCover)
+}
+
+// SetupImport registers the cover import under /admin/books.
+func (api *BookCoverAPI) SetupImport(group *echo.Group) {
+	group.POST("/covers/import", api.importCovers)
+	group.GET("/covers/import", api.getImport)
+}
+
+// getCover serves the stored copy of the book's cover while it was fetched
+// from the book's current cover_url, and redirects to cover_url otherwise.
+func (api *BookCoverAPI) getCover(c echo.Context) error {
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	if book.CoverURL == nil || *book.CoverURL == "" {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book has no cover",
+			ErrorCode: models.ErrCodeBookCoverNotFound,
+		})
+	}
+	if api.store != nil {
+		cover, err := api.coverRepo.GetByBook(ctx, book.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error retrieving book cover",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		if cover != nil && cover.SourceURL == *book.CoverURL {
+			body, err := api.store.Get(ctx, cover.ObjectKey)
+			if err == nil {
+				defer body.Close()
+				return c.Stream(http.StatusOK, cover.ContentType, body)
+			}
+			// The source still has the image, so a lost copy is no reason
+			// to fail.
+			slog.WarnContext(ctx, "Failed to read stored book cover",
+				"book_id", book.ID,
+				"error", err,
+			)
+		}
+	}
+	return c.Redirect(http.StatusFound, *book.CoverURL)
+}
+
+// importCovers starts importing the uploaded CSV in the background. The CSV
+// has a header row with isbn and url columns; every row must have an ISBN
+// and an http or https URL, or nothing is imported. Progress is at
+// GET /admin/books/covers/import.
+func (api *BookCoverAPI) importCovers(c echo.Context) error {
+	if api.importJob == nil {
+		return c.JSON(http.StatusServiceUnavailable, models.Response{
+			Message:   "Cover storage is not enabled",
+			ErrorCode: models.ErrCodeServiceUnavailable,
+		})
+	}
+	body, err := csvUpload(c)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return fieldValidationError(c, "file", "required", "is required")
+		}
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return err
+		}
+		return bindError(c, err)
+	}
+	defer body.Close()
+	entries, err := parseCoverImport(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		var parseErr *csv.ParseError
+		if errors.As(err, &maxBytesErr) {
+			return bindError(c, err)
+		}
+		if errors.As(err, &parseErr) {
+			return fieldValidationError(c, "file", "cover_import", "must be a CSV with isbn and url columns")
+		}
+		return fieldValidationError(c, "file", "cover_import", err.Error())
+	}
+
+	report, started := api.importJob.Trigger(entries)
+	if !started {
+		return c.JSON(http.StatusConflict, models.Response{
+			Data:      report,
+			Message:   "Cover import is already running",
+			ErrorCode: models.ErrCodeJobRunning,
+		})
+	}
+	return c.JSON(http.StatusAccepted, models.Response{
+		Data:    report,
+		Message: "Cover import started",
+	})
+}
+
+// getImport reports the current or last import, with null data before the
+// first import.
+func (api *BookCoverAPI) getImport(c echo.Context) error {
+	if api.importJob == nil {
+		return c.JSON(http.StatusServiceUnavailable, models.Response{
+			Message:   "Cover storage is not enabled",
+			ErrorCode: models.ErrCodeServiceUnavailable,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    api.importJob.Status(),
+		Message: "Cover import status retrieved successfully",
+	})
+}
+
+// parseCoverImport reads the rows of a cover import. Errors other than CSV
+// syntax errors describe what is wrong with the file, for the client.
+func parseCoverImport(r io.Reader) ([]jobs.CoverImportEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("must not be empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	isbnColumn, urlColumn := -1, -1
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "isbn":
+			isbnColumn = i
+		case "url":
+			urlColumn = i
+		}
+	}
+	if isbnColumn < 0 || urlColumn < 0 {
+		return nil, errors.New("must be a CSV with isbn and url columns")
+	}
+
+	var entries []jobs.CoverImportEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		entry := jobs.CoverImportEntry{Line: line}
+		if isbnColumn < len(record) {
+			entry.ISBN = strings.TrimSpace(record[isbnColumn])
+		}
+		if urlColumn < len(record) {
+			entry.URL = strings.TrimSpace(record[urlColumn])
+		}
+		if entry.ISBN == "" && entry.URL == "" {
+			continue
+		}
+		if entry.ISBN == "" {
+			return nil, fmt.Errorf("line %d has no ISBN", line)
+		}
+		if !isHTTPURL(entry.URL) {
+			return nil, fmt.Errorf("line %d must have an http or https URL", line)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("must have at least one row")
+	}
+	return entries, nil
+}
+
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,330 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	enrichmentFieldDescription = "description"
+	enrichmentFieldPages       = "pages"
+	enrichmentFieldCoverURL    = "cover_url"
+)
+
+// BookEnrichmentAPI lets admins review the metadata the enrichment job
+// found before it reaches the catalog.
+type BookEnrichmentAPI struct {
+	enrichmentRepo *repositories.BookEnrichmentRepository
+	bookRepo       repositories.BookRepo
+	authMw         *auth.Middleware
+}
+
+// ApplyBookEnrichmentRequest picks the proposed fields to apply. Without
+// fields, every proposed field the book is still missing is applied, so a
+// value an admin entered since the lookup is kept.
+type ApplyBookEnrichmentRequest struct {
+	Fields []string `json:"fields,omitempty" validate:"omitempty,dive,oneof=description pages cover_url"`
+}
+
+type BookEnrichmentDetail struct {
+	ID           string             `json:"id"`
+	BookID       string             `json:"book_id"`
+	BookTitle    string             `json:"book_title"`
+	Status       string             `json:"status"`
+	Changes      []EnrichmentChange `json:"changes"`
+	ReviewedBy   *string            `json:"reviewed_by"`
+	ReviewedDate *time.Time         `json:"reviewed_date"`
+	CreatedDate  time.Time          `json:"created_date"`
+}
+
+// EnrichmentChange is one line of the review diff: the book's value now,
+// null when missing, against the value a catalog proposed.
+type EnrichmentChange struct {
+	Field    string `json:"field"`
+	Current  any    `json:"current"`
+	Proposed any    `json:"proposed"`
+	Source   string `json:"source"`
+}
+
+type BookEnrichmentListResponse struct {
+	Enrichments []BookEnrichmentDetail `json:"enrichments"`
+	Total       int64                  `json:"total"`
+	Limit       int                    `json:"limit"`
+	Offset      int                    `json:"offset"`
+}
+
+func NewBookEnrichmentAPI(enrichmentRepo *repositories.BookEnrichmentRepository, bookRepo repositories.BookRepo, authMw *auth.Middleware) *BookEnrichmentAPI {
+	return &BookEnrichmentAPI{
+		enrichmentRepo: enrichmentRepo,
+		bookRepo:       bookRepo,
+		authMw:         authMw,
+	}
+}
+
+func (api *BookEnrichmentAPI) Setup(group *echo.Group) {
+	group.GET("", api.getEnrichments)
+	group.GET("/:id", api.getEnrichment)
+	group.POST("/:id/apply", api.applyEnrichment)
+	group.POST("/:id/reject", api.rejectEnrichment)
+}
+
+// getEnrichments lists pending enrichments unless status asks for others.
+func (api *BookEnrichmentAPI) getEnrichments(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "":
+		status = models.EnrichmentStatusPending
+	case models.EnrichmentStatusPending, models.EnrichmentStatusApplied, models.EnrichmentStatusRejected, models.EnrichmentStatusNoMatch:
+	default:
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "status",
+					Message: "must be one of: pending, applied, rejected, no_match",
+				},
+			},
+		})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	ctx := c.Request().Context()
+	enrichments, err := api.enrichmentRepo.GetAll(ctx, status, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving enrichments",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.enrichmentRepo.Count(ctx, status)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting enrichments",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]BookEnrichmentDetail, len(enrichments))
+	for i := range enrichments {
+		details[i], err = api.enrichmentDetail(ctx, &enrichments[i], userLocation(c))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error retrieving book",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: BookEnrichmentListResponse{
+			Enrichments: details,
+			Total:       total,
+			Limit:       limit,
+			Offset:      offset,
+		},
+		Message: "Enrichments retrieved successfully",
+	})
+}
+
+func (api *BookEnrichmentAPI) getEnrichment(c echo.Context) error {
+	enrichment, err := api.enrichmentRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return enrichmentLookupError(c, err)
+	}
+	detail, err := api.enrichmentDetail(c.Request().Context(), enrichment, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Enrichment retrieved successfully",
+	})
+}
+
+func (api *BookEnrichmentAPI) applyEnrichment(c echo.Context) error {
+	var req ApplyBookEnrichmentRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	ctx := c.Request().Context()
+	enrichment, err := api.enrichmentRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return enrichmentLookupError(c, err)
+	}
+	if enrichment.Status != models.EnrichmentStatusPending {
+		return enrichmentReviewedError(c)
+	}
+	book, err := api.bookRepo.GetByID(ctx, enrichment.BookID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Book not found",
+				ErrorCode: models.ErrCodeBookNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	before := *book
+
+	selected := make(map[string]bool, len(req.Fields))
+	for _, field := range req.Fields {
+		selected[field] = true
+	}
+	apply := func(field string, missing bool) bool {
+		if len(selected) > 0 {
+			return selected[field]
+		}
+		return missing
+	}
+	if enrichment.Description != nil && apply(enrichmentFieldDescription, book.Description == nil || *book.Description == "") {
+		book.Description = enrichment.Description
+	}
+	if enrichment.Pages != nil && apply(enrichmentFieldPages, book.Pages == nil) {
+		book.Pages = enrichment.Pages
+	}
+	if enrichment.CoverURL != nil && apply(enrichmentFieldCoverURL, book.CoverURL == nil || *book.CoverURL == "") {
+		book.CoverURL = enrichment.CoverURL
+	}
+
+	reviewerID := api.authMw.GetUserFromContext(c).UserID
+	err = api.enrichmentRepo.Apply(ctx, enrichment, book, reviewerID)
+	if errors.Is(err, repositories.ErrEnrichmentReviewed) {
+		return enrichmentReviewedError(c)
+	}
+	if err == repositories.ErrVersionConflict {
+		return bookVersionConflict(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error applying enrichment",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	auditRecord(c, "book", book.ID, before, book)
+	detail, err := api.enrichmentDetail(ctx, enrichment, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Enrichment applied successfully",
+	})
+}
+
+func (api *BookEnrichmentAPI) rejectEnrichment(c echo.Context) error {
+	ctx := c.Request().Context()
+	enrichment, err := api.enrichmentRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return enrichmentLookupError(c, err)
+	}
+	err = api.enrichmentRepo.Reject(ctx, enrichment, api.authMw.GetUserFromContext(c).UserID)
+	if errors.Is(err, repositories.ErrEnrichmentReviewed) {
+		return enrichmentReviewedError(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error rejecting enrichment",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	detail, err := api.enrichmentDetail(ctx, enrichment, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Enrichment rejected successfully",
+	})
+}
+
+// enrichmentDetail diffs the enrichment against the book as it is now. A
+// deleted book shows every current value as null.
+func (api *BookEnrichmentAPI) enrichmentDetail(ctx context.Context, enrichment *models.BookEnrichment, location *time.Location) (BookEnrichmentDetail, error) {
+	book, err := api.bookRepo.GetByID(ctx, enrichment.BookID)
+	if err == gorm.ErrRecordNotFound {
+		book, err = &models.Book{}, nil
+	}
+	if err != nil {
+		return BookEnrichmentDetail{}, err
+	}
+	detail := BookEnrichmentDetail{
+		ID:           enrichment.ID,
+		BookID:       enrichment.BookID,
+		BookTitle:    book.Title,
+		Status:       enrichment.Status,
+		Changes:      []EnrichmentChange{},
+		ReviewedBy:   enrichment.ReviewedBy,
+		ReviewedDate: timeIn(enrichment.ReviewedDate, location),
+		CreatedDate:  enrichment.CreatedDate.In(location),
+	}
+	if enrichment.Description != nil {
+		detail.Changes = append(detail.Changes, EnrichmentChange{
+			Field:    enrichmentFieldDescription,
+			Current:  book.Description,
+			Proposed: *enrichment.Description,
+			Source:   stringValue(enrichment.DescriptionSource),
+		})
+	}
+	if enrichment.Pages != nil {
+		detail.Changes = append(detail.Changes, EnrichmentChange{
+			Field:    enrichmentFieldPages,
+			Current:  book.Pages,
+			Proposed: *enrichment.Pages,
+			Source:   stringValue(enrichment.PagesSource),
+		})
+	}
+	if enrichment.CoverURL != nil {
+		detail.Changes = append(detail.Changes, EnrichmentChange{
+			Field:    enrichmentFieldCoverURL,
+			Current:  book.CoverURL,
+			Proposed: *enrichment.CoverURL,
+			Source:   stringValue(enrichment.CoverURLSource),
+		})
+	}
+	return detail, nil
+}
+
+func enrichmentLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Enrichment not found",
+			ErrorCode: models.ErrCodeEnrichmentNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving enrichment",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func enrichmentReviewedError(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "Enrichment has already been reviewed",
+		ErrorCode: models.ErrCodeEnrichmentReviewed,
+	})
+}
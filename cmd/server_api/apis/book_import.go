@@ -0,0 +1,232 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// bookImportBatchSize is how many imported books go into one INSERT.
+const bookImportBatchSize = 500
+
+// bookImportRequiredColumns must be in the header of an import file. The
+// other columns of the CSV export are optional, and id and rating columns
+// are ignored, so an export can be imported into another catalog.
+var bookImportRequiredColumns = []string{"title", "author", "language", "quantity"}
+
+// bookImportRow is one line of an import file, validated like the body of
+// POST /books.
+type bookImportRow struct {
+	Title             string  `json:"title" validate:"required"`
+	Author            string  `json:"author" validate:"required"`
+	ISBN              *string `json:"isbn"`
+	Publisher         *string `json:"publisher"`
+	PublicationYear   *int    `json:"publication_year"`
+	Genre             *string `json:"genre"`
+	Language          string  `json:"language" validate:"required"`
+	Quantity          int     `json:"quantity" validate:"min=0"`
+	AvailableQuantity int     `json:"available_quantity" validate:"min=0"`
+	Location          *string `json:"location"`
+	Status            string  `json:"status" validate:"oneof=active inactive on_order"`
+}
+
+type BookImportReport struct {
+	Rows    int   `json:"rows"`
+	Created int64 `json:"created"`
+	Skipped int64 `json:"skipped"`
+}
+
+// importBooks adds the books in a CSV file to the catalog. Every row is
+// checked before anything is written, so a file with errors imports
+// nothing. Rows whose ISBN is already in the catalog, or earlier in the
+// file, are skipped.
+func (api *BookAPI) importBooks(c echo.Context) error {
+	body, err := csvUpload(c)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return fieldValidationError(c, "file", "required", "is required")
+		}
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return err
+		}
+		return bindError(c, err)
+	}
+	defer body.Close()
+
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err != nil && err != io.EOF {
+		return bookImportReadError(c, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range bookImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return fieldValidationError(c, "file", "book_import", "must be a CSV file with title, author, language and quantity columns")
+		}
+	}
+
+	var books []models.Book
+	var fieldErrors []models.FieldError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return bookImportReadError(c, err)
+		}
+		line, _ := reader.FieldPos(0)
+		row, rowErrors := parseBookImportRow(record, columns)
+		var validationErrors validator.ValidationErrors
+		if errors.As(c.Validate(row), &validationErrors) {
+			for _, fieldErr := range validationErrors {
+				rowErrors = append(rowErrors, models.FieldError{
+					Field:   fieldErr.Field(),
+					Rule:    fieldErr.Tag(),
+					Message: fieldErrorMessage(fieldErr),
+				})
+			}
+		}
+		for _, fieldErr := range rowErrors {
+			fieldErr.Field = fmt.Sprintf("rows[%d].%s", line, fieldErr.Field)
+			fieldErrors = append(fieldErrors, fieldErr)
+		}
+		if len(rowErrors) > 0 {
+			continue
+		}
+		books = append(books, models.Book{
+			ID:                uuid.New().String(),
+			Title:             row.Title,
+			Author:            row.Author,
+			ISBN:              row.ISBN,
+			Publisher:         row.Publisher,
+			PublicationYear:   row.PublicationYear,
+			Genre:             row.Genre,
+			Language:          row.Language,
+			Quantity:          row.Quantity,
+			AvailableQuantity: row.AvailableQuantity,
+			Location:          row.Location,
+			Status:            row.Status,
+			DigitalLoanLimit:  models.DefaultDigitalLoanLimit,
+			LoanPolicy:        models.BookLoanPolicyStandard,
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+
+	created, err := api.bookRepo.CreateBatch(c.Request().Context(), books, bookImportBatchSize)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error importing books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: BookImportReport{
+			Rows:    len(books),
+			Created: created,
+			Skipped: int64(len(books)) - created,
+		},
+		Message: "Books imported successfully",
+	})
+}
+
+// parseBookImportRow reads a record into a row. Empty optional cells are
+// left nil, an empty available_quantity means all copies are available and
+// an empty status means active.
+func parseBookImportRow(record []string, columns map[string]int) (*bookImportRow, []models.FieldError) {
+	cell := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	optional := func(name string) *string {
+		if value := cell(name); value != "" {
+			return &value
+		}
+		return nil
+	}
+	var fieldErrors []models.FieldError
+	number := func(name string) *int {
+		value := cell(name)
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   name,
+				Rule:    "number",
+				Message: "must be a whole number",
+			})
+			return nil
+		}
+		return &n
+	}
+
+	row := &bookImportRow{
+		Title:           cell("title"),
+		Author:          cell("author"),
+		ISBN:            optional("isbn"),
+		Publisher:       optional("publisher"),
+		PublicationYear: number("publication_year"),
+		Genre:           optional("genre"),
+		Language:        cell("language"),
+		Location:        optional("location"),
+		Status:          cell("status"),
+	}
+	if quantity := number("quantity"); quantity != nil {
+		row.Quantity = *quantity
+		row.AvailableQuantity = *quantity
+	} else if cell("quantity") == "" {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "quantity",
+			Rule:    "required",
+			Message: "is required",
+		})
+	}
+	if available := number("available_quantity"); available != nil {
+		row.AvailableQuantity = *available
+		if *available > row.Quantity {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "available_quantity",
+				Rule:    "ltefield",
+				Message: "must not exceed quantity",
+			})
+		}
+	}
+	if row.Status == "" {
+		row.Status = models.BookStatusActive
+	}
+	return row, fieldErrors
+}
+
+func bookImportReadError(c echo.Context, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return bindError(c, err)
+	}
+	return fieldValidationError(c, "file", "book_import", "must be a CSV file with title, author, language and quantity columns")
+}
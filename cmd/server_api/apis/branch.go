@@ -0,0 +1,703 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var errBranchAccessDenied = errors.New("branch access denied")
+
+// BranchAccess resolves the caller's branch for visibility rules and
+// branch-scoped staff. An admin without a branch is a system admin; an admin
+// with one is staff of that branch only.
+type BranchAccess struct {
+	branchRepo *repositories.BranchRepository
+	userRepo   repositories.UserRepo
+	authMw     *auth.Middleware
+}
+
+type BranchAPI struct {
+	branchRepo   *repositories.BranchRepository
+	bookRepo     repositories.BookRepo
+	transferRepo *repositories.TransferRepository
+	branches     *BranchAccess
+	authMw       *auth.Middleware
+}
+
+type CreateBranchRequest struct {
+	Code       string  `json:"code" validate:"required,max=50"`
+	Name       string  `json:"name" validate:"required,max=100"`
+	Address    *string `json:"address,omitempty"`
+	Visibility string  `json:"visibility" validate:"omitempty,oneof=network local"`
+}
+
+type UpdateBranchRequest struct {
+	Name       *string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Address    *string `json:"address,omitempty"`
+	Visibility *string `json:"visibility,omitempty" validate:"omitempty,oneof=network local"`
+}
+
+type SetHoldingRequest struct {
+	Quantity          int     `json:"quantity" validate:"min=0"`
+	AvailableQuantity int     `json:"available_quantity" validate:"min=0,ltefield=Quantity"`
+	Location          *string `json:"location,omitempty"`
+}
+
+type BranchDetail struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Address     *string   `json:"address"`
+	Visibility  string    `json:"visibility"`
+	CreatedDate time.Time `json:"created_date"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+type HoldingDetail struct {
+	BranchID          string    `json:"branch_id"`
+	BranchCode        string    `json:"branch_code"`
+	BranchName        string    `json:"branch_name"`
+	Quantity          int       `json:"quantity"`
+	AvailableQuantity int       `json:"available_quantity"`
+	InTransitQuantity int       `json:"in_transit_quantity"`
+	RequestedQuantity int       `json:"requested_quantity"`
+	Location          *string   `json:"location"`
+	UpdatedDate       time.Time `json:"updated_date"`
+}
+
+func NewBranchAccess(branchRepo *repositories.BranchRepository, userRepo repositories.UserRepo, authMw *auth.Middleware) *BranchAccess {
+	return &BranchAccess{
+		branchRepo: branchRepo,
+		userRepo:   userRepo,
+		authMw:     authMw,
+	}
+}
+
+// Viewer returns the calling user, or nil for anonymous requests. Public
+// routes have no RequireAuth, so an optional bearer token is checked here.
+func (a *BranchAccess) Viewer(c echo.Context) (*models.User, error) {
+	userID := ""
+	if claims := a.authMw.GetUserFromContext(c); claims != nil {
+		userID = claims.UserID
+	} else {
+		userID = a.authMw.UserID(c)
+	}
+	if userID == "" {
+		return nil, nil
+	}
+	user, err := a.userRepo.GetByID(c.Request().Context(), userID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return user, err
+}
+
+// StaffBranchID returns the branch a branch-scoped admin manages, or "" for
+// system admins.
+func (a *BranchAccess) StaffBranchID(c echo.Context) (string, error) {
+	user, err := a.Viewer(c)
+	if err != nil || user == nil || user.BranchID == nil {
+		return "", err
+	}
+	return *user.BranchID, nil
+}
+
+// VisibleBranch loads a branch the caller may see holdings at. Local branches
+// are reported as not found to everyone but their own members, their staff,
+// their kiosks and system admins.
+func (a *BranchAccess) VisibleBranch(c echo.Context, branchID string) (*models.Branch, error) {
+	branch, err := a.branchRepo.GetByID(c.Request().Context(), branchID)
+	if err != nil {
+		return nil, err
+	}
+	if branch.Visibility == models.BranchVisibilityNetwork {
+		return branch, nil
+	}
+	viewer, err := a.Viewer(c)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeLocalBranch(viewer, branch.ID) && kioskBranchID(c) != branch.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return branch, nil
+}
+
+// HiddenBranchIDs lists the local branches the caller may not see, for
+// listings that filter on them in the query.
+func (a *BranchAccess) HiddenBranchIDs(c echo.Context) ([]string, error) {
+	branches, err := a.branchRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return nil, err
+	}
+	viewer, err := a.Viewer(c)
+	if err != nil {
+		return nil, err
+	}
+	var hidden []string
+	for _, branch := range branches {
+		if branch.Visibility != models.BranchVisibilityNetwork && !canSeeLocalBranch(viewer, branch.ID) && kioskBranchID(c) != branch.ID {
+			hidden = append(hidden, branch.ID)
+		}
+	}
+	return hidden, nil
+}
+
+// ManagedBranch loads a branch the calling admin may manage: any branch for
+// system admins, only their own for branch staff.
+func (a *BranchAccess) ManagedBranch(c echo.Context, branchID string) (*models.Branch, error) {
+	staffBranchID, err := a.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && staffBranchID != branchID {
+		return nil, errBranchAccessDenied
+	}
+	return a.branchRepo.GetByID(c.Request().Context(), branchID)
+}
+
+// ScopedUsers scopes user reads to a branch. Branch staff are always limited
+// to their own branch; system admins see every user unless branchID is set.
+func (a *BranchAccess) ScopedUsers(c echo.Context, branchID string) (repositories.UserRepo, error) {
+	scope, err := a.UserBranchID(c, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" {
+		return a.userRepo, nil
+	}
+	return a.userRepo.InBranch(scope), nil
+}
+
+// UserBranchID returns the branch user reads are limited to under the rules
+// of ScopedUsers, or "" for every branch, for queries that do not go through
+// UserRepo.
+func (a *BranchAccess) UserBranchID(c echo.Context, branchID string) (string, error) {
+	staffBranchID, err := a.StaffBranchID(c)
+	if err != nil {
+		return "", err
+	}
+	if staffBranchID != "" {
+		if branchID != "" && branchID != staffBranchID {
+			return "", errBranchAccessDenied
+		}
+		return staffBranchID, nil
+	}
+	if branchID == "" {
+		return "", nil
+	}
+	if _, err := a.branchRepo.GetByID(c.Request().Context(), branchID); err != nil {
+		return "", err
+	}
+	return branchID, nil
+}
+
+// RequireSystemAdmin rejects branch staff with errBranchAccessDenied, for
+// actions that affect the whole network.
+func (a *BranchAccess) RequireSystemAdmin(c echo.Context) error {
+	staffBranchID, err := a.StaffBranchID(c)
+	if err != nil {
+		return err
+	}
+	if staffBranchID != "" {
+		return errBranchAccessDenied
+	}
+	return nil
+}
+
+// systemAdminError answers a RequireSystemAdmin failure on endpoints that are
+// not about a particular branch.
+func systemAdminError(c echo.Context, err error) error {
+	if err == errBranchAccessDenied {
+		return c.JSON(http.StatusForbidden, models.Response{
+			Message:   "Insufficient permissions",
+			ErrorCode: models.ErrCodeInsufficientPermissions,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error resolving caller",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func canSeeLocalBranch(viewer *models.User, branchID string) bool {
+	if viewer == nil {
+		return false
+	}
+	if viewer.BranchID == nil {
+		return viewer.Role == "admin"
+	}
+	return *viewer.BranchID == branchID
+}
+
+func NewBranchAPI(branchRepo *repositories.BranchRepository, bookRepo repositories.BookRepo, transferRepo *repositories.TransferRepository, branches *BranchAccess, authMw *auth.Middleware) *BranchAPI {
+	return &BranchAPI{
+		branchRepo:   branchRepo,
+		bookRepo:     bookRepo,
+		transferRepo: transferRepo,
+		branches:     branches,
+		authMw:       authMw,
+	}
+}
+
+func (api *BranchAPI) Setup(group *echo.Group) {
+	group.GET("", api.getBranches)
+	group.GET("/:id", api.getBranch)
+}
+
+// SetupAdmin registers branch management. Only system admins may create,
+// change or delete branches.
+func (api *BranchAPI) SetupAdmin(group *echo.Group) {
+	group.POST("", api.createBranch)
+	group.PUT("/:id", api.updateBranch)
+	group.DELETE("/:id", api.deleteBranch)
+}
+
+// SetupPublic registers the branch list and per-branch holdings for the
+// public OPAC. Callers there are anonymous, so only network branches show.
+func (api *BranchAPI) SetupPublic(group *echo.Group) {
+	group.GET("/branches", api.getBranches)
+	group.GET("/branches/:id", api.getBranch)
+	group.GET("/books/:id/holdings", api.getHoldings)
+}
+
+// SetupHoldings registers per-branch holdings under /books.
+func (api *BranchAPI) SetupHoldings(group *echo.Group) {
+	group.GET("/:id/holdings", api.getHoldings)
+	group.PUT("/:id/holdings/:branchId", api.setHolding, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.DELETE("/:id/holdings/:branchId", api.deleteHolding, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+}
+
+func (api *BranchAPI) getBranches(c echo.Context) error {
+	branches, err := api.branchRepo.GetAll(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving branches",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	viewer, err := api.branches.Viewer(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error resolving caller",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	branchDetails := make([]BranchDetail, 0, len(branches))
+	for i := range branches {
+		if branches[i].Visibility != models.BranchVisibilityNetwork && !canSeeLocalBranch(viewer, branches[i].ID) {
+			continue
+		}
+		branchDetails = append(branchDetails, toBranchDetail(&branches[i], userLocation(c)))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    branchDetails,
+		Message: "Branches retrieved successfully",
+	})
+}
+
+func (api *BranchAPI) getBranch(c echo.Context) error {
+	branch, err := api.branches.VisibleBranch(c, c.Param("id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toBranchDetail(branch, userLocation(c)),
+		Message: "Branch retrieved successfully",
+	})
+}
+
+func (api *BranchAPI) createBranch(c echo.Context) error {
+	var req CreateBranchRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return branchLookupError(c, err)
+	}
+	exists, err := api.branchRepo.CodeExists(c.Request().Context(), req.Code)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking branch code availability",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if exists {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Branch code already exists",
+			ErrorCode: models.ErrCodeBranchExists,
+		})
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.BranchVisibilityNetwork
+	}
+	branch := &models.Branch{
+		ID:         uuid.New().String(),
+		Code:       req.Code,
+		Name:       req.Name,
+		Address:    req.Address,
+		Visibility: visibility,
+	}
+	if err := api.branchRepo.Create(c.Request().Context(), branch); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating branch",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "branch", branch.ID, nil, toBranchDetail(branch, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toBranchDetail(branch, userLocation(c)),
+		Message: "Branch created successfully",
+	})
+}
+
+func (api *BranchAPI) updateBranch(c echo.Context) error {
+	var req UpdateBranchRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return branchLookupError(c, err)
+	}
+	branch, err := api.branchRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	before := toBranchDetail(branch, time.UTC)
+	if req.Name != nil {
+		branch.Name = *req.Name
+	}
+	if req.Address != nil {
+		branch.Address = req.Address
+	}
+	if req.Visibility != nil {
+		branch.Visibility = *req.Visibility
+	}
+	if branch.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Name cannot be empty",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "name",
+					Message: "Name cannot be empty",
+				},
+			},
+		})
+	}
+	if err := api.branchRepo.Update(c.Request().Context(), branch); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating branch",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "branch", branch.ID, before, toBranchDetail(branch, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toBranchDetail(branch, userLocation(c)),
+		Message: "Branch updated successfully",
+	})
+}
+
+func (api *BranchAPI) deleteBranch(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return branchLookupError(c, err)
+	}
+	id := c.Param("id")
+	branch, err := api.branchRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	inUse, err := api.branchRepo.InUse(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking branch usage",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if inUse {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Branch still has users or holdings",
+			ErrorCode: models.ErrCodeBranchInUse,
+		})
+	}
+	if err := api.branchRepo.Delete(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting branch",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "branch", id, toBranchDetail(branch, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Branch deleted successfully",
+	})
+}
+
+// getHoldings lists the book's copies per branch, leaving out local branches
+// the caller may not see. Open transfers show up as requested copies on the
+// source branch and in-transit copies on the destination branch, which is
+// listed even before it holds any copies.
+func (api *BranchAPI) getHoldings(c echo.Context) error {
+	bookID := c.Param("id")
+	if _, err := api.bookRepo.GetByID(c.Request().Context(), bookID); err != nil {
+		return bookLookupError(c, err)
+	}
+	holdings, err := api.bookRepo.GetHoldings(c.Request().Context(), bookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving holdings",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	transfers, err := api.transferRepo.GetOpenByBook(c.Request().Context(), bookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving transfers",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	holdingDetails := make([]HoldingDetail, 0, len(holdings))
+	detailIndex := make(map[string]int, len(holdings))
+	for _, holding := range holdings {
+		detailIndex[holding.BranchID] = len(holdingDetails)
+		holdingDetails = append(holdingDetails, HoldingDetail{
+			BranchID:          holding.BranchID,
+			Quantity:          holding.Quantity,
+			AvailableQuantity: holding.AvailableQuantity,
+			Location:          holding.Location,
+			UpdatedDate:       holding.UpdatedDate.In(userLocation(c)),
+		})
+	}
+	for _, transfer := range transfers {
+		branchID := transfer.FromBranchID
+		if transfer.Status == models.TransferStatusInTransit {
+			branchID = transfer.ToBranchID
+		}
+		i, ok := detailIndex[branchID]
+		if !ok {
+			i = len(holdingDetails)
+			detailIndex[branchID] = i
+			holdingDetails = append(holdingDetails, HoldingDetail{
+				BranchID:    branchID,
+				UpdatedDate: transfer.UpdatedDate.In(userLocation(c)),
+			})
+		}
+		if transfer.Status == models.TransferStatusInTransit {
+			holdingDetails[i].InTransitQuantity += transfer.Quantity
+		} else {
+			holdingDetails[i].RequestedQuantity += transfer.Quantity
+		}
+	}
+	branchIDs := make([]string, len(holdingDetails))
+	for i, holdingDetail := range holdingDetails {
+		branchIDs[i] = holdingDetail.BranchID
+	}
+	branches, err := api.branchRepo.GetByIDs(c.Request().Context(), branchIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving branches",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	viewer, err := api.branches.Viewer(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error resolving caller",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	branchesByID := make(map[string]models.Branch, len(branches))
+	for _, branch := range branches {
+		branchesByID[branch.ID] = branch
+	}
+	visibleDetails := make([]HoldingDetail, 0, len(holdingDetails))
+	for _, holdingDetail := range holdingDetails {
+		branch, ok := branchesByID[holdingDetail.BranchID]
+		if !ok {
+			continue
+		}
+		if branch.Visibility != models.BranchVisibilityNetwork && !canSeeLocalBranch(viewer, branch.ID) && kioskBranchID(c) != branch.ID {
+			continue
+		}
+		holdingDetail.BranchCode = branch.Code
+		holdingDetail.BranchName = branch.Name
+		visibleDetails = append(visibleDetails, holdingDetail)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    visibleDetails,
+		Message: "Holdings retrieved successfully",
+	})
+}
+
+func (api *BranchAPI) setHolding(c echo.Context) error {
+	var req SetHoldingRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	bookID := c.Param("id")
+	if _, err := api.bookRepo.GetByID(c.Request().Context(), bookID); err != nil {
+		return bookLookupError(c, err)
+	}
+	branch, err := api.branches.ManagedBranch(c, c.Param("branchId"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	before, err := api.holdingSnapshot(c, bookID, branch)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating holding",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	holding := &models.BookHolding{
+		ID:                uuid.New().String(),
+		BookID:            bookID,
+		BranchID:          branch.ID,
+		Quantity:          req.Quantity,
+		AvailableQuantity: req.AvailableQuantity,
+		Location:          req.Location,
+	}
+	if err := api.bookRepo.SetHolding(c.Request().Context(), holding); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating holding",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	after := toHoldingSnapshot(holding, branch)
+	auditRecord(c, "book_holding", bookID, before, after)
+	return c.JSON(http.StatusOK, models.Response{
+		Data: HoldingDetail{
+			BranchID:          branch.ID,
+			BranchCode:        branch.Code,
+			BranchName:        branch.Name,
+			Quantity:          holding.Quantity,
+			AvailableQuantity: holding.AvailableQuantity,
+			Location:          holding.Location,
+			UpdatedDate:       holding.UpdatedDate.In(userLocation(c)),
+		},
+		Message: "Holding updated successfully",
+	})
+}
+
+func (api *BranchAPI) deleteHolding(c echo.Context) error {
+	bookID := c.Param("id")
+	if _, err := api.bookRepo.GetByID(c.Request().Context(), bookID); err != nil {
+		return bookLookupError(c, err)
+	}
+	branch, err := api.branches.ManagedBranch(c, c.Param("branchId"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	before, err := api.holdingSnapshot(c, bookID, branch)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting holding",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	err = api.bookRepo.DeleteHolding(c.Request().Context(), bookID, branch.ID)
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book has no holding at this branch",
+			ErrorCode: models.ErrCodeHoldingNotFound,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting holding",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "book_holding", bookID, before, nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Holding deleted successfully",
+	})
+}
+
+// holdingSnapshot returns the book's holding at branch for the audit log, or
+// nil if there is none.
+func (api *BranchAPI) holdingSnapshot(c echo.Context, bookID string, branch *models.Branch) (any, error) {
+	holdings, err := api.bookRepo.GetHoldings(c.Request().Context(), bookID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range holdings {
+		if holdings[i].BranchID == branch.ID {
+			return toHoldingSnapshot(&holdings[i], branch), nil
+		}
+	}
+	return nil, nil
+}
+
+func toHoldingSnapshot(holding *models.BookHolding, branch *models.Branch) HoldingDetail {
+	return HoldingDetail{
+		BranchID:          branch.ID,
+		BranchCode:        branch.Code,
+		BranchName:        branch.Name,
+		Quantity:          holding.Quantity,
+		AvailableQuantity: holding.AvailableQuantity,
+		Location:          holding.Location,
+		UpdatedDate:       holding.UpdatedDate.UTC(),
+	}
+}
+
+func branchLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Branch not found",
+			ErrorCode: models.ErrCodeBranchNotFound,
+		})
+	}
+	if err == errBranchAccessDenied {
+		return c.JSON(http.StatusForbidden, models.Response{
+			Message:   "Branch staff can only manage their own branch",
+			ErrorCode: models.ErrCodeBranchAccessDenied,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving branch",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func bookLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving book",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toBranchDetail(branch *models.Branch, location *time.Location) BranchDetail {
+	return BranchDetail{
+		ID:          branch.ID,
+		Code:        branch.Code,
+		Name:        branch.Name,
+		Address:     branch.Address,
+		Visibility:  branch.Visibility,
+		CreatedDate: branch.CreatedDate.In(location),
+		UpdatedDate: branch.UpdatedDate.In(location),
+	}
+}
@@ -0,0 +1,270 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	// icsDateLayout and icsTimeLayout are the iCalendar DATE and UTC
+	// DATE-TIME formats.
+	icsDateLayout = "20060102"
+	icsTimeLayout = "20060102T150405Z"
+	// icsLineOctets is the longest a content line may be before it is
+	// folded.
+	icsLineOctets = 75
+)
+
+// CalendarFeedAPI publishes a member's loan due dates as an iCalendar feed
+// that Google Calendar, Apple Calendar and similar apps subscribe to. Those
+// apps cannot send a bearer token, so the feed URL carries a secret token of
+// its own, which the member creates and revokes with their JWT.
+type CalendarFeedAPI struct {
+	feedRepo *repositories.CalendarFeedRepository
+	loanRepo *repositories.DigitalLoanRepository
+	bookRepo repositories.BookRepo
+	userRepo repositories.UserRepo
+	authMw   *auth.Middleware
+}
+
+type CalendarFeedDetail struct {
+	URL         string    `json:"url"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+func NewCalendarFeedAPI(feedRepo *repositories.CalendarFeedRepository, loanRepo *repositories.DigitalLoanRepository, bookRepo repositories.BookRepo, userRepo repositories.UserRepo, authMw *auth.Middleware) *CalendarFeedAPI {
+	return &CalendarFeedAPI{
+		feedRepo: feedRepo,
+		loanRepo: loanRepo,
+		bookRepo: bookRepo,
+		userRepo: userRepo,
+		authMw:   authMw,
+	}
+}
+
+// Setup registers management of the caller's feed under /me/calendar-feed.
+func (api *CalendarFeedAPI) Setup(group *echo.Group) {
+	group.GET("", api.getFeed)
+	group.POST("", api.createFeed)
+	group.DELETE("", api.deleteFeed)
+}
+
+// SetupFeed registers the feed itself. It is outside the /me group because
+// it is authenticated by its token rather than a JWT.
+func (api *CalendarFeedAPI) SetupFeed(group *echo.Group) {
+	group.GET("/me/due-dates.ics", api.getDueDates)
+}
+
+func (api *CalendarFeedAPI) getFeed(c echo.Context) error {
+	feed, err := api.feedRepo.GetByUser(c.Request().Context(), api.authMw.GetUserFromContext(c).UserID)
+	if err != nil {
+		return calendarFeedLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    api.toCalendarFeedDetail(c, feed),
+		Message: "Calendar feed retrieved successfully",
+	})
+}
+
+// createFeed returns the caller's feed URL, creating the feed on first use.
+// To change the URL, delete the feed and create it again.
+func (api *CalendarFeedAPI) createFeed(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := api.authMw.GetUserFromContext(c).UserID
+	feed, err := api.feedRepo.GetByUser(ctx, userID)
+	if err == nil {
+		return c.JSON(http.StatusOK, models.Response{
+			Data:    api.toCalendarFeedDetail(c, feed),
+			Message: "Calendar feed retrieved successfully",
+		})
+	}
+	if err != gorm.ErrRecordNotFound {
+		return calendarFeedLookupError(c, err)
+	}
+	token, err := generateShareToken()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating calendar feed",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	feed = &models.CalendarFeed{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Token:  token,
+	}
+	err = api.feedRepo.Create(ctx, feed)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// A concurrent request created it first.
+		feed, err = api.feedRepo.GetByUser(ctx, userID)
+		if err != nil {
+			return calendarFeedLookupError(c, err)
+		}
+		return c.JSON(http.StatusOK, models.Response{
+			Data:    api.toCalendarFeedDetail(c, feed),
+			Message: "Calendar feed retrieved successfully",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating calendar feed",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    api.toCalendarFeedDetail(c, feed),
+		Message: "Calendar feed created successfully",
+	})
+}
+
+func (api *CalendarFeedAPI) deleteFeed(c echo.Context) error {
+	deleted, err := api.feedRepo.DeleteByUser(c.Request().Context(), api.authMw.GetUserFromContext(c).UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting calendar feed",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if deleted == 0 {
+		return calendarFeedLookupError(c, gorm.ErrRecordNotFound)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Calendar feed deleted successfully",
+	})
+}
+
+// getDueDates renders the member's active digital loans as all-day events
+// on their due dates in the member's time zone. Unknown or revoked tokens,
+// and tokens of inactive accounts, are reported as not found.
+func (api *CalendarFeedAPI) getDueDates(c echo.Context) error {
+	ctx := c.Request().Context()
+	token := c.QueryParam("token")
+	if token == "" {
+		return calendarFeedLookupError(c, gorm.ErrRecordNotFound)
+	}
+	feed, err := api.feedRepo.GetByToken(ctx, token)
+	if err != nil {
+		return calendarFeedLookupError(c, err)
+	}
+	user, err := api.userRepo.GetByID(ctx, feed.UserID)
+	if err != nil {
+		return calendarFeedLookupError(c, err)
+	}
+	if user.Status != "active" {
+		return calendarFeedLookupError(c, gorm.ErrRecordNotFound)
+	}
+	now := time.Now().UTC()
+	loans, err := api.loanRepo.GetActiveByUser(ctx, user.ID, now)
+	if err != nil {
+		return calendarFeedError(c)
+	}
+	bookIDs := make([]string, len(loans))
+	for i, loan := range loans {
+		bookIDs[i] = loan.BookID
+	}
+	books, err := api.bookRepo.GetByIDs(ctx, bookIDs)
+	if err != nil {
+		return calendarFeedError(c)
+	}
+	titles := make(map[string]string, len(books))
+	for _, book := range books {
+		titles[book.ID] = book.Title
+	}
+
+	location := user.Location()
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//Book Management System//Loan Due Dates//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	writeICSLine(&b, "X-WR-CALNAME:Library due dates")
+	writeICSLine(&b, "X-WR-TIMEZONE:"+location.String())
+	writeICSLine(&b, "REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	writeICSLine(&b, "X-PUBLISHED-TTL:PT1H")
+	for _, loan := range loans {
+		title, ok := titles[loan.BookID]
+		if !ok {
+			continue
+		}
+		due := loan.DueDate.In(location)
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, "UID:"+loan.ID+"@bookms")
+		writeICSLine(&b, "DTSTAMP:"+now.Format(icsTimeLayout))
+		writeICSLine(&b, "LAST-MODIFIED:"+loan.UpdatedDate.UTC().Format(icsTimeLayout))
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+due.Format(icsDateLayout))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+due.AddDate(0, 0, 1).Format(icsDateLayout))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText("Due: "+title))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(`Your loan of "`+title+`" ends at `+due.Format("15:04 MST")+"."))
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+	writeICSLine(&b, "END:VCALENDAR")
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+}
+
+func (api *CalendarFeedAPI) toCalendarFeedDetail(c echo.Context, feed *models.CalendarFeed) CalendarFeedDetail {
+	return CalendarFeedDetail{
+		URL:         c.Scheme() + "://" + c.Request().Host + "/api/v1/me/due-dates.ics?token=" + url.QueryEscape(feed.Token),
+		CreatedDate: feed.CreatedDate.In(userLocation(c)),
+	}
+}
+
+// writeICSLine ends a content line with CRLF, folding it onto continuation
+// lines that start with a space once it passes icsLineOctets. Lines are
+// only split between UTF-8 characters.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// escapeICSText escapes a TEXT property value.
+func escapeICSText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+func calendarFeedLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Calendar feed not found",
+			ErrorCode: models.ErrCodeCalendarFeedNotFound,
+		})
+	}
+	return calendarFeedError(c)
+}
+
+func calendarFeedError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving calendar feed",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
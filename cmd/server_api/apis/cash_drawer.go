@@ -0,0 +1,512 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// CashDrawerAPI runs the desk's cash drawer: staff open a session with a
+// float, record the payments they take and close it with the cash they
+// count. Branch staff only see their own branch's drawer.
+type CashDrawerAPI struct {
+	drawerRepo *repositories.CashDrawerRepository
+	userRepo   repositories.UserRepo
+	branches   *BranchAccess
+	authMw     *auth.Middleware
+}
+
+type OpenCashDrawerRequest struct {
+	BranchID     string  `json:"branch_id"`
+	OpeningFloat float64 `json:"opening_float" validate:"min=0"`
+}
+
+type RecordPaymentRequest struct {
+	Amount      float64 `json:"amount" validate:"min=0.01"`
+	Method      string  `json:"method" validate:"required,oneof=cash card"`
+	UserID      *string `json:"user_id,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type CloseCashDrawerRequest struct {
+	CountedTotal *float64 `json:"counted_total" validate:"required,min=0"`
+	Note         *string  `json:"note,omitempty"`
+}
+
+// CashDrawerDetail is a session with its takings. ExpectedCash is the
+// opening float plus the cash taken, fixed at close; Variance is the counted
+// total less ExpectedCash, negative when the drawer is short.
+type CashDrawerDetail struct {
+	ID           string     `json:"id"`
+	BranchID     string     `json:"branch_id"`
+	Status       string     `json:"status"`
+	OpeningFloat float64    `json:"opening_float"`
+	CashTotal    float64    `json:"cash_total"`
+	CardTotal    float64    `json:"card_total"`
+	Payments     int64      `json:"payments"`
+	ExpectedCash float64    `json:"expected_cash"`
+	CountedTotal *float64   `json:"counted_total,omitempty"`
+	Variance     *float64   `json:"variance,omitempty"`
+	Note         *string    `json:"note,omitempty"`
+	OpenedBy     string     `json:"opened_by"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedBy     *string    `json:"closed_by,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
+
+type CashDrawerPaymentDetail struct {
+	ID          string    `json:"id"`
+	SessionID   string    `json:"session_id"`
+	UserID      *string   `json:"user_id,omitempty"`
+	Amount      float64   `json:"amount"`
+	Method      string    `json:"method"`
+	Description *string   `json:"description,omitempty"`
+	RecordedBy  string    `json:"recorded_by"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+type CashDrawerListResponse struct {
+	Sessions []CashDrawerDetail `json:"sessions"`
+	Total    int64              `json:"total"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+}
+
+// CashReconciliationReport compares the cash recorded in the sessions closed
+// between From and To with the cash counted in their drawers.
+type CashReconciliationReport struct {
+	From     time.Time                `json:"from"`
+	To       time.Time                `json:"to"`
+	Sessions []CashDrawerDetail       `json:"sessions"`
+	Totals   CashReconciliationTotals `json:"totals"`
+}
+
+// CashReconciliationTotals sums the sessions of a report. Discrepancies
+// counts the sessions whose variance is not zero.
+type CashReconciliationTotals struct {
+	Sessions      int     `json:"sessions"`
+	Discrepancies int     `json:"discrepancies"`
+	OpeningFloat  float64 `json:"opening_float"`
+	CashTotal     float64 `json:"cash_total"`
+	CardTotal     float64 `json:"card_total"`
+	ExpectedCash  float64 `json:"expected_cash"`
+	CountedTotal  float64 `json:"counted_total"`
+	Variance      float64 `json:"variance"`
+}
+
+func NewCashDrawerAPI(drawerRepo *repositories.CashDrawerRepository, userRepo repositories.UserRepo, branches *BranchAccess, authMw *auth.Middleware) *CashDrawerAPI {
+	return &CashDrawerAPI{
+		drawerRepo: drawerRepo,
+		userRepo:   userRepo,
+		branches:   branches,
+		authMw:     authMw,
+	}
+}
+
+// Setup registers the drawer sessions under /admin/cash-drawers.
+func (api *CashDrawerAPI) Setup(group *echo.Group) {
+	group.POST("", api.openSession)
+	group.GET("", api.getSessions)
+	group.GET("/:id", api.getSession)
+	group.GET("/:id/payments", api.getPayments)
+	group.POST("/:id/payments", api.recordPayment)
+	group.POST("/:id/close", api.closeSession)
+}
+
+// SetupReports registers the reconciliation report under /reports.
+func (api *CashDrawerAPI) SetupReports(group *echo.Group) {
+	group.GET("/cash-drawers", api.getReconciliation)
+}
+
+// openSession opens a drawer at the caller's branch, or at branch_id, which
+// system admins must give.
+func (api *CashDrawerAPI) openSession(c echo.Context) error {
+	var req OpenCashDrawerRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	branchID := req.BranchID
+	if branchID == "" {
+		staffBranchID, err := api.branches.StaffBranchID(c)
+		if err != nil {
+			return branchLookupError(c, err)
+		}
+		if staffBranchID == "" {
+			return fieldValidationError(c, "branch_id", "required", "is required")
+		}
+		branchID = staffBranchID
+	}
+	if _, err := api.branches.ManagedBranch(c, branchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	session := &models.CashDrawerSession{
+		ID:           uuid.New().String(),
+		BranchID:     branchID,
+		OpeningFloat: roundCents(req.OpeningFloat),
+		OpenedBy:     api.authMw.GetUserFromContext(c).UserID,
+	}
+	if err := api.drawerRepo.Open(c.Request().Context(), session); err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	auditRecord(c, "cash_drawer_session", session.ID, nil, toCashDrawerDetail(session, nil, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toCashDrawerDetail(session, nil, userLocation(c)),
+		Message: "Cash drawer opened successfully",
+	})
+}
+
+func (api *CashDrawerAPI) getSessions(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status != "" && status != "open" && status != "closed" {
+		return queryParamError(c, &models.FieldError{Field: "status", Message: "must be one of: open, closed"})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	branchID, err := api.branches.UserBranchID(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	ctx := c.Request().Context()
+	filter := repositories.CashDrawerFilter{
+		BranchID: branchID,
+		Status:   status,
+	}
+	sessions, err := api.drawerRepo.GetAll(ctx, filter, limit, offset)
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	total, err := api.drawerRepo.Count(ctx, filter)
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	details, err := api.cashDrawerDetails(c, sessions)
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: CashDrawerListResponse{
+			Sessions: details,
+			Total:    total,
+			Limit:    limit,
+			Offset:   offset,
+		},
+		Message: "Cash drawer sessions retrieved successfully",
+	})
+}
+
+func (api *CashDrawerAPI) getSession(c echo.Context) error {
+	session, err := api.visibleSession(c, c.Param("id"))
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	details, err := api.cashDrawerDetails(c, []models.CashDrawerSession{*session})
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details[0],
+		Message: "Cash drawer session retrieved successfully",
+	})
+}
+
+func (api *CashDrawerAPI) getPayments(c echo.Context) error {
+	session, err := api.visibleSession(c, c.Param("id"))
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	payments, err := api.drawerRepo.GetPayments(c.Request().Context(), session.ID)
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	location := userLocation(c)
+	details := make([]CashDrawerPaymentDetail, len(payments))
+	for i := range payments {
+		details[i] = toCashDrawerPaymentDetail(&payments[i], location)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details,
+		Message: "Payments retrieved successfully",
+	})
+}
+
+func (api *CashDrawerAPI) recordPayment(c echo.Context) error {
+	session, err := api.visibleSession(c, c.Param("id"))
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	var req RecordPaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	ctx := c.Request().Context()
+	if req.UserID != nil {
+		if _, err := api.userRepo.GetByID(ctx, *req.UserID); err != nil {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+	}
+	payment := &models.CashDrawerPayment{
+		ID:          uuid.New().String(),
+		SessionID:   session.ID,
+		UserID:      req.UserID,
+		Amount:      roundCents(req.Amount),
+		Method:      req.Method,
+		Description: req.Description,
+		RecordedBy:  api.authMw.GetUserFromContext(c).UserID,
+	}
+	if err := api.drawerRepo.AddPayment(ctx, payment); err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	auditRecord(c, "cash_drawer_payment", payment.ID, nil, toCashDrawerPaymentDetail(payment, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toCashDrawerPaymentDetail(payment, userLocation(c)),
+		Message: "Payment recorded successfully",
+	})
+}
+
+// closeSession closes the drawer with the cash counted in it and returns the
+// session's reconciliation.
+func (api *CashDrawerAPI) closeSession(c echo.Context) error {
+	session, err := api.visibleSession(c, c.Param("id"))
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	var req CloseCashDrawerRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	ctx := c.Request().Context()
+	userID := api.authMw.GetUserFromContext(c).UserID
+	before := *session
+	if err := api.drawerRepo.Close(ctx, session, userID, roundCents(*req.CountedTotal), req.Note); err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	totals, err := api.drawerRepo.Totals(ctx, []string{session.ID})
+	if err != nil {
+		return cashDrawerLookupError(c, err)
+	}
+	auditRecord(c, "cash_drawer_session", session.ID, toCashDrawerDetail(&before, totals, time.UTC), toCashDrawerDetail(session, totals, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toCashDrawerDetail(session, totals, userLocation(c)),
+		Message: "Cash drawer closed successfully",
+	})
+}
+
+// getReconciliation reports the sessions closed over whole days in the
+// caller's zone, by default the last 30, with their variances.
+func (api *CashDrawerAPI) getReconciliation(c echo.Context) error {
+	location := userLocation(c)
+	var fieldErrors []models.FieldError
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if parsed, ok := reportDateParam(c, "to", location, &fieldErrors); ok {
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays+1)
+	if parsed, ok := reportDateParam(c, "from", location, &fieldErrors); ok {
+		from = parsed
+	}
+	if len(fieldErrors) == 0 && to.Before(from) {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "to",
+			Message: "must be on or after from",
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	branchID, err := api.branches.UserBranchID(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	sessions, err := api.drawerRepo.GetClosed(c.Request().Context(), repositories.CashDrawerFilter{
+		BranchID:   branchID,
+		ClosedFrom: from,
+		ClosedTo:   to.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return reconciliationError(c)
+	}
+	details, err := api.cashDrawerDetails(c, sessions)
+	if err != nil {
+		return reconciliationError(c)
+	}
+	var totals CashReconciliationTotals
+	for _, detail := range details {
+		totals.Sessions++
+		totals.OpeningFloat += detail.OpeningFloat
+		totals.CashTotal += detail.CashTotal
+		totals.CardTotal += detail.CardTotal
+		totals.ExpectedCash += detail.ExpectedCash
+		totals.CountedTotal += *detail.CountedTotal
+		totals.Variance += *detail.Variance
+		if *detail.Variance != 0 {
+			totals.Discrepancies++
+		}
+	}
+	totals.OpeningFloat = roundCents(totals.OpeningFloat)
+	totals.CashTotal = roundCents(totals.CashTotal)
+	totals.CardTotal = roundCents(totals.CardTotal)
+	totals.ExpectedCash = roundCents(totals.ExpectedCash)
+	totals.CountedTotal = roundCents(totals.CountedTotal)
+	totals.Variance = roundCents(totals.Variance)
+	return c.JSON(http.StatusOK, models.Response{
+		Data: CashReconciliationReport{
+			From:     from,
+			To:       to,
+			Sessions: details,
+			Totals:   totals,
+		},
+		Message: "Cash reconciliation report retrieved successfully",
+	})
+}
+
+// visibleSession loads a session, hiding other branches' drawers from branch
+// staff.
+func (api *CashDrawerAPI) visibleSession(c echo.Context, id string) (*models.CashDrawerSession, error) {
+	session, err := api.drawerRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && staffBranchID != session.BranchID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return session, nil
+}
+
+func (api *CashDrawerAPI) cashDrawerDetails(c echo.Context, sessions []models.CashDrawerSession) ([]CashDrawerDetail, error) {
+	ids := make([]string, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+	totals, err := api.drawerRepo.Totals(c.Request().Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+	location := userLocation(c)
+	details := make([]CashDrawerDetail, len(sessions))
+	for i := range sessions {
+		details[i] = toCashDrawerDetail(&sessions[i], totals, location)
+	}
+	return details, nil
+}
+
+func cashDrawerLookupError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Cash drawer session not found",
+			ErrorCode: models.ErrCodeCashDrawerNotFound,
+		})
+	case errors.Is(err, repositories.ErrCashDrawerOpen):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Branch already has an open cash drawer",
+			ErrorCode: models.ErrCodeCashDrawerOpen,
+		})
+	case errors.Is(err, repositories.ErrCashDrawerClosed):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Cash drawer session is closed",
+			ErrorCode: models.ErrCodeCashDrawerClosed,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing cash drawer",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func reconciliationError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error generating cash reconciliation report",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+// roundCents rounds an amount of money to whole cents.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// toCashDrawerDetail fills in the session's takings from totals, which may
+// hold other sessions' too. An open session's expected cash is what the
+// drawer should hold now.
+func toCashDrawerDetail(session *models.CashDrawerSession, totals []repositories.CashDrawerTotal, location *time.Location) CashDrawerDetail {
+	detail := CashDrawerDetail{
+		ID:           session.ID,
+		BranchID:     session.BranchID,
+		Status:       "open",
+		OpeningFloat: session.OpeningFloat,
+		CountedTotal: session.CountedTotal,
+		Note:         session.Note,
+		OpenedBy:     session.OpenedBy,
+		OpenedAt:     session.OpenedAt.In(location),
+		ClosedBy:     session.ClosedBy,
+		ClosedAt:     timeIn(session.ClosedAt, location),
+	}
+	for _, total := range totals {
+		if total.SessionID != session.ID {
+			continue
+		}
+		detail.Payments += total.Payments
+		switch total.Method {
+		case models.PaymentMethodCash:
+			detail.CashTotal = total.Amount
+		case models.PaymentMethodCard:
+			detail.CardTotal = total.Amount
+		}
+	}
+	detail.ExpectedCash = roundCents(session.OpeningFloat + detail.CashTotal)
+	if session.ClosedAt != nil {
+		detail.Status = "closed"
+	}
+	if session.ExpectedCash != nil {
+		detail.ExpectedCash = *session.ExpectedCash
+	}
+	if session.CountedTotal != nil {
+		variance := roundCents(*session.CountedTotal - detail.ExpectedCash)
+		detail.Variance = &variance
+	}
+	return detail
+}
+
+func toCashDrawerPaymentDetail(payment *models.CashDrawerPayment, location *time.Location) CashDrawerPaymentDetail {
+	return CashDrawerPaymentDetail{
+		ID:          payment.ID,
+		SessionID:   payment.SessionID,
+		UserID:      payment.UserID,
+		Amount:      payment.Amount,
+		Method:      payment.Method,
+		Description: payment.Description,
+		RecordedBy:  payment.RecordedBy,
+		CreatedDate: payment.CreatedDate.In(location),
+	}
+}
@@ -0,0 +1,315 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// CatalogSnapshotAPI stores summaries of the catalog and reports how the
+// catalog changed since one was taken, for board reports. Snapshots cover
+// the whole catalog; only system admins may delete them.
+type CatalogSnapshotAPI struct {
+	snapshotRepo *repositories.CatalogSnapshotRepository
+	branches     *BranchAccess
+	authMw       *auth.Middleware
+}
+
+type CreateCatalogSnapshotRequest struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=200"`
+}
+
+type CatalogSnapshotDetail struct {
+	ID          string    `json:"id"`
+	Note        *string   `json:"note,omitempty"`
+	Books       int       `json:"books"`
+	Copies      int       `json:"copies"`
+	TakenBy     *string   `json:"taken_by,omitempty"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+type CatalogSnapshotListResponse struct {
+	Snapshots []CatalogSnapshotDetail `json:"snapshots"`
+	Total     int64                   `json:"total"`
+	Limit     int                     `json:"limit"`
+	Offset    int                     `json:"offset"`
+}
+
+// CatalogDiffReport compares the catalog at ComparedAt with a snapshot.
+// Each list holds at most limit books, by title; the summary counts them
+// all.
+type CatalogDiffReport struct {
+	Snapshot   CatalogSnapshotDetail `json:"snapshot"`
+	ComparedAt time.Time             `json:"compared_at"`
+	Summary    CatalogDiffSummary    `json:"summary"`
+	Added      []CatalogDiffBook     `json:"added"`
+	Removed    []CatalogDiffBook     `json:"removed"`
+	Changed    []CatalogDiffChange   `json:"changed"`
+}
+
+type CatalogDiffSummary struct {
+	BooksBefore  int   `json:"books_before"`
+	BooksAfter   int   `json:"books_after"`
+	CopiesBefore int   `json:"copies_before"`
+	CopiesAfter  int   `json:"copies_after"`
+	Added        int64 `json:"added"`
+	Removed      int64 `json:"removed"`
+	Changed      int64 `json:"changed"`
+}
+
+// CatalogDiffBook is an added book as it is now, or a removed book as the
+// snapshot recorded it.
+type CatalogDiffBook struct {
+	BookID   string  `json:"book_id"`
+	Title    string  `json:"title"`
+	Author   string  `json:"author"`
+	ISBN     *string `json:"isbn"`
+	Quantity int     `json:"quantity"`
+}
+
+type CatalogDiffChange struct {
+	BookID         string  `json:"book_id"`
+	Title          string  `json:"title"`
+	Author         string  `json:"author"`
+	ISBN           *string `json:"isbn"`
+	QuantityBefore int     `json:"quantity_before"`
+	QuantityAfter  int     `json:"quantity_after"`
+	Change         int     `json:"change"`
+}
+
+func NewCatalogSnapshotAPI(snapshotRepo *repositories.CatalogSnapshotRepository, branches *BranchAccess, authMw *auth.Middleware) *CatalogSnapshotAPI {
+	return &CatalogSnapshotAPI{
+		snapshotRepo: snapshotRepo,
+		branches:     branches,
+		authMw:       authMw,
+	}
+}
+
+// Setup registers the snapshots under /admin/catalog-snapshots.
+func (api *CatalogSnapshotAPI) Setup(group *echo.Group) {
+	group.POST("", api.createSnapshot)
+	group.GET("", api.getSnapshots)
+	group.GET("/:id", api.getSnapshot)
+	group.DELETE("/:id", api.deleteSnapshot)
+}
+
+// SetupReports registers the catalog diff under /reports.
+func (api *CatalogSnapshotAPI) SetupReports(group *echo.Group) {
+	group.GET("/catalog-diff", api.getDiff)
+}
+
+func (api *CatalogSnapshotAPI) createSnapshot(c echo.Context) error {
+	var req CreateCatalogSnapshotRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	takenBy := api.authMw.GetUserFromContext(c).UserID
+	snapshot := &models.CatalogSnapshot{
+		ID:      uuid.New().String(),
+		Note:    req.Note,
+		TakenBy: &takenBy,
+	}
+	if err := api.snapshotRepo.Create(c.Request().Context(), snapshot); err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	auditRecord(c, "catalog_snapshot", snapshot.ID, nil, toCatalogSnapshotDetail(snapshot, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toCatalogSnapshotDetail(snapshot, userLocation(c)),
+		Message: "Catalog snapshot taken successfully",
+	})
+}
+
+func (api *CatalogSnapshotAPI) getSnapshots(c echo.Context) error {
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	ctx := c.Request().Context()
+	snapshots, err := api.snapshotRepo.GetAll(ctx, limit, offset)
+	if err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	total, err := api.snapshotRepo.Count(ctx)
+	if err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	location := userLocation(c)
+	details := make([]CatalogSnapshotDetail, len(snapshots))
+	for i := range snapshots {
+		details[i] = toCatalogSnapshotDetail(&snapshots[i], location)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: CatalogSnapshotListResponse{
+			Snapshots: details,
+			Total:     total,
+			Limit:     limit,
+			Offset:    offset,
+		},
+		Message: "Catalog snapshots retrieved successfully",
+	})
+}
+
+func (api *CatalogSnapshotAPI) getSnapshot(c echo.Context) error {
+	snapshot, err := api.snapshotRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toCatalogSnapshotDetail(snapshot, userLocation(c)),
+		Message: "Catalog snapshot retrieved successfully",
+	})
+}
+
+func (api *CatalogSnapshotAPI) deleteSnapshot(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	ctx := c.Request().Context()
+	snapshot, err := api.snapshotRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	if err := api.snapshotRepo.Delete(ctx, snapshot.ID); err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	auditRecord(c, "catalog_snapshot", snapshot.ID, toCatalogSnapshotDetail(snapshot, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Catalog snapshot deleted successfully",
+	})
+}
+
+// getDiff compares the live catalog with snapshot_id: the books added and
+// removed since, and those whose quantity changed. limit applies to each
+// list.
+func (api *CatalogSnapshotAPI) getDiff(c echo.Context) error {
+	snapshotID := c.QueryParam("snapshot_id")
+	if snapshotID == "" {
+		return queryParamError(c, &models.FieldError{Field: "snapshot_id", Message: "snapshot_id is required"})
+	}
+	limit, _, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	ctx := c.Request().Context()
+	snapshot, err := api.snapshotRepo.GetByID(ctx, snapshotID)
+	if err != nil {
+		return catalogSnapshotLookupError(c, err)
+	}
+	comparedAt := time.Now().UTC()
+	current, err := api.snapshotRepo.CurrentTotals(ctx)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	added, err := api.snapshotRepo.GetAdded(ctx, snapshot.ID, limit)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	addedCount, err := api.snapshotRepo.CountAdded(ctx, snapshot.ID)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	removed, err := api.snapshotRepo.GetRemoved(ctx, snapshot.ID, limit)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	removedCount, err := api.snapshotRepo.CountRemoved(ctx, snapshot.ID)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	changes, err := api.snapshotRepo.GetQuantityChanges(ctx, snapshot.ID, limit)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+	changedCount, err := api.snapshotRepo.CountQuantityChanges(ctx, snapshot.ID)
+	if err != nil {
+		return catalogDiffError(c)
+	}
+
+	location := userLocation(c)
+	report := CatalogDiffReport{
+		Snapshot:   toCatalogSnapshotDetail(snapshot, location),
+		ComparedAt: comparedAt.In(location),
+		Summary: CatalogDiffSummary{
+			BooksBefore:  snapshot.Books,
+			BooksAfter:   current.Books,
+			CopiesBefore: snapshot.Copies,
+			CopiesAfter:  current.Copies,
+			Added:        addedCount,
+			Removed:      removedCount,
+			Changed:      changedCount,
+		},
+		Added:   toCatalogDiffBooks(added),
+		Removed: toCatalogDiffBooks(removed),
+		Changed: make([]CatalogDiffChange, len(changes)),
+	}
+	for i, change := range changes {
+		report.Changed[i] = CatalogDiffChange{
+			BookID:         change.BookID,
+			Title:          change.Title,
+			Author:         change.Author,
+			ISBN:           change.ISBN,
+			QuantityBefore: change.QuantityBefore,
+			QuantityAfter:  change.QuantityAfter,
+			Change:         change.QuantityAfter - change.QuantityBefore,
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Catalog diff retrieved successfully",
+	})
+}
+
+func toCatalogSnapshotDetail(snapshot *models.CatalogSnapshot, location *time.Location) CatalogSnapshotDetail {
+	return CatalogSnapshotDetail{
+		ID:          snapshot.ID,
+		Note:        snapshot.Note,
+		Books:       snapshot.Books,
+		Copies:      snapshot.Copies,
+		TakenBy:     snapshot.TakenBy,
+		CreatedDate: snapshot.CreatedDate.In(location),
+	}
+}
+
+func toCatalogDiffBooks(books []models.CatalogSnapshotBook) []CatalogDiffBook {
+	details := make([]CatalogDiffBook, len(books))
+	for i, book := range books {
+		details[i] = CatalogDiffBook{
+			BookID:   book.BookID,
+			Title:    book.Title,
+			Author:   book.Author,
+			ISBN:     book.ISBN,
+			Quantity: book.Quantity,
+		}
+	}
+	return details
+}
+
+func catalogSnapshotLookupError(c echo.Context, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Catalog snapshot not found",
+			ErrorCode: models.ErrCodeCatalogSnapshotNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing catalog snapshot",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func catalogDiffError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error generating catalog diff",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
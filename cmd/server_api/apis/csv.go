@@ -0,0 +1,184 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/negotiate"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const mimeTextCSV = "text/csv"
+
+var bookCSVHeader = []string{
+	"id",
+	"title",
+	"author",
+	"isbn",
+	"publisher",
+	"publication_year",
+	"genre",
+	"language",
+	"quantity",
+	"available_quantity",
+	"location",
+	"status",
+	"rating_average",
+	"rating_count",
+}
+
+// bookListResponse serves a page of books as CSV when the Accept header ranks
+// text/csv above JSON, and as the usual JSON envelope otherwise.
+func bookListResponse(c echo.Context, books []models.Book, body models.Response) error {
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if negotiate.Preferred(accept, echo.MIMEApplicationJSON, mimeTextCSV) != mimeTextCSV {
+		return jsonWithETag(c, body)
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(bookCSVHeader); err != nil {
+		return err
+	}
+	for i := range books {
+		if err := w.Write(bookCSVRecord(&books[i])); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return blobWithETag(c, mimeTextCSV+"; charset=utf-8", buf.Bytes())
+}
+
+func bookCSVRecord(book *models.Book) []string {
+	return []string{
+		book.ID,
+		book.Title,
+		book.Author,
+		stringValue(book.ISBN),
+		stringValue(book.Publisher),
+		intValue(book.PublicationYear),
+		stringValue(book.Genre),
+		book.Language,
+		strconv.Itoa(book.Quantity),
+		strconv.Itoa(book.AvailableQuantity),
+		stringValue(book.Location),
+		book.Status,
+		strconv.FormatFloat(book.RatingAverage, 'f', 2, 64),
+		strconv.Itoa(book.RatingCount),
+	}
+}
+
+// csvStream writes a CSV download straight to the response a batch at a
+// time, so an export never holds more than one batch. Nothing is sent until
+// the first batch, so an error before then can still get a JSON response.
+type csvStream struct {
+	c        echo.Context
+	filename string
+	header   []string
+	w        *csv.Writer
+}
+
+func newCSVStream(c echo.Context, filename string, header []string) *csvStream {
+	return &csvStream{
+		c:        c,
+		filename: filename,
+		header:   header,
+	}
+}
+
+// WriteBatch writes records and flushes them to the client.
+func (s *csvStream) WriteBatch(records [][]string) error {
+	if s.w == nil {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	if err := s.w.WriteAll(records); err != nil {
+		return err
+	}
+	s.c.Response().Flush()
+	return nil
+}
+
+// Close finishes the download. If err is set after rows were sent the
+// connection is aborted, so the client sees a failed download instead of a
+// file that silently stops; before that err is returned for the handler to
+// report.
+func (s *csvStream) Close(err error) error {
+	if err != nil {
+		if s.w == nil {
+			return err
+		}
+		slog.ErrorContext(s.c.Request().Context(), "CSV export failed",
+			"path", s.c.Path(),
+			"error", err,
+		)
+		panic(http.ErrAbortHandler)
+	}
+	if s.w == nil {
+		return s.start()
+	}
+	return nil
+}
+
+func (s *csvStream) start() error {
+	res := s.c.Response()
+	res.Header().Set(echo.HeaderContentType, mimeTextCSV+"; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", s.filename))
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	s.w = csv.NewWriter(res)
+	if err := s.w.Write(s.header); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func timeValue(t *time.Time, location *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(location).Format(time.RFC3339)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// csvUpload returns an uploaded CSV file, sent either as the file part of a
+// multipart form or as a text/csv body.
+func csvUpload(c echo.Context) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case echo.MIMEMultipartForm:
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return header.Open()
+	case mimeTextCSV:
+		return c.Request().Body, nil
+	}
+	return nil, echo.ErrUnsupportedMediaType
+}
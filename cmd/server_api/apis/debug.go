@@ -0,0 +1,29 @@
+package apis
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DebugAPI struct{}
+
+func NewDebugAPI() *DebugAPI {
+	return &DebugAPI{}
+}
+
+// Setup expects the group to be mounted at /debug because pprof.Index
+// resolves profile names relative to /debug/pprof/. /debug/vars serves the
+// expvar counters, including the database circuit breaker.
+func (api *DebugAPI) Setup(group *echo.Group) {
+	group.GET("/vars", echo.WrapHandler(expvar.Handler()))
+	group.GET("/pprof/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	group.GET("/pprof/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	group.GET("/pprof/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	group.GET("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	group.POST("/pprof/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	group.GET("/pprof/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	group.GET("/pprof/:profile", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+}
@@ -0,0 +1,302 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// kioskDeviceKey holds the *models.Device CheckDevice loaded for a kiosk
+// request.
+const kioskDeviceKey = "kiosk_device"
+
+// DeviceAPI registers unattended kiosks. A device gets one long-lived token
+// at registration, limited to its scopes and accepted only on the /kiosk
+// routes. Revoking the device stops the token at once.
+type DeviceAPI struct {
+	deviceRepo *repositories.DeviceRepository
+	branches   *BranchAccess
+	jwt        *auth.JWT
+	tokenTTL   time.Duration
+	authMw     *auth.Middleware
+}
+
+// RegisterDeviceRequest registers a kiosk at a branch, or network-wide when
+// branch_id is omitted. Scopes default to search.
+type RegisterDeviceRequest struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	BranchID *string  `json:"branch_id,omitempty"`
+	Scopes   []string `json:"scopes,omitempty" validate:"omitempty,min=1,dive,oneof=search"`
+}
+
+// DeviceDetail carries Token only in the registration response; it cannot be
+// retrieved again.
+type DeviceDetail struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name"`
+	BranchID         *string    `json:"branch_id"`
+	Scopes           []string   `json:"scopes"`
+	Token            string     `json:"token,omitempty"`
+	TokenExpiresDate time.Time  `json:"token_expires_date"`
+	LastSeenDate     *time.Time `json:"last_seen_date"`
+	CreatedBy        string     `json:"created_by"`
+	RevokedDate      *time.Time `json:"revoked_date"`
+	RevokedBy        *string    `json:"revoked_by"`
+	CreatedDate      time.Time  `json:"created_date"`
+	UpdatedDate      time.Time  `json:"updated_date"`
+}
+
+type DeviceListResponse struct {
+	Devices []DeviceDetail `json:"devices"`
+	Total   int64          `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+// NewDeviceAPI takes how long device tokens stay valid.
+func NewDeviceAPI(deviceRepo *repositories.DeviceRepository, branches *BranchAccess, jwt *auth.JWT, tokenTTL time.Duration, authMw *auth.Middleware) *DeviceAPI {
+	return &DeviceAPI{
+		deviceRepo: deviceRepo,
+		branches:   branches,
+		jwt:        jwt,
+		tokenTTL:   tokenTTL,
+		authMw:     authMw,
+	}
+}
+
+// CheckDevice is the auth.DeviceCheck for the /kiosk routes. It turns down
+// revoked and unknown devices, records when each device was last seen and
+// keeps the device on the context for branch visibility.
+func CheckDevice(deviceRepo *repositories.DeviceRepository) auth.DeviceCheck {
+	return func(c echo.Context, deviceID string) (bool, error) {
+		ctx := c.Request().Context()
+		device, err := deviceRepo.GetByID(ctx, deviceID)
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if device.RevokedDate != nil {
+			return false, nil
+		}
+		if err := deviceRepo.MarkSeen(ctx, device.ID, time.Now().UTC()); err != nil {
+			return false, err
+		}
+		c.Set(kioskDeviceKey, device)
+		return true, nil
+	}
+}
+
+// Setup registers device management under /admin/devices.
+func (api *DeviceAPI) Setup(group *echo.Group) {
+	group.GET("", api.getDevices)
+	group.POST("", api.registerDevice)
+	group.GET("/:id", api.getDevice)
+	group.POST("/:id/revoke", api.revokeDevice)
+}
+
+func (api *DeviceAPI) getDevices(c echo.Context) error {
+	var revoked *bool
+	switch status := c.QueryParam("status"); status {
+	case "":
+	case "active", "revoked":
+		value := status == "revoked"
+		revoked = &value
+	default:
+		return queryParamError(c, &models.FieldError{Field: "status", Message: "status must be active or revoked"})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	branchID := c.QueryParam("branch_id")
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	if staffBranchID != "" {
+		if branchID != "" && branchID != staffBranchID {
+			return branchLookupError(c, errBranchAccessDenied)
+		}
+		branchID = staffBranchID
+	}
+	filter := repositories.DeviceFilter{
+		BranchID: branchID,
+		Revoked:  revoked,
+	}
+	devices, err := api.deviceRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving devices",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.deviceRepo.CountFiltered(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting devices",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	deviceDetails := make([]DeviceDetail, len(devices))
+	for i := range devices {
+		deviceDetails[i] = toDeviceDetail(&devices[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: DeviceListResponse{
+			Devices: deviceDetails,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		},
+		Message: "Devices retrieved successfully",
+	})
+}
+
+func (api *DeviceAPI) getDevice(c echo.Context) error {
+	device, err := api.managedDevice(c, c.Param("id"))
+	if err != nil {
+		return deviceLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toDeviceDetail(device, userLocation(c)),
+		Message: "Device retrieved successfully",
+	})
+}
+
+// registerDevice creates a device and returns its token. Branch staff may
+// only register devices at their own branch.
+func (api *DeviceAPI) registerDevice(c echo.Context) error {
+	var req RegisterDeviceRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.BranchID == nil {
+		if err := api.branches.RequireSystemAdmin(c); err != nil {
+			return branchLookupError(c, err)
+		}
+	} else if _, err := api.branches.ManagedBranch(c, *req.BranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{models.DeviceScopeSearch}
+	}
+	device := &models.Device{
+		ID:               uuid.New().String(),
+		Name:             req.Name,
+		BranchID:         req.BranchID,
+		Scopes:           strings.Join(scopes, " "),
+		TokenExpiresDate: time.Now().UTC().Add(api.tokenTTL).Truncate(time.Second),
+		CreatedBy:        api.authMw.GetUserFromContext(c).UserID,
+	}
+	token, err := api.jwt.GenerateDeviceToken(device.ID, scopes, device.TokenExpiresDate)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error generating device token",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if err := api.deviceRepo.Create(c.Request().Context(), device); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error registering device",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "device", device.ID, nil, toDeviceDetail(device, time.UTC))
+	detail := toDeviceDetail(device, userLocation(c))
+	detail.Token = token
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    detail,
+		Message: "Device registered successfully",
+	})
+}
+
+func (api *DeviceAPI) revokeDevice(c echo.Context) error {
+	device, err := api.managedDevice(c, c.Param("id"))
+	if err != nil {
+		return deviceLookupError(c, err)
+	}
+	before := toDeviceDetail(device, time.UTC)
+	if err := api.deviceRepo.Revoke(c.Request().Context(), device, api.authMw.GetUserFromContext(c).UserID); err != nil {
+		return deviceLookupError(c, err)
+	}
+	auditRecord(c, "device", device.ID, before, toDeviceDetail(device, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toDeviceDetail(device, userLocation(c)),
+		Message: "Device revoked",
+	})
+}
+
+// managedDevice loads a device the calling admin may manage, hiding other
+// branches' and network-wide devices from branch staff.
+func (api *DeviceAPI) managedDevice(c echo.Context, id string) (*models.Device, error) {
+	device, err := api.deviceRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && (device.BranchID == nil || *device.BranchID != staffBranchID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return device, nil
+}
+
+// kioskBranchID returns the branch of the kiosk making the request, or "".
+func kioskBranchID(c echo.Context) string {
+	device, ok := c.Get(kioskDeviceKey).(*models.Device)
+	if !ok || device.BranchID == nil {
+		return ""
+	}
+	return *device.BranchID
+}
+
+func deviceLookupError(c echo.Context, err error) error {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Device not found",
+			ErrorCode: models.ErrCodeDeviceNotFound,
+		})
+	case errors.Is(err, repositories.ErrDeviceRevoked):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Device was already revoked",
+			ErrorCode: models.ErrCodeDeviceRevoked,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving device",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toDeviceDetail(device *models.Device, location *time.Location) DeviceDetail {
+	return DeviceDetail{
+		ID:               device.ID,
+		Name:             device.Name,
+		BranchID:         device.BranchID,
+		Scopes:           strings.Fields(device.Scopes),
+		TokenExpiresDate: device.TokenExpiresDate.In(location),
+		LastSeenDate:     timeIn(device.LastSeenDate, location),
+		CreatedBy:        device.CreatedBy,
+		RevokedDate:      timeIn(device.RevokedDate, location),
+		RevokedBy:        device.RevokedBy,
+		CreatedDate:      device.CreatedDate.In(location),
+		UpdatedDate:      device.UpdatedDate.In(location),
+	}
+}
@@ -0,0 +1,720 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/notifications"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"book-management-system/pkg/bodylimit"
+	"book-management-system/pkg/objectstore"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ebookContentTypes lists the formats a book's files may have.
+var ebookContentTypes = map[string]string{
+	models.BookFileFormatEPUB: "application/epub+zip",
+	models.BookFileFormatPDF:  "application/pdf",
+}
+
+// EbookAPI stores ebook files and lends them to members. Files are never
+// served by the API; a member with an active loan gets a signed object
+// storage URL that expires after a few minutes, and never after the loan.
+// Members who opt in get a receipt for every checkout and return.
+type EbookAPI struct {
+	fileRepo     *repositories.BookFileRepository
+	loanRepo     *repositories.DigitalLoanRepository
+	bookRepo     repositories.BookRepo
+	userRepo     repositories.UserRepo
+	planRepo     *repositories.MembershipPlanRepository
+	store        objectstore.Store
+	prefix       string
+	maxFileBytes int64
+	downloadTTL  time.Duration
+	notifier     *notifications.Notifier
+	authMw       *auth.Middleware
+}
+
+type CreateDigitalLoanRequest struct {
+	BookID string `json:"book_id" validate:"required"`
+}
+
+type BookFileDetail struct {
+	Format      string    `json:"format"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	SHA256      string    `json:"sha256"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+// EbookAvailability tells the catalog whether a book can be borrowed as an
+// ebook right now.
+type EbookAvailability struct {
+	BookID           string           `json:"book_id"`
+	Files            []BookFileDetail `json:"files"`
+	DigitalLoanLimit int              `json:"digital_loan_limit"`
+	LoanPolicy       string           `json:"loan_policy"`
+	MaxLoanDays      *int             `json:"max_loan_days,omitempty"`
+	OnLoan           int64            `json:"on_loan"`
+	Available        int64            `json:"available"`
+}
+
+type DigitalLoanDetail struct {
+	ID           string     `json:"id"`
+	BookID       string     `json:"book_id"`
+	BookTitle    string     `json:"book_title"`
+	Formats      []string   `json:"formats"`
+	DueDate      time.Time  `json:"due_date"`
+	ReturnedDate *time.Time `json:"returned_date,omitempty"`
+	CreatedDate  time.Time  `json:"created_date"`
+}
+
+type EbookDownload struct {
+	Format    string    `json:"format"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewEbookAPI takes a nil store when ebook lending is disabled. Uploads are
+// limited to maxFileBytes instead of the usual request body limit.
+func NewEbookAPI(fileRepo *repositories.BookFileRepository, loanRepo *repositories.DigitalLoanRepository, bookRepo repositories.BookRepo, userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository, store objectstore.Store, prefix string, maxFileBytes int64, downloadTTL time.Duration, notifier *notifications.Notifier, authMw *auth.Middleware) *EbookAPI {
+	return &EbookAPI{
+		fileRepo:     fileRepo,
+		loanRepo:     loanRepo,
+		bookRepo:     bookRepo,
+		userRepo:     userRepo,
+		planRepo:     planRepo,
+		store:        store,
+		prefix:       strings.Trim(prefix, "/"),
+		maxFileBytes: maxFileBytes,
+		downloadTTL:  downloadTTL,
+		notifier:     notifier,
+		authMw:       authMw,
+	}
+}
+
+// SetupAvailability registers the public ebook availability of a book under
+// /books.
+func (api *EbookAPI) SetupAvailability(group *echo.Group) {
+	group.GET("/:id/ebook", api.getAvailability)
+}
+
+// SetupFiles registers ebook file management under /admin/books.
+func (api *EbookAPI) SetupFiles(group *echo.Group) {
+	group.GET("/:id/files", api.getFiles)
+	group.PUT("/:id/files/:format", api.uploadFile, bodylimit.Middleware(api.maxFileBytes))
+	group.DELETE("/:id/files/:format", api.deleteFile)
+}
+
+// SetupLoans registers the caller's digital loans under /me/digital-loans.
+func (api *EbookAPI) SetupLoans(group *echo.Group) {
+	group.GET("", api.getLoans)
+	group.POST("", api.createLoan)
+	group.POST("/:id/return", api.returnLoan)
+	group.GET("/:id/download", api.downloadLoan)
+}
+
+func (api *EbookAPI) getAvailability(c echo.Context) error {
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	files, err := api.fileRepo.GetByBook(ctx, book.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving ebook files",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	onLoan, err := api.loanRepo.CountActiveByBook(ctx, book.ID, time.Now().UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting digital loans",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	availability := EbookAvailability{
+		BookID:           book.ID,
+		Files:            toBookFileDetails(files, userLocation(c)),
+		DigitalLoanLimit: book.DigitalLoanLimit,
+		LoanPolicy:       book.LoanPolicy,
+		MaxLoanDays:      book.MaxLoanDays,
+		OnLoan:           onLoan,
+	}
+	// Lowering the limit does not end loans already made, so more copies
+	// than the limit may be out.
+	if len(files) > 0 && book.LoanPolicy != models.BookLoanPolicyReferenceOnly && onLoan < int64(book.DigitalLoanLimit) {
+		availability.Available = int64(book.DigitalLoanLimit) - onLoan
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    availability,
+		Message: "Ebook availability retrieved successfully",
+	})
+}
+
+func (api *EbookAPI) getFiles(c echo.Context) error {
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	files, err := api.fileRepo.GetByBook(ctx, book.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving ebook files",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toBookFileDetails(files, userLocation(c)),
+		Message: "Ebook files retrieved successfully",
+	})
+}
+
+// uploadFile stores the request body as the book's file in the format,
+// replacing any file it had in that format. Each upload gets a new object
+// key, so download links handed out for the old file stop working once it
+// is deleted.
+func (api *EbookAPI) uploadFile(c echo.Context) error {
+	format := c.Param("format")
+	contentType, ok := ebookContentTypes[format]
+	if !ok {
+		return ebookFormatError(c)
+	}
+	if api.store == nil {
+		return ebooksDisabledError(c)
+	}
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	data, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return bindError(c, err)
+	}
+	if len(data) == 0 {
+		return fieldValidationError(c, "file", "required", "is required")
+	}
+	if !isEbookFormat(format, data) {
+		return fieldValidationError(c, "file", "ebook_format", "must match the format in the URL")
+	}
+
+	sum := sha256.Sum256(data)
+	fileID := uuid.New().String()
+	key := api.prefix + "/" + book.ID + "/" + fileID + "." + format
+	if err := api.store.Put(ctx, key, contentType, "", data); err != nil {
+		slog.ErrorContext(ctx, "Failed to store ebook file",
+			"book_id", book.ID,
+			"error", err,
+		)
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error storing ebook file",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	uploadedBy := api.authMw.GetUserFromContext(c).UserID
+	file := &models.BookFile{
+		ID:          fileID,
+		BookID:      book.ID,
+		Format:      format,
+		ObjectKey:   key,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		UploadedBy:  &uploadedBy,
+	}
+	replaced, err := api.fileRepo.Save(ctx, file)
+	if err != nil {
+		api.deleteObject(c, key)
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error saving ebook file",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if replaced != nil {
+		api.deleteObject(c, replaced.ObjectKey)
+		auditRecord(c, "book_file", file.ID, replaced, file)
+	} else {
+		auditRecord(c, "book_file", file.ID, nil, file)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toBookFileDetail(file, userLocation(c)),
+		Message: "Ebook file uploaded successfully",
+	})
+}
+
+func (api *EbookAPI) deleteFile(c echo.Context) error {
+	if _, ok := ebookContentTypes[c.Param("format")]; !ok {
+		return ebookFormatError(c)
+	}
+	if api.store == nil {
+		return ebooksDisabledError(c)
+	}
+	ctx := c.Request().Context()
+	file, err := api.fileRepo.Get(ctx, c.Param("id"), c.Param("format"))
+	if err != nil {
+		return bookFileLookupError(c, err)
+	}
+	if err := api.fileRepo.Delete(ctx, file); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting ebook file",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	api.deleteObject(c, file.ObjectKey)
+	auditRecord(c, "book_file", file.ID, file, nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Ebook file deleted successfully",
+	})
+}
+
+func (api *EbookAPI) getLoans(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := api.authMw.GetUserFromContext(c).UserID
+	loans, err := api.loanRepo.GetActiveByUser(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving digital loans",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]DigitalLoanDetail, len(loans))
+	for i := range loans {
+		details[i], err = api.loanDetail(c, &loans[i])
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error retrieving digital loans",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details,
+		Message: "Digital loans retrieved successfully",
+	})
+}
+
+// createLoan lends a book's ebook to the caller for their membership plan's
+// loan period. Digital loans count towards the plan's max_loans.
+func (api *EbookAPI) createLoan(c echo.Context) error {
+	var req CreateDigitalLoanRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if api.store == nil {
+		return ebooksDisabledError(c)
+	}
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, req.BookID)
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+	files, err := api.fileRepo.GetByBook(ctx, book.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving ebook files",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if len(files) == 0 {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book has no ebook",
+			ErrorCode: models.ErrCodeBookFileNotFound,
+		})
+	}
+	userID := api.authMw.GetUserFromContext(c).UserID
+	user, err := api.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	plan, err := api.planRepo.GetByID(user.MembershipPlanID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	loan := &models.DigitalLoan{
+		ID:     uuid.New().String(),
+		BookID: book.ID,
+		UserID: userID,
+	}
+	err = api.loanRepo.Create(ctx, loan, plan)
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return bookLookupError(c, err)
+	case errors.Is(err, repositories.ErrReferenceOnly):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "This book is for reference only and cannot be borrowed",
+			ErrorCode: models.ErrCodeBookReferenceOnly,
+		})
+	case errors.Is(err, repositories.ErrNoDigitalCopies):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "All digital copies of this book are on loan",
+			ErrorCode: models.ErrCodeNoDigitalCopies,
+		})
+	case errors.Is(err, repositories.ErrDigitalLoanExists):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You already have this book on loan",
+			ErrorCode: models.ErrCodeDigitalLoanExists,
+		})
+	case errors.Is(err, repositories.ErrLoanLimitReached):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You have reached your membership plan's loan limit",
+			ErrorCode: models.ErrCodeLoanLimitReached,
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating digital loan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	detail, err := api.loanDetail(c, loan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving digital loans",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	api.sendReceipt(c, "checkout", loan, book.Title)
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    detail,
+		Message: "Digital loan created successfully",
+	})
+}
+
+func (api *EbookAPI) returnLoan(c echo.Context) error {
+	loan, err := api.ownLoan(c)
+	if err != nil {
+		return digitalLoanLookupError(c, err)
+	}
+	err = api.loanRepo.Return(c.Request().Context(), loan)
+	if errors.Is(err, repositories.ErrDigitalLoanEnded) {
+		return digitalLoanEndedError(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error returning digital loan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	detail, err := api.loanDetail(c, loan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving digital loans",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	api.sendReceipt(c, "return", loan, detail.BookTitle)
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Digital loan returned successfully",
+	})
+}
+
+// sendReceipt notifies the member of a checkout or return, itemizing the
+// loans they have after it. Receipts are opt-in through the loan_receipt
+// notification preference. A receipt that fails is logged; the loan stands.
+func (api *EbookAPI) sendReceipt(c echo.Context, action string, loan *models.DigitalLoan, title string) {
+	ctx := c.Request().Context()
+	data := map[string]any{
+		"action": action,
+		"title":  title,
+	}
+	if loan.ReturnedDate != nil {
+		data["returned_date"] = *loan.ReturnedDate
+	} else {
+		data["due_date"] = loan.DueDate
+	}
+	items, err := api.receiptItems(ctx, loan.UserID)
+	if err == nil {
+		data["loans"] = items
+		err = api.notifier.Emit(ctx, notifications.Event{
+			Type:   notifications.EventLoanReceipt,
+			UserID: loan.UserID,
+			Data:   data,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send loan receipt",
+			"digital_loan_id", loan.ID,
+			"error", err,
+		)
+	}
+}
+
+// receiptItems lists a member's active loans, soonest due first, with the
+// title and due date of each.
+func (api *EbookAPI) receiptItems(ctx context.Context, userID string) ([]map[string]any, error) {
+	loans, err := api.loanRepo.GetActiveByUser(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	bookIDs := make([]string, len(loans))
+	for i, loan := range loans {
+		bookIDs[i] = loan.BookID
+	}
+	books, err := api.bookRepo.GetByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string, len(books))
+	for _, book := range books {
+		titles[book.ID] = book.Title
+	}
+	items := make([]map[string]any, len(loans))
+	for i, loan := range loans {
+		items[i] = map[string]any{
+			"title":    titles[loan.BookID],
+			"due_date": loan.DueDate,
+		}
+	}
+	return items, nil
+}
+
+// downloadLoan hands out a signed URL for one of the loaned book's files,
+// the EPUB unless format asks for another. The URL expires after the
+// download TTL or when the loan falls due, whichever is sooner; returning the
+// loan early does not revoke a URL already handed out.
+func (api *EbookAPI) downloadLoan(c echo.Context) error {
+	format := c.QueryParam("format")
+	if _, ok := ebookContentTypes[format]; format != "" && !ok {
+		return ebookFormatError(c)
+	}
+	if api.store == nil {
+		return ebooksDisabledError(c)
+	}
+	loan, err := api.ownLoan(c)
+	if err != nil {
+		return digitalLoanLookupError(c, err)
+	}
+	now := time.Now().UTC()
+	expires := min(api.downloadTTL, loan.DueDate.Sub(now)).Truncate(time.Second)
+	if !loan.Active(now) || expires <= 0 {
+		return digitalLoanEndedError(c)
+	}
+
+	ctx := c.Request().Context()
+	var file *models.BookFile
+	if format == "" {
+		files, err := api.fileRepo.GetByBook(ctx, loan.BookID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error retrieving ebook files",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		if len(files) == 0 {
+			return bookFileLookupError(c, gorm.ErrRecordNotFound)
+		}
+		file = &files[0]
+	} else {
+		file, err = api.fileRepo.Get(ctx, loan.BookID, format)
+		if err != nil {
+			return bookFileLookupError(c, err)
+		}
+	}
+	book, err := api.bookRepo.GetByID(ctx, loan.BookID)
+	if err != nil {
+		return bookLookupError(c, err)
+	}
+
+	query := url.Values{}
+	query.Set("response-content-type", file.ContentType)
+	query.Set("response-content-disposition", `attachment; filename="`+ebookFilename(book.Title)+"."+file.Format+`"`)
+	link, err := api.store.PresignGet(file.ObjectKey, query, expires)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating download link",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	// The link is a bearer credential for the file.
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, models.Response{
+		Data: EbookDownload{
+			Format:    file.Format,
+			URL:       link,
+			ExpiresAt: now.Add(expires).In(userLocation(c)),
+		},
+		Message: "Download link created successfully",
+	})
+}
+
+// ownLoan loads the loan in the URL. Other members' loans are reported as
+// not found.
+func (api *EbookAPI) ownLoan(c echo.Context) (*models.DigitalLoan, error) {
+	loan, err := api.loanRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if loan.UserID != api.authMw.GetUserFromContext(c).UserID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return loan, nil
+}
+
+func (api *EbookAPI) loanDetail(c echo.Context, loan *models.DigitalLoan) (DigitalLoanDetail, error) {
+	ctx := c.Request().Context()
+	book, err := api.bookRepo.GetByID(ctx, loan.BookID)
+	if err == gorm.ErrRecordNotFound {
+		book, err = &models.Book{}, nil
+	}
+	if err != nil {
+		return DigitalLoanDetail{}, err
+	}
+	files, err := api.fileRepo.GetByBook(ctx, loan.BookID)
+	if err != nil {
+		return DigitalLoanDetail{}, err
+	}
+	formats := make([]string, len(files))
+	for i, file := range files {
+		formats[i] = file.Format
+	}
+	location := userLocation(c)
+	return DigitalLoanDetail{
+		ID:           loan.ID,
+		BookID:       loan.BookID,
+		BookTitle:    book.Title,
+		Formats:      formats,
+		DueDate:      loan.DueDate.In(location),
+		ReturnedDate: timeIn(loan.ReturnedDate, location),
+		CreatedDate:  loan.CreatedDate.In(location),
+	}, nil
+}
+
+// deleteObject removes a file's object. A failure leaves an unreferenced
+// object behind and is only logged.
+func (api *EbookAPI) deleteObject(c echo.Context, key string) {
+	if err := api.store.Delete(c.Request().Context(), key); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to delete ebook file object",
+			"key", key,
+			"error", err,
+		)
+	}
+}
+
+// isEbookFormat checks the file's signature. An EPUB is a ZIP archive whose
+// first entry is an uncompressed "mimetype" file naming the EPUB type.
+func isEbookFormat(format string, data []byte) bool {
+	switch format {
+	case models.BookFileFormatEPUB:
+		return bytes.HasPrefix(data, []byte("PK\x03\x04")) &&
+			len(data) > 30 && bytes.HasPrefix(data[30:], []byte("mimetypeapplication/epub+zip"))
+	case models.BookFileFormatPDF:
+		return bytes.HasPrefix(data, []byte("%PDF-"))
+	}
+	return false
+}
+
+// ebookFilename turns a title into a download file name, keeping only
+// characters that are safe in a Content-Disposition header.
+func ebookFilename(title string) string {
+	var b strings.Builder
+	for _, r := range title {
+		switch {
+		case 'A' <= r && r <= 'Z', 'a' <= r && r <= 'z', '0' <= r && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "ebook"
+	}
+	return b.String()
+}
+
+func toBookFileDetails(files []models.BookFile, location *time.Location) []BookFileDetail {
+	details := make([]BookFileDetail, len(files))
+	for i := range files {
+		details[i] = toBookFileDetail(&files[i], location)
+	}
+	return details
+}
+
+func toBookFileDetail(file *models.BookFile, location *time.Location) BookFileDetail {
+	return BookFileDetail{
+		Format:      file.Format,
+		ContentType: file.ContentType,
+		SizeBytes:   file.SizeBytes,
+		SHA256:      file.SHA256,
+		UpdatedDate: file.UpdatedDate.In(location),
+	}
+}
+
+func ebookFormatError(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, models.Response{
+		Message:   "Request validation failed",
+		ErrorCode: models.ErrCodeValidation,
+		Errors: []models.FieldError{
+			{
+				Field:   "format",
+				Message: "must be one of: epub, pdf",
+			},
+		},
+	})
+}
+
+func bookFileLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Ebook file not found",
+			ErrorCode: models.ErrCodeBookFileNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving ebook files",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func digitalLoanLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Digital loan not found",
+			ErrorCode: models.ErrCodeDigitalLoanNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving digital loans",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func digitalLoanEndedError(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "Digital loan has ended",
+		ErrorCode: models.ErrCodeDigitalLoanEnded,
+	})
+}
+
+func ebooksDisabledError(c echo.Context) error {
+	return c.JSON(http.StatusServiceUnavailable, models.Response{
+		Message:   "Ebook lending is not enabled",
+		ErrorCode: models.ErrCodeServiceUnavailable,
+	})
+}
@@ -0,0 +1,84 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/auth"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HTTPErrorHandler writes errors that reach echo in the models.Response
+// envelope: unmatched routes and methods, auth middleware rejections and
+// panics caught by the recover middleware. Handlers keep writing their own
+// responses. Server errors are logged and their details withheld, except
+// 503s: those are deliberate refusals, such as an open circuit breaker, whose
+// message tells the client what is going on.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	response := models.Response{
+		Message:   http.StatusText(http.StatusInternalServerError),
+		ErrorCode: models.ErrCodeInternal,
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		response.Message = http.StatusText(status)
+		if message, ok := httpErr.Message.(string); ok && (status < http.StatusInternalServerError || status == http.StatusServiceUnavailable) {
+			response.Message = message
+		}
+		response.ErrorCode = statusErrorCode(status)
+	}
+	var authErr *auth.Error
+	if errors.As(err, &authErr) {
+		response.ErrorCode = authErr.Code
+	}
+
+	if status >= http.StatusInternalServerError && status != http.StatusServiceUnavailable {
+		slog.ErrorContext(c.Request().Context(), "Request failed",
+			"method", c.Request().Method,
+			"path", c.Path(),
+			"error", err,
+		)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, response)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to write error response",
+			"error", err,
+		)
+	}
+}
+
+func statusErrorCode(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return models.ErrCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return models.ErrCodeMethodNotAllowed
+	case http.StatusUnauthorized:
+		return models.ErrCodeAuthenticationRequired
+	case http.StatusForbidden:
+		return models.ErrCodeInsufficientPermissions
+	case http.StatusRequestEntityTooLarge:
+		return models.ErrCodePayloadTooLarge
+	case http.StatusServiceUnavailable:
+		return models.ErrCodeServiceUnavailable
+	case http.StatusTooManyRequests:
+		return models.ErrCodeRateLimitExceeded
+	}
+	if status >= http.StatusInternalServerError {
+		return models.ErrCodeInternal
+	}
+	return models.ErrCodeInvalidRequest
+}
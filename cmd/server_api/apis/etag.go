@@ -0,0 +1,46 @@
+package apis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// jsonWithETag writes a 200 JSON response tagged with a hash of its body, or
+// a bodiless 304 when the client already holds that representation. Hashing
+// the body covers list pages too, where membership and totals change without
+// any single book's updated_date moving.
+func jsonWithETag(c echo.Context, body any) error {
+	data, err := json.Marshal(localize(c, body))
+	if err != nil {
+		return err
+	}
+	return blobWithETag(c, echo.MIMEApplicationJSON, data)
+}
+
+func blobWithETag(c echo.Context, contentType string, data []byte) error {
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	header.Add(echo.HeaderVary, "Accept")
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/events"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const eventStreamHeartbeat = 30 * time.Second
+
+type EventAPI struct {
+	broker *events.Broker
+}
+
+func NewEventAPI(broker *events.Broker) *EventAPI {
+	return &EventAPI{
+		broker: broker,
+	}
+}
+
+func (api *EventAPI) Setup(group *echo.Group) {
+	group.GET("/stream", api.stream)
+}
+
+func (api *EventAPI) stream(c echo.Context) error {
+	messages, unsubscribe := api.broker.Subscribe()
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case msg := <-messages:
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", msg.Event, msg.Data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// exportBatchSize is how many rows an export reads and sends at a time.
+const exportBatchSize = 500
+
+// ExportAPI streams whole tables as CSV downloads, unlike the CSV output of
+// list endpoints, which is a single page.
+type ExportAPI struct {
+	exportRepo *repositories.ExportRepository
+	branches   *BranchAccess
+}
+
+var digitalLoanCSVHeader = []string{
+	"id",
+	"book_id",
+	"book_title",
+	"user_id",
+	"user_email",
+	"created_date",
+	"due_date",
+	"returned_date",
+	"status",
+}
+
+// Payment exports are laid out for import into accounting software: Xero's
+// bank statement CSV and QuickBooks Online's three-column bank CSV.
+const (
+	paymentFormatXero       = "xero"
+	paymentFormatQuickBooks = "quickbooks"
+)
+
+var xeroPaymentCSVHeader = []string{
+	"Date",
+	"Amount",
+	"Payee",
+	"Description",
+	"Reference",
+}
+
+var quickBooksPaymentCSVHeader = []string{
+	"Date",
+	"Description",
+	"Amount",
+}
+
+var memberCSVHeader = []string{
+	"id",
+	"email",
+	"first_name",
+	"last_name",
+	"card_number",
+	"membership_plan_id",
+	"branch_id",
+	"status",
+	"created_date",
+	"last_login_at",
+	"engagement",
+}
+
+func NewExportAPI(exportRepo *repositories.ExportRepository, branches *BranchAccess) *ExportAPI {
+	return &ExportAPI{
+		exportRepo: exportRepo,
+		branches:   branches,
+	}
+}
+
+// Setup registers the exports under /admin/exports.
+func (api *ExportAPI) Setup(group *echo.Group) {
+	group.GET("/books", api.exportBooks)
+	group.GET("/digital-loans", api.exportDigitalLoans)
+	group.GET("/members", api.exportMembers)
+	group.GET("/payments", api.exportPayments)
+}
+
+// exportBooks streams the catalog with the filters of GET /books.
+func (api *ExportAPI) exportBooks(c echo.Context) error {
+	minRating, ok := parseMinRating(c.QueryParam("min_rating"))
+	if !ok {
+		return queryParamError(c, &models.FieldError{Field: "min_rating", Message: "min_rating must be a number between 0 and 5"})
+	}
+	branchID := c.QueryParam("branch_id")
+	if branchID != "" {
+		if _, err := api.branches.VisibleBranch(c, branchID); err != nil {
+			return branchLookupError(c, err)
+		}
+	}
+	filter := repositories.BookFilter{
+		Status:    c.QueryParam("status"),
+		Genre:     c.QueryParam("genre"),
+		Author:    c.QueryParam("author"),
+		MinRating: minRating,
+	}
+	stream := newCSVStream(c, exportFilename("books", userLocation(c)), bookCSVHeader)
+	err := api.exportRepo.EachBook(c.Request().Context(), filter, branchID, exportBatchSize, func(books []models.Book) error {
+		records := make([][]string, len(books))
+		for i := range books {
+			records[i] = bookCSVRecord(&books[i])
+		}
+		return stream.WriteBatch(records)
+	})
+	if err := stream.Close(err); err != nil {
+		return exportError(c)
+	}
+	return nil
+}
+
+// exportDigitalLoans streams the digital loans made between from and to,
+// whole days in the caller's zone. Branch staff get only their own members'
+// loans.
+func (api *ExportAPI) exportDigitalLoans(c echo.Context) error {
+	location := userLocation(c)
+	var fieldErrors []models.FieldError
+	filter := repositories.DigitalLoanExportFilter{}
+	filter.From, filter.To = exportPeriod(c, location, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	branchID, err := api.branches.UserBranchID(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	filter.BranchID = branchID
+	now := time.Now().UTC()
+	stream := newCSVStream(c, exportFilename("digital-loans", location), digitalLoanCSVHeader)
+	err = api.exportRepo.EachDigitalLoan(c.Request().Context(), filter, exportBatchSize, func(loans []repositories.DigitalLoanExport) error {
+		records := make([][]string, len(loans))
+		for i := range loans {
+			records[i] = digitalLoanCSVRecord(&loans[i], now, location)
+		}
+		return stream.WriteBatch(records)
+	})
+	if err := stream.Close(err); err != nil {
+		return exportError(c)
+	}
+	return nil
+}
+
+// exportMembers streams the per-member rows behind the member engagement
+// report, with each member's engagement under the same dormant_days rule.
+// Branch staff get only their own branch's members.
+func (api *ExportAPI) exportMembers(c echo.Context) error {
+	dormantDays := defaultDormantDays
+	if daysStr := c.QueryParam("dormant_days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 {
+			return queryParamError(c, &models.FieldError{Field: "dormant_days", Message: "must be a positive integer"})
+		}
+		dormantDays = d
+	}
+	branchID, err := api.branches.UserBranchID(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	location := userLocation(c)
+	dormantSince := time.Now().UTC().AddDate(0, 0, -dormantDays)
+	stream := newCSVStream(c, exportFilename("members", location), memberCSVHeader)
+	err = api.exportRepo.EachMember(c.Request().Context(), branchID, exportBatchSize, func(members []models.User) error {
+		records := make([][]string, len(members))
+		for i := range members {
+			records[i] = memberCSVRecord(&members[i], dormantSince, location)
+		}
+		return stream.WriteBatch(records)
+	})
+	if err := stream.Close(err); err != nil {
+		return exportError(c)
+	}
+	return nil
+}
+
+// exportPayments streams the payments taken at the desk between from and to,
+// whole days in the caller's zone, in the accounting format named by
+// format. Branch staff get only their own branch's payments.
+func (api *ExportAPI) exportPayments(c echo.Context) error {
+	location := userLocation(c)
+	var fieldErrors []models.FieldError
+	format := c.QueryParam("format")
+	header := xeroPaymentCSVHeader
+	switch format {
+	case paymentFormatXero:
+	case paymentFormatQuickBooks:
+		header = quickBooksPaymentCSVHeader
+	case "":
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "format",
+			Message: "is required",
+		})
+	default:
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "format",
+			Message: "must be xero or quickbooks",
+		})
+	}
+	filter := repositories.PaymentExportFilter{}
+	filter.From, filter.To = exportPeriod(c, location, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	branchID, err := api.branches.UserBranchID(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	filter.BranchID = branchID
+	stream := newCSVStream(c, exportFilename("payments-"+format, location), header)
+	err = api.exportRepo.EachPayment(c.Request().Context(), filter, exportBatchSize, func(payments []repositories.PaymentExport) error {
+		records := make([][]string, len(payments))
+		for i := range payments {
+			records[i] = paymentCSVRecord(&payments[i], format, location)
+		}
+		return stream.WriteBatch(records)
+	})
+	if err := stream.Close(err); err != nil {
+		return exportError(c)
+	}
+	return nil
+}
+
+// exportPeriod reads the from and to days of an export, whole days in
+// location, as the range [from, to). Either may be zero, leaving that end
+// open.
+func exportPeriod(c echo.Context, location *time.Location, fieldErrors *[]models.FieldError) (from, to time.Time) {
+	from, fromOK := reportDateParam(c, "from", location, fieldErrors)
+	to, toOK := reportDateParam(c, "to", location, fieldErrors)
+	if toOK {
+		to = to.AddDate(0, 0, 1)
+	}
+	if fromOK && toOK && !to.After(from) {
+		*fieldErrors = append(*fieldErrors, models.FieldError{
+			Field:   "to",
+			Message: "must be on or after from",
+		})
+	}
+	return from, to
+}
+
+func exportError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error generating export",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+// exportFilename names a download after its table and today's date in the
+// caller's zone.
+func exportFilename(name string, location *time.Location) string {
+	return name + "-" + time.Now().In(location).Format(reportDateLayout) + ".csv"
+}
+
+func digitalLoanCSVRecord(loan *repositories.DigitalLoanExport, now time.Time, location *time.Location) []string {
+	status := "expired"
+	switch {
+	case loan.ReturnedDate != nil:
+		status = "returned"
+	case loan.Active(now):
+		status = "active"
+	}
+	return []string{
+		loan.ID,
+		loan.BookID,
+		loan.BookTitle,
+		loan.UserID,
+		loan.UserEmail,
+		timeValue(&loan.CreatedDate, location),
+		timeValue(&loan.DueDate, location),
+		timeValue(loan.ReturnedDate, location),
+		status,
+	}
+}
+
+func memberCSVRecord(member *models.User, dormantSince time.Time, location *time.Location) []string {
+	engagement := "active"
+	switch inactive, dormant := memberEngagement(member, dormantSince); {
+	case inactive:
+		engagement = "inactive"
+	case dormant:
+		engagement = "dormant"
+	}
+	return []string{
+		member.ID,
+		member.Email,
+		member.FirstName,
+		member.LastName,
+		member.CardNumber,
+		member.MembershipPlanID,
+		stringValue(member.BranchID),
+		member.Status,
+		timeValue(&member.CreatedDate, location),
+		timeValue(member.LastLoginAt, location),
+		engagement,
+	}
+}
+
+// paymentCSVRecord lays out a payment for format. Amounts are positive, as
+// money received, with two decimals. Xero dates are day first and QuickBooks
+// dates month first, each the day the payment was taken in location.
+func paymentCSVRecord(payment *repositories.PaymentExport, format string, location *time.Location) []string {
+	amount := strconv.FormatFloat(payment.Amount, 'f', 2, 64)
+	payee := strings.TrimSpace(stringValue(payment.UserFirstName) + " " + stringValue(payment.UserLastName))
+	description := "Card payment"
+	if payment.Method == models.PaymentMethodCash {
+		description = "Cash payment"
+	}
+	if payment.Description != nil && *payment.Description != "" {
+		description += ": " + *payment.Description
+	}
+	day := payment.CreatedDate.In(location)
+	if format == paymentFormatQuickBooks {
+		// The three-column format has no payee column.
+		if payee != "" {
+			description += " (" + payee + ")"
+		}
+		return []string{
+			day.Format("01/02/2006"),
+			description,
+			amount,
+		}
+	}
+	return []string{
+		day.Format("02/01/2006"),
+		amount,
+		payee,
+		description,
+		payment.ID,
+	}
+}
@@ -0,0 +1,130 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const newBooksFeedSize = 50
+
+type FeedAPI struct {
+	bookRepo repositories.BookRepo
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID       string        `xml:"id"`
+	Title    string        `xml:"title"`
+	Updated  string        `xml:"updated"`
+	Author   atomAuthor    `xml:"author"`
+	Category *atomCategory `xml:"category,omitempty"`
+	Summary  string        `xml:"summary,omitempty"`
+	Link     atomLink      `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func NewFeedAPI(bookRepo repositories.BookRepo) *FeedAPI {
+	return &FeedAPI{
+		bookRepo: bookRepo,
+	}
+}
+
+func (api *FeedAPI) Setup(group *echo.Group) {
+	group.GET("/new-books.atom", api.getNewBooksFeed)
+}
+
+func (api *FeedAPI) getNewBooksFeed(c echo.Context) error {
+	genre := c.QueryParam("genre")
+	var books []models.Book
+	var err error
+	if genre != "" {
+		books, err = api.bookRepo.GetByGenre(c.Request().Context(), genre, newBooksFeedSize, 0)
+	} else {
+		books, err = api.bookRepo.GetAll(c.Request().Context(), newBooksFeedSize, 0)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to retrieve books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	baseURL := c.Scheme() + "://" + c.Request().Host
+	selfURL := baseURL + c.Request().URL.Path
+	title := "New books"
+	if genre != "" {
+		selfURL += "?genre=" + url.QueryEscape(genre)
+		title = "New books: " + genre
+	}
+	updated := time.Unix(0, 0).UTC()
+	if len(books) > 0 {
+		updated = books[0].CreatedDate
+	}
+	feed := atomFeed{
+		ID:      selfURL,
+		Title:   title,
+		Updated: updated.Format(time.RFC3339),
+		Link: []atomLink{
+			{
+				Href: selfURL,
+				Rel:  "self",
+			},
+		},
+		Entries: make([]atomEntry, len(books)),
+	}
+	for i, book := range books {
+		entry := atomEntry{
+			ID:      "urn:uuid:" + book.ID,
+			Title:   book.Title,
+			Updated: book.CreatedDate.Format(time.RFC3339),
+			Author: atomAuthor{
+				Name: book.Author,
+			},
+			Link: atomLink{
+				Href: baseURL + "/api/v1/books/" + url.PathEscape(book.ID),
+			},
+		}
+		if book.Genre != nil {
+			entry.Category = &atomCategory{
+				Term: *book.Genre,
+			}
+		}
+		if book.Description != nil {
+			entry.Summary = *book.Description
+		}
+		feed.Entries[i] = entry
+	}
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to build feed",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.Blob(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
@@ -2,44 +2,123 @@ package apis
 
 import (
 	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/circuitbreaker"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
+const dependencyCheckTimeout = 2 * time.Second
+
 type HealthzAPI struct {
-	db *gorm.DB
+	db       *gorm.DB
+	checks   []dependencyCheck
+	breakers map[string]*circuitbreaker.Breaker
+}
+
+type dependencyCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status          string                             `json:"status"`
+	Dependencies    map[string]DependencyStatus        `json:"dependencies"`
+	CircuitBreakers map[string]circuitbreaker.Snapshot `json:"circuit_breakers,omitempty"`
 }
 
 func NewHealthzAPI(db *gorm.DB) *HealthzAPI {
-	return &HealthzAPI{
+	api := &HealthzAPI{
 		db: db,
 	}
+	return api.AddCheck("database", api.pingDatabase)
+}
+
+// AddCheck registers another dependency for the readiness probe.
+func (a *HealthzAPI) AddCheck(name string, check func(ctx context.Context) error) *HealthzAPI {
+	a.checks = append(a.checks, dependencyCheck{
+		name:  name,
+		check: check,
+	})
+	return a
+}
+
+// AddCircuitBreaker reports the breaker in the readiness probe, which fails
+// while it is open so load balancers drain the instance.
+func (a *HealthzAPI) AddCircuitBreaker(name string, breaker *circuitbreaker.Breaker) *HealthzAPI {
+	if a.breakers == nil {
+		a.breakers = make(map[string]*circuitbreaker.Breaker)
+	}
+	a.breakers[name] = breaker
+	return a
 }
 
 func (a *HealthzAPI) Setup(g *echo.Group) {
-	g.GET("/healthz", a.checkHealth)
+	g.GET("/healthz", a.checkReady)
+	g.GET("/healthz/live", a.checkLive)
+	g.GET("/healthz/ready", a.checkReady)
 }
 
-func (a *HealthzAPI) checkHealth(c echo.Context) error {
+func (a *HealthzAPI) checkLive(c echo.Context) error {
+	return c.JSON(
+		http.StatusOK,
+		models.Response{
+			Message: "alive",
+		},
+	)
+}
 
-	sqlDB, err := a.db.DB()
-	if err != nil {
-		return c.JSON(
-			http.StatusInternalServerError,
-			models.Response{
-				Message: err.Error(),
-			},
-		)
+func (a *HealthzAPI) checkReady(c echo.Context) error {
+	ready := true
+	dependencies := make(map[string]DependencyStatus, len(a.checks))
+	for _, dep := range a.checks {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), dependencyCheckTimeout)
+		start := time.Now()
+		err := dep.check(ctx)
+		cancel()
+		status := DependencyStatus{
+			Status:    "ok",
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if err != nil {
+			ready = false
+			status.Status = "down"
+			status.Error = err.Error()
+		}
+		dependencies[dep.name] = status
+	}
+	var breakers map[string]circuitbreaker.Snapshot
+	for name, breaker := range a.breakers {
+		if breakers == nil {
+			breakers = make(map[string]circuitbreaker.Snapshot, len(a.breakers))
+		}
+		snapshot := breaker.Snapshot()
+		if snapshot.State == circuitbreaker.StateOpen {
+			ready = false
+		}
+		breakers[name] = snapshot
 	}
 
-	err = sqlDB.Ping()
-	if err != nil {
+	if !ready {
 		return c.JSON(
-			http.StatusInternalServerError,
+			http.StatusServiceUnavailable,
 			models.Response{
-				Message: err.Error(),
+				Message:   "not ready",
+				ErrorCode: models.ErrCodeServiceUnavailable,
+				Data: ReadinessResponse{
+					Status:          "down",
+					Dependencies:    dependencies,
+					CircuitBreakers: breakers,
+				},
 			},
 		)
 	}
@@ -48,6 +127,19 @@ func (a *HealthzAPI) checkHealth(c echo.Context) error {
 		http.StatusOK,
 		models.Response{
 			Message: "healthy",
+			Data: ReadinessResponse{
+				Status:          "ok",
+				Dependencies:    dependencies,
+				CircuitBreakers: breakers,
+			},
 		},
 	)
 }
+
+func (a *HealthzAPI) pingDatabase(ctx context.Context) error {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,79 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+const languageContextKey = "i18n.language"
+
+type translator struct {
+	catalog  *i18n.Catalog
+	language string
+}
+
+// Localize negotiates the response language from Accept-Language. JSON
+// responses written afterwards have their messages translated by the
+// serializer, so handlers keep using the English text as the message ID.
+func Localize(catalog *i18n.Catalog) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			language := catalog.Negotiate(c.Request().Header.Get("Accept-Language"))
+			c.Set(languageContextKey, &translator{
+				catalog:  catalog,
+				language: language,
+			})
+			header := c.Response().Header()
+			header.Set("Content-Language", language)
+			header.Add(echo.HeaderVary, "Accept-Language")
+			return next(c)
+		}
+	}
+}
+
+// localize returns a copy of a response body with its messages translated.
+// Besides models.Response it covers the map bodies written by pkg middlewares.
+func localize(c echo.Context, body any) any {
+	t, ok := c.Get(languageContextKey).(*translator)
+	if !ok {
+		return body
+	}
+	switch body := body.(type) {
+	case models.Response:
+		return t.response(body)
+	case *models.Response:
+		if body == nil {
+			return body
+		}
+		response := t.response(*body)
+		return &response
+	case map[string]string:
+		translated := make(map[string]string, len(body))
+		for key, value := range body {
+			translated[key] = value
+		}
+		if message, ok := body["message"]; ok {
+			translated["message"] = t.catalog.Translate(t.language, message)
+		}
+		return translated
+	}
+	return body
+}
+
+func (t *translator) response(response models.Response) models.Response {
+	response.Message = t.catalog.Translate(t.language, response.Message)
+	if len(response.Errors) > 0 {
+		fieldErrors := make([]models.FieldError, len(response.Errors))
+		for i, fieldErr := range response.Errors {
+			fieldErrors[i] = models.FieldError{
+				Field:   fieldErr.Field,
+				Rule:    fieldErr.Rule,
+				Message: t.catalog.Translate(t.language, fieldErr.Message),
+			}
+		}
+		response.Errors = fieldErrors
+	}
+	return response
+}
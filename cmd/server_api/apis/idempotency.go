@@ -0,0 +1,208 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	idempotentResourceKey    = "idempotent_resource"
+)
+
+// IdempotentReplayFunc rebuilds the response to a replayed request from the
+// resource the first request created, for routes whose responses must not
+// be stored, such as those carrying tokens.
+type IdempotentReplayFunc func(c echo.Context, status int, resourceID string) error
+
+type Idempotency struct {
+	idempotencyRepo *repositories.IdempotencyKeyRepository
+	authMw          *auth.Middleware
+	ttl             time.Duration
+}
+
+func NewIdempotency(idempotencyRepo *repositories.IdempotencyKeyRepository, authMw *auth.Middleware, ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		idempotencyRepo: idempotencyRepo,
+		authMw:          authMw,
+		ttl:             ttl,
+	}
+}
+
+// Middleware replays the first response to a request carrying an
+// Idempotency-Key header. Keys are scoped to the authenticated user (or the
+// client IP for anonymous requests), method and path; reusing one with a
+// different body is rejected. Failed requests (5xx) release the key so the
+// client can retry. Register it after RequireAuth so the user is known.
+// Responses are stored verbatim, so use ReplayMiddleware on routes that
+// return credentials.
+func (m *Idempotency) Middleware() echo.MiddlewareFunc {
+	return m.middleware(nil)
+}
+
+// ReplayMiddleware is Middleware for routes whose handler calls
+// setIdempotentResource. Only the resource ID is stored for those
+// responses, and replay rebuilds them; responses without a resource, such
+// as validation errors, are stored verbatim.
+func (m *Idempotency) ReplayMiddleware(replay IdempotentReplayFunc) echo.MiddlewareFunc {
+	return m.middleware(replay)
+}
+
+func (m *Idempotency) middleware(replay IdempotentReplayFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, models.Response{
+					Message:   "Invalid Idempotency-Key header",
+					ErrorCode: models.ErrCodeValidation,
+					Errors: []models.FieldError{
+						{
+							Field:   IdempotencyKeyHeader,
+							Message: "Idempotency-Key must be at most 255 characters",
+						},
+					},
+				})
+			}
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return bindError(c, err)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+			requestHash := hex.EncodeToString(sum[:])
+
+			userID := "ip:" + c.RealIP()
+			if claims := m.authMw.GetUserFromContext(c); claims != nil {
+				userID = claims.UserID
+			}
+			method := c.Request().Method
+			path := c.Request().URL.Path
+
+			existing, err := m.idempotencyRepo.Get(userID, method, path, key)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return idempotencyInternalError(c)
+			}
+			if existing != nil && time.Now().UTC().After(existing.ExpiresAt) {
+				if err := m.idempotencyRepo.Release(existing.ID); err != nil {
+					return idempotencyInternalError(c)
+				}
+				existing = nil
+			}
+			if existing != nil {
+				return replayIdempotent(c, existing, requestHash, replay)
+			}
+
+			record := &models.IdempotencyKey{
+				ID:             uuid.New().String(),
+				IdempotencyKey: key,
+				UserID:         userID,
+				Method:         method,
+				Path:           path,
+				RequestHash:    requestHash,
+				ExpiresAt:      time.Now().UTC().Add(m.ttl),
+			}
+			if err := m.idempotencyRepo.Create(record); err != nil {
+				// Lost the race to a concurrent request with the same key.
+				existing, getErr := m.idempotencyRepo.Get(userID, method, path, key)
+				if getErr != nil {
+					return idempotencyInternalError(c)
+				}
+				return replayIdempotent(c, existing, requestHash, replay)
+			}
+
+			recorder := &responseRecorder{
+				ResponseWriter: c.Response().Writer,
+			}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				if releaseErr := m.idempotencyRepo.Release(record.ID); releaseErr != nil {
+					c.Logger().Error(releaseErr)
+				}
+				return err
+			}
+			contentType := c.Response().Header().Get(echo.HeaderContentType)
+			responseBody := recorder.body.String()
+			resourceID, _ := c.Get(idempotentResourceKey).(string)
+			if replay == nil {
+				resourceID = ""
+			}
+			if resourceID != "" {
+				responseBody = ""
+			}
+			if completeErr := m.idempotencyRepo.Complete(record.ID, status, contentType, responseBody, resourceID); completeErr != nil {
+				c.Logger().Error(completeErr)
+			}
+			return nil
+		}
+	}
+}
+
+func replayIdempotent(c echo.Context, existing *models.IdempotencyKey, requestHash string, replay IdempotentReplayFunc) error {
+	if existing.RequestHash != requestHash {
+		return c.JSON(http.StatusUnprocessableEntity, models.Response{
+			Message:   "Idempotency-Key was already used with a different request body",
+			ErrorCode: models.ErrCodeIdempotencyKeyReused,
+		})
+	}
+	if existing.StatusCode == 0 {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "A request with this Idempotency-Key is still being processed",
+			ErrorCode: models.ErrCodeIdempotencyInProgress,
+		})
+	}
+	c.Response().Header().Set(IdempotentReplayedHeader, "true")
+	if existing.ResourceID != "" && replay != nil {
+		return replay(c, existing.StatusCode, existing.ResourceID)
+	}
+	return c.Blob(existing.StatusCode, existing.ContentType, []byte(existing.ResponseBody))
+}
+
+// setIdempotentResource records the resource the handler created, so
+// ReplayMiddleware stores its ID instead of the response body.
+func setIdempotentResource(c echo.Context, id string) {
+	c.Set(idempotentResourceKey, id)
+}
+
+func idempotencyInternalError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error checking idempotency key",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+// responseRecorder copies the response body while passing it through.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
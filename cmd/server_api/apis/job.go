@@ -0,0 +1,223 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/jobs"
+	"book-management-system/cmd/server_api/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JobAPI struct {
+	inactiveAccountJob *jobs.InactiveAccountJob
+	dailyStatsJob      *jobs.DailyStatsJob
+	warehouseExportJob *jobs.WarehouseExportJob
+	lowStockAlertJob   *jobs.LowStockAlertJob
+	enrichmentJob      *jobs.MetadataEnrichmentJob
+	savedSearchJob     *jobs.SavedSearchAlertJob
+	loanReminderJob    *jobs.LoanReminderJob
+	retentionJob       *jobs.RetentionJob
+}
+
+// NewJobAPI takes a nil warehouseExportJob when the export is disabled.
+func NewJobAPI(inactiveAccountJob *jobs.InactiveAccountJob, dailyStatsJob *jobs.DailyStatsJob, warehouseExportJob *jobs.WarehouseExportJob, lowStockAlertJob *jobs.LowStockAlertJob, enrichmentJob *jobs.MetadataEnrichmentJob, savedSearchJob *jobs.SavedSearchAlertJob, loanReminderJob *jobs.LoanReminderJob, retentionJob *jobs.RetentionJob) *JobAPI {
+	return &JobAPI{
+		inactiveAccountJob: inactiveAccountJob,
+		dailyStatsJob:      dailyStatsJob,
+		warehouseExportJob: warehouseExportJob,
+		lowStockAlertJob:   lowStockAlertJob,
+		enrichmentJob:      enrichmentJob,
+		savedSearchJob:     savedSearchJob,
+		loanReminderJob:    loanReminderJob,
+		retentionJob:       retentionJob,
+	}
+}
+
+func (api *JobAPI) Setup(group *echo.Group) {
+	group.POST("/inactive-accounts", api.runInactiveAccounts)
+	group.POST("/daily-stats", api.runDailyStats)
+	group.POST("/warehouse-export", api.runWarehouseExport)
+	group.POST("/low-stock-alerts", api.runLowStockAlerts)
+	group.POST("/metadata-enrichment", api.runMetadataEnrichment)
+	group.GET("/metadata-enrichment", api.getMetadataEnrichment)
+	group.POST("/saved-search-alerts", api.runSavedSearchAlerts)
+	group.POST("/loan-reminders", api.runLoanReminders)
+	group.POST("/retention", api.runRetention)
+}
+
+func (api *JobAPI) runInactiveAccounts(c echo.Context) error {
+	dryRun := true
+	if dryRunStr := c.QueryParam("dry_run"); dryRunStr != "" {
+		d, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.Response{
+				Message:   "dry_run must be a boolean",
+				ErrorCode: models.ErrCodeValidation,
+				Errors: []models.FieldError{
+					{
+						Field:   "dry_run",
+						Message: "dry_run must be a boolean",
+					},
+				},
+			})
+		}
+		dryRun = d
+	}
+	report, err := api.inactiveAccountJob.Run(c.Request().Context(), dryRun)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running inactive account job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Inactive account job completed successfully",
+	})
+}
+
+func (api *JobAPI) runDailyStats(c echo.Context) error {
+	stat, err := api.dailyStatsJob.Run(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running daily stats job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toDailyStatDetail(stat, userLocation(c)),
+		Message: "Daily stats job completed successfully",
+	})
+}
+
+func (api *JobAPI) runWarehouseExport(c echo.Context) error {
+	if api.warehouseExportJob == nil {
+		return c.JSON(http.StatusServiceUnavailable, models.Response{
+			Message:   "Warehouse export is not enabled",
+			ErrorCode: models.ErrCodeServiceUnavailable,
+		})
+	}
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	var fieldErrors []models.FieldError
+	if parsed, ok := reportDateParam(c, "date", time.UTC, &fieldErrors); ok {
+		day = parsed
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	report, err := api.warehouseExportJob.Run(c.Request().Context(), day)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running warehouse export job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Warehouse export job completed successfully",
+	})
+}
+
+func (api *JobAPI) runLowStockAlerts(c echo.Context) error {
+	report, err := api.lowStockAlertJob.Run(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running low stock alert job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Low stock alert job completed successfully",
+	})
+}
+
+// runMetadataEnrichment starts the enrichment job in the background over at
+// most limit books. Its progress is at GET /admin/jobs/metadata-enrichment
+// and its proposals under /admin/enrichments.
+func (api *JobAPI) runMetadataEnrichment(c echo.Context) error {
+	limit, _, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	report, started := api.enrichmentJob.Trigger(limit)
+	if !started {
+		return c.JSON(http.StatusConflict, models.Response{
+			Data:      report,
+			Message:   "Metadata enrichment job is already running",
+			ErrorCode: models.ErrCodeJobRunning,
+		})
+	}
+	return c.JSON(http.StatusAccepted, models.Response{
+		Data:    report,
+		Message: "Metadata enrichment job started",
+	})
+}
+
+// getMetadataEnrichment reports the current or last run, with null data
+// before the first run.
+func (api *JobAPI) getMetadataEnrichment(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    api.enrichmentJob.Status(),
+		Message: "Metadata enrichment job status retrieved successfully",
+	})
+}
+
+func (api *JobAPI) runSavedSearchAlerts(c echo.Context) error {
+	report, err := api.savedSearchJob.Run(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running saved search alert job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Saved search alert job completed successfully",
+	})
+}
+
+func (api *JobAPI) runLoanReminders(c echo.Context) error {
+	report, err := api.loanReminderJob.Run(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running loan reminder job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Loan reminder job completed successfully",
+	})
+}
+
+// runRetention runs the retention job. It only reports what would be purged
+// unless dry_run=false.
+func (api *JobAPI) runRetention(c echo.Context) error {
+	dryRun := true
+	if dryRunStr := c.QueryParam("dry_run"); dryRunStr != "" {
+		d, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return queryParamError(c, &models.FieldError{Field: "dry_run", Message: "dry_run must be a boolean"})
+		}
+		dryRun = d
+	}
+	report, err := api.retentionJob.Run(c.Request().Context(), dryRun)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running retention job",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Retention job completed successfully",
+	})
+}
@@ -0,0 +1,590 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// LibraryEventAPI publishes events and announcements and takes member RSVPs.
+// Events at a local branch are only shown to those who may see the branch,
+// and only that branch's staff may manage them; network-wide events are
+// managed by system admins.
+type LibraryEventAPI struct {
+	eventRepo *repositories.LibraryEventRepository
+	userRepo  repositories.UserRepo
+	branches  *BranchAccess
+	authMw    *auth.Middleware
+}
+
+// LibraryEventRequest creates or replaces an event. Announcements start
+// showing at starts_at, or straight away when it is left out, and cannot take
+// RSVPs.
+type LibraryEventRequest struct {
+	Kind        string     `json:"kind" validate:"required,oneof=event announcement"`
+	Title       string     `json:"title" validate:"required,max=200"`
+	Description *string    `json:"description,omitempty"`
+	BranchID    *string    `json:"branch_id,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	RSVPEnabled bool       `json:"rsvp_enabled"`
+	Capacity    *int       `json:"capacity,omitempty" validate:"omitempty,min=1"`
+}
+
+type LibraryEventDetail struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	BranchID    *string    `json:"branch_id"`
+	Location    *string    `json:"location"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	RSVPEnabled bool       `json:"rsvp_enabled"`
+	Capacity    *int       `json:"capacity"`
+	RSVPCount   int64      `json:"rsvp_count"`
+	SpotsLeft   *int64     `json:"spots_left"`
+	CreatedDate time.Time  `json:"created_date"`
+	UpdatedDate time.Time  `json:"updated_date"`
+}
+
+type LibraryEventListResponse struct {
+	Events []LibraryEventDetail `json:"events"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+type EventRSVPDetail struct {
+	UserID      string    `json:"user_id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+func NewLibraryEventAPI(eventRepo *repositories.LibraryEventRepository, userRepo repositories.UserRepo, branches *BranchAccess, authMw *auth.Middleware) *LibraryEventAPI {
+	return &LibraryEventAPI{
+		eventRepo: eventRepo,
+		userRepo:  userRepo,
+		branches:  branches,
+		authMw:    authMw,
+	}
+}
+
+// Setup registers the event listing and member RSVPs under /library-events.
+func (api *LibraryEventAPI) Setup(group *echo.Group) {
+	group.GET("", api.getEvents)
+	group.GET("/:id", api.getEvent)
+	group.POST("/:id/rsvp", api.createRSVP, api.authMw.RequireAuth())
+	group.DELETE("/:id/rsvp", api.deleteRSVP, api.authMw.RequireAuth())
+}
+
+// SetupPublic registers the event listing for the public OPAC.
+func (api *LibraryEventAPI) SetupPublic(group *echo.Group) {
+	group.GET("/library-events", api.getEvents)
+	group.GET("/library-events/:id", api.getEvent)
+}
+
+// SetupAdmin registers event management under /admin/library-events.
+func (api *LibraryEventAPI) SetupAdmin(group *echo.Group) {
+	group.GET("", api.getAllEvents)
+	group.POST("", api.createEvent)
+	group.PUT("/:id", api.updateEvent)
+	group.DELETE("/:id", api.deleteEvent)
+	group.GET("/:id/rsvps", api.getRSVPs)
+}
+
+// SetupRSVPs registers the caller's upcoming RSVPs under /me/rsvps.
+func (api *LibraryEventAPI) SetupRSVPs(group *echo.Group) {
+	group.GET("", api.getMyRSVPs)
+}
+
+// getEvents lists upcoming and ongoing events and the announcements showing
+// now, soonest first.
+func (api *LibraryEventAPI) getEvents(c echo.Context) error {
+	kind := c.QueryParam("kind")
+	if kind != "" && kind != models.LibraryEventKindEvent && kind != models.LibraryEventKindAnnouncement {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "kind must be event or announcement",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "kind",
+					Message: "kind must be event or announcement",
+				},
+			},
+		})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	branchID := c.QueryParam("branch_id")
+	if branchID != "" {
+		if _, err := api.branches.VisibleBranch(c, branchID); err != nil {
+			return branchLookupError(c, err)
+		}
+	}
+	hidden, err := api.branches.HiddenBranchIDs(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error resolving caller",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	filter := repositories.LibraryEventFilter{
+		Kind:            kind,
+		BranchID:        branchID,
+		HiddenBranchIDs: hidden,
+		CurrentAt:       time.Now().UTC(),
+	}
+	return api.eventList(c, filter, limit, offset)
+}
+
+// getAllEvents lists past and future events for staff, latest first. Branch
+// staff only see their own branch's events.
+func (api *LibraryEventAPI) getAllEvents(c echo.Context) error {
+	kind := c.QueryParam("kind")
+	branchID := c.QueryParam("branch_id")
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	if staffBranchID != "" {
+		if branchID != "" && branchID != staffBranchID {
+			return branchLookupError(c, errBranchAccessDenied)
+		}
+		branchID = staffBranchID
+	}
+	filter := repositories.LibraryEventFilter{
+		Kind:     kind,
+		BranchID: branchID,
+	}
+	return api.eventList(c, filter, limit, offset)
+}
+
+func (api *LibraryEventAPI) eventList(c echo.Context, filter repositories.LibraryEventFilter, limit, offset int) error {
+	events, err := api.eventRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving events",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.eventRepo.CountFiltered(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting events",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	eventDetails, err := api.eventDetails(c, events)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: LibraryEventListResponse{
+			Events: eventDetails,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+		Message: "Events retrieved successfully",
+	})
+}
+
+func (api *LibraryEventAPI) getEvent(c echo.Context) error {
+	event, err := api.visibleEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	eventDetails, err := api.eventDetails(c, []models.LibraryEvent{*event})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    eventDetails[0],
+		Message: "Event retrieved successfully",
+	})
+}
+
+func (api *LibraryEventAPI) createEvent(c echo.Context) error {
+	var req LibraryEventRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if fieldErr := checkLibraryEventRequest(&req); fieldErr != nil {
+		return fieldValidationError(c, fieldErr.Field, fieldErr.Rule, fieldErr.Message)
+	}
+	if err := api.requireManager(c, req.BranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	event := &models.LibraryEvent{
+		ID:        uuid.New().String(),
+		CreatedBy: &api.authMw.GetUserFromContext(c).UserID,
+	}
+	applyLibraryEventRequest(event, &req)
+	if err := api.eventRepo.Create(c.Request().Context(), event); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating event",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "library_event", event.ID, nil, toLibraryEventDetail(event, 0, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toLibraryEventDetail(event, 0, userLocation(c)),
+		Message: "Event created successfully",
+	})
+}
+
+// updateEvent replaces an event. Lowering the capacity below the current
+// RSVPs keeps them but takes no more.
+func (api *LibraryEventAPI) updateEvent(c echo.Context) error {
+	var req LibraryEventRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if fieldErr := checkLibraryEventRequest(&req); fieldErr != nil {
+		return fieldValidationError(c, fieldErr.Field, fieldErr.Rule, fieldErr.Message)
+	}
+	event, err := api.managedEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	if err := api.requireManager(c, req.BranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	counts, err := api.eventRepo.CountRSVPs(c.Request().Context(), []string{event.ID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	before := toLibraryEventDetail(event, counts[event.ID], time.UTC)
+	applyLibraryEventRequest(event, &req)
+	if err := api.eventRepo.Update(c.Request().Context(), event); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating event",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "library_event", event.ID, before, toLibraryEventDetail(event, counts[event.ID], time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toLibraryEventDetail(event, counts[event.ID], userLocation(c)),
+		Message: "Event updated successfully",
+	})
+}
+
+func (api *LibraryEventAPI) deleteEvent(c echo.Context) error {
+	event, err := api.managedEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	if err := api.eventRepo.Delete(c.Request().Context(), event.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting event",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "library_event", event.ID, toLibraryEventDetail(event, 0, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Event deleted successfully",
+	})
+}
+
+func (api *LibraryEventAPI) getRSVPs(c echo.Context) error {
+	event, err := api.managedEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	rsvps, err := api.eventRepo.GetRSVPs(c.Request().Context(), event.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	userIDs := make([]string, len(rsvps))
+	for i, rsvp := range rsvps {
+		userIDs[i] = rsvp.UserID
+	}
+	users, err := api.userRepo.GetByIDs(c.Request().Context(), userIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	usersByID := make(map[string]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+	rsvpDetails := make([]EventRSVPDetail, 0, len(rsvps))
+	for _, rsvp := range rsvps {
+		user, ok := usersByID[rsvp.UserID]
+		if !ok {
+			continue
+		}
+		rsvpDetails = append(rsvpDetails, EventRSVPDetail{
+			UserID:      user.ID,
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			Email:       user.Email,
+			CreatedDate: rsvp.CreatedDate.In(userLocation(c)),
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    rsvpDetails,
+		Message: "RSVPs retrieved successfully",
+	})
+}
+
+func (api *LibraryEventAPI) createRSVP(c echo.Context) error {
+	event, err := api.visibleEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	rsvp := &models.EventRSVP{
+		ID:      uuid.New().String(),
+		EventID: event.ID,
+		UserID:  api.authMw.GetUserFromContext(c).UserID,
+	}
+	if err := api.eventRepo.CreateRSVP(c.Request().Context(), rsvp); err != nil {
+		return eventLookupError(c, err)
+	}
+	return api.rsvpResponse(c, event, http.StatusCreated, "RSVP recorded")
+}
+
+func (api *LibraryEventAPI) deleteRSVP(c echo.Context) error {
+	event, err := api.visibleEvent(c, c.Param("id"))
+	if err != nil {
+		return eventLookupError(c, err)
+	}
+	err = api.eventRepo.DeleteRSVP(c.Request().Context(), event.ID, api.authMw.GetUserFromContext(c).UserID)
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "You have not RSVPed to this event",
+			ErrorCode: models.ErrCodeRSVPNotFound,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error cancelling RSVP",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return api.rsvpResponse(c, event, http.StatusOK, "RSVP cancelled")
+}
+
+// rsvpResponse answers an RSVP change with the event's updated counts.
+func (api *LibraryEventAPI) rsvpResponse(c echo.Context, event *models.LibraryEvent, status int, message string) error {
+	eventDetails, err := api.eventDetails(c, []models.LibraryEvent{*event})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(status, models.Response{
+		Data:    eventDetails[0],
+		Message: message,
+	})
+}
+
+// getMyRSVPs lists the events the caller has RSVPed to that have not ended.
+func (api *LibraryEventAPI) getMyRSVPs(c echo.Context) error {
+	userID := api.authMw.GetUserFromContext(c).UserID
+	events, err := api.eventRepo.GetRSVPedByUser(c.Request().Context(), userID, time.Now().UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving events",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	eventDetails, err := api.eventDetails(c, events)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting RSVPs",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    eventDetails,
+		Message: "Events retrieved successfully",
+	})
+}
+
+// checkLibraryEventRequest makes the checks on a LibraryEventRequest that its
+// struct tags cannot.
+func checkLibraryEventRequest(req *LibraryEventRequest) *models.FieldError {
+	switch {
+	case req.Kind == models.LibraryEventKindEvent && req.StartsAt == nil:
+		return &models.FieldError{Field: "starts_at", Rule: "required", Message: "is required"}
+	case req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt):
+		return &models.FieldError{Field: "ends_at", Rule: "gtfield", Message: "must be after starts_at"}
+	case req.RSVPEnabled && req.Kind != models.LibraryEventKindEvent:
+		return &models.FieldError{Field: "rsvp_enabled", Rule: "kind", Message: "is only allowed for events"}
+	case req.Capacity != nil && !req.RSVPEnabled:
+		return &models.FieldError{Field: "capacity", Rule: "rsvp_enabled", Message: "requires rsvp_enabled"}
+	}
+	return nil
+}
+
+// requireManager checks the caller may manage events at branchID, or
+// network-wide events when it is nil.
+func (api *LibraryEventAPI) requireManager(c echo.Context, branchID *string) error {
+	if branchID == nil {
+		return api.branches.RequireSystemAdmin(c)
+	}
+	_, err := api.branches.ManagedBranch(c, *branchID)
+	return err
+}
+
+// visibleEvent loads an event, hiding those at local branches the caller may
+// not see.
+func (api *LibraryEventAPI) visibleEvent(c echo.Context, id string) (*models.LibraryEvent, error) {
+	event, err := api.eventRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if event.BranchID != nil {
+		if _, err := api.branches.VisibleBranch(c, *event.BranchID); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// managedEvent loads an event the calling admin may manage, hiding other
+// branches' events from branch staff.
+func (api *LibraryEventAPI) managedEvent(c echo.Context, id string) (*models.LibraryEvent, error) {
+	event, err := api.eventRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && (event.BranchID == nil || *event.BranchID != staffBranchID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return event, nil
+}
+
+func (api *LibraryEventAPI) eventDetails(c echo.Context, events []models.LibraryEvent) ([]LibraryEventDetail, error) {
+	eventIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		if event.RSVPEnabled {
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+	counts, err := api.eventRepo.CountRSVPs(c.Request().Context(), eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	eventDetails := make([]LibraryEventDetail, len(events))
+	for i := range events {
+		eventDetails[i] = toLibraryEventDetail(&events[i], counts[events[i].ID], userLocation(c))
+	}
+	return eventDetails, nil
+}
+
+func applyLibraryEventRequest(event *models.LibraryEvent, req *LibraryEventRequest) {
+	startsAt := time.Now().UTC()
+	if req.StartsAt != nil {
+		startsAt = req.StartsAt.UTC()
+	}
+	var endsAt *time.Time
+	if req.EndsAt != nil {
+		utc := req.EndsAt.UTC()
+		endsAt = &utc
+	}
+	event.Kind = req.Kind
+	event.Title = req.Title
+	event.Description = req.Description
+	event.BranchID = req.BranchID
+	event.Location = req.Location
+	event.StartsAt = startsAt
+	event.EndsAt = endsAt
+	event.RSVPEnabled = req.RSVPEnabled
+	event.Capacity = req.Capacity
+}
+
+func eventLookupError(c echo.Context, err error) error {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Event not found",
+			ErrorCode: models.ErrCodeEventNotFound,
+		})
+	case errors.Is(err, repositories.ErrRSVPClosed):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "This event is not taking RSVPs",
+			ErrorCode: models.ErrCodeRSVPClosed,
+		})
+	case errors.Is(err, repositories.ErrRSVPExists):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You have already RSVPed to this event",
+			ErrorCode: models.ErrCodeRSVPExists,
+		})
+	case errors.Is(err, repositories.ErrEventFull):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "This event is full",
+			ErrorCode: models.ErrCodeEventFull,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving event",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toLibraryEventDetail(event *models.LibraryEvent, rsvpCount int64, location *time.Location) LibraryEventDetail {
+	detail := LibraryEventDetail{
+		ID:          event.ID,
+		Kind:        event.Kind,
+		Title:       event.Title,
+		Description: event.Description,
+		BranchID:    event.BranchID,
+		Location:    event.Location,
+		StartsAt:    event.StartsAt.In(location),
+		EndsAt:      timeIn(event.EndsAt, location),
+		RSVPEnabled: event.RSVPEnabled,
+		Capacity:    event.Capacity,
+		RSVPCount:   rsvpCount,
+		CreatedDate: event.CreatedDate.In(location),
+		UpdatedDate: event.UpdatedDate.In(location),
+	}
+	if event.RSVPEnabled && event.Capacity != nil {
+		spotsLeft := max(int64(*event.Capacity)-rsvpCount, 0)
+		detail.SpotsLeft = &spotsLeft
+	}
+	return detail
+}
@@ -0,0 +1,522 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type ListAPI struct {
+	listRepo *repositories.ListRepository
+	bookRepo repositories.BookRepo
+	authMw   *auth.Middleware
+}
+
+type SaveListRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type AddListItemRequest struct {
+	BookID string `json:"book_id" validate:"required"`
+}
+
+type ReorderListRequest struct {
+	BookIDs []string `json:"book_ids" validate:"required"`
+}
+
+type ListDetail struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	ShareToken  *string          `json:"share_token,omitempty"`
+	Items       []ListItemDetail `json:"items,omitempty"`
+	CreatedDate time.Time        `json:"created_date"`
+	UpdatedDate time.Time        `json:"updated_date"`
+}
+
+type ListItemDetail struct {
+	BookID    string    `json:"book_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	Position  int       `json:"position"`
+	AddedDate time.Time `json:"added_date"`
+}
+
+func NewListAPI(listRepo *repositories.ListRepository, bookRepo repositories.BookRepo, authMw *auth.Middleware) *ListAPI {
+	return &ListAPI{
+		listRepo: listRepo,
+		bookRepo: bookRepo,
+		authMw:   authMw,
+	}
+}
+
+func (api *ListAPI) Setup(group *echo.Group) {
+	group.GET("", api.getLists)
+	group.POST("", api.createList)
+	group.GET("/:id", api.getList)
+	group.PUT("/:id", api.updateList)
+	group.DELETE("/:id", api.deleteList)
+	group.POST("/:id/items", api.addItem)
+	group.DELETE("/:id/items/:bookId", api.removeItem)
+	group.PUT("/:id/order", api.reorderItems)
+	group.POST("/:id/share", api.shareList)
+	group.DELETE("/:id/share", api.unshareList)
+	group.POST("/import/goodreads", api.importGoodreads)
+}
+
+func (api *ListAPI) SetupShared(group *echo.Group) {
+	group.GET("/:token", api.getSharedList)
+}
+
+func (api *ListAPI) getLists(c echo.Context) error {
+	claims := api.authMw.GetUserFromContext(c)
+	lists, err := api.listRepo.GetByUserID(claims.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading lists",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	listDetails := make([]ListDetail, len(lists))
+	for i := range lists {
+		listDetails[i] = toListDetail(&lists[i], nil, userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    listDetails,
+		Message: "Reading lists retrieved successfully",
+	})
+}
+
+func (api *ListAPI) createList(c echo.Context) error {
+	var req SaveListRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "List name is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "name",
+					Message: "List name is required",
+				},
+			},
+		})
+	}
+	list := &models.ReadingList{
+		ID:     uuid.New().String(),
+		UserID: api.authMw.GetUserFromContext(c).UserID,
+		Name:   req.Name,
+	}
+	if err := api.listRepo.Create(list); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toListDetail(list, nil, userLocation(c)),
+		Message: "Reading list created successfully",
+	})
+}
+
+func (api *ListAPI) getList(c echo.Context) error {
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	items, err := api.listItemDetails(c.Request().Context(), list.ID, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toListDetail(list, items, userLocation(c)),
+		Message: "Reading list retrieved successfully",
+	})
+}
+
+func (api *ListAPI) updateList(c echo.Context) error {
+	var req SaveListRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "List name is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "name",
+					Message: "List name is required",
+				},
+			},
+		})
+	}
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	list.Name = req.Name
+	if err := api.listRepo.Update(list); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toListDetail(list, nil, userLocation(c)),
+		Message: "Reading list updated successfully",
+	})
+}
+
+func (api *ListAPI) deleteList(c echo.Context) error {
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	if err := api.listRepo.Delete(list.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Reading list deleted successfully",
+	})
+}
+
+func (api *ListAPI) addItem(c echo.Context) error {
+	var req AddListItemRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.BookID == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Book ID is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "id",
+					Message: "Book ID is required",
+				},
+			},
+		})
+	}
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	_, err = api.bookRepo.GetByID(c.Request().Context(), req.BookID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Book not found",
+				ErrorCode: models.ErrCodeBookNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	exists, err := api.listRepo.ItemExists(list.ID, req.BookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if exists {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Book is already in this reading list",
+			ErrorCode: models.ErrCodeReadingListItemExists,
+		})
+	}
+	item := &models.ReadingListItem{
+		ID:     uuid.New().String(),
+		ListID: list.ID,
+		BookID: req.BookID,
+	}
+	if err := api.listRepo.AddItem(item); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error adding book to reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	items, err := api.listItemDetails(c.Request().Context(), list.ID, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toListDetail(list, items, userLocation(c)),
+		Message: "Book added to reading list successfully",
+	})
+}
+
+func (api *ListAPI) removeItem(c echo.Context) error {
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	removed, err := api.listRepo.RemoveItem(list.ID, c.Param("bookId"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error removing book from reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if removed == 0 {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Book is not in this reading list",
+			ErrorCode: models.ErrCodeReadingListItemNotFound,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Book removed from reading list successfully",
+	})
+}
+
+func (api *ListAPI) reorderItems(c echo.Context) error {
+	var req ReorderListRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	items, err := api.listRepo.GetItems(list.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if !sameBookSet(items, req.BookIDs) {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "book_ids must list every book in the reading list exactly once",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "book_ids",
+					Message: "book_ids must list every book in the reading list exactly once",
+				},
+			},
+		})
+	}
+	if err := api.listRepo.Reorder(list.ID, req.BookIDs); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error reordering reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	itemDetails, err := api.listItemDetails(c.Request().Context(), list.ID, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toListDetail(list, itemDetails, userLocation(c)),
+		Message: "Reading list reordered successfully",
+	})
+}
+
+func (api *ListAPI) shareList(c echo.Context) error {
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	if list.ShareToken == nil {
+		token, err := generateShareToken()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error generating share link",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		list.ShareToken = &token
+		if err := api.listRepo.Update(list); err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error sharing reading list",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toListDetail(list, nil, userLocation(c)),
+		Message: "Reading list shared successfully",
+	})
+}
+
+func (api *ListAPI) unshareList(c echo.Context) error {
+	list, err := api.findOwnList(c)
+	if err != nil {
+		return listLookupError(c, err)
+	}
+	list.ShareToken = nil
+	if err := api.listRepo.Update(list); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error unsharing reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toListDetail(list, nil, userLocation(c)),
+		Message: "Reading list is no longer shared",
+	})
+}
+
+func (api *ListAPI) getSharedList(c echo.Context) error {
+	list, err := api.listRepo.GetByShareToken(c.Param("token"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Reading list not found",
+				ErrorCode: models.ErrCodeReadingListNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	items, err := api.listItemDetails(c.Request().Context(), list.ID, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading list items",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	detail := toListDetail(list, items, userLocation(c))
+	detail.ShareToken = nil
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Reading list retrieved successfully",
+	})
+}
+
+func (api *ListAPI) findOwnList(c echo.Context) (*models.ReadingList, error) {
+	list, err := api.listRepo.GetByID(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+	if list.UserID != api.authMw.GetUserFromContext(c).UserID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return list, nil
+}
+
+func listLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Reading list not found",
+			ErrorCode: models.ErrCodeReadingListNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving reading list",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func (api *ListAPI) listItemDetails(ctx context.Context, listID string, location *time.Location) ([]ListItemDetail, error) {
+	items, err := api.listRepo.GetItems(listID)
+	if err != nil {
+		return nil, err
+	}
+	bookIDs := make([]string, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	books, err := api.bookRepo.GetByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	booksByID := make(map[string]models.Book, len(books))
+	for _, book := range books {
+		booksByID[book.ID] = book
+	}
+	itemDetails := make([]ListItemDetail, 0, len(items))
+	for _, item := range items {
+		book, ok := booksByID[item.BookID]
+		if !ok {
+			continue
+		}
+		itemDetails = append(itemDetails, ListItemDetail{
+			BookID:    book.ID,
+			Title:     book.Title,
+			Author:    book.Author,
+			Position:  item.Position,
+			AddedDate: item.CreatedDate.In(location),
+		})
+	}
+	return itemDetails, nil
+}
+
+func toListDetail(list *models.ReadingList, items []ListItemDetail, location *time.Location) ListDetail {
+	return ListDetail{
+		ID:          list.ID,
+		Name:        list.Name,
+		ShareToken:  list.ShareToken,
+		Items:       items,
+		CreatedDate: list.CreatedDate.In(location),
+		UpdatedDate: list.UpdatedDate.In(location),
+	}
+}
+
+func sameBookSet(items []models.ReadingListItem, bookIDs []string) bool {
+	if len(items) != len(bookIDs) {
+		return false
+	}
+	remaining := make(map[string]bool, len(items))
+	for _, item := range items {
+		remaining[item.BookID] = true
+	}
+	for _, id := range bookIDs {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
+
+func generateShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
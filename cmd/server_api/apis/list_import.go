@@ -0,0 +1,283 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/goodreads"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// goodreadsTitleCandidates bounds how many books sharing a title fragment are
+// compared when an entry has no matching ISBN.
+const goodreadsTitleCandidates = 20
+
+// goodreadsListNames names the reading lists the exclusive shelves import
+// into. Custom shelves keep their Goodreads name.
+var goodreadsListNames = map[string]string{
+	goodreads.ShelfRead:             "Read",
+	goodreads.ShelfCurrentlyReading: "Currently Reading",
+	goodreads.ShelfToRead:           "Want to Read",
+}
+
+type GoodreadsImportReport struct {
+	DryRun    bool                      `json:"dry_run"`
+	Entries   int                       `json:"entries"`
+	Matched   int                       `json:"matched"`
+	Lists     []GoodreadsImportList     `json:"lists"`
+	Unmatched []GoodreadsUnmatchedEntry `json:"unmatched"`
+}
+
+// GoodreadsImportList reports what the import did to one reading list. ID is
+// empty for a list a dry run would create.
+type GoodreadsImportList struct {
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name"`
+	Shelf         string `json:"shelf"`
+	Created       bool   `json:"created"`
+	Added         int    `json:"added"`
+	AlreadyListed int    `json:"already_listed"`
+}
+
+type GoodreadsUnmatchedEntry struct {
+	Line   int    `json:"line"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	ISBN   string `json:"isbn,omitempty"`
+}
+
+// goodreadsShelf tracks the reading list a shelf imports into.
+type goodreadsShelf struct {
+	report *GoodreadsImportList
+	list   *models.ReadingList
+	books  map[string]bool
+}
+
+// importGoodreads adds the catalog books found in a Goodreads library export
+// to the caller's reading lists, one list per shelf, creating lists as
+// needed. Entries are matched by ISBN, then by title and author; books
+// already on a list are left where they are, so re-importing a newer export
+// only adds what changed.
+func (api *ListAPI) importGoodreads(c echo.Context) error {
+	dryRun := false
+	if dryRunStr := c.QueryParam("dry_run"); dryRunStr != "" {
+		d, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, models.Response{
+				Message:   "dry_run must be a boolean",
+				ErrorCode: models.ErrCodeValidation,
+				Errors: []models.FieldError{
+					{
+						Field:   "dry_run",
+						Message: "dry_run must be a boolean",
+					},
+				},
+			})
+		}
+		dryRun = d
+	}
+
+	body, err := csvUpload(c)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return fieldValidationError(c, "file", "required", "is required")
+		}
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return err
+		}
+		return bindError(c, err)
+	}
+	defer body.Close()
+	entries, err := goodreads.Parse(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return bindError(c, err)
+		}
+		return fieldValidationError(c, "file", "goodreads_export", "must be a Goodreads library export CSV")
+	}
+
+	ctx := c.Request().Context()
+	userID := api.authMw.GetUserFromContext(c).UserID
+	lists, err := api.listRepo.GetByUserID(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reading lists",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	existing := make(map[string]*models.ReadingList, len(lists))
+	for i := range lists {
+		existing[strings.ToLower(lists[i].Name)] = &lists[i]
+	}
+
+	report := GoodreadsImportReport{
+		DryRun:    dryRun,
+		Entries:   len(entries),
+		Lists:     []GoodreadsImportList{},
+		Unmatched: []GoodreadsUnmatchedEntry{},
+	}
+	var shelves []*goodreadsShelf
+	shelfByName := make(map[string]*goodreadsShelf)
+	for _, entry := range entries {
+		book, err := api.matchGoodreadsEntry(ctx, entry)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error matching books",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		if book == nil {
+			isbn := entry.ISBN13
+			if isbn == "" {
+				isbn = entry.ISBN
+			}
+			report.Unmatched = append(report.Unmatched, GoodreadsUnmatchedEntry{
+				Line:   entry.Line,
+				Title:  entry.Title,
+				Author: entry.Author,
+				ISBN:   isbn,
+			})
+			continue
+		}
+		report.Matched++
+
+		for _, shelfName := range entry.Shelves {
+			name := shelfName
+			if listName, ok := goodreadsListNames[shelfName]; ok {
+				name = listName
+			}
+			shelf, ok := shelfByName[strings.ToLower(name)]
+			if !ok {
+				shelf = &goodreadsShelf{
+					report: &GoodreadsImportList{
+						Name:  name,
+						Shelf: shelfName,
+					},
+					list:  existing[strings.ToLower(name)],
+					books: make(map[string]bool),
+				}
+				if shelf.list != nil {
+					shelf.report.ID = shelf.list.ID
+					shelf.report.Name = shelf.list.Name
+					items, err := api.listRepo.GetItems(shelf.list.ID)
+					if err != nil {
+						return c.JSON(http.StatusInternalServerError, models.Response{
+							Message:   "Error retrieving reading list items",
+							ErrorCode: models.ErrCodeInternal,
+						})
+					}
+					for _, item := range items {
+						shelf.books[item.BookID] = true
+					}
+				}
+				shelfByName[strings.ToLower(name)] = shelf
+				shelves = append(shelves, shelf)
+			}
+			if shelf.books[book.ID] {
+				shelf.report.AlreadyListed++
+				continue
+			}
+			shelf.books[book.ID] = true
+			shelf.report.Added++
+			if dryRun {
+				if shelf.list == nil {
+					shelf.report.Created = true
+				}
+				continue
+			}
+			if shelf.list == nil {
+				shelf.list = &models.ReadingList{
+					ID:     uuid.New().String(),
+					UserID: userID,
+					Name:   name,
+				}
+				if err := api.listRepo.Create(shelf.list); err != nil {
+					return c.JSON(http.StatusInternalServerError, models.Response{
+						Message:   "Error creating reading list",
+						ErrorCode: models.ErrCodeInternal,
+					})
+				}
+				shelf.report.ID = shelf.list.ID
+				shelf.report.Created = true
+			}
+			item := &models.ReadingListItem{
+				ID:     uuid.New().String(),
+				ListID: shelf.list.ID,
+				BookID: book.ID,
+			}
+			if err := api.listRepo.AddItem(item); err != nil {
+				return c.JSON(http.StatusInternalServerError, models.Response{
+					Message:   "Error adding book to reading list",
+					ErrorCode: models.ErrCodeInternal,
+				})
+			}
+		}
+	}
+	for _, shelf := range shelves {
+		report.Lists = append(report.Lists, *shelf.report)
+	}
+
+	message := "Goodreads export imported successfully"
+	if dryRun {
+		message = "Goodreads export checked successfully"
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: message,
+	})
+}
+
+// matchGoodreadsEntry finds the catalog book for an entry, or nil. Goodreads
+// titles carry the series in parentheses, which is ignored when comparing
+// titles.
+func (api *ListAPI) matchGoodreadsEntry(ctx context.Context, entry goodreads.Entry) (*models.Book, error) {
+	for _, isbn := range []string{entry.ISBN13, goodreads.ISBN13(entry.ISBN), entry.ISBN} {
+		if isbn == "" {
+			continue
+		}
+		books, err := api.bookRepo.GetFiltered(ctx, repositories.BookFilter{ISBN: isbn}, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(books) > 0 {
+			return &books[0], nil
+		}
+	}
+
+	title := goodreadsTitle(entry.Title)
+	if title == "" {
+		return nil, nil
+	}
+	filter := repositories.BookFilter{
+		Title:  title,
+		Author: entry.Author,
+	}
+	books, err := api.bookRepo.GetFiltered(ctx, filter, goodreadsTitleCandidates, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range books {
+		if strings.EqualFold(goodreadsTitle(books[i].Title), title) {
+			return &books[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// goodreadsTitle drops a trailing "(Series, #1)".
+func goodreadsTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if strings.HasSuffix(title, ")") {
+		if i := strings.LastIndex(title, " ("); i > 0 {
+			title = title[:i]
+		}
+	}
+	return strings.TrimSpace(title)
+}
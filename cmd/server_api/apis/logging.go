@@ -0,0 +1,110 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/auth"
+	"book-management-system/pkg/logging"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LoggingAPI struct {
+	levels   *logging.Levels
+	branches *BranchAccess
+	authMw   *auth.Middleware
+}
+
+// UpdateLoggingRequest changes the global level and module overrides. An
+// empty module level removes the override.
+type UpdateLoggingRequest struct {
+	Level   *string           `json:"level,omitempty" validate:"omitnil,oneof=debug info warn error"`
+	Modules map[string]string `json:"modules,omitempty" validate:"dive,keys,required,excludesall= ,endkeys,omitempty,oneof=debug info warn error"`
+}
+
+type LoggingDetail struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+func NewLoggingAPI(levels *logging.Levels, branches *BranchAccess, authMw *auth.Middleware) *LoggingAPI {
+	return &LoggingAPI{
+		levels:   levels,
+		branches: branches,
+		authMw:   authMw,
+	}
+}
+
+func (api *LoggingAPI) Setup(group *echo.Group) {
+	group.GET("", api.getLogging)
+	group.PUT("", api.updateLogging)
+}
+
+func (api *LoggingAPI) getLogging(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    api.detail(),
+		Message: "Logging configuration retrieved successfully",
+	})
+}
+
+func (api *LoggingAPI) updateLogging(c echo.Context) error {
+	var req UpdateLoggingRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	var level slog.Level
+	if req.Level != nil {
+		level, _ = logging.ParseLevel(*req.Level)
+	}
+	modules := make([]string, 0, len(req.Modules))
+	for module := range req.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	before := api.detail()
+	if req.Level != nil {
+		api.levels.SetGlobal(level)
+	}
+	for _, module := range modules {
+		if value := req.Modules[module]; value != "" {
+			moduleLevel, _ := logging.ParseLevel(value)
+			api.levels.SetModule(module, moduleLevel)
+		} else {
+			api.levels.ResetModule(module)
+		}
+	}
+	detail := api.detail()
+	auditRecord(c, "logging", "", before, detail)
+	slog.WarnContext(c.Request().Context(), "Log levels changed",
+		"user_id", api.authMw.GetUserFromContext(c).UserID,
+		"level", detail.Level,
+		"modules", logging.FormatModuleLevels(api.levels.Modules()),
+	)
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    detail,
+		Message: "Logging configuration updated successfully",
+	})
+}
+
+func (api *LoggingAPI) detail() LoggingDetail {
+	modules := make(map[string]string)
+	for module, level := range api.levels.Modules() {
+		modules[module] = strings.ToLower(level.String())
+	}
+	return LoggingDetail{
+		Level:   strings.ToLower(api.levels.Global().String()),
+		Modules: modules,
+	}
+}
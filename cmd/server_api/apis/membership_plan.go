@@ -0,0 +1,280 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// defaultMaxReservations applies to plans created without max_reservations,
+// matching the value existing plans were given.
+const defaultMaxReservations = 2
+
+type MembershipPlanAPI struct {
+	planRepo *repositories.MembershipPlanRepository
+	userRepo repositories.UserRepo
+}
+
+type CreateMembershipPlanRequest struct {
+	Code            string `json:"code" validate:"required"`
+	Name            string `json:"name" validate:"required"`
+	MaxLoans        int    `json:"max_loans" validate:"min=0"`
+	LoanPeriodDays  int    `json:"loan_period_days" validate:"min=1"`
+	MaxHolds        int    `json:"max_holds" validate:"min=0"`
+	MaxReservations *int   `json:"max_reservations,omitempty" validate:"omitempty,min=0"`
+}
+
+type UpdateMembershipPlanRequest struct {
+	Name            *string `json:"name,omitempty"`
+	MaxLoans        *int    `json:"max_loans,omitempty" validate:"omitempty,min=0"`
+	LoanPeriodDays  *int    `json:"loan_period_days,omitempty" validate:"omitempty,min=1"`
+	MaxHolds        *int    `json:"max_holds,omitempty" validate:"omitempty,min=0"`
+	MaxReservations *int    `json:"max_reservations,omitempty" validate:"omitempty,min=0"`
+}
+
+type MembershipPlanDetail struct {
+	ID              string    `json:"id"`
+	Code            string    `json:"code"`
+	Name            string    `json:"name"`
+	MaxLoans        int       `json:"max_loans"`
+	LoanPeriodDays  int       `json:"loan_period_days"`
+	MaxHolds        int       `json:"max_holds"`
+	MaxReservations int       `json:"max_reservations"`
+	CreatedDate     time.Time `json:"created_date"`
+	UpdatedDate     time.Time `json:"updated_date"`
+}
+
+func NewMembershipPlanAPI(planRepo *repositories.MembershipPlanRepository, userRepo repositories.UserRepo) *MembershipPlanAPI {
+	return &MembershipPlanAPI{
+		planRepo: planRepo,
+		userRepo: userRepo,
+	}
+}
+
+func (api *MembershipPlanAPI) Setup(group *echo.Group) {
+	group.POST("", api.createPlan)
+	group.GET("", api.getPlans)
+	group.GET("/:id", api.getPlanByID)
+	group.PUT("/:id", api.updatePlan)
+	group.DELETE("/:id", api.deletePlan)
+}
+
+func (api *MembershipPlanAPI) createPlan(c echo.Context) error {
+	var req CreateMembershipPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.Code == "" || req.Name == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Code and name are required",
+			ErrorCode: models.ErrCodeValidation,
+		})
+	}
+	if req.MaxLoans < 0 || req.MaxHolds < 0 || req.LoanPeriodDays < 1 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Limits cannot be negative and loan period must be at least one day",
+			ErrorCode: models.ErrCodeValidation,
+		})
+	}
+	exists, err := api.planRepo.CodeExists(req.Code)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking plan code availability",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if exists {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Membership plan code already exists",
+			ErrorCode: models.ErrCodeMembershipPlanExists,
+		})
+	}
+	maxReservations := defaultMaxReservations
+	if req.MaxReservations != nil {
+		maxReservations = *req.MaxReservations
+	}
+	plan := &models.MembershipPlan{
+		ID:              uuid.New().String(),
+		Code:            req.Code,
+		Name:            req.Name,
+		MaxLoans:        req.MaxLoans,
+		LoanPeriodDays:  req.LoanPeriodDays,
+		MaxHolds:        req.MaxHolds,
+		MaxReservations: maxReservations,
+	}
+	err = api.planRepo.Create(plan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "membership_plan", plan.ID, nil, toMembershipPlanDetail(plan, time.UTC))
+	response := models.Response{
+		Data:    toMembershipPlanDetail(plan, userLocation(c)),
+		Message: "Membership plan created successfully",
+	}
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (api *MembershipPlanAPI) getPlans(c echo.Context) error {
+	plans, err := api.planRepo.GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plans",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	planDetails := make([]MembershipPlanDetail, len(plans))
+	for i := range plans {
+		planDetails[i] = toMembershipPlanDetail(&plans[i], userLocation(c))
+	}
+	response := models.Response{
+		Data:    planDetails,
+		Message: "Membership plans retrieved successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *MembershipPlanAPI) getPlanByID(c echo.Context) error {
+	plan, err := api.planRepo.GetByID(c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Membership plan not found",
+				ErrorCode: models.ErrCodeMembershipPlanNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	response := models.Response{
+		Data:    toMembershipPlanDetail(plan, userLocation(c)),
+		Message: "Membership plan retrieved successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *MembershipPlanAPI) updatePlan(c echo.Context) error {
+	var req UpdateMembershipPlanRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	plan, err := api.planRepo.GetByID(c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Membership plan not found",
+				ErrorCode: models.ErrCodeMembershipPlanNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	before := toMembershipPlanDetail(plan, time.UTC)
+	if req.Name != nil {
+		plan.Name = *req.Name
+	}
+	if req.MaxLoans != nil {
+		plan.MaxLoans = *req.MaxLoans
+	}
+	if req.LoanPeriodDays != nil {
+		plan.LoanPeriodDays = *req.LoanPeriodDays
+	}
+	if req.MaxHolds != nil {
+		plan.MaxHolds = *req.MaxHolds
+	}
+	if req.MaxReservations != nil {
+		plan.MaxReservations = *req.MaxReservations
+	}
+	if plan.Name == "" || plan.MaxLoans < 0 || plan.MaxHolds < 0 || plan.LoanPeriodDays < 1 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Limits cannot be negative and loan period must be at least one day",
+			ErrorCode: models.ErrCodeValidation,
+		})
+	}
+	err = api.planRepo.Update(plan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "membership_plan", plan.ID, before, toMembershipPlanDetail(plan, time.UTC))
+	response := models.Response{
+		Data:    toMembershipPlanDetail(plan, userLocation(c)),
+		Message: "Membership plan updated successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *MembershipPlanAPI) deletePlan(c echo.Context) error {
+	id := c.Param("id")
+	plan, err := api.planRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Membership plan not found",
+				ErrorCode: models.ErrCodeMembershipPlanNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	members, err := api.userRepo.CountByMembershipPlan(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking membership plan usage",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if members > 0 {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Membership plan is assigned to existing users",
+			ErrorCode: models.ErrCodeMembershipPlanInUse,
+		})
+	}
+	err = api.planRepo.Delete(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "membership_plan", id, toMembershipPlanDetail(plan, time.UTC), nil)
+	response := models.Response{
+		Message: "Membership plan deleted successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func toMembershipPlanDetail(plan *models.MembershipPlan, location *time.Location) MembershipPlanDetail {
+	return MembershipPlanDetail{
+		ID:              plan.ID,
+		Code:            plan.Code,
+		Name:            plan.Name,
+		MaxLoans:        plan.MaxLoans,
+		LoanPeriodDays:  plan.LoanPeriodDays,
+		MaxHolds:        plan.MaxHolds,
+		MaxReservations: plan.MaxReservations,
+		CreatedDate:     plan.CreatedDate.In(location),
+		UpdatedDate:     plan.UpdatedDate.In(location),
+	}
+}
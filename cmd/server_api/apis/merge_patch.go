@@ -0,0 +1,92 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mergePatchMIME is the JSON Merge Patch (RFC 7396) media type. PATCH
+// endpoints also accept plain application/json.
+const mergePatchMIME = "application/merge-patch+json"
+
+var errMergePatchNotObject = errors.New("merge patch must be a JSON object")
+
+// bindMergePatch applies the request body as a merge patch to current and
+// decodes the result into dst, which should be a pointer to a zero value of
+// current's type. A member patched to null is removed, so it decodes to its
+// zero value, nil for pointer fields. The result is decoded as strictly as
+// any other request body, so patching a field current doesn't have fails.
+func bindMergePatch(c echo.Context, current, dst any) error {
+	if contentType := c.Request().Header.Get(echo.HeaderContentType); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchMIME && mediaType != echo.MIMEApplicationJSON) {
+			return echo.ErrUnsupportedMediaType
+		}
+	}
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	patch, err := decodeJSON(body)
+	if err != nil {
+		return err
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		return errMergePatchNotObject
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	target, err := decodeJSON(data)
+	if err != nil {
+		return err
+	}
+	merged, err := json.Marshal(applyMergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dst)
+}
+
+// applyMergePatch is the MergePatch function of RFC 7396, section 2.
+func applyMergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = applyMergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// decodeJSON keeps numbers as json.Number so large integers survive the
+// round trip.
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("request body must contain a single JSON value")
+	}
+	return value, nil
+}
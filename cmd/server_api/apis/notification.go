@@ -0,0 +1,254 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/notifications"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type NotificationAPI struct {
+	notificationRepo *repositories.NotificationRepository
+	notifier         *notifications.Notifier
+	authMw           *auth.Middleware
+}
+
+type UpdateNotificationPreferenceRequest struct {
+	EventType string `json:"event_type" validate:"required"`
+	Channel   string `json:"channel" validate:"required"`
+	Enabled   bool   `json:"enabled"`
+}
+
+type NotificationDetail struct {
+	ID          string     `json:"id"`
+	EventType   string     `json:"event_type"`
+	Channel     string     `json:"channel"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedDate time.Time  `json:"created_date"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationDetail `json:"notifications"`
+	Total         int64                `json:"total"`
+	Unread        int64                `json:"unread"`
+	Limit         int                  `json:"limit"`
+	Offset        int                  `json:"offset"`
+}
+
+type NotificationPreferenceDetail struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Enabled   bool   `json:"enabled"`
+}
+
+func NewNotificationAPI(notificationRepo *repositories.NotificationRepository, notifier *notifications.Notifier, authMw *auth.Middleware) *NotificationAPI {
+	return &NotificationAPI{
+		notificationRepo: notificationRepo,
+		notifier:         notifier,
+		authMw:           authMw,
+	}
+}
+
+func (api *NotificationAPI) Setup(group *echo.Group) {
+	group.GET("", api.getNotifications)
+	group.POST("/read-all", api.markAllRead)
+	group.POST("/:id/read", api.markRead)
+	group.GET("/preferences", api.getPreferences)
+	group.PUT("/preferences", api.updatePreference)
+}
+
+func (api *NotificationAPI) getNotifications(c echo.Context) error {
+	claims := api.authMw.GetUserFromContext(c)
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	unreadOnly := c.QueryParam("unread") == "true"
+	notificationList, err := api.notificationRepo.GetByUserID(claims.UserID, unreadOnly, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving notifications",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.notificationRepo.CountByUserID(claims.UserID, unreadOnly)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting notifications",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	unread, err := api.notificationRepo.CountByUserID(claims.UserID, true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting notifications",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	notificationDetails := make([]NotificationDetail, len(notificationList))
+	for i := range notificationList {
+		notificationDetails[i] = toNotificationDetail(&notificationList[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: NotificationListResponse{
+			Notifications: notificationDetails,
+			Total:         total,
+			Unread:        unread,
+			Limit:         limit,
+			Offset:        offset,
+		},
+		Message: "Notifications retrieved successfully",
+	})
+}
+
+func (api *NotificationAPI) markRead(c echo.Context) error {
+	claims := api.authMw.GetUserFromContext(c)
+	affected, err := api.notificationRepo.MarkRead(c.Param("id"), claims.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating notification",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if affected == 0 {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Notification not found",
+			ErrorCode: models.ErrCodeNotificationNotFound,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Notification marked as read",
+	})
+}
+
+func (api *NotificationAPI) markAllRead(c echo.Context) error {
+	claims := api.authMw.GetUserFromContext(c)
+	affected, err := api.notificationRepo.MarkAllRead(claims.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating notifications",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: map[string]any{
+			"updated": affected,
+		},
+		Message: "Notifications marked as read",
+	})
+}
+
+func (api *NotificationAPI) getPreferences(c echo.Context) error {
+	claims := api.authMw.GetUserFromContext(c)
+	preferences, err := api.notificationRepo.GetPreferences(claims.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving notification preferences",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	stored := make(map[string]bool, len(preferences))
+	for _, preference := range preferences {
+		stored[preference.EventType+"/"+preference.Channel] = preference.Enabled
+	}
+	var preferenceDetails []NotificationPreferenceDetail
+	for _, eventType := range notifications.EventTypes() {
+		for _, channel := range api.notifier.Channels() {
+			enabled, ok := stored[eventType+"/"+channel]
+			preferenceDetails = append(preferenceDetails, NotificationPreferenceDetail{
+				EventType: eventType,
+				Channel:   channel,
+				Enabled:   enabled || (!ok && notifications.DefaultEnabled(eventType)),
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    preferenceDetails,
+		Message: "Notification preferences retrieved successfully",
+	})
+}
+
+func (api *NotificationAPI) updatePreference(c echo.Context) error {
+	var req UpdateNotificationPreferenceRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if !notifications.IsEventType(req.EventType) {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Unknown notification event type",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "event_type",
+					Message: "Unknown notification event type",
+				},
+			},
+		})
+	}
+	if !api.notifier.HasChannel(req.Channel) {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Unknown notification channel",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "channel",
+					Message: "Unknown notification channel",
+				},
+			},
+		})
+	}
+	claims := api.authMw.GetUserFromContext(c)
+	preference, err := api.notificationRepo.GetPreference(claims.UserID, req.EventType, req.Channel)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error retrieving notification preference",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		preference = &models.NotificationPreference{
+			ID:        uuid.New().String(),
+			UserID:    claims.UserID,
+			EventType: req.EventType,
+			Channel:   req.Channel,
+		}
+	}
+	preference.Enabled = req.Enabled
+	if err := api.notificationRepo.SavePreference(preference); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error saving notification preference",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: NotificationPreferenceDetail{
+			EventType: preference.EventType,
+			Channel:   preference.Channel,
+			Enabled:   preference.Enabled,
+		},
+		Message: "Notification preference updated successfully",
+	})
+}
+
+func toNotificationDetail(notification *models.Notification, location *time.Location) NotificationDetail {
+	return NotificationDetail{
+		ID:          notification.ID,
+		EventType:   notification.EventType,
+		Channel:     notification.Channel,
+		Title:       notification.Title,
+		Body:        notification.Body,
+		ReadAt:      timeIn(notification.ReadAt, location),
+		CreatedDate: notification.CreatedDate.In(location),
+	}
+}
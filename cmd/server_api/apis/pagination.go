@@ -0,0 +1,66 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxPageSizeContextKey = "pagination.max_page_size"
+	defaultPageSize       = 20
+	// defaultMaxPageSize applies to requests that did not pass through
+	// PageSize, such as saved reports run by the scheduler.
+	defaultMaxPageSize = 100
+)
+
+// PageSize sets the largest limit list endpoints accept, so one request
+// can't read a whole table into memory.
+func PageSize(maxPageSize int) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(maxPageSizeContextKey, maxPageSize)
+			return next(c)
+		}
+	}
+}
+
+// pageParams reads the limit and offset query parameters. A missing or
+// non-positive limit becomes defaultLimit, capped at the maximum page size,
+// and a negative offset 0. ok is false when limit is above the maximum;
+// respond with pageSizeError.
+func pageParams(c echo.Context, defaultLimit int) (limit, offset int, ok bool) {
+	maxLimit := maxPageSize(c)
+	limit, _ = strconv.Atoi(c.QueryParam("limit"))
+	if limit <= 0 {
+		limit = min(defaultLimit, maxLimit)
+	}
+	offset, _ = strconv.Atoi(c.QueryParam("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset, limit <= maxLimit
+}
+
+func maxPageSize(c echo.Context) int {
+	if size, ok := c.Get(maxPageSizeContextKey).(int); ok {
+		return size
+	}
+	return defaultMaxPageSize
+}
+
+func pageSizeError(c echo.Context) error {
+	return c.JSON(http.StatusBadRequest, models.Response{
+		Message:   "Request validation failed",
+		ErrorCode: models.ErrCodeValidation,
+		Errors: []models.FieldError{
+			{
+				Field:   "limit",
+				Message: fmt.Sprintf("must be at most %d", maxPageSize(c)),
+			},
+		},
+	})
+}
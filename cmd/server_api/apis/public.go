@@ -0,0 +1,36 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PublicCatalog serves the read-only routes the public OPAC uses without a
+// token. Credentials are dropped before the handler runs, so every caller
+// gets the anonymous view and shared caches may store the response; that
+// view only lists network branches. Successful responses may be cached for
+// maxAge and served stale for as long again while the cache revalidates.
+// Errors are never cached.
+func PublicCatalog(maxAge time.Duration) echo.MiddlewareFunc {
+	seconds := int(maxAge / time.Second)
+	cacheControl := fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", seconds, seconds)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Request().Header.Del(echo.HeaderAuthorization)
+			res := c.Response()
+			// Handlers such as getBook set Cache-Control themselves, so it is
+			// overridden once the status is known.
+			res.Before(func() {
+				if res.Status == http.StatusOK || res.Status == http.StatusNotModified {
+					res.Header().Set("Cache-Control", cacheControl)
+				} else {
+					res.Header().Set("Cache-Control", "no-store")
+				}
+			})
+			return next(c)
+		}
+	}
+}
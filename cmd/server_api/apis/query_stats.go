@@ -0,0 +1,115 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/pkg/querystats"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const defaultQueryStatsLimit = 10
+
+type QueryStatsAPI struct {
+	recorder *querystats.Recorder
+	branches *BranchAccess
+}
+
+type QueryStatDetail struct {
+	Query     string    `json:"query"`
+	Count     int64     `json:"count"`
+	SlowCount int64     `json:"slow_count"`
+	Errors    int64     `json:"errors"`
+	TotalMs   float64   `json:"total_ms"`
+	MeanMs    float64   `json:"mean_ms"`
+	MaxMs     float64   `json:"max_ms"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type QueryStatsResponse struct {
+	Queries         []QueryStatDetail `json:"queries"`
+	Sort            string            `json:"sort"`
+	Tracked         int               `json:"tracked"`
+	Dropped         int64             `json:"dropped"`
+	SlowThresholdMs float64           `json:"slow_threshold_ms"`
+	Since           time.Time         `json:"since"`
+}
+
+func NewQueryStatsAPI(recorder *querystats.Recorder, branches *BranchAccess) *QueryStatsAPI {
+	return &QueryStatsAPI{
+		recorder: recorder,
+		branches: branches,
+	}
+}
+
+func (api *QueryStatsAPI) Setup(group *echo.Group) {
+	group.GET("", api.getQueryStats)
+	group.DELETE("", api.resetQueryStats)
+}
+
+func (api *QueryStatsAPI) getQueryStats(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	limit, _, ok := pageParams(c, defaultQueryStatsLimit)
+	if !ok {
+		return pageSizeError(c)
+	}
+	sortBy := c.QueryParam("sort")
+	if sortBy == "" {
+		sortBy = querystats.SortMax
+	}
+	if sortBy != querystats.SortMax && sortBy != querystats.SortMean && sortBy != querystats.SortTotal {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "sort",
+					Message: "must be one of: max, mean, total",
+				},
+			},
+		})
+	}
+	snapshot := api.recorder.Top(limit, sortBy)
+	location := userLocation(c)
+	queryDetails := make([]QueryStatDetail, len(snapshot.Queries))
+	for i, stat := range snapshot.Queries {
+		queryDetails[i] = QueryStatDetail{
+			Query:     stat.Query,
+			Count:     stat.Count,
+			SlowCount: stat.SlowCount,
+			Errors:    stat.Errors,
+			TotalMs:   milliseconds(stat.Total),
+			MeanMs:    milliseconds(stat.Mean()),
+			MaxMs:     milliseconds(stat.Max),
+			LastSeen:  stat.LastSeen.In(location),
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: QueryStatsResponse{
+			Queries:         queryDetails,
+			Sort:            sortBy,
+			Tracked:         snapshot.Tracked,
+			Dropped:         snapshot.Dropped,
+			SlowThresholdMs: milliseconds(snapshot.SlowThreshold),
+			Since:           snapshot.Since.In(location),
+		},
+		Message: "Query statistics retrieved successfully",
+	})
+}
+
+func (api *QueryStatsAPI) resetQueryStats(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	api.recorder.Reset()
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Query statistics reset",
+	})
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,257 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// readingHistoryTopGenres is how many genres each year of history lists.
+const readingHistoryTopGenres = 3
+
+// ReadingHistoryAPI shows members the books they have read, one year at a
+// time. A book counts as read once its loan has ended, by return or by
+// falling due, and belongs to the year the loan ended in the member's time
+// zone. Members who turn history off have their ended loans deleted.
+type ReadingHistoryAPI struct {
+	loanRepo *repositories.DigitalLoanRepository
+	bookRepo repositories.BookRepo
+	userRepo repositories.UserRepo
+	authMw   *auth.Middleware
+}
+
+type UpdateReadingHistorySettingsRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+type ReadingHistory struct {
+	Enabled bool                 `json:"enabled"`
+	Years   []ReadingHistoryYear `json:"years"`
+}
+
+type ReadingHistoryYear struct {
+	Year      int                  `json:"year"`
+	BooksRead int                  `json:"books_read"`
+	Pages     int                  `json:"pages"`
+	TopGenres []GenreCount         `json:"top_genres"`
+	Books     []ReadingHistoryBook `json:"books"`
+}
+
+type GenreCount struct {
+	Genre string `json:"genre"`
+	Books int    `json:"books"`
+}
+
+type ReadingHistoryBook struct {
+	BookID       string    `json:"book_id"`
+	Title        string    `json:"title"`
+	Author       string    `json:"author"`
+	Genre        *string   `json:"genre"`
+	Pages        *int      `json:"pages"`
+	FinishedDate time.Time `json:"finished_date"`
+}
+
+type ReadingHistorySettings struct {
+	Enabled bool  `json:"enabled"`
+	Deleted int64 `json:"deleted"`
+}
+
+func NewReadingHistoryAPI(loanRepo *repositories.DigitalLoanRepository, bookRepo repositories.BookRepo, userRepo repositories.UserRepo, authMw *auth.Middleware) *ReadingHistoryAPI {
+	return &ReadingHistoryAPI{
+		loanRepo: loanRepo,
+		bookRepo: bookRepo,
+		userRepo: userRepo,
+		authMw:   authMw,
+	}
+}
+
+// Setup registers the caller's reading history under /me/history.
+func (api *ReadingHistoryAPI) Setup(group *echo.Group) {
+	group.GET("", api.getHistory)
+	group.PUT("/settings", api.updateSettings)
+}
+
+// getHistory returns the caller's years of reading, newest first. Each book
+// is counted once a year however often it was borrowed; books since removed
+// from the catalog are left out.
+func (api *ReadingHistoryAPI) getHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, err := api.userRepo.GetByID(ctx, api.authMw.GetUserFromContext(c).UserID)
+	if err != nil {
+		return readingHistoryUserError(c, err)
+	}
+	history := ReadingHistory{
+		Enabled: user.ReadingHistoryEnabled,
+		Years:   []ReadingHistoryYear{},
+	}
+	if !user.ReadingHistoryEnabled {
+		return c.JSON(http.StatusOK, models.Response{
+			Data:    history,
+			Message: "Reading history retrieved successfully",
+		})
+	}
+	loans, err := api.loanRepo.GetEndedByUser(ctx, user.ID, time.Now().UTC())
+	if err != nil {
+		return readingHistoryError(c)
+	}
+	bookIDs := make([]string, 0, len(loans))
+	for _, loan := range loans {
+		bookIDs = append(bookIDs, loan.BookID)
+	}
+	books, err := api.bookRepo.GetByIDs(ctx, bookIDs)
+	if err != nil {
+		return readingHistoryError(c)
+	}
+	booksByID := make(map[string]*models.Book, len(books))
+	for i := range books {
+		booksByID[books[i].ID] = &books[i]
+	}
+
+	location := userLocation(c)
+	read := make(map[int]map[string]bool)
+	for _, loan := range loans {
+		book, ok := booksByID[loan.BookID]
+		if !ok {
+			continue
+		}
+		finished := loan.DueDate
+		if loan.ReturnedDate != nil {
+			finished = *loan.ReturnedDate
+		}
+		finished = finished.In(location)
+		year := finished.Year()
+		if read[year] == nil {
+			read[year] = make(map[string]bool)
+			history.Years = append(history.Years, ReadingHistoryYear{
+				Year:      year,
+				TopGenres: []GenreCount{},
+			})
+		}
+		if read[year][book.ID] {
+			continue
+		}
+		read[year][book.ID] = true
+		entry := &history.Years[len(history.Years)-1]
+		entry.BooksRead++
+		if book.Pages != nil {
+			entry.Pages += *book.Pages
+		}
+		entry.Books = append(entry.Books, ReadingHistoryBook{
+			BookID:       book.ID,
+			Title:        book.Title,
+			Author:       book.Author,
+			Genre:        book.Genre,
+			Pages:        book.Pages,
+			FinishedDate: finished,
+		})
+	}
+	for i := range history.Years {
+		history.Years[i].TopGenres = topGenres(history.Years[i].Books)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    history,
+		Message: "Reading history retrieved successfully",
+	})
+}
+
+// updateSettings turns the caller's reading history on or off. Turning it
+// off deletes the loans that have already ended, including the borrowing
+// record that lets a member review a book; later loans are deleted by the
+// reading history purge job as they end.
+func (api *ReadingHistoryAPI) updateSettings(c echo.Context) error {
+	var req UpdateReadingHistorySettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	ctx := c.Request().Context()
+	user, err := api.userRepo.GetByID(ctx, api.authMw.GetUserFromContext(c).UserID)
+	if err != nil {
+		return readingHistoryUserError(c, err)
+	}
+	if user.ReadingHistoryEnabled != *req.Enabled {
+		user.ReadingHistoryEnabled = *req.Enabled
+		err = api.userRepo.Update(ctx, user)
+		if err == repositories.ErrVersionConflict {
+			return userVersionConflict(c)
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error updating user",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+	}
+	settings := ReadingHistorySettings{
+		Enabled: user.ReadingHistoryEnabled,
+	}
+	if !user.ReadingHistoryEnabled {
+		settings.Deleted, err = api.loanRepo.DeleteEndedByUser(ctx, user.ID, time.Now().UTC())
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error deleting reading history",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    settings,
+		Message: "Reading history settings updated successfully",
+	})
+}
+
+// topGenres counts the books of each genre, most read first and then by
+// name. Books without a genre are not counted.
+func topGenres(books []ReadingHistoryBook) []GenreCount {
+	counts := make(map[string]int)
+	for _, book := range books {
+		if book.Genre != nil && *book.Genre != "" {
+			counts[*book.Genre]++
+		}
+	}
+	genres := make([]GenreCount, 0, len(counts))
+	for genre, count := range counts {
+		genres = append(genres, GenreCount{
+			Genre: genre,
+			Books: count,
+		})
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if genres[i].Books != genres[j].Books {
+			return genres[i].Books > genres[j].Books
+		}
+		return genres[i].Genre < genres[j].Genre
+	})
+	if len(genres) > readingHistoryTopGenres {
+		genres = genres[:readingHistoryTopGenres]
+	}
+	return genres
+}
+
+func readingHistoryUserError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "User not found",
+			ErrorCode: models.ErrCodeUserNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving user",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func readingHistoryError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving reading history",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
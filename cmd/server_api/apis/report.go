@@ -0,0 +1,581 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	reportDateLayout    = "2006-01-02"
+	reportMonthLayout   = "2006-01"
+	defaultReportMonths = 12
+	maxReportMonths     = 120
+	defaultDormantDays  = 90
+	defaultStatsDays    = 30
+	maxStatsDays        = 366
+	defaultMinSearches  = 2
+)
+
+type ReportAPI struct {
+	statsRepo      *repositories.DailyStatRepository
+	searchTermRepo *repositories.SearchTermRepository
+	auditRepo      *repositories.AuditLogRepository
+	branches       *BranchAccess
+}
+
+type MemberEngagementSummary struct {
+	TotalMembers    int `json:"total_members"`
+	ActiveMembers   int `json:"active_members"`
+	DormantMembers  int `json:"dormant_members"`
+	InactiveMembers int `json:"inactive_members"`
+}
+
+func (s *MemberEngagementSummary) add(inactive, dormant bool) {
+	switch {
+	case inactive:
+		s.InactiveMembers++
+	case dormant:
+		s.DormantMembers++
+	default:
+		s.ActiveMembers++
+	}
+}
+
+type RegistrationBucket struct {
+	Month      string `json:"month"`
+	NewMembers int    `json:"new_members"`
+}
+
+// MemberCohort is the current engagement of the members who registered in a
+// month.
+type MemberCohort struct {
+	Month      string `json:"month"`
+	Registered int    `json:"registered"`
+	Active     int    `json:"active"`
+	Dormant    int    `json:"dormant"`
+	Inactive   int    `json:"inactive"`
+}
+
+func (m *MemberCohort) add(inactive, dormant bool) {
+	switch {
+	case inactive:
+		m.Inactive++
+	case dormant:
+		m.Dormant++
+	default:
+		m.Active++
+	}
+}
+
+type MemberEngagementReport struct {
+	From          time.Time               `json:"from"`
+	To            time.Time               `json:"to"`
+	DormantSince  time.Time               `json:"dormant_since"`
+	Summary       MemberEngagementSummary `json:"summary"`
+	Registrations []RegistrationBucket    `json:"registrations"`
+	Cohorts       []MemberCohort          `json:"cohorts,omitempty"`
+}
+
+type DailyStatDetail struct {
+	Date            string    `json:"date"`
+	Titles          int       `json:"titles"`
+	Copies          int       `json:"copies"`
+	AvailableCopies int       `json:"available_copies"`
+	NewTitles       int       `json:"new_titles"`
+	Members         int       `json:"members"`
+	NewMembers      int       `json:"new_members"`
+	UpdatedDate     time.Time `json:"updated_date"`
+}
+
+type DailyStatsResponse struct {
+	From string            `json:"from"`
+	To   string            `json:"to"`
+	Days []DailyStatDetail `json:"days"`
+}
+
+// FailedSearch is a query that found no books, a sign of a title the
+// catalog is missing.
+type FailedSearch struct {
+	Query    string `json:"query"`
+	Searches int    `json:"searches"`
+	Days     int    `json:"days"`
+}
+
+type DemandReport struct {
+	From           string         `json:"from"`
+	To             string         `json:"to"`
+	MinSearches    int            `json:"min_searches"`
+	FailedSearches []FailedSearch `json:"failed_searches"`
+}
+
+// SearchTermCount is a query's searches over a report's range, and how many
+// of them found nothing.
+type SearchTermCount struct {
+	Query       string `json:"query"`
+	Searches    int    `json:"searches"`
+	ZeroResults int    `json:"zero_results"`
+	Days        int    `json:"days"`
+}
+
+// SearchTermsReport shows what patrons searched the catalog for and what
+// they could not find. TopTerms and ZeroResultTerms hold at most limit
+// queries each; the totals count every search.
+type SearchTermsReport struct {
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Searches        int64             `json:"searches"`
+	ZeroResults     int64             `json:"zero_results"`
+	Terms           int64             `json:"terms"`
+	TopTerms        []SearchTermCount `json:"top_terms"`
+	ZeroResultTerms []SearchTermCount `json:"zero_result_terms"`
+}
+
+// StaffActivity counts one admin's audited changes.
+type StaffActivity struct {
+	UserID         string `json:"user_id"`
+	Email          string `json:"email"`
+	Actions        int    `json:"actions"`
+	BooksCataloged int    `json:"books_cataloged"`
+}
+
+type StaffActivityReport struct {
+	From  time.Time       `json:"from"`
+	To    time.Time       `json:"to"`
+	Staff []StaffActivity `json:"staff"`
+}
+
+func NewReportAPI(statsRepo *repositories.DailyStatRepository, searchTermRepo *repositories.SearchTermRepository, auditRepo *repositories.AuditLogRepository, branches *BranchAccess) *ReportAPI {
+	return &ReportAPI{
+		statsRepo:      statsRepo,
+		searchTermRepo: searchTermRepo,
+		auditRepo:      auditRepo,
+		branches:       branches,
+	}
+}
+
+func (api *ReportAPI) Setup(group *echo.Group) {
+	group.GET("/members", api.getMemberEngagement)
+	group.GET("/daily-stats", api.getDailyStats)
+	group.GET("/demand", api.getDemand)
+	group.GET("/search-terms", api.getSearchTerms)
+	group.GET("/staff", api.getStaffActivity)
+}
+
+func (api *ReportAPI) getMemberEngagement(c echo.Context) error {
+	location := userLocation(c)
+	var fieldErrors []models.FieldError
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if parsed, ok := reportDateParam(c, "to", location, &fieldErrors); ok {
+		to = parsed
+	}
+	from := time.Date(to.Year(), to.Month()-defaultReportMonths+1, 1, 0, 0, 0, 0, location)
+	if parsed, ok := reportDateParam(c, "from", location, &fieldErrors); ok {
+		from = parsed
+	}
+	if len(fieldErrors) == 0 {
+		if to.Before(from) {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "to",
+				Message: "must be on or after from",
+			})
+		} else if monthsBetween(from, to) > maxReportMonths {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "to",
+				Message: fmt.Sprintf("must be within %d months of from", maxReportMonths),
+			})
+		}
+	}
+	dormantDays := defaultDormantDays
+	if daysStr := c.QueryParam("dormant_days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "dormant_days",
+				Message: "must be a positive integer",
+			})
+		}
+		dormantDays = d
+	}
+	withCohorts := false
+	if cohortsStr := c.QueryParam("cohorts"); cohortsStr != "" {
+		b, err := strconv.ParseBool(cohortsStr)
+		if err != nil {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "cohorts",
+				Message: "must be a boolean",
+			})
+		}
+		withCohorts = b
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+
+	userRepo, err := api.branches.ScopedUsers(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	members, err := userRepo.GetMemberActivity(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error generating member engagement report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	// The range covers whole days in the caller's zone, so to is inclusive.
+	end := to.AddDate(0, 0, 1)
+	dormantSince := time.Now().UTC().AddDate(0, 0, -dormantDays)
+	months := monthsBetween(from, to) + 1
+	registrations := make([]RegistrationBucket, months)
+	cohorts := make([]MemberCohort, months)
+	for i := range registrations {
+		month := time.Date(from.Year(), from.Month()+time.Month(i), 1, 0, 0, 0, 0, location).Format(reportMonthLayout)
+		registrations[i].Month = month
+		cohorts[i].Month = month
+	}
+	var summary MemberEngagementSummary
+	for _, member := range members {
+		inactive, dormant := memberEngagement(&member, dormantSince)
+		summary.TotalMembers++
+		summary.add(inactive, dormant)
+
+		if member.CreatedDate.Before(from) || !member.CreatedDate.Before(end) {
+			continue
+		}
+		i := monthsBetween(from, member.CreatedDate.In(location))
+		registrations[i].NewMembers++
+		cohorts[i].Registered++
+		cohorts[i].add(inactive, dormant)
+	}
+
+	report := MemberEngagementReport{
+		From:          from,
+		To:            to,
+		DormantSince:  dormantSince.In(location),
+		Summary:       summary,
+		Registrations: registrations,
+	}
+	if withCohorts {
+		report.Cohorts = cohorts
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    report,
+		Message: "Member engagement report retrieved successfully",
+	})
+}
+
+// getDailyStats reads the pre-computed daily_stats rows. Days are UTC dates,
+// and days before the job first ran are simply absent.
+func (api *ReportAPI) getDailyStats(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	var fieldErrors []models.FieldError
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if parsed, ok := reportDateParam(c, "to", time.UTC, &fieldErrors); ok {
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays+1)
+	if parsed, ok := reportDateParam(c, "from", time.UTC, &fieldErrors); ok {
+		from = parsed
+	}
+	if len(fieldErrors) == 0 {
+		if to.Before(from) {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "to",
+				Message: "must be on or after from",
+			})
+		} else if to.Sub(from) >= maxStatsDays*24*time.Hour {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "to",
+				Message: fmt.Sprintf("must be within %d days of from", maxStatsDays),
+			})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	stats, err := api.statsRepo.GetRange(c.Request().Context(), from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving daily statistics",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	days := make([]DailyStatDetail, len(stats))
+	for i := range stats {
+		days[i] = toDailyStatDetail(&stats[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: DailyStatsResponse{
+			From: from.Format(reportDateLayout),
+			To:   to.Format(reportDateLayout),
+			Days: days,
+		},
+		Message: "Daily statistics retrieved successfully",
+	})
+}
+
+// getDemand ranks the searches that found nothing over a range of UTC days.
+func (api *ReportAPI) getDemand(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	var fieldErrors []models.FieldError
+	from, to := searchReportRange(c, &fieldErrors)
+	minSearches := defaultMinSearches
+	if minStr := c.QueryParam("min_searches"); minStr != "" {
+		m, err := strconv.Atoi(minStr)
+		if err != nil || m <= 0 {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "min_searches",
+				Message: "must be a positive integer",
+			})
+		}
+		minSearches = m
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	limit, _, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	totals, err := api.searchTermRepo.GetTopZeroResults(c.Request().Context(), from, to, minSearches, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error generating demand report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	searches := make([]FailedSearch, len(totals))
+	for i, total := range totals {
+		searches[i] = FailedSearch{
+			Query:    total.Query,
+			Searches: total.ZeroResults,
+			Days:     total.Days,
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: DemandReport{
+			From:           from.Format(reportDateLayout),
+			To:             to.Format(reportDateLayout),
+			MinSearches:    minSearches,
+			FailedSearches: searches,
+		},
+		Message: "Demand report retrieved successfully",
+	})
+}
+
+// getSearchTerms reports the most searched queries and the queries that
+// most often found nothing over a range of UTC days. limit applies to each
+// list.
+func (api *ReportAPI) getSearchTerms(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	var fieldErrors []models.FieldError
+	from, to := searchReportRange(c, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	limit, _, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	ctx := c.Request().Context()
+	summary, err := api.searchTermRepo.Summarize(ctx, from, to)
+	if err != nil {
+		return searchTermsReportError(c)
+	}
+	top, err := api.searchTermRepo.GetTop(ctx, from, to, limit)
+	if err != nil {
+		return searchTermsReportError(c)
+	}
+	zeroResults, err := api.searchTermRepo.GetTopZeroResults(ctx, from, to, 1, limit)
+	if err != nil {
+		return searchTermsReportError(c)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: SearchTermsReport{
+			From:            from.Format(reportDateLayout),
+			To:              to.Format(reportDateLayout),
+			Searches:        summary.Searches,
+			ZeroResults:     summary.ZeroResults,
+			Terms:           summary.Terms,
+			TopTerms:        toSearchTermCounts(top),
+			ZeroResultTerms: toSearchTermCounts(zeroResults),
+		},
+		Message: "Search terms report retrieved successfully",
+	})
+}
+
+// getStaffActivity counts each admin's changes from the audit log over whole
+// days in the caller's zone.
+func (api *ReportAPI) getStaffActivity(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	location := userLocation(c)
+	var fieldErrors []models.FieldError
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if parsed, ok := reportDateParam(c, "to", location, &fieldErrors); ok {
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays+1)
+	if parsed, ok := reportDateParam(c, "from", location, &fieldErrors); ok {
+		from = parsed
+	}
+	if len(fieldErrors) == 0 && to.Before(from) {
+		fieldErrors = append(fieldErrors, models.FieldError{
+			Field:   "to",
+			Message: "must be on or after from",
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	activity, err := api.auditRepo.GetActivityByActor(c.Request().Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error generating staff activity report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	staff := make([]StaffActivity, len(activity))
+	for i, actor := range activity {
+		staff[i] = StaffActivity{
+			UserID:         actor.ActorID,
+			Email:          actor.ActorEmail,
+			Actions:        actor.Actions,
+			BooksCataloged: actor.BooksCataloged,
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: StaffActivityReport{
+			From:  from,
+			To:    to,
+			Staff: staff,
+		},
+		Message: "Staff activity report retrieved successfully",
+	})
+}
+
+// memberEngagement reports whether a member is inactive, or else dormant:
+// not logged in, or registered if they never have, since dormantSince.
+func memberEngagement(member *models.User, dormantSince time.Time) (inactive, dormant bool) {
+	lastActive := member.CreatedDate
+	if member.LastLoginAt != nil {
+		lastActive = *member.LastLoginAt
+	}
+	inactive = member.Status == "inactive"
+	return inactive, !inactive && lastActive.Before(dormantSince)
+}
+
+// reportDateParam parses a YYYY-MM-DD query parameter as midnight in
+// location. ok is false when the parameter is absent or invalid; invalid
+// values are added to fieldErrors.
+func reportDateParam(c echo.Context, name string, location *time.Location, fieldErrors *[]models.FieldError) (time.Time, bool) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.ParseInLocation(reportDateLayout, value, location)
+	if err != nil {
+		*fieldErrors = append(*fieldErrors, models.FieldError{
+			Field:   name,
+			Message: "must be a date in YYYY-MM-DD format",
+		})
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+func toDailyStatDetail(stat *models.DailyStat, location *time.Location) DailyStatDetail {
+	return DailyStatDetail{
+		Date:            stat.StatDate.UTC().Format(reportDateLayout),
+		Titles:          stat.Titles,
+		Copies:          stat.Copies,
+		AvailableCopies: stat.AvailableCopies,
+		NewTitles:       stat.NewTitles,
+		Members:         stat.Members,
+		NewMembers:      stat.NewMembers,
+		UpdatedDate:     stat.UpdatedDate.In(location),
+	}
+}
+
+// monthsBetween counts calendar months from from's month to to's month.
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// searchReportRange reads the from and to UTC days of a search report,
+// by default the last 30 days up to today.
+func searchReportRange(c echo.Context, fieldErrors *[]models.FieldError) (from, to time.Time) {
+	now := time.Now().UTC()
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if parsed, ok := reportDateParam(c, "to", time.UTC, fieldErrors); ok {
+		to = parsed
+	}
+	from = to.AddDate(0, 0, -defaultStatsDays+1)
+	if parsed, ok := reportDateParam(c, "from", time.UTC, fieldErrors); ok {
+		from = parsed
+	}
+	if len(*fieldErrors) == 0 && to.Before(from) {
+		*fieldErrors = append(*fieldErrors, models.FieldError{
+			Field:   "to",
+			Message: "must be on or after from",
+		})
+	}
+	return from, to
+}
+
+func toSearchTermCounts(totals []repositories.SearchTermTotal) []SearchTermCount {
+	counts := make([]SearchTermCount, len(totals))
+	for i, total := range totals {
+		counts[i] = SearchTermCount{
+			Query:       total.Query,
+			Searches:    total.Searches,
+			ZeroResults: total.ZeroResults,
+			Days:        total.Days,
+		}
+	}
+	return counts
+}
+
+func searchTermsReportError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error generating search terms report",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
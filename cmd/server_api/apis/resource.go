@@ -0,0 +1,681 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAvailabilityDays = 7
+	maxAvailabilityDays     = 31
+)
+
+// ResourceAPI lets members reserve rooms and equipment. Resources follow
+// their branch's visibility, and only that branch's staff may manage them.
+// Reservations may not overlap, may last at most the resource's max_minutes,
+// and members may hold as many that have not ended as their membership plan's
+// max_reservations.
+type ResourceAPI struct {
+	resourceRepo    *repositories.ResourceRepository
+	reservationRepo *repositories.ReservationRepository
+	userRepo        repositories.UserRepo
+	planRepo        *repositories.MembershipPlanRepository
+	branches        *BranchAccess
+	window          time.Duration
+	authMw          *auth.Middleware
+}
+
+type ResourceRequest struct {
+	BranchID    string  `json:"branch_id" validate:"required"`
+	Kind        string  `json:"kind" validate:"required,oneof=room equipment"`
+	Name        string  `json:"name" validate:"required,max=100"`
+	Description *string `json:"description,omitempty"`
+	Location    *string `json:"location,omitempty"`
+	Seats       *int    `json:"seats,omitempty" validate:"omitempty,min=1"`
+	MaxMinutes  int     `json:"max_minutes" validate:"min=15,max=1440"`
+}
+
+type CreateReservationRequest struct {
+	ResourceID string    `json:"resource_id" validate:"required"`
+	StartsAt   time.Time `json:"starts_at" validate:"required"`
+	EndsAt     time.Time `json:"ends_at" validate:"required"`
+	Note       *string   `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+type ResourceDetail struct {
+	ID          string    `json:"id"`
+	BranchID    string    `json:"branch_id"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Location    *string   `json:"location"`
+	Seats       *int      `json:"seats"`
+	MaxMinutes  int       `json:"max_minutes"`
+	CreatedDate time.Time `json:"created_date"`
+	UpdatedDate time.Time `json:"updated_date"`
+}
+
+type ResourceListResponse struct {
+	Resources []ResourceDetail `json:"resources"`
+	Total     int64            `json:"total"`
+	Limit     int              `json:"limit"`
+	Offset    int              `json:"offset"`
+}
+
+// ReservedSlot is a time a resource is taken, without saying by whom.
+type ReservedSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type ResourceAvailability struct {
+	ResourceID string         `json:"resource_id"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Reserved   []ReservedSlot `json:"reserved"`
+}
+
+type ReservationDetail struct {
+	ID            string             `json:"id"`
+	ResourceID    string             `json:"resource_id"`
+	ResourceName  string             `json:"resource_name"`
+	BranchID      string             `json:"branch_id"`
+	StartsAt      time.Time          `json:"starts_at"`
+	EndsAt        time.Time          `json:"ends_at"`
+	Note          *string            `json:"note"`
+	CancelledDate *time.Time         `json:"cancelled_date,omitempty"`
+	Member        *ReservationMember `json:"member,omitempty"`
+	CreatedDate   time.Time          `json:"created_date"`
+}
+
+// ReservationMember identifies who made a reservation, for staff.
+type ReservationMember struct {
+	UserID    string `json:"user_id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+// NewResourceAPI takes the booking window, how far ahead members may
+// reserve.
+func NewResourceAPI(resourceRepo *repositories.ResourceRepository, reservationRepo *repositories.ReservationRepository, userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository, branches *BranchAccess, window time.Duration, authMw *auth.Middleware) *ResourceAPI {
+	return &ResourceAPI{
+		resourceRepo:    resourceRepo,
+		reservationRepo: reservationRepo,
+		userRepo:        userRepo,
+		planRepo:        planRepo,
+		branches:        branches,
+		window:          window,
+		authMw:          authMw,
+	}
+}
+
+// Setup registers the resource listing and availability under /resources.
+func (api *ResourceAPI) Setup(group *echo.Group) {
+	group.GET("", api.getResources)
+	group.GET("/:id", api.getResource)
+	group.GET("/:id/availability", api.getAvailability)
+}
+
+// SetupAdmin registers resource management under /admin/resources.
+func (api *ResourceAPI) SetupAdmin(group *echo.Group) {
+	group.POST("", api.createResource)
+	group.PUT("/:id", api.updateResource)
+	group.DELETE("/:id", api.deleteResource)
+	group.GET("/:id/reservations", api.getResourceReservations)
+	group.POST("/:id/reservations/:reservationId/cancel", api.cancelResourceReservation)
+}
+
+// SetupReservations registers the caller's reservations under
+// /me/reservations.
+func (api *ResourceAPI) SetupReservations(group *echo.Group) {
+	group.GET("", api.getReservations)
+	group.POST("", api.createReservation)
+	group.POST("/:id/cancel", api.cancelReservation)
+}
+
+func (api *ResourceAPI) getResources(c echo.Context) error {
+	kind := c.QueryParam("kind")
+	if kind != "" && kind != models.ResourceKindRoom && kind != models.ResourceKindEquipment {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "kind must be room or equipment",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "kind",
+					Message: "kind must be room or equipment",
+				},
+			},
+		})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	branchID := c.QueryParam("branch_id")
+	if branchID != "" {
+		if _, err := api.branches.VisibleBranch(c, branchID); err != nil {
+			return branchLookupError(c, err)
+		}
+	}
+	hidden, err := api.branches.HiddenBranchIDs(c)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error resolving caller",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	filter := repositories.ResourceFilter{
+		Kind:            kind,
+		BranchID:        branchID,
+		HiddenBranchIDs: hidden,
+	}
+	resources, err := api.resourceRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving resources",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.resourceRepo.CountFiltered(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting resources",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	resourceDetails := make([]ResourceDetail, len(resources))
+	for i := range resources {
+		resourceDetails[i] = toResourceDetail(&resources[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: ResourceListResponse{
+			Resources: resourceDetails,
+			Total:     total,
+			Limit:     limit,
+			Offset:    offset,
+		},
+		Message: "Resources retrieved successfully",
+	})
+}
+
+func (api *ResourceAPI) getResource(c echo.Context) error {
+	resource, err := api.visibleResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toResourceDetail(resource, userLocation(c)),
+		Message: "Resource retrieved successfully",
+	})
+}
+
+// getAvailability lists when the resource is reserved, over the range
+// calendarRange reads.
+func (api *ResourceAPI) getAvailability(c echo.Context) error {
+	from, to, fieldErr := calendarRange(c)
+	if fieldErr != nil {
+		return queryParamError(c, fieldErr)
+	}
+	resource, err := api.visibleResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	reservations, err := api.reservationRepo.GetByResource(c.Request().Context(), resource.ID, from.UTC(), to.UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reservations",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	slots := make([]ReservedSlot, len(reservations))
+	for i, reservation := range reservations {
+		slots[i] = ReservedSlot{
+			StartsAt: reservation.StartsAt.In(userLocation(c)),
+			EndsAt:   reservation.EndsAt.In(userLocation(c)),
+		}
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: ResourceAvailability{
+			ResourceID: resource.ID,
+			From:       from,
+			To:         to,
+			Reserved:   slots,
+		},
+		Message: "Availability retrieved successfully",
+	})
+}
+
+func (api *ResourceAPI) createResource(c echo.Context) error {
+	var req ResourceRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if _, err := api.branches.ManagedBranch(c, req.BranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	resource := &models.Resource{
+		ID: uuid.New().String(),
+	}
+	applyResourceRequest(resource, &req)
+	if err := api.resourceRepo.Create(c.Request().Context(), resource); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating resource",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "resource", resource.ID, nil, toResourceDetail(resource, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toResourceDetail(resource, userLocation(c)),
+		Message: "Resource created successfully",
+	})
+}
+
+// updateResource replaces a resource. Reservations already made are kept
+// even if they no longer fit its max_minutes.
+func (api *ResourceAPI) updateResource(c echo.Context) error {
+	var req ResourceRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	resource, err := api.managedResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	if req.BranchID != resource.BranchID {
+		return fieldValidationError(c, "branch_id", "immutable", "cannot be changed")
+	}
+	before := toResourceDetail(resource, time.UTC)
+	applyResourceRequest(resource, &req)
+	if err := api.resourceRepo.Update(c.Request().Context(), resource); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating resource",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "resource", resource.ID, before, toResourceDetail(resource, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toResourceDetail(resource, userLocation(c)),
+		Message: "Resource updated successfully",
+	})
+}
+
+func (api *ResourceAPI) deleteResource(c echo.Context) error {
+	resource, err := api.managedResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	upcoming, err := api.reservationRepo.HasUpcoming(c.Request().Context(), resource.ID, time.Now().UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking resource reservations",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if upcoming {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Resource still has upcoming reservations",
+			ErrorCode: models.ErrCodeResourceInUse,
+		})
+	}
+	if err := api.resourceRepo.Delete(c.Request().Context(), resource.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting resource",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "resource", resource.ID, toResourceDetail(resource, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Resource deleted successfully",
+	})
+}
+
+// getResourceReservations lists who reserved the resource, over the range
+// calendarRange reads.
+func (api *ResourceAPI) getResourceReservations(c echo.Context) error {
+	from, to, fieldErr := calendarRange(c)
+	if fieldErr != nil {
+		return queryParamError(c, fieldErr)
+	}
+	resource, err := api.managedResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	reservations, err := api.reservationRepo.GetByResource(c.Request().Context(), resource.ID, from.UTC(), to.UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reservations",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	userIDs := make([]string, len(reservations))
+	for i, reservation := range reservations {
+		userIDs[i] = reservation.UserID
+	}
+	users, err := api.userRepo.GetByIDs(c.Request().Context(), userIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	membersByID := make(map[string]*ReservationMember, len(users))
+	for _, user := range users {
+		membersByID[user.ID] = &ReservationMember{
+			UserID:    user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+		}
+	}
+	reservationDetails := make([]ReservationDetail, len(reservations))
+	for i := range reservations {
+		reservationDetails[i] = toReservationDetail(&reservations[i], resource, userLocation(c))
+		reservationDetails[i].Member = membersByID[reservations[i].UserID]
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    reservationDetails,
+		Message: "Reservations retrieved successfully",
+	})
+}
+
+func (api *ResourceAPI) cancelResourceReservation(c echo.Context) error {
+	resource, err := api.managedResource(c, c.Param("id"))
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	reservation, err := api.reservationRepo.GetByID(c.Request().Context(), c.Param("reservationId"))
+	if err == nil && reservation.ResourceID != resource.ID {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return reservationLookupError(c, err)
+	}
+	return api.cancel(c, reservation, resource)
+}
+
+func (api *ResourceAPI) getReservations(c echo.Context) error {
+	userID := api.authMw.GetUserFromContext(c).UserID
+	reservations, err := api.reservationRepo.GetActiveByUser(c.Request().Context(), userID, time.Now().UTC())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reservations",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	resourceIDs := make([]string, len(reservations))
+	for i, reservation := range reservations {
+		resourceIDs[i] = reservation.ResourceID
+	}
+	resources, err := api.resourceRepo.GetByIDs(c.Request().Context(), resourceIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving resources",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	resourcesByID := make(map[string]*models.Resource, len(resources))
+	for i := range resources {
+		resourcesByID[resources[i].ID] = &resources[i]
+	}
+	reservationDetails := make([]ReservationDetail, 0, len(reservations))
+	for i := range reservations {
+		resource, ok := resourcesByID[reservations[i].ResourceID]
+		if !ok {
+			continue
+		}
+		reservationDetails = append(reservationDetails, toReservationDetail(&reservations[i], resource, userLocation(c)))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    reservationDetails,
+		Message: "Reservations retrieved successfully",
+	})
+}
+
+func (api *ResourceAPI) createReservation(c echo.Context) error {
+	var req CreateReservationRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	now := time.Now().UTC()
+	if !req.StartsAt.After(now) {
+		return fieldValidationError(c, "starts_at", "future", "must be in the future")
+	}
+	if req.StartsAt.After(now.Add(api.window)) {
+		return fieldValidationError(c, "starts_at", "window", "is further ahead than reservations are taken")
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return fieldValidationError(c, "ends_at", "gtfield", "must be after starts_at")
+	}
+	ctx := c.Request().Context()
+	resource, err := api.visibleResource(c, req.ResourceID)
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	if req.EndsAt.Sub(req.StartsAt) > time.Duration(resource.MaxMinutes)*time.Minute {
+		return fieldValidationError(c, "ends_at", "max_minutes", "must be within max_minutes of starts_at")
+	}
+	userID := api.authMw.GetUserFromContext(c).UserID
+	user, err := api.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	plan, err := api.planRepo.GetByID(user.MembershipPlanID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving membership plan",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+
+	reservation := &models.Reservation{
+		ID:         uuid.New().String(),
+		ResourceID: resource.ID,
+		UserID:     userID,
+		StartsAt:   req.StartsAt.UTC(),
+		EndsAt:     req.EndsAt.UTC(),
+		Note:       req.Note,
+	}
+	if err := api.reservationRepo.Create(ctx, reservation, plan.MaxReservations); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return resourceLookupError(c, err)
+		}
+		return reservationLookupError(c, err)
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toReservationDetail(reservation, resource, userLocation(c)),
+		Message: "Reservation created successfully",
+	})
+}
+
+func (api *ResourceAPI) cancelReservation(c echo.Context) error {
+	reservation, err := api.reservationRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err == nil && reservation.UserID != api.authMw.GetUserFromContext(c).UserID {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return reservationLookupError(c, err)
+	}
+	resource, err := api.resourceRepo.GetByID(c.Request().Context(), reservation.ResourceID)
+	if err != nil {
+		return resourceLookupError(c, err)
+	}
+	return api.cancel(c, reservation, resource)
+}
+
+func (api *ResourceAPI) cancel(c echo.Context, reservation *models.Reservation, resource *models.Resource) error {
+	userID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.reservationRepo.Cancel(c.Request().Context(), reservation, userID); err != nil {
+		return reservationLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toReservationDetail(reservation, resource, userLocation(c)),
+		Message: "Reservation cancelled",
+	})
+}
+
+// visibleResource loads a resource, hiding those at local branches the
+// caller may not see.
+func (api *ResourceAPI) visibleResource(c echo.Context, id string) (*models.Resource, error) {
+	resource, err := api.resourceRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := api.branches.VisibleBranch(c, resource.BranchID); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// managedResource loads a resource the calling admin may manage, hiding
+// other branches' resources from branch staff.
+func (api *ResourceAPI) managedResource(c echo.Context, id string) (*models.Resource, error) {
+	resource, err := api.resourceRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && staffBranchID != resource.BranchID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return resource, nil
+}
+
+// calendarRange reads the from and days query parameters: days days
+// (default 7, at most 31) from the start of the day from (default today), in
+// the caller's time zone.
+func calendarRange(c echo.Context) (from, to time.Time, fieldErr *models.FieldError) {
+	location := userLocation(c)
+	now := time.Now().In(location)
+	from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if value := c.QueryParam("from"); value != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, value, location)
+		if err != nil {
+			return from, to, &models.FieldError{Field: "from", Message: "from must be a date in YYYY-MM-DD format"}
+		}
+		from = parsed
+	}
+	days := defaultAvailabilityDays
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAvailabilityDays {
+			return from, to, &models.FieldError{Field: "days", Message: "days must be a whole number between 1 and 31"}
+		}
+		days = parsed
+	}
+	return from, from.AddDate(0, 0, days), nil
+}
+
+// queryParamError rejects a malformed query parameter.
+func queryParamError(c echo.Context, fieldErr *models.FieldError) error {
+	return c.JSON(http.StatusBadRequest, models.Response{
+		Message:   fieldErr.Message,
+		ErrorCode: models.ErrCodeValidation,
+		Errors:    []models.FieldError{*fieldErr},
+	})
+}
+
+func applyResourceRequest(resource *models.Resource, req *ResourceRequest) {
+	resource.BranchID = req.BranchID
+	resource.Kind = req.Kind
+	resource.Name = req.Name
+	resource.Description = req.Description
+	resource.Location = req.Location
+	resource.Seats = req.Seats
+	resource.MaxMinutes = req.MaxMinutes
+}
+
+func resourceLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Resource not found",
+			ErrorCode: models.ErrCodeResourceNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving resource",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func reservationLookupError(c echo.Context, err error) error {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Reservation not found",
+			ErrorCode: models.ErrCodeReservationNotFound,
+		})
+	case errors.Is(err, repositories.ErrReservationConflict):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "The resource is already reserved for part of that time",
+			ErrorCode: models.ErrCodeReservationConflict,
+		})
+	case errors.Is(err, repositories.ErrReservationLimitReached):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You have reached your membership plan's reservation limit",
+			ErrorCode: models.ErrCodeReservationLimitReached,
+		})
+	case errors.Is(err, repositories.ErrReservationEnded):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Reservation was already cancelled or has ended",
+			ErrorCode: models.ErrCodeReservationEnded,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing reservation",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toResourceDetail(resource *models.Resource, location *time.Location) ResourceDetail {
+	return ResourceDetail{
+		ID:          resource.ID,
+		BranchID:    resource.BranchID,
+		Kind:        resource.Kind,
+		Name:        resource.Name,
+		Description: resource.Description,
+		Location:    resource.Location,
+		Seats:       resource.Seats,
+		MaxMinutes:  resource.MaxMinutes,
+		CreatedDate: resource.CreatedDate.In(location),
+		UpdatedDate: resource.UpdatedDate.In(location),
+	}
+}
+
+func toReservationDetail(reservation *models.Reservation, resource *models.Resource, location *time.Location) ReservationDetail {
+	return ReservationDetail{
+		ID:            reservation.ID,
+		ResourceID:    reservation.ResourceID,
+		ResourceName:  resource.Name,
+		BranchID:      resource.BranchID,
+		StartsAt:      reservation.StartsAt.In(location),
+		EndsAt:        reservation.EndsAt.In(location),
+		Note:          reservation.Note,
+		CancelledDate: timeIn(reservation.CancelledDate, location),
+		CreatedDate:   reservation.CreatedDate.In(location),
+	}
+}
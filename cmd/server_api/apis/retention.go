@@ -0,0 +1,298 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/jobs"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// RetentionAPI is the trash for soft-deleted books and users: it lists them
+// with the date the retention job will purge them, restores them, or purges
+// them at once. Only system admins can use it.
+type RetentionAPI struct {
+	retentionRepo *repositories.RetentionRepository
+	branches      *BranchAccess
+	policy        jobs.RetentionPolicy
+}
+
+// DeletedBookDetail is a soft-deleted book. PurgeAfter is nil when deleted
+// books are kept forever.
+type DeletedBookDetail struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Author      string     `json:"author"`
+	ISBN        *string    `json:"isbn"`
+	DeletedDate time.Time  `json:"deleted_date"`
+	PurgeAfter  *time.Time `json:"purge_after"`
+}
+
+// DeletedUserDetail is a soft-deleted user. PurgeAfter is nil when deleted
+// users are kept forever.
+type DeletedUserDetail struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	Role        string     `json:"role"`
+	CardNumber  string     `json:"card_number"`
+	DeletedDate time.Time  `json:"deleted_date"`
+	PurgeAfter  *time.Time `json:"purge_after"`
+}
+
+type DeletedBookListResponse struct {
+	Books  []DeletedBookDetail `json:"books"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+type DeletedUserListResponse struct {
+	Users  []DeletedUserDetail `json:"users"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+func NewRetentionAPI(retentionRepo *repositories.RetentionRepository, branches *BranchAccess, policy jobs.RetentionPolicy) *RetentionAPI {
+	return &RetentionAPI{
+		retentionRepo: retentionRepo,
+		branches:      branches,
+		policy:        policy,
+	}
+}
+
+// Setup registers the trash under /admin/trash.
+func (api *RetentionAPI) Setup(group *echo.Group) {
+	group.GET("/books", api.getDeletedBooks)
+	group.POST("/books/:id/restore", api.restoreBook)
+	group.DELETE("/books/:id", api.purgeBook)
+	group.GET("/users", api.getDeletedUsers)
+	group.POST("/users/:id/restore", api.restoreUser)
+	group.DELETE("/users/:id", api.purgeUser)
+}
+
+func (api *RetentionAPI) getDeletedBooks(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	books, err := api.retentionRepo.GetDeletedBooks(c.Request().Context(), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving deleted books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.retentionRepo.CountDeletedBooks(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting deleted books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]DeletedBookDetail, len(books))
+	for i := range books {
+		details[i] = api.toDeletedBookDetail(&books[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: DeletedBookListResponse{
+			Books:  details,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+		Message: "Deleted books retrieved successfully",
+	})
+}
+
+func (api *RetentionAPI) restoreBook(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	book, err := api.retentionRepo.GetDeletedBook(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return deletedBookError(c, err)
+	}
+	if err := api.retentionRepo.RestoreBook(c.Request().Context(), book); err != nil {
+		return deletedBookError(c, err)
+	}
+	auditRecord(c, "book", book.ID, nil, book)
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    book,
+		Message: "Book restored successfully",
+	})
+}
+
+// purgeBook removes a deleted book for good without waiting for its
+// retention window.
+func (api *RetentionAPI) purgeBook(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	book, err := api.retentionRepo.GetDeletedBook(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return deletedBookError(c, err)
+	}
+	if err := api.retentionRepo.PurgeBook(c.Request().Context(), book.ID, false); err != nil {
+		return deletedBookError(c, err)
+	}
+	auditRecord(c, "book", book.ID, api.toDeletedBookDetail(book, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Book purged successfully",
+	})
+}
+
+func (api *RetentionAPI) getDeletedUsers(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	users, err := api.retentionRepo.GetDeletedUsers(c.Request().Context(), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving deleted users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.retentionRepo.CountDeletedUsers(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting deleted users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]DeletedUserDetail, len(users))
+	for i := range users {
+		details[i] = api.toDeletedUserDetail(&users[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: DeletedUserListResponse{
+			Users:  details,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+		Message: "Deleted users retrieved successfully",
+	})
+}
+
+// restoreUser undeletes a user unless a live account has taken the email
+// since.
+func (api *RetentionAPI) restoreUser(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	ctx := c.Request().Context()
+	user, err := api.retentionRepo.GetDeletedUser(ctx, c.Param("id"))
+	if err != nil {
+		return deletedUserError(c, err)
+	}
+	err = api.retentionRepo.RestoreUser(ctx, user)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Email already exists",
+			ErrorCode: models.ErrCodeEmailExists,
+		})
+	}
+	if err != nil {
+		return deletedUserError(c, err)
+	}
+	auditRecord(c, "user", user.ID, nil, toUserDetail(user, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toUserDetail(user, userLocation(c)),
+		Message: "User restored successfully",
+	})
+}
+
+// purgeUser removes a deleted user for good without waiting for their
+// retention window.
+func (api *RetentionAPI) purgeUser(c echo.Context) error {
+	if err := api.branches.RequireSystemAdmin(c); err != nil {
+		return systemAdminError(c, err)
+	}
+	user, err := api.retentionRepo.GetDeletedUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return deletedUserError(c, err)
+	}
+	if err := api.retentionRepo.PurgeUser(c.Request().Context(), user.ID, false); err != nil {
+		return deletedUserError(c, err)
+	}
+	auditRecord(c, "user", user.ID, api.toDeletedUserDetail(user, time.UTC), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "User purged successfully",
+	})
+}
+
+func deletedBookError(c echo.Context, err error) error {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Deleted book not found",
+			ErrorCode: models.ErrCodeBookNotFound,
+		})
+	case errors.Is(err, repositories.ErrPurgeBlocked):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Book is still referenced by transfers, digital loans or ebook files",
+			ErrorCode: models.ErrCodePurgeBlocked,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing deleted book",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func deletedUserError(c echo.Context, err error) error {
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Deleted user not found",
+			ErrorCode: models.ErrCodeUserNotFound,
+		})
+	case errors.Is(err, repositories.ErrPurgeBlocked):
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "User is still referenced by loans, reservations or other library records",
+			ErrorCode: models.ErrCodePurgeBlocked,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing deleted user",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func (api *RetentionAPI) toDeletedBookDetail(book *models.Book, location *time.Location) DeletedBookDetail {
+	return DeletedBookDetail{
+		ID:          book.ID,
+		Title:       book.Title,
+		Author:      book.Author,
+		ISBN:        book.ISBN,
+		DeletedDate: book.DeletedDate.In(location),
+		PurgeAfter:  timeIn(jobs.PurgeAfter(book.DeletedDate, api.policy.BookDays), location),
+	}
+}
+
+func (api *RetentionAPI) toDeletedUserDetail(user *models.User, location *time.Location) DeletedUserDetail {
+	return DeletedUserDetail{
+		ID:          user.ID,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		CardNumber:  user.CardNumber,
+		DeletedDate: user.DeletedDate.In(location),
+		PurgeAfter:  timeIn(jobs.PurgeAfter(user.DeletedDate, api.policy.UserDays), location),
+	}
+}
@@ -0,0 +1,237 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ReviewAPI lets members rate and review the books they have borrowed.
+// Circulation of physical copies is not recorded, so only digital loans count
+// as borrowing.
+type ReviewAPI struct {
+	reviewRepo *repositories.ReviewRepository
+	bookRepo   repositories.BookRepo
+	userRepo   repositories.UserRepo
+	loanRepo   *repositories.DigitalLoanRepository
+	authMw     *auth.Middleware
+}
+
+type CreateReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text"`
+}
+
+type ReviewDetail struct {
+	ID           string    `json:"id"`
+	BookID       string    `json:"book_id"`
+	UserID       string    `json:"user_id"`
+	ReviewerName string    `json:"reviewer_name"`
+	Rating       int       `json:"rating"`
+	Text         string    `json:"text"`
+	CreatedDate  time.Time `json:"created_date"`
+}
+
+type ReviewListResponse struct {
+	Reviews []ReviewDetail `json:"reviews"`
+	Total   int64          `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+func NewReviewAPI(reviewRepo *repositories.ReviewRepository, bookRepo repositories.BookRepo, userRepo repositories.UserRepo, loanRepo *repositories.DigitalLoanRepository, authMw *auth.Middleware) *ReviewAPI {
+	return &ReviewAPI{
+		reviewRepo: reviewRepo,
+		bookRepo:   bookRepo,
+		userRepo:   userRepo,
+		loanRepo:   loanRepo,
+		authMw:     authMw,
+	}
+}
+
+func (api *ReviewAPI) Setup(group *echo.Group) {
+	group.GET("/:id/reviews", api.getReviews)
+	group.POST("/:id/reviews", api.createReview, api.authMw.RequireAuth())
+}
+
+func (api *ReviewAPI) createReview(c echo.Context) error {
+	bookID := c.Param("id")
+	var req CreateReviewRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Rating must be between 1 and 5",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "rating",
+					Message: "Rating must be between 1 and 5",
+				},
+			},
+		})
+	}
+	_, err := api.bookRepo.GetByID(c.Request().Context(), bookID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Book not found",
+				ErrorCode: models.ErrCodeBookNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	claims := api.authMw.GetUserFromContext(c)
+	borrowed, err := api.loanRepo.HasBorrowed(c.Request().Context(), claims.UserID, bookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking loan history",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if !borrowed {
+		return c.JSON(http.StatusForbidden, models.Response{
+			Message:   "You can only review books you have borrowed",
+			ErrorCode: models.ErrCodeBookNotBorrowed,
+		})
+	}
+	exists, err := api.reviewRepo.ExistsForUser(bookID, claims.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking existing reviews",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if exists {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You have already reviewed this book",
+			ErrorCode: models.ErrCodeReviewExists,
+		})
+	}
+	review := &models.Review{
+		ID:     uuid.New().String(),
+		BookID: bookID,
+		UserID: claims.UserID,
+		Rating: req.Rating,
+		Body:   strings.TrimSpace(req.Text),
+	}
+	if err := api.reviewRepo.Create(review); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating review",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	reviewDetails, err := api.toReviewDetails(c.Request().Context(), []models.Review{*review}, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reviewer",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    reviewDetails[0],
+		Message: "Review created successfully",
+	})
+}
+
+func (api *ReviewAPI) getReviews(c echo.Context) error {
+	bookID := c.Param("id")
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	_, err := api.bookRepo.GetByID(c.Request().Context(), bookID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "Book not found",
+				ErrorCode: models.ErrCodeBookNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving book",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	reviews, err := api.reviewRepo.GetByBookID(bookID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reviews",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.reviewRepo.CountByBookID(bookID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting reviews",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	reviewDetails, err := api.toReviewDetails(c.Request().Context(), reviews, userLocation(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving reviewers",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: ReviewListResponse{
+			Reviews: reviewDetails,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		},
+		Message: "Reviews retrieved successfully",
+	})
+}
+
+func (api *ReviewAPI) toReviewDetails(ctx context.Context, reviews []models.Review, location *time.Location) ([]ReviewDetail, error) {
+	userIDs := make([]string, len(reviews))
+	for i, review := range reviews {
+		userIDs[i] = review.UserID
+	}
+	users, err := api.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(users))
+	for _, user := range users {
+		names[user.ID] = reviewerName(&user)
+	}
+	reviewDetails := make([]ReviewDetail, len(reviews))
+	for i, review := range reviews {
+		reviewDetails[i] = ReviewDetail{
+			ID:           review.ID,
+			BookID:       review.BookID,
+			UserID:       review.UserID,
+			ReviewerName: names[review.UserID],
+			Rating:       review.Rating,
+			Text:         review.Body,
+			CreatedDate:  review.CreatedDate.In(location),
+		}
+	}
+	return reviewDetails, nil
+}
+
+func reviewerName(user *models.User) string {
+	lastName := []rune(user.LastName)
+	if len(lastName) == 0 {
+		return user.FirstName
+	}
+	return user.FirstName + " " + string(lastName[0]) + "."
+}
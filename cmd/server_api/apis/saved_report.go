@@ -0,0 +1,437 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// savedReportParams lists the query parameters each report type accepts.
+var savedReportParams = map[string][]string{
+	"members":      {"from", "to", "dormant_days", "cohorts", "branch_id"},
+	"daily-stats":  {"from", "to"},
+	"demand":       {"from", "to", "min_searches", "limit"},
+	"search-terms": {"from", "to", "limit"},
+	"staff":        {"from", "to"},
+}
+
+// SavedReportAPI stores report definitions and runs them through the
+// ReportAPI handlers as their owner, so a saved report always returns what
+// the equivalent GET /reports request would.
+type SavedReportAPI struct {
+	savedRepo *repositories.SavedReportRepository
+	userRepo  repositories.UserRepo
+	reports   *ReportAPI
+	authMw    *auth.Middleware
+	echo      *echo.Echo
+}
+
+type CreateSavedReportRequest struct {
+	Name          string            `json:"name" validate:"required,max=100"`
+	ReportType    string            `json:"report_type" validate:"required,oneof=members daily-stats demand search-terms staff"`
+	Params        map[string]string `json:"params,omitempty"`
+	ScheduleHours *int              `json:"schedule_hours,omitempty" validate:"omitempty,min=1,max=720"`
+}
+
+// UpdateSavedReportRequest replaces params when given. A schedule_hours of 0
+// stops scheduled runs.
+type UpdateSavedReportRequest struct {
+	Name          *string           `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Params        map[string]string `json:"params,omitempty"`
+	ScheduleHours *int              `json:"schedule_hours,omitempty" validate:"omitempty,min=0,max=720"`
+}
+
+type SavedReportDetail struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	ReportType    string            `json:"report_type"`
+	Params        map[string]string `json:"params"`
+	ScheduleHours *int              `json:"schedule_hours"`
+	NextRunAt     *time.Time        `json:"next_run_at"`
+	LastRunAt     *time.Time        `json:"last_run_at"`
+	LastStatus    *int              `json:"last_status"`
+	LastResult    json.RawMessage   `json:"last_result,omitempty"`
+	CreatedDate   time.Time         `json:"created_date"`
+	UpdatedDate   time.Time         `json:"updated_date"`
+}
+
+// reportResult is a report response as stored, with its data kept verbatim.
+type reportResult struct {
+	Message   string              `json:"message"`
+	ErrorCode string              `json:"error_code,omitempty"`
+	Errors    []models.FieldError `json:"errors,omitempty"`
+	Data      json.RawMessage     `json:"data,omitempty"`
+}
+
+// reportRecorder captures a report handler's response.
+type reportRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *reportRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *reportRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *reportRecorder) WriteHeader(int) {}
+
+func NewSavedReportAPI(savedRepo *repositories.SavedReportRepository, userRepo repositories.UserRepo, reports *ReportAPI, authMw *auth.Middleware, e *echo.Echo) *SavedReportAPI {
+	return &SavedReportAPI{
+		savedRepo: savedRepo,
+		userRepo:  userRepo,
+		reports:   reports,
+		authMw:    authMw,
+		echo:      e,
+	}
+}
+
+func (api *SavedReportAPI) Setup(group *echo.Group) {
+	group.POST("", api.createSavedReport)
+	group.GET("", api.getSavedReports)
+	group.GET("/:id", api.getSavedReport)
+	group.PUT("/:id", api.updateSavedReport)
+	group.DELETE("/:id", api.deleteSavedReport)
+	group.POST("/:id/run", api.runSavedReport)
+}
+
+func (api *SavedReportAPI) createSavedReport(c echo.Context) error {
+	var req CreateSavedReportRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if fieldErrors := savedReportParamErrors(req.ReportType, req.Params); len(fieldErrors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, models.Response{
+			Message:   "Request validation failed",
+			ErrorCode: models.ErrCodeValidation,
+			Errors:    fieldErrors,
+		})
+	}
+	userID := api.authMw.UserID(c)
+	exists, err := api.savedRepo.NameExists(c.Request().Context(), userID, req.Name, "")
+	if err != nil || exists {
+		return savedReportNameError(c, err)
+	}
+	report := &models.SavedReport{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Name:       req.Name,
+		ReportType: req.ReportType,
+		Params:     encodeReportParams(req.Params),
+	}
+	setReportSchedule(report, req.ScheduleHours)
+	if err = api.savedRepo.Create(c.Request().Context(), report); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating saved report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "saved_report", report.ID, nil, toSavedReportDetail(report, time.UTC, false))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toSavedReportDetail(report, userLocation(c), false),
+		Message: "Saved report created successfully",
+	})
+}
+
+func (api *SavedReportAPI) getSavedReports(c echo.Context) error {
+	reports, err := api.savedRepo.GetByUserID(c.Request().Context(), api.authMw.UserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving saved reports",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]SavedReportDetail, len(reports))
+	for i := range reports {
+		details[i] = toSavedReportDetail(&reports[i], userLocation(c), false)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details,
+		Message: "Saved reports retrieved successfully",
+	})
+}
+
+func (api *SavedReportAPI) getSavedReport(c echo.Context) error {
+	report, err := api.savedReport(c)
+	if err != nil {
+		return savedReportError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toSavedReportDetail(report, userLocation(c), true),
+		Message: "Saved report retrieved successfully",
+	})
+}
+
+func (api *SavedReportAPI) updateSavedReport(c echo.Context) error {
+	var req UpdateSavedReportRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	report, err := api.savedReport(c)
+	if err != nil {
+		return savedReportError(c, err)
+	}
+	before := toSavedReportDetail(report, time.UTC, false)
+	if req.Params != nil {
+		if fieldErrors := savedReportParamErrors(report.ReportType, req.Params); len(fieldErrors) > 0 {
+			return c.JSON(http.StatusUnprocessableEntity, models.Response{
+				Message:   "Request validation failed",
+				ErrorCode: models.ErrCodeValidation,
+				Errors:    fieldErrors,
+			})
+		}
+		report.Params = encodeReportParams(req.Params)
+	}
+	if req.Name != nil && *req.Name != report.Name {
+		exists, err := api.savedRepo.NameExists(c.Request().Context(), report.UserID, *req.Name, report.ID)
+		if err != nil || exists {
+			return savedReportNameError(c, err)
+		}
+		report.Name = *req.Name
+	}
+	if req.ScheduleHours != nil {
+		setReportSchedule(report, req.ScheduleHours)
+	}
+	if err := api.savedRepo.Update(c.Request().Context(), report); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating saved report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "saved_report", report.ID, before, toSavedReportDetail(report, time.UTC, false))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toSavedReportDetail(report, userLocation(c), false),
+		Message: "Saved report updated successfully",
+	})
+}
+
+func (api *SavedReportAPI) deleteSavedReport(c echo.Context) error {
+	report, err := api.savedReport(c)
+	if err != nil {
+		return savedReportError(c, err)
+	}
+	if err := api.savedRepo.Delete(c.Request().Context(), report.UserID, report.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting saved report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "saved_report", report.ID, toSavedReportDetail(report, time.UTC, false), nil)
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Saved report deleted successfully",
+	})
+}
+
+// runSavedReport runs the report now and answers with its response. Manual
+// runs do not move the schedule.
+func (api *SavedReportAPI) runSavedReport(c echo.Context) error {
+	report, err := api.savedReport(c)
+	if err != nil {
+		return savedReportError(c, err)
+	}
+	status, body, err := api.RunSaved(c.Request().Context(), report)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running saved report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	err = api.savedRepo.RecordRun(c.Request().Context(), report.ID, time.Now().UTC(), status, string(body), report.NextRunAt)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error recording saved report run",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	var result reportResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error running saved report",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	response := models.Response{
+		Message:   result.Message,
+		ErrorCode: result.ErrorCode,
+		Errors:    result.Errors,
+	}
+	if result.Data != nil {
+		response.Data = result.Data
+	}
+	return c.JSON(status, response)
+}
+
+// RunSaved runs report as its owner and returns the status and untranslated
+// body the report handler wrote. Owners who are no longer active admins get
+// the 403 the report routes would give them.
+func (api *SavedReportAPI) RunSaved(ctx context.Context, report *models.SavedReport) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/reports/"+report.ReportType+"?"+report.Params, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	recorder := &reportRecorder{
+		header: http.Header{},
+	}
+	c := api.echo.NewContext(req, recorder)
+	owner, err := api.userRepo.GetByID(ctx, report.UserID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, nil, err
+	}
+	if owner == nil || owner.Role != "admin" || owner.Status != "active" {
+		err = c.JSON(http.StatusForbidden, models.Response{
+			Message:   "Insufficient permissions",
+			ErrorCode: models.ErrCodeInsufficientPermissions,
+		})
+		return c.Response().Status, recorder.body.Bytes(), err
+	}
+	c.Set(auth.UserContextKey, &auth.Claims{
+		UserID: owner.ID,
+		Email:  owner.Email,
+		Role:   owner.Role,
+	})
+	c.Set(locationContextKey, &callerLocation{
+		location: owner.Location(),
+	})
+	handler := api.reportHandler(report.ReportType)
+	if handler == nil {
+		return 0, nil, fmt.Errorf("unknown report type %q", report.ReportType)
+	}
+	if err := handler(c); err != nil {
+		api.echo.HTTPErrorHandler(err, c)
+	}
+	return c.Response().Status, recorder.body.Bytes(), nil
+}
+
+func (api *SavedReportAPI) reportHandler(reportType string) echo.HandlerFunc {
+	switch reportType {
+	case "members":
+		return api.reports.getMemberEngagement
+	case "daily-stats":
+		return api.reports.getDailyStats
+	case "demand":
+		return api.reports.getDemand
+	case "search-terms":
+		return api.reports.getSearchTerms
+	case "staff":
+		return api.reports.getStaffActivity
+	}
+	return nil
+}
+
+func (api *SavedReportAPI) savedReport(c echo.Context) (*models.SavedReport, error) {
+	return api.savedRepo.GetByID(c.Request().Context(), api.authMw.UserID(c), c.Param("id"))
+}
+
+func savedReportError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Saved report not found",
+			ErrorCode: models.ErrCodeSavedReportNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving saved report",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func savedReportNameError(c echo.Context, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking saved report name",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "A saved report with this name already exists",
+		ErrorCode: models.ErrCodeSavedReportExists,
+	})
+}
+
+func savedReportParamErrors(reportType string, params map[string]string) []models.FieldError {
+	allowed := make(map[string]bool)
+	for _, name := range savedReportParams[reportType] {
+		allowed[name] = true
+	}
+	var fieldErrors []models.FieldError
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if !allowed[name] {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "params." + name,
+				Message: "is not a parameter of this report",
+			})
+		}
+	}
+	return fieldErrors
+}
+
+func encodeReportParams(params map[string]string) string {
+	values := url.Values{}
+	for name, value := range params {
+		values.Set(name, value)
+	}
+	return values.Encode()
+}
+
+func decodeReportParams(encoded string) map[string]string {
+	params := make(map[string]string)
+	values, _ := url.ParseQuery(encoded)
+	for name := range values {
+		params[name] = values.Get(name)
+	}
+	return params
+}
+
+// setReportSchedule schedules the first run for the next job poll, or stops
+// scheduled runs when hours is nil or 0.
+func setReportSchedule(report *models.SavedReport, hours *int) {
+	if hours == nil || *hours == 0 {
+		report.ScheduleHours = nil
+		report.NextRunAt = nil
+		return
+	}
+	now := time.Now().UTC()
+	report.ScheduleHours = hours
+	report.NextRunAt = &now
+}
+
+func toSavedReportDetail(report *models.SavedReport, location *time.Location, withResult bool) SavedReportDetail {
+	detail := SavedReportDetail{
+		ID:            report.ID,
+		Name:          report.Name,
+		ReportType:    report.ReportType,
+		Params:        decodeReportParams(report.Params),
+		ScheduleHours: report.ScheduleHours,
+		NextRunAt:     timeIn(report.NextRunAt, location),
+		LastRunAt:     timeIn(report.LastRunAt, location),
+		LastStatus:    report.LastStatus,
+		CreatedDate:   report.CreatedDate.In(location),
+		UpdatedDate:   report.UpdatedDate.In(location),
+	}
+	if withResult && report.LastResult != nil {
+		detail.LastResult = json.RawMessage(*report.LastResult)
+	}
+	return detail
+}
@@ -0,0 +1,302 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// maxSavedSearches caps each member's saved searches, since the alert job
+// runs every one of them.
+const maxSavedSearches = 20
+
+// SavedSearchAPI keeps members' book searches. The saved search alert job
+// notifies the owner when books added later match one.
+type SavedSearchAPI struct {
+	searchRepo *repositories.SavedSearchRepository
+	bookRepo   repositories.BookRepo
+	authMw     *auth.Middleware
+}
+
+// SavedSearchRequest takes at least one criterion. query matches title,
+// author, genre and ISBN like GET /books/search, title and author match
+// substrings and genre matches exactly. PUT replaces every criterion.
+type SavedSearchRequest struct {
+	Name   string  `json:"name" validate:"required,max=100"`
+	Query  *string `json:"query,omitempty" validate:"omitempty,max=200"`
+	Title  *string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Author *string `json:"author,omitempty" validate:"omitempty,max=200"`
+	Genre  *string `json:"genre,omitempty" validate:"omitempty,max=100"`
+}
+
+type SavedSearchDetail struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Query         *string    `json:"query"`
+	Title         *string    `json:"title"`
+	Author        *string    `json:"author"`
+	Genre         *string    `json:"genre"`
+	LastCheckedAt time.Time  `json:"last_checked_at"`
+	LastMatchedAt *time.Time `json:"last_matched_at"`
+	CreatedDate   time.Time  `json:"created_date"`
+	UpdatedDate   time.Time  `json:"updated_date"`
+}
+
+func NewSavedSearchAPI(searchRepo *repositories.SavedSearchRepository, bookRepo repositories.BookRepo, authMw *auth.Middleware) *SavedSearchAPI {
+	return &SavedSearchAPI{
+		searchRepo: searchRepo,
+		bookRepo:   bookRepo,
+		authMw:     authMw,
+	}
+}
+
+// Setup registers the caller's saved searches under /me/saved-searches.
+func (api *SavedSearchAPI) Setup(group *echo.Group) {
+	group.POST("", api.createSavedSearch)
+	group.GET("", api.getSavedSearches)
+	group.GET("/:id", api.getSavedSearch)
+	group.PUT("/:id", api.updateSavedSearch)
+	group.DELETE("/:id", api.deleteSavedSearch)
+	group.GET("/:id/results", api.getSavedSearchResults)
+}
+
+// createSavedSearch saves a search. Only books added from now on trigger
+// alerts.
+func (api *SavedSearchAPI) createSavedSearch(c echo.Context) error {
+	var req SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	search := &models.SavedSearch{
+		ID:            uuid.New().String(),
+		UserID:        api.authMw.UserID(c),
+		LastCheckedAt: time.Now().UTC(),
+	}
+	if !applySavedSearchRequest(search, &req) {
+		return savedSearchCriteriaError(c)
+	}
+	ctx := c.Request().Context()
+	count, err := api.searchRepo.CountByUserID(ctx, search.UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting saved searches",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if count >= maxSavedSearches {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "You have reached the saved search limit",
+			ErrorCode: models.ErrCodeSavedSearchLimitReached,
+		})
+	}
+	exists, err := api.searchRepo.NameExists(ctx, search.UserID, search.Name, "")
+	if err != nil || exists {
+		return savedSearchNameError(c, err)
+	}
+	if err := api.searchRepo.Create(ctx, search); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating saved search",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toSavedSearchDetail(search, userLocation(c)),
+		Message: "Saved search created successfully",
+	})
+}
+
+func (api *SavedSearchAPI) getSavedSearches(c echo.Context) error {
+	searches, err := api.searchRepo.GetByUserID(c.Request().Context(), api.authMw.UserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving saved searches",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	details := make([]SavedSearchDetail, len(searches))
+	for i := range searches {
+		details[i] = toSavedSearchDetail(&searches[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    details,
+		Message: "Saved searches retrieved successfully",
+	})
+}
+
+func (api *SavedSearchAPI) getSavedSearch(c echo.Context) error {
+	search, err := api.savedSearch(c)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toSavedSearchDetail(search, userLocation(c)),
+		Message: "Saved search retrieved successfully",
+	})
+}
+
+// updateSavedSearch replaces a search. Books added before the change are
+// not matched again against the new criteria.
+func (api *SavedSearchAPI) updateSavedSearch(c echo.Context) error {
+	var req SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	search, err := api.savedSearch(c)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+	if !applySavedSearchRequest(search, &req) {
+		return savedSearchCriteriaError(c)
+	}
+	exists, err := api.searchRepo.NameExists(c.Request().Context(), search.UserID, search.Name, search.ID)
+	if err != nil || exists {
+		return savedSearchNameError(c, err)
+	}
+	if err := api.searchRepo.Update(c.Request().Context(), search); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error updating saved search",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toSavedSearchDetail(search, userLocation(c)),
+		Message: "Saved search updated successfully",
+	})
+}
+
+func (api *SavedSearchAPI) deleteSavedSearch(c echo.Context) error {
+	search, err := api.savedSearch(c)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+	if err := api.searchRepo.Delete(c.Request().Context(), search.UserID, search.ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting saved search",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Saved search deleted successfully",
+	})
+}
+
+// getSavedSearchResults runs the search over the whole catalog, newest
+// first.
+func (api *SavedSearchAPI) getSavedSearchResults(c echo.Context) error {
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	search, err := api.savedSearch(c)
+	if err != nil {
+		return savedSearchError(c, err)
+	}
+	filter := repositories.SavedSearchFilter(search)
+	books, err := api.bookRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to search books",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.bookRepo.CountFiltered(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to get book count",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return bookListResponse(c, books, models.Response{
+		Data: map[string]any{
+			"books":  books,
+			"total":  total,
+			"limit":  limit,
+			"offset": offset,
+		},
+		Message: "Books search completed successfully",
+	})
+}
+
+func (api *SavedSearchAPI) savedSearch(c echo.Context) (*models.SavedSearch, error) {
+	return api.searchRepo.GetByID(c.Request().Context(), api.authMw.UserID(c), c.Param("id"))
+}
+
+// applySavedSearchRequest copies the request onto search, dropping blank
+// criteria. It reports false when no criterion is left.
+func applySavedSearchRequest(search *models.SavedSearch, req *SavedSearchRequest) bool {
+	search.Name = req.Name
+	search.Query = searchCriterion(req.Query)
+	search.Title = searchCriterion(req.Title)
+	search.Author = searchCriterion(req.Author)
+	search.Genre = searchCriterion(req.Genre)
+	return search.Query != nil || search.Title != nil || search.Author != nil || search.Genre != nil
+}
+
+func searchCriterion(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func savedSearchCriteriaError(c echo.Context) error {
+	return fieldValidationError(c, "query", "required_without_all", "at least one of query, title, author or genre is required")
+}
+
+func savedSearchError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Saved search not found",
+			ErrorCode: models.ErrCodeSavedSearchNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving saved search",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func savedSearchNameError(c echo.Context, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking saved search name",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "A saved search with this name already exists",
+		ErrorCode: models.ErrCodeSavedSearchExists,
+	})
+}
+
+func toSavedSearchDetail(search *models.SavedSearch, location *time.Location) SavedSearchDetail {
+	return SavedSearchDetail{
+		ID:            search.ID,
+		Name:          search.Name,
+		Query:         search.Query,
+		Title:         search.Title,
+		Author:        search.Author,
+		Genre:         search.Genre,
+		LastCheckedAt: search.LastCheckedAt.In(location),
+		LastMatchedAt: timeIn(search.LastMatchedAt, location),
+		CreatedDate:   search.CreatedDate.In(location),
+		UpdatedDate:   search.UpdatedDate.In(location),
+	}
+}
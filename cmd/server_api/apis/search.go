@@ -0,0 +1,144 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultSearchGroupSize is how many results each group of a global search
+// returns unless limit is given.
+const defaultSearchGroupSize = 5
+
+const (
+	searchGroupBooks   = "books"
+	searchGroupAuthors = "authors"
+	searchGroupMembers = "members"
+)
+
+// SearchAPI is the single search box of the desk UI: one query across books,
+// authors and, for staff, member accounts.
+type SearchAPI struct {
+	bookRepo   repositories.BookRepo
+	searchRepo *repositories.SearchRepository
+	branches   *BranchAccess
+}
+
+type SearchResponse struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
+
+// SearchGroup is the results of one type. Results holds []models.Book,
+// []AuthorResult or []UserDetail according to Type, and Total counts every
+// match, not only those returned.
+type SearchGroup struct {
+	Type    string `json:"type"`
+	Total   int64  `json:"total"`
+	Results any    `json:"results"`
+}
+
+type AuthorResult struct {
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
+
+func NewSearchAPI(bookRepo repositories.BookRepo, searchRepo *repositories.SearchRepository, branches *BranchAccess) *SearchAPI {
+	return &SearchAPI{
+		bookRepo:   bookRepo,
+		searchRepo: searchRepo,
+		branches:   branches,
+	}
+}
+
+func (api *SearchAPI) Setup(group *echo.Group) {
+	group.GET("", api.search)
+}
+
+// search matches q against book titles, authors, genres and ISBNs, author
+// names, and, when the caller is an admin, the names, emails and card
+// numbers of the accounts they may see. limit applies to each group.
+func (api *SearchAPI) search(c echo.Context) error {
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return queryParamError(c, &models.FieldError{Field: "q", Message: "Search query (q) is required"})
+	}
+	limit, _, ok := pageParams(c, defaultSearchGroupSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	viewer, err := api.branches.Viewer(c)
+	if err != nil {
+		return searchError(c)
+	}
+	ctx := c.Request().Context()
+
+	filter := repositories.BookFilter{Keyword: query}
+	books, err := api.bookRepo.GetFiltered(ctx, filter, limit, 0)
+	if err != nil {
+		return searchError(c)
+	}
+	bookTotal, err := api.bookRepo.CountFiltered(ctx, filter)
+	if err != nil {
+		return searchError(c)
+	}
+
+	authors, err := api.searchRepo.SearchAuthors(ctx, query, limit)
+	if err != nil {
+		return searchError(c)
+	}
+	authorTotal, err := api.searchRepo.CountAuthors(ctx, query)
+	if err != nil {
+		return searchError(c)
+	}
+	authorResults := make([]AuthorResult, len(authors))
+	for i, author := range authors {
+		authorResults[i] = AuthorResult{
+			Name:  author.Name,
+			Books: author.Books,
+		}
+	}
+
+	groups := []SearchGroup{
+		{Type: searchGroupBooks, Total: bookTotal, Results: books},
+		{Type: searchGroupAuthors, Total: authorTotal, Results: authorResults},
+	}
+	if viewer != nil && viewer.Role == "admin" {
+		userRepo, err := api.branches.ScopedUsers(c, "")
+		if err != nil {
+			return searchError(c)
+		}
+		users, err := userRepo.SearchUsers(ctx, query, limit, 0)
+		if err != nil {
+			return searchError(c)
+		}
+		userTotal, err := userRepo.CountSearch(ctx, query)
+		if err != nil {
+			return searchError(c)
+		}
+		location := userLocation(c)
+		members := make([]UserDetail, len(users))
+		for i := range users {
+			members[i] = toUserDetail(&users[i], location)
+		}
+		groups = append(groups, SearchGroup{Type: searchGroupMembers, Total: userTotal, Results: members})
+	}
+
+	return c.JSON(http.StatusOK, models.Response{
+		Data: SearchResponse{
+			Query:  query,
+			Groups: groups,
+		},
+		Message: "Search completed successfully",
+	})
+}
+
+func searchError(c echo.Context) error {
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Failed to search",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
@@ -0,0 +1,170 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/librarycard"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const setupTokenBytes = 32
+
+type SetupAPI struct {
+	userRepo repositories.UserRepo
+	planRepo *repositories.MembershipPlanRepository
+	mu       sync.Mutex
+	token    string
+}
+
+type SetupRequest struct {
+	Token     string `json:"token" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=8"`
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
+}
+
+func NewSetupAPI(userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository) *SetupAPI {
+	return &SetupAPI{
+		userRepo: userRepo,
+		planRepo: planRepo,
+	}
+}
+
+func (api *SetupAPI) Setup(group *echo.Group) {
+	group.POST("/setup", api.setup)
+}
+
+// Bootstrap gives an empty users table its first admin. With an email and
+// password it creates the admin directly; otherwise it returns a one-time
+// token that unlocks POST /auth/setup until an admin is created.
+func (api *SetupAPI) Bootstrap(ctx context.Context, email, password string) (string, error) {
+	count, err := api.userRepo.Count(ctx)
+	if err != nil || count > 0 {
+		return "", err
+	}
+	if email != "" && password != "" {
+		user, err := api.createAdmin(ctx, email, password, "System", "Administrator")
+		if err != nil {
+			return "", err
+		}
+		slog.Info(
+			"Initial admin created",
+			"user_id", user.ID,
+			"email", user.Email,
+		)
+		return "", nil
+	}
+	buf := make([]byte, setupTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	api.mu.Lock()
+	api.token = token
+	api.mu.Unlock()
+	return token, nil
+}
+
+func (api *SetupAPI) setup(c echo.Context) error {
+	var req SetupRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.token == "" {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Setup has already been completed",
+			ErrorCode: models.ErrCodeSetupCompleted,
+		})
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(api.token)) != 1 {
+		return c.JSON(http.StatusUnauthorized, models.Response{
+			Message:   "Invalid setup token",
+			ErrorCode: models.ErrCodeInvalidSetupToken,
+		})
+	}
+	count, err := api.userRepo.Count(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if count > 0 {
+		api.token = ""
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Setup has already been completed",
+			ErrorCode: models.ErrCodeSetupCompleted,
+		})
+	}
+	user, err := api.createAdmin(c.Request().Context(), req.Email, req.Password, req.FirstName, req.LastName)
+	if errors.Is(err, repositories.ErrEmailExists) {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Email already exists",
+			ErrorCode: models.ErrCodeEmailExists,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating admin account",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	api.token = ""
+	slog.Info(
+		"Initial admin created via setup token",
+		"user_id", user.ID,
+		"email", user.Email,
+	)
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toUserProfile(user),
+		Message: "Admin account created successfully",
+	})
+}
+
+func (api *SetupAPI) createAdmin(ctx context.Context, email, password, firstName, lastName string) (*models.User, error) {
+	plan, err := api.planRepo.GetByCode(defaultMembershipPlanCode)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	cardNumber, err := librarycard.Issue(ctx, api.userRepo.CardNumberExists)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		ID:                    uuid.NewString(),
+		Email:                 normalizeEmail(email),
+		PasswordHash:          string(hashedPassword),
+		FirstName:             firstName,
+		LastName:              lastName,
+		Role:                  "admin",
+		Status:                "active",
+		MembershipPlanID:      plan.ID,
+		CardNumber:            cardNumber,
+		ReadingHistoryEnabled: true,
+	}
+	if err := api.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,631 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/cql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	sruVersion          = "1.2"
+	sruDefaultRecords   = 10
+	sruDiagnosticPrefix = "info:srw/diagnostic/1/"
+	sruDublinCoreSchema = "info:srw/schema/1/dc-v1.1"
+	sruMARCXMLSchema    = "info:srw/schema/1/marcxml-v1.1"
+	sruExplainSchema    = "http://explain.z3950.org/dtd/2.0/"
+	dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+	sruAllRecordsIndex  = "cql.allrecords"
+	sruDatabase         = "api/v1/sru"
+	sruContentType      = "text/xml; charset=utf-8"
+	sruRecordPackingXML = "xml"
+	sruMARCLeader       = "00000nam a2200000 a 4500"
+)
+
+// SRU diagnostics from the SRU 1.2 diagnostics list.
+const (
+	sruDiagGeneral             = 1
+	sruDiagUnsupportedOp       = 4
+	sruDiagUnsupportedVersion  = 5
+	sruDiagUnsupportedValue    = 6
+	sruDiagMissingParameter    = 7
+	sruDiagQuerySyntax         = 10
+	sruDiagUnsupportedIndex    = 16
+	sruDiagUnsupportedRelation = 19
+	sruDiagUnsupportedFeature  = 48
+	sruDiagStartOutOfRange     = 61
+	sruDiagUnknownSchema       = 66
+	sruDiagUnsupportedPacking  = 71
+	sruDiagNoStylesheets       = 110
+)
+
+var sruDiagnosticMessages = map[int]string{
+	sruDiagGeneral:             "General system error",
+	sruDiagUnsupportedOp:       "Unsupported operation",
+	sruDiagUnsupportedVersion:  "Unsupported version",
+	sruDiagUnsupportedValue:    "Unsupported parameter value",
+	sruDiagMissingParameter:    "Mandatory parameter not supplied",
+	sruDiagQuerySyntax:         "Query syntax error",
+	sruDiagUnsupportedIndex:    "Unsupported index",
+	sruDiagUnsupportedRelation: "Unsupported relation",
+	sruDiagUnsupportedFeature:  "Query feature unsupported",
+	sruDiagStartOutOfRange:     "First record position out of range",
+	sruDiagUnknownSchema:       "Unknown schema for retrieval",
+	sruDiagUnsupportedPacking:  "Unsupported record packing",
+	sruDiagNoStylesheets:       "Stylesheets not supported",
+}
+
+// sruIndex is a searchable CQL index and the BookFilter field it sets.
+// Exact indexes already match whole values, so they also accept == and
+// exact; the rest match substrings and only accept =.
+type sruIndex struct {
+	set   string
+	name  string
+	title string
+	exact bool
+	field func(filter *repositories.BookFilter) *string
+}
+
+var sruIndexes = []sruIndex{
+	{
+		set:   "cql",
+		name:  "serverChoice",
+		title: "Title, author, subject or ISBN",
+		field: func(filter *repositories.BookFilter) *string { return &filter.Keyword },
+	},
+	{
+		set:   "dc",
+		name:  "title",
+		title: "Title",
+		field: func(filter *repositories.BookFilter) *string { return &filter.Title },
+	},
+	{
+		set:   "dc",
+		name:  "creator",
+		title: "Author",
+		field: func(filter *repositories.BookFilter) *string { return &filter.Author },
+	},
+	{
+		set:   "dc",
+		name:  "subject",
+		title: "Genre",
+		exact: true,
+		field: func(filter *repositories.BookFilter) *string { return &filter.Genre },
+	},
+	{
+		set:   "bath",
+		name:  "isbn",
+		title: "ISBN",
+		exact: true,
+		field: func(filter *repositories.BookFilter) *string { return &filter.ISBN },
+	},
+	{
+		set:   "dc",
+		name:  "identifier",
+		title: "ISBN",
+		exact: true,
+		field: func(filter *repositories.BookFilter) *string { return &filter.ISBN },
+	},
+}
+
+var sruContextSets = []zeerexSet{
+	{Name: "cql", Identifier: "info:srw/cql-context-set/1/cql-v1.2"},
+	{Name: "dc", Identifier: "info:srw/cql-context-set/1/dc-v1.1"},
+	{Name: "bath", Identifier: "http://zing.z3950.org/cql/bath/2.0/"},
+}
+
+var sruRecordSchemas = []zeerexSchema{
+	{Name: "dc", Identifier: sruDublinCoreSchema, Title: "Dublin Core"},
+	{Name: "marcxml", Identifier: sruMARCXMLSchema, Title: "MARC21 XML"},
+}
+
+// SRUAPI serves the catalog over SRU 1.2 so other libraries and union
+// catalogs can search it. Problems with a request are reported as SRU
+// diagnostics in the response body rather than as JSON errors, since that
+// is what SRU clients read.
+type SRUAPI struct {
+	bookRepo repositories.BookRepo
+}
+
+type sruDiagnostic struct {
+	XMLName xml.Name `xml:"http://www.loc.gov/zing/srw/diagnostic/ diagnostic"`
+	URI     string   `xml:"uri"`
+	Details string   `xml:"details,omitempty"`
+	Message string   `xml:"message"`
+}
+
+type sruRecord struct {
+	Schema   string        `xml:"recordSchema"`
+	Packing  string        `xml:"recordPacking"`
+	Data     sruRecordData `xml:"recordData"`
+	Position int           `xml:"recordPosition,omitempty"`
+}
+
+type sruRecordData struct {
+	Record any
+}
+
+type sruSearchRetrieveResponse struct {
+	XMLName            xml.Name        `xml:"http://www.loc.gov/zing/srw/ searchRetrieveResponse"`
+	Version            string          `xml:"version"`
+	NumberOfRecords    int64           `xml:"numberOfRecords"`
+	Records            *sruRecords     `xml:"records,omitempty"`
+	NextRecordPosition int             `xml:"nextRecordPosition,omitempty"`
+	Diagnostics        *sruDiagnostics `xml:"diagnostics,omitempty"`
+}
+
+type sruRecords struct {
+	Records []sruRecord `xml:"record"`
+}
+
+type sruDiagnostics struct {
+	Diagnostics []sruDiagnostic
+}
+
+type sruExplainResponse struct {
+	XMLName     xml.Name        `xml:"http://www.loc.gov/zing/srw/ explainResponse"`
+	Version     string          `xml:"version"`
+	Record      *sruRecord      `xml:"record,omitempty"`
+	Diagnostics *sruDiagnostics `xml:"diagnostics,omitempty"`
+}
+
+type dublinCoreRecord struct {
+	XMLName     xml.Name `xml:"info:srw/schema/1/dc-schema dc"`
+	Identifier  []dublinCoreElement
+	Title       dublinCoreElement
+	Creator     dublinCoreElement
+	Publisher   *dublinCoreElement
+	Date        *dublinCoreElement
+	Subject     *dublinCoreElement
+	Description *dublinCoreElement
+	Language    *dublinCoreElement
+	Format      *dublinCoreElement
+}
+
+type dublinCoreElement struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type marcRecord struct {
+	XMLName       xml.Name           `xml:"http://www.loc.gov/MARC21/slim record"`
+	Leader        string             `xml:"leader"`
+	ControlFields []marcControlField `xml:"controlfield"`
+	DataFields    []marcDataField    `xml:"datafield"`
+}
+
+type marcControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcDataField struct {
+	Tag       string         `xml:"tag,attr"`
+	Ind1      string         `xml:"ind1,attr"`
+	Ind2      string         `xml:"ind2,attr"`
+	Subfields []marcSubfield `xml:"subfield"`
+}
+
+type marcSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type zeerexExplain struct {
+	XMLName      xml.Name           `xml:"http://explain.z3950.org/dtd/2.0/ explain"`
+	ServerInfo   zeerexServerInfo   `xml:"serverInfo"`
+	DatabaseInfo zeerexDatabaseInfo `xml:"databaseInfo"`
+	IndexInfo    zeerexIndexInfo    `xml:"indexInfo"`
+	SchemaInfo   []zeerexSchema     `xml:"schemaInfo>schema"`
+	ConfigInfo   zeerexConfigInfo   `xml:"configInfo"`
+}
+
+type zeerexServerInfo struct {
+	Protocol string `xml:"protocol,attr"`
+	Version  string `xml:"version,attr"`
+	Host     string `xml:"host"`
+	Port     string `xml:"port"`
+	Database string `xml:"database"`
+}
+
+type zeerexDatabaseInfo struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+}
+
+type zeerexIndexInfo struct {
+	Sets    []zeerexSet   `xml:"set"`
+	Indexes []zeerexIndex `xml:"index"`
+}
+
+type zeerexSet struct {
+	Name       string `xml:"name,attr"`
+	Identifier string `xml:"identifier,attr"`
+}
+
+type zeerexIndex struct {
+	Title string        `xml:"title"`
+	Map   zeerexMapName `xml:"map>name"`
+}
+
+type zeerexMapName struct {
+	Set  string `xml:"set,attr"`
+	Name string `xml:",chardata"`
+}
+
+type zeerexSchema struct {
+	Name       string `xml:"name,attr"`
+	Identifier string `xml:"identifier,attr"`
+	Title      string `xml:"title"`
+}
+
+type zeerexConfigInfo struct {
+	Default zeerexConfigValue `xml:"default"`
+	Setting zeerexConfigValue `xml:"setting"`
+}
+
+type zeerexConfigValue struct {
+	Type  string `xml:"type,attr"`
+	Value int    `xml:",chardata"`
+}
+
+func NewSRUAPI(bookRepo repositories.BookRepo) *SRUAPI {
+	return &SRUAPI{
+		bookRepo: bookRepo,
+	}
+}
+
+func (api *SRUAPI) Setup(group *echo.Group) {
+	group.GET("", api.handle)
+}
+
+func (api *SRUAPI) handle(c echo.Context) error {
+	if version := c.QueryParam("version"); version != "" && version != "1.1" && version != sruVersion {
+		return api.writeDiagnostic(c, http.StatusOK, sruDiagUnsupportedVersion, sruVersion)
+	}
+	if c.QueryParam("stylesheet") != "" {
+		return api.writeDiagnostic(c, http.StatusOK, sruDiagNoStylesheets, "")
+	}
+	if packing := c.QueryParam("recordPacking"); packing != "" && packing != sruRecordPackingXML {
+		return api.writeDiagnostic(c, http.StatusOK, sruDiagUnsupportedPacking, packing)
+	}
+
+	operation := c.QueryParam("operation")
+	if operation == "" {
+		operation = "explain"
+		if c.QueryParam("query") != "" {
+			operation = "searchRetrieve"
+		}
+	}
+	switch operation {
+	case "explain":
+		return api.explain(c)
+	case "searchRetrieve":
+		return api.searchRetrieve(c)
+	}
+	return api.writeDiagnostic(c, http.StatusOK, sruDiagUnsupportedOp, operation)
+}
+
+func (api *SRUAPI) explain(c echo.Context) error {
+	host, port := c.Request().Host, "80"
+	if c.Scheme() == "https" {
+		port = "443"
+	}
+	if i := strings.LastIndex(host, ":"); i > strings.LastIndex(host, "]") {
+		host, port = host[:i], host[i+1:]
+	}
+	explain := zeerexExplain{
+		ServerInfo: zeerexServerInfo{
+			Protocol: "SRU",
+			Version:  sruVersion,
+			Host:     host,
+			Port:     port,
+			Database: sruDatabase,
+		},
+		DatabaseInfo: zeerexDatabaseInfo{
+			Title:       "Library catalog",
+			Description: "Bibliographic records and holdings of this library's catalog.",
+		},
+		IndexInfo: zeerexIndexInfo{
+			Sets: sruContextSets,
+		},
+		SchemaInfo: sruRecordSchemas,
+		ConfigInfo: zeerexConfigInfo{
+			Default: zeerexConfigValue{
+				Type:  "numberOfRecords",
+				Value: min(sruDefaultRecords, maxPageSize(c)),
+			},
+			Setting: zeerexConfigValue{
+				Type:  "maximumRecords",
+				Value: maxPageSize(c),
+			},
+		},
+	}
+	for _, index := range sruIndexes {
+		explain.IndexInfo.Indexes = append(explain.IndexInfo.Indexes, zeerexIndex{
+			Title: index.title,
+			Map: zeerexMapName{
+				Set:  index.set,
+				Name: index.name,
+			},
+		})
+	}
+	return api.write(c, http.StatusOK, sruExplainResponse{
+		Version: sruVersion,
+		Record: &sruRecord{
+			Schema:  sruExplainSchema,
+			Packing: sruRecordPackingXML,
+			Data: sruRecordData{
+				Record: explain,
+			},
+		},
+	})
+}
+
+func (api *SRUAPI) searchRetrieve(c echo.Context) error {
+	query := c.QueryParam("query")
+	if query == "" {
+		return api.writeDiagnostic(c, http.StatusOK, sruDiagMissingParameter, "query")
+	}
+	start := 1
+	if value := c.QueryParam("startRecord"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return api.writeDiagnostic(c, http.StatusOK, sruDiagUnsupportedValue, "startRecord")
+		}
+		start = n
+	}
+	maxRecords := min(sruDefaultRecords, maxPageSize(c))
+	if value := c.QueryParam("maximumRecords"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxPageSize(c) {
+			return api.writeDiagnostic(c, http.StatusOK, sruDiagUnsupportedValue, "maximumRecords")
+		}
+		maxRecords = n
+	}
+	schema := sruDublinCoreSchema
+	if value := c.QueryParam("recordSchema"); value != "" {
+		schema = ""
+		for _, s := range sruRecordSchemas {
+			if value == s.Name || value == s.Identifier {
+				schema = s.Identifier
+			}
+		}
+		if schema == "" {
+			return api.writeDiagnostic(c, http.StatusOK, sruDiagUnknownSchema, value)
+		}
+	}
+	filter, code, details := sruFilter(query)
+	if code != 0 {
+		return api.writeDiagnostic(c, http.StatusOK, code, details)
+	}
+
+	ctx := c.Request().Context()
+	total, err := api.bookRepo.CountFiltered(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "SRU search failed", "error", err)
+		return api.writeDiagnostic(c, http.StatusInternalServerError, sruDiagGeneral, "")
+	}
+	response := sruSearchRetrieveResponse{
+		Version:         sruVersion,
+		NumberOfRecords: total,
+	}
+	if maxRecords == 0 || total == 0 {
+		return api.write(c, http.StatusOK, response)
+	}
+	if int64(start) > total {
+		return api.writeDiagnostic(c, http.StatusOK, sruDiagStartOutOfRange, strconv.Itoa(start))
+	}
+	books, err := api.bookRepo.GetFiltered(ctx, filter, maxRecords, start-1)
+	if err != nil {
+		slog.ErrorContext(ctx, "SRU search failed", "error", err)
+		return api.writeDiagnostic(c, http.StatusInternalServerError, sruDiagGeneral, "")
+	}
+	response.Records = &sruRecords{}
+	for i, book := range books {
+		record := sruRecord{
+			Schema:   schema,
+			Packing:  sruRecordPackingXML,
+			Position: start + i,
+		}
+		if schema == sruMARCXMLSchema {
+			record.Data.Record = marcRecordFor(book)
+		} else {
+			record.Data.Record = dublinCoreRecordFor(book)
+		}
+		response.Records.Records = append(response.Records.Records, record)
+	}
+	if next := start + len(books); int64(next) <= total {
+		response.NextRecordPosition = next
+	}
+	return api.write(c, http.StatusOK, response)
+}
+
+// sruFilter turns a CQL query into a BookFilter. A non-zero code is the SRU
+// diagnostic to report instead, with details naming the offending part.
+func sruFilter(query string) (repositories.BookFilter, int, string) {
+	var filter repositories.BookFilter
+	clauses, err := cql.Parse(query)
+	if err != nil {
+		if errors.Is(err, cql.ErrUnsupported) {
+			return filter, sruDiagUnsupportedFeature, strings.TrimPrefix(err.Error(), cql.ErrUnsupported.Error()+": ")
+		}
+		return filter, sruDiagQuerySyntax, strings.TrimPrefix(err.Error(), cql.ErrSyntax.Error()+": ")
+	}
+	used := make(map[*string]bool)
+	for _, clause := range clauses {
+		if clause.Index == sruAllRecordsIndex {
+			continue
+		}
+		index, ok := lookupSRUIndex(clause.Index)
+		if !ok {
+			return filter, sruDiagUnsupportedIndex, clause.Index
+		}
+		if clause.Relation != "=" && (!index.exact || (clause.Relation != "==" && clause.Relation != "exact")) {
+			return filter, sruDiagUnsupportedRelation, clause.Relation
+		}
+		field := index.field(&filter)
+		if used[field] {
+			return filter, sruDiagUnsupportedFeature, fmt.Sprintf("index %s used more than once", clause.Index)
+		}
+		used[field] = true
+		term := clause.Term
+		if !index.exact {
+			// Substring indexes already match anywhere in the value, so
+			// leading and trailing truncation is a no-op.
+			term = strings.Trim(term, "*")
+		}
+		*field = term
+	}
+	return filter, 0, ""
+}
+
+// lookupSRUIndex finds an index by its qualified name, or by its bare name
+// as CQL allows when the context set is left out.
+func lookupSRUIndex(name string) (sruIndex, bool) {
+	set, base, qualified := strings.Cut(name, ".")
+	if !qualified {
+		set, base = "", name
+	}
+	for _, index := range sruIndexes {
+		if strings.EqualFold(index.name, base) && (set == "" || index.set == set) {
+			return index, true
+		}
+	}
+	return sruIndex{}, false
+}
+
+func dublinCoreRecordFor(book models.Book) dublinCoreRecord {
+	record := dublinCoreRecord{
+		Identifier: []dublinCoreElement{dublinCoreValue("identifier", "urn:uuid:"+book.ID)},
+		Title:      dublinCoreValue("title", book.Title),
+		Creator:    dublinCoreValue("creator", book.Author),
+	}
+	if book.ISBN != nil {
+		record.Identifier = append(record.Identifier, dublinCoreValue("identifier", "urn:isbn:"+*book.ISBN))
+	}
+	if book.Publisher != nil {
+		record.Publisher = dublinCoreOptional("publisher", *book.Publisher)
+	}
+	if book.PublicationYear != nil {
+		record.Date = dublinCoreOptional("date", strconv.Itoa(*book.PublicationYear))
+	}
+	if book.Genre != nil {
+		record.Subject = dublinCoreOptional("subject", *book.Genre)
+	}
+	if book.Description != nil {
+		record.Description = dublinCoreOptional("description", *book.Description)
+	}
+	if book.Language != "" {
+		record.Language = dublinCoreOptional("language", book.Language)
+	}
+	if book.Pages != nil {
+		record.Format = dublinCoreOptional("format", fmt.Sprintf("%d pages", *book.Pages))
+	}
+	return record
+}
+
+func dublinCoreValue(name, value string) dublinCoreElement {
+	return dublinCoreElement{
+		XMLName: xml.Name{Space: dublinCoreNamespace, Local: name},
+		Value:   value,
+	}
+}
+
+func dublinCoreOptional(name, value string) *dublinCoreElement {
+	element := dublinCoreValue(name, value)
+	return &element
+}
+
+// marcRecordFor builds a minimal MARC 21 bibliographic record. Field 852
+// carries the shelf location and current availability so union catalogs
+// can show holdings.
+func marcRecordFor(book models.Book) marcRecord {
+	record := marcRecord{
+		Leader: sruMARCLeader,
+		ControlFields: []marcControlField{
+			{Tag: "001", Value: book.ID},
+		},
+	}
+	if book.ISBN != nil {
+		record.DataFields = append(record.DataFields, marcField("020", " ", " ", "a", *book.ISBN))
+	}
+	record.DataFields = append(record.DataFields,
+		marcField("100", "1", " ", "a", book.Author),
+		marcField("245", "1", "0", "a", book.Title),
+	)
+	if book.Publisher != nil || book.PublicationYear != nil {
+		publication := marcDataField{Tag: "260", Ind1: " ", Ind2: " "}
+		if book.Publisher != nil {
+			publication.Subfields = append(publication.Subfields, marcSubfield{Code: "b", Value: *book.Publisher})
+		}
+		if book.PublicationYear != nil {
+			publication.Subfields = append(publication.Subfields, marcSubfield{Code: "c", Value: strconv.Itoa(*book.PublicationYear)})
+		}
+		record.DataFields = append(record.DataFields, publication)
+	}
+	if book.Pages != nil {
+		record.DataFields = append(record.DataFields, marcField("300", " ", " ", "a", fmt.Sprintf("%d pages", *book.Pages)))
+	}
+	if book.Description != nil {
+		record.DataFields = append(record.DataFields, marcField("520", " ", " ", "a", *book.Description))
+	}
+	if book.Genre != nil {
+		record.DataFields = append(record.DataFields, marcField("650", " ", "4", "a", *book.Genre))
+	}
+	holdings := marcDataField{Tag: "852", Ind1: " ", Ind2: " "}
+	if book.Location != nil {
+		holdings.Subfields = append(holdings.Subfields, marcSubfield{Code: "c", Value: *book.Location})
+	}
+	holdings.Subfields = append(holdings.Subfields, marcSubfield{
+		Code:  "z",
+		Value: fmt.Sprintf("%d of %d available", book.AvailableQuantity, book.Quantity),
+	})
+	record.DataFields = append(record.DataFields, holdings)
+	return record
+}
+
+func marcField(tag, ind1, ind2, code, value string) marcDataField {
+	return marcDataField{
+		Tag:  tag,
+		Ind1: ind1,
+		Ind2: ind2,
+		Subfields: []marcSubfield{
+			{Code: code, Value: value},
+		},
+	}
+}
+
+func (api *SRUAPI) writeDiagnostic(c echo.Context, status, code int, details string) error {
+	return api.write(c, status, sruSearchRetrieveResponse{
+		Version: sruVersion,
+		Diagnostics: &sruDiagnostics{
+			Diagnostics: []sruDiagnostic{
+				{
+					URI:     sruDiagnosticPrefix + strconv.Itoa(code),
+					Details: details,
+					Message: sruDiagnosticMessages[code],
+				},
+			},
+		},
+	})
+}
+
+func (api *SRUAPI) write(c echo.Context, status int, response any) error {
+	body, err := xml.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Failed to build SRU response",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.Blob(status, sruContentType, append([]byte(xml.Header), body...))
+}
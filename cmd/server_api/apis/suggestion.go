@@ -0,0 +1,447 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var errSuggestionResolved = errors.New("suggestion already resolved")
+
+type SuggestionAPI struct {
+	suggestionRepo *repositories.SuggestionRepository
+	bookRepo       repositories.BookRepo
+	authMw         *auth.Middleware
+}
+
+type CreateSuggestionRequest struct {
+	Title  string  `json:"title" validate:"required"`
+	Author string  `json:"author" validate:"required"`
+	ISBN   *string `json:"isbn,omitempty"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type AcceptSuggestionRequest struct {
+	Language string  `json:"language" validate:"required"`
+	Quantity int     `json:"quantity" validate:"min=0"`
+	Genre    *string `json:"genre,omitempty"`
+	Location *string `json:"location,omitempty"`
+}
+
+type RejectSuggestionRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type MergeSuggestionRequest struct {
+	IntoID string `json:"into_id" validate:"required"`
+}
+
+type SuggestionDetail struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	Title           string     `json:"title"`
+	Author          string     `json:"author"`
+	ISBN            *string    `json:"isbn,omitempty"`
+	Note            *string    `json:"note,omitempty"`
+	Status          string     `json:"status"`
+	RejectionReason *string    `json:"rejection_reason,omitempty"`
+	MergedIntoID    *string    `json:"merged_into_id,omitempty"`
+	MergedCount     int64      `json:"merged_count"`
+	BookID          *string    `json:"book_id,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedDate     time.Time  `json:"created_date"`
+}
+
+type SuggestionListResponse struct {
+	Suggestions []SuggestionDetail `json:"suggestions"`
+	Total       int64              `json:"total"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+}
+
+func NewSuggestionAPI(suggestionRepo *repositories.SuggestionRepository, bookRepo repositories.BookRepo, authMw *auth.Middleware) *SuggestionAPI {
+	return &SuggestionAPI{
+		suggestionRepo: suggestionRepo,
+		bookRepo:       bookRepo,
+		authMw:         authMw,
+	}
+}
+
+func (api *SuggestionAPI) Setup(group *echo.Group) {
+	group.POST("", api.createSuggestion)
+	group.GET("", api.getOwnSuggestions)
+}
+
+func (api *SuggestionAPI) SetupAdmin(group *echo.Group) {
+	group.GET("", api.getSuggestions)
+	group.GET("/:id", api.getSuggestion)
+	group.POST("/:id/accept", api.acceptSuggestion)
+	group.POST("/:id/reject", api.rejectSuggestion)
+	group.POST("/:id/merge", api.mergeSuggestion)
+}
+
+func (api *SuggestionAPI) createSuggestion(c echo.Context) error {
+	var req CreateSuggestionRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Author = strings.TrimSpace(req.Author)
+	if req.Title == "" || req.Author == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Title and author are required",
+			ErrorCode: models.ErrCodeValidation,
+		})
+	}
+	if req.ISBN != nil {
+		isbn := strings.TrimSpace(*req.ISBN)
+		req.ISBN = &isbn
+		if isbn == "" {
+			req.ISBN = nil
+		}
+	}
+	if req.ISBN != nil {
+		owned, err := api.bookRepo.ISBNExists(c.Request().Context(), *req.ISBN)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error checking catalog",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		if owned {
+			return c.JSON(http.StatusConflict, models.Response{
+				Message:   "The library already has a book with this ISBN",
+				ErrorCode: models.ErrCodeISBNExists,
+			})
+		}
+	}
+	suggestion := &models.Suggestion{
+		ID:     uuid.New().String(),
+		UserID: api.authMw.GetUserFromContext(c).UserID,
+		Title:  req.Title,
+		Author: req.Author,
+		ISBN:   req.ISBN,
+		Note:   req.Note,
+		Status: models.SuggestionStatusPending,
+	}
+	message := "Suggestion submitted successfully"
+	duplicate, err := api.suggestionRepo.FindPendingDuplicate(req.Title, req.Author, req.ISBN)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error checking existing suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if duplicate != nil {
+		suggestion.Status = models.SuggestionStatusMerged
+		suggestion.MergedIntoID = &duplicate.ID
+		message = "Suggestion merged with an existing request"
+	}
+	if err := api.suggestionRepo.Create(suggestion); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating suggestion",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toSuggestionDetail(suggestion, 0, userLocation(c)),
+		Message: message,
+	})
+}
+
+func (api *SuggestionAPI) getOwnSuggestions(c echo.Context) error {
+	suggestions, err := api.suggestionRepo.GetByUserID(api.authMw.GetUserFromContext(c).UserID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	suggestionDetails := make([]SuggestionDetail, len(suggestions))
+	for i := range suggestions {
+		suggestionDetails[i] = toSuggestionDetail(&suggestions[i], 0, userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    suggestionDetails,
+		Message: "Suggestions retrieved successfully",
+	})
+}
+
+func (api *SuggestionAPI) getSuggestions(c echo.Context) error {
+	status := c.QueryParam("status")
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	suggestions, err := api.suggestionRepo.GetAll(status, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.suggestionRepo.Count(status)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	ids := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		ids[i] = suggestion.ID
+	}
+	mergedCounts, err := api.suggestionRepo.CountMerged(ids)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting merged suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	suggestionDetails := make([]SuggestionDetail, len(suggestions))
+	for i := range suggestions {
+		suggestionDetails[i] = toSuggestionDetail(&suggestions[i], mergedCounts[suggestions[i].ID], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: SuggestionListResponse{
+			Suggestions: suggestionDetails,
+			Total:       total,
+			Limit:       limit,
+			Offset:      offset,
+		},
+		Message: "Suggestions retrieved successfully",
+	})
+}
+
+func (api *SuggestionAPI) getSuggestion(c echo.Context) error {
+	suggestion, err := api.suggestionRepo.GetByID(c.Param("id"))
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	mergedCounts, err := api.suggestionRepo.CountMerged([]string{suggestion.ID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting merged suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toSuggestionDetail(suggestion, mergedCounts[suggestion.ID], userLocation(c)),
+		Message: "Suggestion retrieved successfully",
+	})
+}
+
+func (api *SuggestionAPI) acceptSuggestion(c echo.Context) error {
+	var req AcceptSuggestionRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.Language == "" || req.Quantity < 0 {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Language is required and quantity cannot be negative",
+			ErrorCode: models.ErrCodeValidation,
+		})
+	}
+	suggestion, err := api.pendingSuggestion(c.Param("id"))
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	book := &models.Book{
+		ID:                uuid.New().String(),
+		Title:             suggestion.Title,
+		Author:            suggestion.Author,
+		ISBN:              suggestion.ISBN,
+		Genre:             req.Genre,
+		Language:          req.Language,
+		Quantity:          req.Quantity,
+		AvailableQuantity: 0,
+		Location:          req.Location,
+		Status:            models.BookStatusOnOrder,
+		DigitalLoanLimit:  models.DefaultDigitalLoanLimit,
+		LoanPolicy:        models.BookLoanPolicyStandard,
+	}
+	mergedCount, err := api.mergedCount(suggestion.ID)
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	before := toSuggestionDetail(suggestion, mergedCount, time.UTC)
+	reviewerID := api.authMw.GetUserFromContext(c).UserID
+	err = api.suggestionRepo.Accept(suggestion, book, reviewerID)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "A book with this ISBN already exists",
+			ErrorCode: models.ErrCodeISBNExists,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error accepting suggestion",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "suggestion", suggestion.ID, before, toSuggestionDetail(suggestion, mergedCount, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    book,
+		Message: "Suggestion accepted and book placed on order",
+	})
+}
+
+func (api *SuggestionAPI) rejectSuggestion(c echo.Context) error {
+	var req RejectSuggestionRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Rejection reason is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "reason",
+					Message: "Rejection reason is required",
+				},
+			},
+		})
+	}
+	suggestion, err := api.pendingSuggestion(c.Param("id"))
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	mergedCount, err := api.mergedCount(suggestion.ID)
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	before := toSuggestionDetail(suggestion, mergedCount, time.UTC)
+	reviewerID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.suggestionRepo.Reject(suggestion, req.Reason, reviewerID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error rejecting suggestion",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "suggestion", suggestion.ID, before, toSuggestionDetail(suggestion, mergedCount, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Suggestion rejected",
+	})
+}
+
+func (api *SuggestionAPI) mergeSuggestion(c echo.Context) error {
+	var req MergeSuggestionRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.IntoID == "" || req.IntoID == c.Param("id") {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "into_id must reference a different suggestion",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "into_id",
+					Message: "into_id must reference a different suggestion",
+				},
+			},
+		})
+	}
+	duplicate, err := api.pendingSuggestion(c.Param("id"))
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	target, err := api.pendingSuggestion(req.IntoID)
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	mergedCount, err := api.mergedCount(duplicate.ID)
+	if err != nil {
+		return suggestionLookupError(c, err)
+	}
+	before := toSuggestionDetail(duplicate, mergedCount, time.UTC)
+	reviewerID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.suggestionRepo.Merge(duplicate, target, reviewerID); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error merging suggestions",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	// Suggestions merged into the duplicate now point at the target.
+	auditRecord(c, "suggestion", duplicate.ID, before, toSuggestionDetail(duplicate, 0, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Message: "Suggestions merged successfully",
+	})
+}
+
+func (api *SuggestionAPI) pendingSuggestion(id string) (*models.Suggestion, error) {
+	suggestion, err := api.suggestionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != models.SuggestionStatusPending {
+		return nil, errSuggestionResolved
+	}
+	return suggestion, nil
+}
+
+func (api *SuggestionAPI) mergedCount(id string) (int64, error) {
+	counts, err := api.suggestionRepo.CountMerged([]string{id})
+	if err != nil {
+		return 0, err
+	}
+	return counts[id], nil
+}
+
+func suggestionLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Suggestion not found",
+			ErrorCode: models.ErrCodeSuggestionNotFound,
+		})
+	}
+	if err == errSuggestionResolved {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Suggestion has already been resolved",
+			ErrorCode: models.ErrCodeSuggestionResolved,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving suggestion",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toSuggestionDetail(suggestion *models.Suggestion, mergedCount int64, location *time.Location) SuggestionDetail {
+	return SuggestionDetail{
+		ID:              suggestion.ID,
+		UserID:          suggestion.UserID,
+		Title:           suggestion.Title,
+		Author:          suggestion.Author,
+		ISBN:            suggestion.ISBN,
+		Note:            suggestion.Note,
+		Status:          suggestion.Status,
+		RejectionReason: suggestion.RejectionReason,
+		MergedIntoID:    suggestion.MergedIntoID,
+		MergedCount:     mergedCount,
+		BookID:          suggestion.BookID,
+		ReviewedAt:      timeIn(suggestion.ReviewedAt, location),
+		CreatedDate:     suggestion.CreatedDate.In(location),
+	}
+}
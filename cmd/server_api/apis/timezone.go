@@ -0,0 +1,70 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"log/slog"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const locationContextKey = "timezone.location"
+
+// callerLocation looks up the caller's time zone the first time a handler
+// renders a timestamp, so requests that never do cost no extra query.
+type callerLocation struct {
+	resolve  func() *time.Location
+	location *time.Location
+}
+
+// UserTimezone lets handlers render timestamps in the time zone of the user
+// holding the bearer token. Anonymous callers and public endpoints get UTC.
+func UserTimezone(userRepo repositories.UserRepo, authMw *auth.Middleware) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(locationContextKey, &callerLocation{
+				resolve: func() *time.Location {
+					userID := authMw.UserID(c)
+					if userID == "" {
+						return time.UTC
+					}
+					user, err := userRepo.GetByID(c.Request().Context(), userID)
+					if err != nil {
+						if err != gorm.ErrRecordNotFound {
+							slog.ErrorContext(c.Request().Context(), "Failed to resolve user time zone",
+								"user_id", userID,
+								"error", err,
+							)
+						}
+						return time.UTC
+					}
+					return user.Location()
+				},
+			})
+			return next(c)
+		}
+	}
+}
+
+// userLocation returns the time zone responses to this request are rendered
+// in. Storage stays UTC; only the JSON representation changes.
+func userLocation(c echo.Context) *time.Location {
+	caller, ok := c.Get(locationContextKey).(*callerLocation)
+	if !ok {
+		return time.UTC
+	}
+	if caller.location == nil {
+		caller.location = caller.resolve()
+	}
+	return caller.location
+}
+
+func timeIn(t *time.Time, location *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := t.In(location)
+	return &local
+}
@@ -0,0 +1,348 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/auth"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+var errTransferStatus = errors.New("transfer is not in the required status")
+
+type TransferAPI struct {
+	transferRepo *repositories.TransferRepository
+	bookRepo     repositories.BookRepo
+	branches     *BranchAccess
+	authMw       *auth.Middleware
+}
+
+type CreateTransferRequest struct {
+	BookID       string  `json:"book_id" validate:"required"`
+	FromBranchID string  `json:"from_branch_id" validate:"required"`
+	ToBranchID   string  `json:"to_branch_id" validate:"required"`
+	Quantity     int     `json:"quantity" validate:"min=1"`
+	Note         *string `json:"note,omitempty"`
+}
+
+type TransferDetail struct {
+	ID           string     `json:"id"`
+	BookID       string     `json:"book_id"`
+	FromBranchID string     `json:"from_branch_id"`
+	ToBranchID   string     `json:"to_branch_id"`
+	Quantity     int        `json:"quantity"`
+	Status       string     `json:"status"`
+	Note         *string    `json:"note,omitempty"`
+	RequestedBy  string     `json:"requested_by"`
+	ShippedAt    *time.Time `json:"shipped_at,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CreatedDate  time.Time  `json:"created_date"`
+	UpdatedDate  time.Time  `json:"updated_date"`
+}
+
+type TransferListResponse struct {
+	Transfers []TransferDetail `json:"transfers"`
+	Total     int64            `json:"total"`
+	Limit     int              `json:"limit"`
+	Offset    int              `json:"offset"`
+}
+
+func NewTransferAPI(transferRepo *repositories.TransferRepository, bookRepo repositories.BookRepo, branches *BranchAccess, authMw *auth.Middleware) *TransferAPI {
+	return &TransferAPI{
+		transferRepo: transferRepo,
+		bookRepo:     bookRepo,
+		branches:     branches,
+		authMw:       authMw,
+	}
+}
+
+// Setup registers the transfer workflow. Branch staff only see transfers
+// involving their branch; only the source ships and only the destination
+// receives.
+func (api *TransferAPI) Setup(group *echo.Group) {
+	group.POST("", api.createTransfer)
+	group.GET("", api.getTransfers)
+	group.GET("/:id", api.getTransfer)
+	group.POST("/:id/ship", api.shipTransfer)
+	group.POST("/:id/receive", api.receiveTransfer)
+	group.POST("/:id/cancel", api.cancelTransfer)
+}
+
+func (api *TransferAPI) createTransfer(c echo.Context) error {
+	var req CreateTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	if req.FromBranchID == req.ToBranchID {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "to_branch_id must differ from from_branch_id",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "to_branch_id",
+					Message: "to_branch_id must differ from from_branch_id",
+				},
+			},
+		})
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	if staffBranchID != "" && staffBranchID != req.FromBranchID && staffBranchID != req.ToBranchID {
+		return branchLookupError(c, errBranchAccessDenied)
+	}
+	if _, err := api.bookRepo.GetByID(c.Request().Context(), req.BookID); err != nil {
+		return bookLookupError(c, err)
+	}
+	if _, err := api.branches.VisibleBranch(c, req.FromBranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	if _, err := api.branches.VisibleBranch(c, req.ToBranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	if err := api.checkAvailable(c, req.BookID, req.FromBranchID, req.Quantity); err != nil {
+		return transferLookupError(c, err)
+	}
+	transfer := &models.BookTransfer{
+		ID:           uuid.New().String(),
+		BookID:       req.BookID,
+		FromBranchID: req.FromBranchID,
+		ToBranchID:   req.ToBranchID,
+		Quantity:     req.Quantity,
+		Status:       models.TransferStatusRequested,
+		Note:         req.Note,
+		RequestedBy:  api.authMw.GetUserFromContext(c).UserID,
+	}
+	if err := api.transferRepo.Create(c.Request().Context(), transfer); err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating transfer",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "book_transfer", transfer.ID, nil, toTransferDetail(transfer, time.UTC))
+	return c.JSON(http.StatusCreated, models.Response{
+		Data:    toTransferDetail(transfer, userLocation(c)),
+		Message: "Transfer requested successfully",
+	})
+}
+
+func (api *TransferAPI) getTransfers(c echo.Context) error {
+	status := c.QueryParam("status")
+	bookID := c.QueryParam("book_id")
+	branchID := c.QueryParam("branch_id")
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	if staffBranchID != "" {
+		if branchID != "" && branchID != staffBranchID {
+			return branchLookupError(c, errBranchAccessDenied)
+		}
+		branchID = staffBranchID
+	}
+	transfers, err := api.transferRepo.GetAll(c.Request().Context(), status, bookID, branchID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving transfers",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := api.transferRepo.Count(c.Request().Context(), status, bookID, branchID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting transfers",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	transferDetails := make([]TransferDetail, len(transfers))
+	for i := range transfers {
+		transferDetails[i] = toTransferDetail(&transfers[i], userLocation(c))
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data: TransferListResponse{
+			Transfers: transferDetails,
+			Total:     total,
+			Limit:     limit,
+			Offset:    offset,
+		},
+		Message: "Transfers retrieved successfully",
+	})
+}
+
+func (api *TransferAPI) getTransfer(c echo.Context) error {
+	transfer, err := api.visibleTransfer(c, c.Param("id"))
+	if err != nil {
+		return transferLookupError(c, err)
+	}
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toTransferDetail(transfer, userLocation(c)),
+		Message: "Transfer retrieved successfully",
+	})
+}
+
+func (api *TransferAPI) shipTransfer(c echo.Context) error {
+	transfer, err := api.visibleTransfer(c, c.Param("id"))
+	if err != nil {
+		return transferLookupError(c, err)
+	}
+	if err := api.requireBranch(c, transfer.FromBranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	if transfer.Status != models.TransferStatusRequested {
+		return transferLookupError(c, errTransferStatus)
+	}
+	before := toTransferDetail(transfer, time.UTC)
+	userID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.transferRepo.Ship(c.Request().Context(), transfer, userID); err != nil {
+		return transferLookupError(c, err)
+	}
+	auditRecord(c, "book_transfer", transfer.ID, before, toTransferDetail(transfer, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toTransferDetail(transfer, userLocation(c)),
+		Message: "Transfer shipped",
+	})
+}
+
+func (api *TransferAPI) receiveTransfer(c echo.Context) error {
+	transfer, err := api.visibleTransfer(c, c.Param("id"))
+	if err != nil {
+		return transferLookupError(c, err)
+	}
+	if err := api.requireBranch(c, transfer.ToBranchID); err != nil {
+		return branchLookupError(c, err)
+	}
+	if transfer.Status != models.TransferStatusInTransit {
+		return transferLookupError(c, errTransferStatus)
+	}
+	before := toTransferDetail(transfer, time.UTC)
+	userID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.transferRepo.Receive(c.Request().Context(), transfer, userID); err != nil {
+		return transferLookupError(c, err)
+	}
+	auditRecord(c, "book_transfer", transfer.ID, before, toTransferDetail(transfer, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toTransferDetail(transfer, userLocation(c)),
+		Message: "Transfer received",
+	})
+}
+
+func (api *TransferAPI) cancelTransfer(c echo.Context) error {
+	transfer, err := api.visibleTransfer(c, c.Param("id"))
+	if err != nil {
+		return transferLookupError(c, err)
+	}
+	if transfer.Status != models.TransferStatusRequested {
+		return transferLookupError(c, errTransferStatus)
+	}
+	before := toTransferDetail(transfer, time.UTC)
+	userID := api.authMw.GetUserFromContext(c).UserID
+	if err := api.transferRepo.Cancel(c.Request().Context(), transfer, userID); err != nil {
+		return transferLookupError(c, err)
+	}
+	auditRecord(c, "book_transfer", transfer.ID, before, toTransferDetail(transfer, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toTransferDetail(transfer, userLocation(c)),
+		Message: "Transfer cancelled",
+	})
+}
+
+// visibleTransfer loads a transfer, hiding transfers between other branches
+// from branch staff.
+func (api *TransferAPI) visibleTransfer(c echo.Context, id string) (*models.BookTransfer, error) {
+	transfer, err := api.transferRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" && staffBranchID != transfer.FromBranchID && staffBranchID != transfer.ToBranchID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return transfer, nil
+}
+
+func (api *TransferAPI) requireBranch(c echo.Context, branchID string) error {
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return err
+	}
+	if staffBranchID != "" && staffBranchID != branchID {
+		return errBranchAccessDenied
+	}
+	return nil
+}
+
+// checkAvailable rejects requests the source branch cannot currently cover.
+// Copies are only reserved when the transfer ships, so this is re-checked
+// then.
+func (api *TransferAPI) checkAvailable(c echo.Context, bookID, branchID string, quantity int) error {
+	holdings, err := api.bookRepo.GetHoldings(c.Request().Context(), bookID)
+	if err != nil {
+		return err
+	}
+	for _, holding := range holdings {
+		if holding.BranchID == branchID && holding.AvailableQuantity >= quantity {
+			return nil
+		}
+	}
+	return repositories.ErrInsufficientCopies
+}
+
+func transferLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "Transfer not found",
+			ErrorCode: models.ErrCodeTransferNotFound,
+		})
+	}
+	if err == errTransferStatus || err == repositories.ErrTransferStateChanged {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Transfer is not in a status that allows this action",
+			ErrorCode: models.ErrCodeTransferInvalidStatus,
+		})
+	}
+	if err == repositories.ErrInsufficientCopies {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Source branch does not have enough available copies",
+			ErrorCode: models.ErrCodeInsufficientCopies,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error processing transfer",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toTransferDetail(transfer *models.BookTransfer, location *time.Location) TransferDetail {
+	return TransferDetail{
+		ID:           transfer.ID,
+		BookID:       transfer.BookID,
+		FromBranchID: transfer.FromBranchID,
+		ToBranchID:   transfer.ToBranchID,
+		Quantity:     transfer.Quantity,
+		Status:       transfer.Status,
+		Note:         transfer.Note,
+		RequestedBy:  transfer.RequestedBy,
+		ShippedAt:    timeIn(transfer.ShippedAt, location),
+		ReceivedAt:   timeIn(transfer.ReceivedAt, location),
+		CancelledAt:  timeIn(transfer.CancelledAt, location),
+		CreatedDate:  transfer.CreatedDate.In(location),
+		UpdatedDate:  transfer.UpdatedDate.In(location),
+	}
+}
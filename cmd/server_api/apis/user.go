@@ -1,36 +1,62 @@
 package apis
 
 import (
+	"book-management-system/cmd/server_api/librarycard"
 	"book-management-system/cmd/server_api/models"
 	"book-management-system/cmd/server_api/repositories"
 	"book-management-system/pkg/auth"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultMembershipPlanCode = "basic"
+	loginHistoryLimit         = 10
+	defaultInactiveDays       = 180
+)
+
 type UserAPI struct {
-	userRepo *repositories.UserRepository
-	authMw   *auth.Middleware
+	userRepo  repositories.UserRepo
+	planRepo  *repositories.MembershipPlanRepository
+	noteRepo  *repositories.UserNoteRepository
+	loginRepo *repositories.LoginEventRepository
+	authMw    *auth.Middleware
+	branches  *BranchAccess
 }
 
 type CreateUserRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=8"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-	Role      string `json:"role" validate:"required,oneof=admin member"`
+	Email            string `json:"email" validate:"required,email"`
+	Password         string `json:"password" validate:"required,min=8"`
+	FirstName        string `json:"first_name" validate:"required"`
+	LastName         string `json:"last_name" validate:"required"`
+	Role             string `json:"role" validate:"required,oneof=admin member"`
+	MembershipPlanID string `json:"membership_plan_id,omitempty"`
+	BranchID         string `json:"branch_id,omitempty"`
+	Timezone         string `json:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
 type UpdateUserRequest struct {
-	FirstName *string `json:"first_name,omitempty"`
-	LastName  *string `json:"last_name,omitempty"`
-	Role      *string `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
-	Status    *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	FirstName        *string `json:"first_name,omitempty"`
+	LastName         *string `json:"last_name,omitempty"`
+	Role             *string `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+	Status           *string `json:"status,omitempty" validate:"omitempty,oneof=active inactive"`
+	MembershipPlanID *string `json:"membership_plan_id,omitempty"`
+	BranchID         *string `json:"branch_id,omitempty"`
+	Timezone         *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	// Version, when given, must match the user's current version.
+	Version *int `json:"version,omitempty"`
+}
+
+type CreateUserNoteRequest struct {
+	Body string `json:"body" validate:"required"`
 }
 
 type UserListResponse struct {
@@ -41,129 +67,168 @@ type UserListResponse struct {
 }
 
 type UserDetail struct {
+	ID                string             `json:"id"`
+	Email             string             `json:"email"`
+	FirstName         string             `json:"first_name"`
+	LastName          string             `json:"last_name"`
+	Role              string             `json:"role"`
+	Status            string             `json:"status"`
+	MembershipPlanID  string             `json:"membership_plan_id"`
+	BranchID          *string            `json:"branch_id"`
+	CardNumber        string             `json:"card_number"`
+	Timezone          string             `json:"timezone"`
+	LastLoginAt       *time.Time         `json:"last_login_at"`
+	FlaggedInactiveAt *time.Time         `json:"flagged_inactive_at"`
+	Version           int                `json:"version"`
+	Notes             []UserNoteDetail   `json:"notes,omitempty"`
+	LoginHistory      []LoginEventDetail `json:"login_history,omitempty"`
+	CreatedDate       time.Time          `json:"created_date"`
+	UpdatedDate       time.Time          `json:"updated_date"`
+}
+
+type LoginEventDetail struct {
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+type InactiveUserListResponse struct {
+	Users         []UserDetail `json:"users"`
+	InactiveSince time.Time    `json:"inactive_since"`
+	Total         int64        `json:"total"`
+	Limit         int          `json:"limit"`
+	Offset        int          `json:"offset"`
+}
+
+type UserNoteDetail struct {
 	ID          string    `json:"id"`
-	Email       string    `json:"email"`
-	FirstName   string    `json:"first_name"`
-	LastName    string    `json:"last_name"`
-	Role        string    `json:"role"`
-	Status      string    `json:"status"`
+	AuthorID    string    `json:"author_id"`
+	Body        string    `json:"body"`
 	CreatedDate time.Time `json:"created_date"`
-	UpdatedDate time.Time `json:"updated_date"`
 }
 
-func NewUserAPI(userRepo *repositories.UserRepository, authMw *auth.Middleware) *UserAPI {
+func NewUserAPI(userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository, noteRepo *repositories.UserNoteRepository, loginRepo *repositories.LoginEventRepository, authMw *auth.Middleware, branches *BranchAccess) *UserAPI {
 	return &UserAPI{
-		userRepo: userRepo,
-		authMw:   authMw,
+		userRepo:  userRepo,
+		planRepo:  planRepo,
+		noteRepo:  noteRepo,
+		loginRepo: loginRepo,
+		authMw:    authMw,
+		branches:  branches,
 	}
 }
 
 func (api *UserAPI) Setup(group *echo.Group) {
-	group.POST("", api.createUser, api.authMw.RequireAdmin())
-	group.GET("", api.getUsers, api.authMw.RequireAdmin())
-	group.GET("/:id", api.getUserByID, api.authMw.RequireAdmin())
-	group.PUT("/:id", api.updateUser, api.authMw.RequireAdmin())
-	group.DELETE("/:id", api.deleteUser, api.authMw.RequireAdmin())
+	group.POST("", api.createUser, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("", api.getUsers, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("/search", api.searchUsers, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("/inactive", api.getInactiveUsers, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("/by-card/:number", api.getUserByCardNumber, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("/:id", api.getUserByID, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.PUT("/:id", api.updateUser, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.PATCH("/:id", api.patchUser, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.DELETE("/:id", api.deleteUser, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.GET("/:id/notes", api.getUserNotes, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.POST("/:id/notes", api.createUserNote, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
+	group.DELETE("/:id/notes/:noteId", api.deleteUserNote, api.authMw.RequireAuth(), api.authMw.RequireAdmin())
 }
 
 func (api *UserAPI) createUser(c echo.Context) error {
 	var req CreateUserRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request format",
-		})
+		return bindError(c, err)
 	}
-	exists, err := api.userRepo.EmailExists(req.Email)
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Email = normalizeEmail(req.Email)
+	plan, err := api.resolveMembershipPlan(req.MembershipPlanID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error checking email availability",
-		})
+		return membershipPlanFieldError(c, err)
 	}
-	if exists {
-		return c.JSON(http.StatusConflict, models.Response{
-			Message: "Email already exists",
-		})
+	branchID, err := api.assignBranch(c, req.BranchID)
+	if err != nil {
+		return branchFieldError(c, err)
 	}
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error processing password",
+			Message:   "Error processing password",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	cardNumber, err := librarycard.Issue(c.Request().Context(), api.userRepo.CardNumberExists)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error issuing library card number",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 	user := &models.User{
-		ID:           generateID(),
-		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		Role:         req.Role,
-		Status:       "active",
+		ID:                    uuid.NewString(),
+		Email:                 req.Email,
+		PasswordHash:          string(hashedPassword),
+		FirstName:             req.FirstName,
+		LastName:              req.LastName,
+		Role:                  req.Role,
+		Status:                "active",
+		MembershipPlanID:      plan.ID,
+		BranchID:              branchID,
+		CardNumber:            cardNumber,
+		Timezone:              req.Timezone,
+		ReadingHistoryEnabled: true,
+	}
+	err = api.userRepo.Create(c.Request().Context(), user)
+	if errors.Is(err, repositories.ErrEmailExists) {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Email already exists",
+			ErrorCode: models.ErrCodeEmailExists,
+		})
 	}
-	err = api.userRepo.Create(user)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error creating user",
+			Message:   "Error creating user",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	auditRecord(c, "user", user.ID, nil, toUserDetail(user, time.UTC))
 	response := models.Response{
-		Data: UserDetail{
-			ID:          user.ID,
-			Email:       user.Email,
-			FirstName:   user.FirstName,
-			LastName:    user.LastName,
-			Role:        user.Role,
-			Status:      user.Status,
-			CreatedDate: user.CreatedDate,
-			UpdatedDate: user.UpdatedDate,
-		},
+		Data:    toUserDetail(user, userLocation(c)),
 		Message: "User created successfully",
 	}
 	return c.JSON(http.StatusCreated, response)
 }
 
 func (api *UserAPI) getUsers(c echo.Context) error {
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	if limit <= 0 {
-		limit = 20
-	}
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
-	if offset < 0 {
-		offset = 0
-	}
-	role := c.QueryParam("role")
-	status := c.QueryParam("status")
-	var users []models.User
-	var err error
-	if role != "" {
-		users, err = api.userRepo.GetByRole(role, limit, offset)
-	} else if status != "" {
-		users, err = api.userRepo.GetByStatus(status, limit, offset)
-	} else {
-		users, err = api.userRepo.GetAll(limit, offset)
+	userRepo, err := api.branches.ScopedUsers(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	filter := repositories.UserFilter{
+		Role:   c.QueryParam("role"),
+		Status: c.QueryParam("status"),
 	}
+	users, err := userRepo.GetFiltered(c.Request().Context(), filter, limit, offset)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error retrieving users",
+			Message:   "Error retrieving users",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
-	total, err := api.userRepo.Count()
+	total, err := userRepo.CountFiltered(c.Request().Context(), filter)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error counting users",
+			Message:   "Error counting users",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
 	userDetails := make([]UserDetail, len(users))
-	for i, user := range users {
-		userDetails[i] = UserDetail{
-			ID:          user.ID,
-			Email:       user.Email,
-			FirstName:   user.FirstName,
-			LastName:    user.LastName,
-			Role:        user.Role,
-			Status:      user.Status,
-			CreatedDate: user.CreatedDate,
-			UpdatedDate: user.UpdatedDate,
-		}
+	for i := range users {
+		userDetails[i] = toUserDetail(&users[i], userLocation(c))
 	}
 	response := models.Response{
 		Data: UserListResponse{
@@ -177,54 +242,244 @@ func (api *UserAPI) getUsers(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+func (api *UserAPI) searchUsers(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Search query (q) is required",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "q",
+					Message: "Search query (q) is required",
+				},
+			},
+		})
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	users, err := userRepo.SearchUsers(c.Request().Context(), query, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error searching users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := userRepo.CountSearch(c.Request().Context(), query)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	userDetails := make([]UserDetail, len(users))
+	for i := range users {
+		userDetails[i] = toUserDetail(&users[i], userLocation(c))
+	}
+	response := models.Response{
+		Data: UserListResponse{
+			Users:  userDetails,
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+		Message: "Users search completed successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
 func (api *UserAPI) getUserByID(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
 	id := c.Param("id")
-	user, err := api.userRepo.GetByID(id)
+	user, err := userRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.JSON(http.StatusNotFound, models.Response{
-				Message: "User not found",
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
 			})
 		}
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error retrieving user",
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	notes, err := api.noteRepo.GetByUserID(user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user notes",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	logins, err := api.loginRepo.GetByUserID(user.ID, loginHistoryLimit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving login history",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	userDetail := toUserDetail(user, userLocation(c))
+	userDetail.Notes = toUserNoteDetails(notes, userLocation(c))
+	userDetail.LoginHistory = toLoginEventDetails(logins, userLocation(c))
 	response := models.Response{
-		Data: UserDetail{
-			ID:          user.ID,
-			Email:       user.Email,
-			FirstName:   user.FirstName,
-			LastName:    user.LastName,
-			Role:        user.Role,
-			Status:      user.Status,
-			CreatedDate: user.CreatedDate,
-			UpdatedDate: user.UpdatedDate,
+		Data:    userDetail,
+		Message: "User retrieved successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *UserAPI) getInactiveUsers(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, c.QueryParam("branch_id"))
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	days := defaultInactiveDays
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 {
+			return c.JSON(http.StatusBadRequest, models.Response{
+				Message:   "Days must be a positive integer",
+				ErrorCode: models.ErrCodeValidation,
+				Errors: []models.FieldError{
+					{
+						Field:   "days",
+						Message: "Days must be a positive integer",
+					},
+				},
+			})
+		}
+		days = d
+	}
+	limit, offset, ok := pageParams(c, defaultPageSize)
+	if !ok {
+		return pageSizeError(c)
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	users, err := userRepo.GetInactiveSince(c.Request().Context(), cutoff, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving inactive users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	total, err := userRepo.CountInactiveSince(c.Request().Context(), cutoff)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error counting inactive users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	userDetails := make([]UserDetail, len(users))
+	for i := range users {
+		userDetails[i] = toUserDetail(&users[i], userLocation(c))
+	}
+	response := models.Response{
+		Data: InactiveUserListResponse{
+			Users:         userDetails,
+			InactiveSince: cutoff.In(userLocation(c)),
+			Total:         total,
+			Limit:         limit,
+			Offset:        offset,
 		},
+		Message: "Inactive users retrieved successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *UserAPI) getUserByCardNumber(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	number := c.Param("number")
+	if !librarycard.Valid(number) {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Invalid library card number",
+			ErrorCode: models.ErrCodeValidation,
+			Errors: []models.FieldError{
+				{
+					Field:   "number",
+					Message: "Invalid library card number",
+				},
+			},
+		})
+	}
+	user, err := userRepo.GetByCardNumber(c.Request().Context(), number)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	notes, err := api.noteRepo.GetByUserID(user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user notes",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	logins, err := api.loginRepo.GetByUserID(user.ID, loginHistoryLimit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving login history",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	userDetail := toUserDetail(user, userLocation(c))
+	userDetail.Notes = toUserNoteDetails(notes, userLocation(c))
+	userDetail.LoginHistory = toLoginEventDetails(logins, userLocation(c))
+	response := models.Response{
+		Data:    userDetail,
 		Message: "User retrieved successfully",
 	}
 	return c.JSON(http.StatusOK, response)
 }
 
 func (api *UserAPI) updateUser(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
 	id := c.Param("id")
 	var req UpdateUserRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, models.Response{
-			Message: "Invalid request format",
-		})
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
 	}
-	user, err := api.userRepo.GetByID(id)
+	user, err := userRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.JSON(http.StatusNotFound, models.Response{
-				Message: "User not found",
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
 			})
 		}
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error retrieving user",
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	if req.Version != nil && *req.Version != user.Version {
+		return userVersionConflict(c)
+	}
+	before := toUserDetail(user, time.UTC)
 	if req.FirstName != nil {
 		user.FirstName = *req.FirstName
 	}
@@ -237,49 +492,407 @@ func (api *UserAPI) updateUser(c echo.Context) error {
 	if req.Status != nil {
 		user.Status = *req.Status
 	}
-	err = api.userRepo.Update(user)
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+	if req.MembershipPlanID != nil {
+		plan, err := api.planRepo.GetByID(*req.MembershipPlanID)
+		if err != nil {
+			return membershipPlanFieldError(c, err)
+		}
+		user.MembershipPlanID = plan.ID
+	}
+	if req.BranchID != nil {
+		branchID, err := api.assignBranch(c, *req.BranchID)
+		if err != nil {
+			return branchFieldError(c, err)
+		}
+		user.BranchID = branchID
+	}
+	return api.saveUser(c, before, user)
+}
+
+// userDocument is the editable part of a user, which PATCH /users/:id merges
+// the request into.
+type userDocument struct {
+	FirstName        string  `json:"first_name" validate:"required"`
+	LastName         string  `json:"last_name" validate:"required"`
+	Role             string  `json:"role" validate:"required,oneof=admin member"`
+	Status           string  `json:"status" validate:"required,oneof=active inactive"`
+	MembershipPlanID string  `json:"membership_plan_id" validate:"required"`
+	BranchID         *string `json:"branch_id"`
+	Timezone         string  `json:"timezone" validate:"omitempty,timezone"`
+	// Version is never part of the stored document; a patch that sets it
+	// must match the user's current version.
+	Version *int `json:"version,omitempty"`
+}
+
+// patchUser applies a JSON Merge Patch. Unlike updateUser it can clear the
+// branch and time zone by patching them to null.
+func (api *UserAPI) patchUser(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	user, err := userRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	current := userDocument{
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		Status:           user.Status,
+		MembershipPlanID: user.MembershipPlanID,
+		BranchID:         user.BranchID,
+		Timezone:         user.Timezone,
+	}
+	var doc userDocument
+	if err := bindMergePatch(c, current, &doc); err != nil {
+		if errors.Is(err, echo.ErrUnsupportedMediaType) {
+			return err
+		}
+		return bindError(c, err)
+	}
+	if err := c.Validate(&doc); err != nil {
+		return validationError(c, err)
+	}
+	if doc.Version != nil && *doc.Version != user.Version {
+		return userVersionConflict(c)
+	}
+	before := toUserDetail(user, time.UTC)
+	user.FirstName = doc.FirstName
+	user.LastName = doc.LastName
+	user.Role = doc.Role
+	user.Status = doc.Status
+	user.Timezone = doc.Timezone
+	if doc.MembershipPlanID != user.MembershipPlanID {
+		plan, err := api.planRepo.GetByID(doc.MembershipPlanID)
+		if err != nil {
+			return membershipPlanFieldError(c, err)
+		}
+		user.MembershipPlanID = plan.ID
+	}
+	if !equalStringPtr(doc.BranchID, user.BranchID) {
+		branchID := ""
+		if doc.BranchID != nil {
+			branchID = *doc.BranchID
+		}
+		assigned, err := api.assignBranch(c, branchID)
+		if err != nil {
+			return branchFieldError(c, err)
+		}
+		user.BranchID = assigned
+	}
+	return api.saveUser(c, before, user)
+}
+
+func (api *UserAPI) saveUser(c echo.Context, before UserDetail, user *models.User) error {
+	err := api.userRepo.Update(c.Request().Context(), user)
+	if err == repositories.ErrVersionConflict {
+		return userVersionConflict(c)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error updating user",
+			Message:   "Error updating user",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	auditRecord(c, "user", user.ID, before, toUserDetail(user, time.UTC))
 	response := models.Response{
-		Data: UserDetail{
-			ID:          user.ID,
-			Email:       user.Email,
-			FirstName:   user.FirstName,
-			LastName:    user.LastName,
-			Role:        user.Role,
-			Status:      user.Status,
-			CreatedDate: user.CreatedDate,
-			UpdatedDate: user.UpdatedDate,
-		},
+		Data:    toUserDetail(user, userLocation(c)),
 		Message: "User updated successfully",
 	}
 	return c.JSON(http.StatusOK, response)
 }
 
+func membershipPlanFieldError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return fieldValidationError(c, "membership_plan_id", "exists", "Membership plan not found")
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error resolving membership plan",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func branchFieldError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return fieldValidationError(c, "branch_id", "exists", "Branch not found")
+	}
+	return branchLookupError(c, err)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func (api *UserAPI) deleteUser(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
 	id := c.Param("id")
-	_, err := api.userRepo.GetByID(id)
+	user, err := userRepo.GetByID(c.Request().Context(), id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.JSON(http.StatusNotFound, models.Response{
-				Message: "User not found",
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
 			})
 		}
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error retrieving user",
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
-	err = api.userRepo.Delete(id)
+	err = api.userRepo.Delete(c.Request().Context(), id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, models.Response{
-			Message: "Error deleting user",
+			Message:   "Error deleting user",
+			ErrorCode: models.ErrCodeInternal,
 		})
 	}
+	auditRecord(c, "user", id, toUserDetail(user, time.UTC), nil)
 	response := models.Response{
 		Message: "User deleted successfully",
 	}
 	return c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+func (api *UserAPI) resolveMembershipPlan(id string) (*models.MembershipPlan, error) {
+	if id == "" {
+		return api.planRepo.GetByCode(defaultMembershipPlanCode)
+	}
+	return api.planRepo.GetByID(id)
+}
+
+func (api *UserAPI) getUserNotes(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	id := c.Param("id")
+	_, err = userRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	notes, err := api.noteRepo.GetByUserID(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user notes",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	response := models.Response{
+		Data:    toUserNoteDetails(notes, userLocation(c)),
+		Message: "User notes retrieved successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (api *UserAPI) createUserNote(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	id := c.Param("id")
+	var req CreateUserNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return bindError(c, err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return validationError(c, err)
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		return fieldValidationError(c, "body", "required", "is required")
+	}
+	_, err = userRepo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	note := &models.UserNote{
+		ID:       uuid.New().String(),
+		UserID:   id,
+		AuthorID: api.authMw.GetUserFromContext(c).UserID,
+		Body:     req.Body,
+	}
+	err = api.noteRepo.Create(note)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error creating user note",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "user_note", note.ID, nil, toUserNoteDetail(note, time.UTC))
+	response := models.Response{
+		Data:    toUserNoteDetail(note, userLocation(c)),
+		Message: "User note created successfully",
+	}
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (api *UserAPI) deleteUserNote(c echo.Context) error {
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	_, err = userRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User not found",
+				ErrorCode: models.ErrCodeUserNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	note, err := api.noteRepo.GetByID(c.Param("noteId"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.JSON(http.StatusNotFound, models.Response{
+				Message:   "User note not found",
+				ErrorCode: models.ErrCodeUserNoteNotFound,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error retrieving user note",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	if note.UserID != c.Param("id") {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "User note not found",
+			ErrorCode: models.ErrCodeUserNoteNotFound,
+		})
+	}
+	err = api.noteRepo.Delete(note.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error deleting user note",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	auditRecord(c, "user_note", note.ID, toUserNoteDetail(note, time.UTC), nil)
+	response := models.Response{
+		Message: "User note deleted successfully",
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// assignBranch resolves the home branch for a created or updated user. An
+// empty branchID means no branch, except for branch staff, whose users always
+// belong to the staff member's own branch.
+func (api *UserAPI) assignBranch(c echo.Context, branchID string) (*string, error) {
+	staffBranchID, err := api.branches.StaffBranchID(c)
+	if err != nil {
+		return nil, err
+	}
+	if staffBranchID != "" {
+		if branchID != "" && branchID != staffBranchID {
+			return nil, errBranchAccessDenied
+		}
+		return &staffBranchID, nil
+	}
+	if branchID == "" {
+		return nil, nil
+	}
+	branch, err := api.branches.branchRepo.GetByID(c.Request().Context(), branchID)
+	if err != nil {
+		return nil, err
+	}
+	return &branch.ID, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func userVersionConflict(c echo.Context) error {
+	return c.JSON(http.StatusConflict, models.Response{
+		Message:   "User was changed by another request; reload it and try again",
+		ErrorCode: models.ErrCodeVersionConflict,
+	})
+}
+
+func toUserDetail(user *models.User, location *time.Location) UserDetail {
+	return UserDetail{
+		ID:                user.ID,
+		Email:             user.Email,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		Role:              user.Role,
+		Status:            user.Status,
+		MembershipPlanID:  user.MembershipPlanID,
+		BranchID:          user.BranchID,
+		CardNumber:        user.CardNumber,
+		Timezone:          user.Timezone,
+		LastLoginAt:       timeIn(user.LastLoginAt, location),
+		FlaggedInactiveAt: timeIn(user.FlaggedInactiveAt, location),
+		Version:           user.Version,
+		CreatedDate:       user.CreatedDate.In(location),
+		UpdatedDate:       user.UpdatedDate.In(location),
+	}
+}
+
+func toUserNoteDetail(note *models.UserNote, location *time.Location) UserNoteDetail {
+	return UserNoteDetail{
+		ID:          note.ID,
+		AuthorID:    note.AuthorID,
+		Body:        note.Body,
+		CreatedDate: note.CreatedDate.In(location),
+	}
+}
+
+func toUserNoteDetails(notes []models.UserNote, location *time.Location) []UserNoteDetail {
+	noteDetails := make([]UserNoteDetail, len(notes))
+	for i := range notes {
+		noteDetails[i] = toUserNoteDetail(&notes[i], location)
+	}
+	return noteDetails
+}
+
+func toLoginEventDetails(events []models.LoginEvent, location *time.Location) []LoginEventDetail {
+	eventDetails := make([]LoginEventDetail, len(events))
+	for i, event := range events {
+		eventDetails[i] = LoginEventDetail{
+			IPAddress:   event.IPAddress,
+			UserAgent:   event.UserAgent,
+			CreatedDate: event.CreatedDate.In(location),
+		}
+	}
+	return eventDetails
+}
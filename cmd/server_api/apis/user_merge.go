@@ -0,0 +1,153 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// UserMergeAPI consolidates duplicate member accounts.
+type UserMergeAPI struct {
+	mergeRepo *repositories.UserMergeRepository
+	branches  *BranchAccess
+}
+
+type UserMergeDetail struct {
+	UserID      string           `json:"user_id"`
+	DuplicateID string           `json:"duplicate_id"`
+	DryRun      bool             `json:"dry_run"`
+	Moved       UserMergeRecords `json:"moved"`
+}
+
+// UserMergeRecords counts the records moved, or that would be moved, onto
+// the surviving account.
+type UserMergeRecords struct {
+	DigitalLoans  int64 `json:"digital_loans"`
+	Reservations  int64 `json:"reservations"`
+	EventRSVPs    int64 `json:"event_rsvps"`
+	ReadingLists  int64 `json:"reading_lists"`
+	Reviews       int64 `json:"reviews"`
+	Suggestions   int64 `json:"suggestions"`
+	SavedSearches int64 `json:"saved_searches"`
+	Notes         int64 `json:"notes"`
+	Payments      int64 `json:"payments"`
+}
+
+func NewUserMergeAPI(mergeRepo *repositories.UserMergeRepository, branches *BranchAccess) *UserMergeAPI {
+	return &UserMergeAPI{
+		mergeRepo: mergeRepo,
+		branches:  branches,
+	}
+}
+
+// Setup registers the merge under /admin/users.
+func (api *UserMergeAPI) Setup(group *echo.Group) {
+	group.POST("/:id/merge/:otherId", api.mergeUsers)
+}
+
+// mergeUsers moves the records of the duplicate account otherId onto id and
+// deactivates the duplicate. With dry_run=true it only reports what would
+// move. Branch staff can merge only accounts at their own branch.
+func (api *UserMergeAPI) mergeUsers(c echo.Context) error {
+	dryRun := false
+	if dryRunStr := c.QueryParam("dry_run"); dryRunStr != "" {
+		d, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return queryParamError(c, &models.FieldError{Field: "dry_run", Message: "dry_run must be a boolean"})
+		}
+		dryRun = d
+	}
+	if c.Param("id") == c.Param("otherId") {
+		return fieldValidationError(c, "otherId", "nefield", "cannot merge a user into itself")
+	}
+	userRepo, err := api.branches.ScopedUsers(c, "")
+	if err != nil {
+		return branchLookupError(c, err)
+	}
+	ctx := c.Request().Context()
+	user, err := userRepo.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		return userMergeLookupError(c, err)
+	}
+	duplicate, err := userRepo.GetByID(ctx, c.Param("otherId"))
+	if err != nil {
+		return userMergeLookupError(c, err)
+	}
+	if duplicate.Role != "member" {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Only member accounts can be merged into another account",
+			ErrorCode: models.ErrCodeUserMergeNotAllowed,
+		})
+	}
+	if user.Status != "active" {
+		return c.JSON(http.StatusConflict, models.Response{
+			Message:   "Cannot merge into an inactive account",
+			ErrorCode: models.ErrCodeUserMergeNotAllowed,
+		})
+	}
+	if dryRun {
+		counts, err := api.mergeRepo.Preview(ctx, duplicate.ID, user.ID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, models.Response{
+				Message:   "Error previewing user merge",
+				ErrorCode: models.ErrCodeInternal,
+			})
+		}
+		return c.JSON(http.StatusOK, models.Response{
+			Data:    toUserMergeDetail(user.ID, duplicate.ID, true, counts),
+			Message: "User merge preview generated successfully",
+		})
+	}
+	before := toUserDetail(duplicate, time.UTC)
+	counts, err := api.mergeRepo.Merge(ctx, duplicate.ID, user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, models.Response{
+			Message:   "Error merging users",
+			ErrorCode: models.ErrCodeInternal,
+		})
+	}
+	duplicate.Status = "inactive"
+	duplicate.Version++
+	auditRecord(c, "user", duplicate.ID, before, toUserDetail(duplicate, time.UTC))
+	return c.JSON(http.StatusOK, models.Response{
+		Data:    toUserMergeDetail(user.ID, duplicate.ID, false, counts),
+		Message: "Users merged successfully",
+	})
+}
+
+func userMergeLookupError(c echo.Context, err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return c.JSON(http.StatusNotFound, models.Response{
+			Message:   "User not found",
+			ErrorCode: models.ErrCodeUserNotFound,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, models.Response{
+		Message:   "Error retrieving user",
+		ErrorCode: models.ErrCodeInternal,
+	})
+}
+
+func toUserMergeDetail(userID, duplicateID string, dryRun bool, counts *repositories.UserMergeCounts) UserMergeDetail {
+	return UserMergeDetail{
+		UserID:      userID,
+		DuplicateID: duplicateID,
+		DryRun:      dryRun,
+		Moved: UserMergeRecords{
+			DigitalLoans:  counts.DigitalLoans,
+			Reservations:  counts.Reservations,
+			EventRSVPs:    counts.EventRSVPs,
+			ReadingLists:  counts.ReadingLists,
+			Reviews:       counts.Reviews,
+			Suggestions:   counts.Suggestions,
+			SavedSearches: counts.SavedSearches,
+			Notes:         counts.Notes,
+			Payments:      counts.Payments,
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package apis
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type RequestValidator struct {
+	validate *validator.Validate
+}
+
+func NewRequestValidator() *RequestValidator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return &RequestValidator{
+		validate: validate,
+	}
+}
+
+func (v *RequestValidator) Validate(i any) error {
+	return v.validate.Struct(i)
+}
+
+func validationError(c echo.Context, err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return c.JSON(http.StatusBadRequest, models.Response{
+			Message:   "Invalid request format",
+			ErrorCode: models.ErrCodeInvalidRequest,
+		})
+	}
+	fieldErrors := make([]models.FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		fieldErrors[i] = models.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: fieldErrorMessage(fieldErr),
+		}
+	}
+	return c.JSON(http.StatusUnprocessableEntity, models.Response{
+		Message:   "Request validation failed",
+		ErrorCode: models.ErrCodeValidation,
+		Errors:    fieldErrors,
+	})
+}
+
+// fieldValidationError rejects a body field that passed its struct tags but
+// failed a check the handler makes itself, such as a reference to a record
+// that doesn't exist, in the same shape as validationError.
+func fieldValidationError(c echo.Context, field, rule, message string) error {
+	return c.JSON(http.StatusUnprocessableEntity, models.Response{
+		Message:   "Request validation failed",
+		ErrorCode: models.ErrCodeValidation,
+		Errors: []models.FieldError{
+			{
+				Field:   field,
+				Rule:    rule,
+				Message: message,
+			},
+		},
+	})
+}
+
+func fieldErrorMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items", fieldErr.Param())
+		}
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "timezone":
+		return "must be an IANA time zone name such as Europe/Madrid"
+	case "url":
+		return "must be a valid URL"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,46 @@
+package events
+
+import "sync"
+
+const subscriberBuffer = 16
+
+type Message struct {
+	Event string
+	Data  []byte
+}
+
+// Broker fans messages out to in-process subscribers such as SSE streams.
+// Slow subscribers miss messages rather than block the broadcaster.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Message]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Message]struct{}),
+	}
+}
+
+func (b *Broker) Subscribe() (<-chan Message, func()) {
+	ch := make(chan Message, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *Broker) Broadcast(msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
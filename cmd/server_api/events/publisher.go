@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const flushTimeout = 5 * time.Second
+
+type Publisher interface {
+	Publish(ctx context.Context, subject, messageID string, data []byte) error
+	Close()
+}
+
+type NATSPublisher struct {
+	conn *nats.Conn
+}
+
+// NewNATSPublisher keeps trying to connect in the background when NATS is
+// not up yet, so the server starts without it; publishes fail and readiness
+// reports nats as down until it connects.
+func NewNATSPublisher(url string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(
+		url,
+		nats.Name("book-management-system"),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSPublisher{
+		conn: conn,
+	}, nil
+}
+
+// Publish sets the Nats-Msg-Id header so JetStream streams can drop the
+// duplicates an at-least-once relay produces after a crash.
+func (p *NATSPublisher) Publish(ctx context.Context, subject, messageID string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(nats.MsgIdHdr, messageID)
+	msg.Data = data
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.flush(ctx)
+}
+
+func (p *NATSPublisher) Subscribe(subject string, handler func(subject string, data []byte)) (func() error, error) {
+	sub, err := p.conn.Subscribe(subject, func(msg *nats.Msg) {
+		handler(msg.Subject, msg.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub.Unsubscribe, nil
+}
+
+func (p *NATSPublisher) Ping(ctx context.Context) error {
+	return p.flush(ctx)
+}
+
+// flush bounds the round trip when the caller has no deadline, which
+// FlushWithContext requires.
+func (p *NATSPublisher) flush(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, flushTimeout)
+		defer cancel()
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *NATSPublisher) Close() {
+	p.conn.Close()
+}
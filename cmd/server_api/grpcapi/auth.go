@@ -0,0 +1,42 @@
+package grpcapi
+
+import (
+	"book-management-system/pkg/auth"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptor validates the bearer token sent in the "authorization"
+// metadata. Every UserService and LoanService call additionally requires the
+// admin role, since they act on any member's account.
+func AuthInterceptor(jwtAuth *auth.JWT) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok || token == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		claims, err := jwtAuth.ValidateToken(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		if adminOnly(info.FullMethod) && claims.Role != "admin" {
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func adminOnly(method string) bool {
+	return strings.HasPrefix(method, "/bookms.v1.UserService/") ||
+		strings.HasPrefix(method, "/bookms.v1.LoanService/")
+}
@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	bookmsv1 "book-management-system/pkg/pb/bookms/v1"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type BookServer struct {
+	bookmsv1.UnimplementedBookServiceServer
+	bookRepo    repositories.BookRepo
+	maxPageSize int
+}
+
+func NewBookServer(bookRepo repositories.BookRepo, maxPageSize int) *BookServer {
+	return &BookServer{
+		bookRepo:    bookRepo,
+		maxPageSize: maxPageSize,
+	}
+}
+
+func (s *BookServer) GetBook(ctx context.Context, req *bookmsv1.GetBookRequest) (*bookmsv1.Book, error) {
+	book, err := s.bookRepo.GetByID(ctx, req.GetId())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, "book not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to retrieve book")
+	}
+	return toBookMessage(book), nil
+}
+
+func (s *BookServer) ListBooks(ctx context.Context, req *bookmsv1.ListBooksRequest) (*bookmsv1.ListBooksResponse, error) {
+	limit, offset, err := s.pagination(req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	filter := repositories.BookFilter{
+		Status: req.GetStatus(),
+		Genre:  req.GetGenre(),
+		Author: req.GetAuthor(),
+	}
+	books, err := s.bookRepo.GetFiltered(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve books")
+	}
+	total, err := s.bookRepo.CountFiltered(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get book count")
+	}
+	return &bookmsv1.ListBooksResponse{
+		Books: toBookMessages(books),
+		Total: total,
+	}, nil
+}
+
+func (s *BookServer) SearchBooks(ctx context.Context, req *bookmsv1.SearchBooksRequest) (*bookmsv1.SearchBooksResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if req.GetMinRating() < 0 || req.GetMinRating() > 5 {
+		return nil, status.Error(codes.InvalidArgument, "min_rating must be between 0 and 5")
+	}
+	limit, offset, err := s.pagination(req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	books, err := s.bookRepo.SearchBooks(ctx, req.GetQuery(), req.GetMinRating(), limit, offset)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to search books")
+	}
+	return &bookmsv1.SearchBooksResponse{
+		Books: toBookMessages(books),
+	}, nil
+}
+
+func (s *BookServer) pagination(limit, offset int32) (int, int, error) {
+	if limit <= 0 {
+		limit = int32(min(20, s.maxPageSize))
+	}
+	if int(limit) > s.maxPageSize {
+		return 0, 0, status.Errorf(codes.InvalidArgument, "limit must be at most %d", s.maxPageSize)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return int(limit), int(offset), nil
+}
+
+func toBookMessages(books []models.Book) []*bookmsv1.Book {
+	messages := make([]*bookmsv1.Book, len(books))
+	for i := range books {
+		messages[i] = toBookMessage(&books[i])
+	}
+	return messages
+}
+
+func toBookMessage(book *models.Book) *bookmsv1.Book {
+	return &bookmsv1.Book{
+		Id:                book.ID,
+		Title:             book.Title,
+		Author:            book.Author,
+		Isbn:              book.ISBN,
+		Genre:             book.Genre,
+		Language:          book.Language,
+		Quantity:          int32(book.Quantity),
+		AvailableQuantity: int32(book.AvailableQuantity),
+		Location:          book.Location,
+		Status:            book.Status,
+		RatingAverage:     book.RatingAverage,
+		RatingCount:       int32(book.RatingCount),
+		CreatedDate:       timestamppb.New(book.CreatedDate),
+		UpdatedDate:       timestamppb.New(book.UpdatedDate),
+	}
+}
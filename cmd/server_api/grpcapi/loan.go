@@ -0,0 +1,162 @@
+package grpcapi
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	bookmsv1 "book-management-system/pkg/pb/bookms/v1"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// LoanServer lends ebooks on behalf of members, applying the same checks as
+// the REST digital loan handlers. Loan receipts are only sent for loans
+// made and returned through REST.
+type LoanServer struct {
+	bookmsv1.UnimplementedLoanServiceServer
+	loanRepo      *repositories.DigitalLoanRepository
+	fileRepo      *repositories.BookFileRepository
+	bookRepo      repositories.BookRepo
+	userRepo      repositories.UserRepo
+	planRepo      *repositories.MembershipPlanRepository
+	ebooksEnabled bool
+}
+
+func NewLoanServer(loanRepo *repositories.DigitalLoanRepository, fileRepo *repositories.BookFileRepository, bookRepo repositories.BookRepo, userRepo repositories.UserRepo, planRepo *repositories.MembershipPlanRepository, ebooksEnabled bool) *LoanServer {
+	return &LoanServer{
+		loanRepo:      loanRepo,
+		fileRepo:      fileRepo,
+		bookRepo:      bookRepo,
+		userRepo:      userRepo,
+		planRepo:      planRepo,
+		ebooksEnabled: ebooksEnabled,
+	}
+}
+
+func (s *LoanServer) GetLoan(ctx context.Context, req *bookmsv1.GetLoanRequest) (*bookmsv1.Loan, error) {
+	loan, err := s.loanRepo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, loanLookupError(err)
+	}
+	return toLoanMessage(loan), nil
+}
+
+// ListActiveLoans returns the member's active loans, due soonest first.
+func (s *LoanServer) ListActiveLoans(ctx context.Context, req *bookmsv1.ListActiveLoansRequest) (*bookmsv1.ListActiveLoansResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	loans, err := s.loanRepo.GetActiveByUser(ctx, req.GetUserId(), time.Now().UTC())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve loans")
+	}
+	messages := make([]*bookmsv1.Loan, len(loans))
+	for i := range loans {
+		messages[i] = toLoanMessage(&loans[i])
+	}
+	return &bookmsv1.ListActiveLoansResponse{
+		Loans: messages,
+	}, nil
+}
+
+// CreateLoan lends a book's ebook to an active member for their membership
+// plan's loan period.
+func (s *LoanServer) CreateLoan(ctx context.Context, req *bookmsv1.CreateLoanRequest) (*bookmsv1.Loan, error) {
+	if req.GetUserId() == "" || req.GetBookId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and book_id are required")
+	}
+	if !s.ebooksEnabled {
+		return nil, status.Error(codes.Unavailable, "ebooks are not enabled")
+	}
+	book, err := s.bookRepo.GetByID(ctx, req.GetBookId())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, status.Error(codes.NotFound, "book not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to retrieve book")
+	}
+	files, err := s.fileRepo.GetByBook(ctx, book.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve ebook files")
+	}
+	if len(files) == 0 {
+		return nil, status.Error(codes.NotFound, "book has no ebook")
+	}
+	user, err := s.userRepo.GetByID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+	if user.Status != "active" {
+		return nil, status.Error(codes.FailedPrecondition, "user is not active")
+	}
+	plan, err := s.planRepo.GetByID(user.MembershipPlanID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve membership plan")
+	}
+
+	loan := &models.DigitalLoan{
+		ID:     uuid.New().String(),
+		BookID: book.ID,
+		UserID: user.ID,
+	}
+	err = s.loanRepo.Create(ctx, loan, plan)
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		return nil, status.Error(codes.NotFound, "book not found")
+	case errors.Is(err, repositories.ErrReferenceOnly):
+		return nil, status.Error(codes.FailedPrecondition, "book is for reference only and cannot be borrowed")
+	case errors.Is(err, repositories.ErrNoDigitalCopies):
+		return nil, status.Error(codes.FailedPrecondition, "all digital copies of this book are on loan")
+	case errors.Is(err, repositories.ErrDigitalLoanExists):
+		return nil, status.Error(codes.AlreadyExists, "user already has this book on loan")
+	case errors.Is(err, repositories.ErrLoanLimitReached):
+		return nil, status.Error(codes.FailedPrecondition, "user has reached their membership plan's loan limit")
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to create loan")
+	}
+	return toLoanMessage(loan), nil
+}
+
+// ReturnLoan ends an active loan early.
+func (s *LoanServer) ReturnLoan(ctx context.Context, req *bookmsv1.ReturnLoanRequest) (*bookmsv1.Loan, error) {
+	loan, err := s.loanRepo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, loanLookupError(err)
+	}
+	err = s.loanRepo.Return(ctx, loan)
+	if errors.Is(err, repositories.ErrDigitalLoanEnded) {
+		return nil, status.Error(codes.FailedPrecondition, "loan has already ended")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to return loan")
+	}
+	return toLoanMessage(loan), nil
+}
+
+func loanLookupError(err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return status.Error(codes.NotFound, "loan not found")
+	}
+	return status.Error(codes.Internal, "failed to retrieve loan")
+}
+
+func toLoanMessage(loan *models.DigitalLoan) *bookmsv1.Loan {
+	message := &bookmsv1.Loan{
+		Id:          loan.ID,
+		BookId:      loan.BookID,
+		UserId:      loan.UserID,
+		DueDate:     timestamppb.New(loan.DueDate),
+		CreatedDate: timestamppb.New(loan.CreatedDate),
+		UpdatedDate: timestamppb.New(loan.UpdatedDate),
+	}
+	if loan.ReturnedDate != nil {
+		message.ReturnedDate = timestamppb.New(*loan.ReturnedDate)
+	}
+	return message
+}
@@ -0,0 +1,66 @@
+package grpcapi
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	bookmsv1 "book-management-system/pkg/pb/bookms/v1"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type UserServer struct {
+	bookmsv1.UnimplementedUserServiceServer
+	userRepo repositories.UserRepo
+}
+
+func NewUserServer(userRepo repositories.UserRepo) *UserServer {
+	return &UserServer{
+		userRepo: userRepo,
+	}
+}
+
+func (s *UserServer) GetUser(ctx context.Context, req *bookmsv1.GetUserRequest) (*bookmsv1.User, error) {
+	user, err := s.userRepo.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+	return toUserMessage(user), nil
+}
+
+func (s *UserServer) GetUserByCardNumber(ctx context.Context, req *bookmsv1.GetUserByCardNumberRequest) (*bookmsv1.User, error) {
+	user, err := s.userRepo.GetByCardNumber(ctx, req.GetCardNumber())
+	if err != nil {
+		return nil, userLookupError(err)
+	}
+	return toUserMessage(user), nil
+}
+
+func userLookupError(err error) error {
+	if err == gorm.ErrRecordNotFound {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	return status.Error(codes.Internal, "failed to retrieve user")
+}
+
+func toUserMessage(user *models.User) *bookmsv1.User {
+	message := &bookmsv1.User{
+		Id:               user.ID,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Role:             user.Role,
+		Status:           user.Status,
+		MembershipPlanId: user.MembershipPlanID,
+		CardNumber:       user.CardNumber,
+		CreatedDate:      timestamppb.New(user.CreatedDate),
+		UpdatedDate:      timestamppb.New(user.UpdatedDate),
+	}
+	if user.LastLoginAt != nil {
+		message.LastLoginAt = timestamppb.New(*user.LastLoginAt)
+	}
+	return message
+}
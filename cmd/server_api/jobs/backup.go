@@ -0,0 +1,286 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"book-management-system/pkg/objectstore"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupBatchSize = 500
+	// backupFormat names the format in each backup's header line.
+	backupFormat      = "bookms-backup"
+	backupKeyPrefix   = "bookms-"
+	backupKeySuffix   = ".ndjson.gz"
+	backupTimeLayout  = "20060102T150405Z"
+	backupContentType = "application/gzip"
+)
+
+// BackupJob takes logical backups of the catalog and its members: a gzipped
+// NDJSON file holding a header line and then one line per row, table by
+// table, uploaded to <prefix>/bookms-<time>.ndjson.gz. After each backup,
+// backups older than the retention period are deleted.
+type BackupJob struct {
+	backupRepo *repositories.BackupRepository
+	store      objectstore.Store
+	prefix     string
+	retention  time.Duration
+	logger     *slog.Logger
+}
+
+type BackupReport struct {
+	Key           string         `json:"key"`
+	Bytes         int            `json:"bytes"`
+	SchemaVersion int64          `json:"schema_version"`
+	Tables        map[string]int `json:"tables"`
+	Deleted       []string       `json:"deleted"`
+	RunAt         time.Time      `json:"run_at"`
+}
+
+type BackupObject struct {
+	Key       string    `json:"key"`
+	Bytes     int64     `json:"bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RestoreReport struct {
+	Key           string         `json:"key"`
+	SchemaVersion int64          `json:"schema_version"`
+	Tables        map[string]int `json:"tables"`
+}
+
+type backupHeader struct {
+	Format        string    `json:"format"`
+	SchemaVersion int64     `json:"schema_version"`
+	Tables        []string  `json:"tables"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type backupLine struct {
+	Table string `json:"table"`
+	Row   any    `json:"row"`
+}
+
+type restoreLine struct {
+	Table string                 `json:"table"`
+	Row   repositories.BackupRow `json:"row"`
+}
+
+// NewBackupJob keeps backups for retention; zero keeps them forever.
+func NewBackupJob(backupRepo *repositories.BackupRepository, store objectstore.Store, prefix string, retention time.Duration) *BackupJob {
+	return &BackupJob{
+		backupRepo: backupRepo,
+		store:      store,
+		prefix:     strings.Trim(prefix, "/"),
+		retention:  retention,
+		logger:     logging.Module("jobs"),
+	}
+}
+
+func (j *BackupJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				j.logger.ErrorContext(ctx, "Backup job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run takes a backup and then applies the retention period. A failure to
+// delete old backups is logged but does not fail the backup.
+func (j *BackupJob) Run(ctx context.Context) (*BackupReport, error) {
+	now := time.Now().UTC()
+	version, err := j.backupRepo.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &BackupReport{
+		Key:           j.keyPrefix() + now.Format(backupTimeLayout) + backupKeySuffix,
+		SchemaVersion: version,
+		Tables:        make(map[string]int, len(repositories.BackupTables)),
+		Deleted:       []string{},
+		RunAt:         now,
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(gz)
+	err = enc.Encode(backupHeader{
+		Format:        backupFormat,
+		SchemaVersion: version,
+		Tables:        repositories.BackupTables,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range repositories.BackupTables {
+		report.Tables[table] = 0
+		err := j.backupRepo.EachRow(ctx, table, backupBatchSize, func(rows []map[string]any) error {
+			for _, row := range rows {
+				if err := enc.Encode(backupLine{Table: table, Row: row}); err != nil {
+					return err
+				}
+			}
+			report.Tables[table] += len(rows)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := j.store.Put(ctx, report.Key, backupContentType, "", buf.Bytes()); err != nil {
+		return nil, err
+	}
+	report.Bytes = buf.Len()
+
+	deleted, err := j.prune(ctx, now)
+	if err != nil {
+		j.logger.ErrorContext(ctx, "Deleting expired backups failed",
+			"error", err,
+		)
+	}
+	report.Deleted = append(report.Deleted, deleted...)
+
+	j.logger.InfoContext(
+		ctx,
+		"Backup job completed",
+		"key", report.Key,
+		"bytes", report.Bytes,
+		"deleted", len(report.Deleted),
+	)
+	return report, nil
+}
+
+// List returns the backups in the store, newest first. Backups are dated by
+// the time in their key, and other objects under the prefix are ignored.
+func (j *BackupJob) List(ctx context.Context) ([]BackupObject, error) {
+	keyPrefix := j.keyPrefix()
+	objects, err := j.store.List(ctx, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	backups := []BackupObject{}
+	for _, object := range objects {
+		stamp, ok := strings.CutSuffix(strings.TrimPrefix(object.Key, keyPrefix), backupKeySuffix)
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupObject{
+			Key:       object.Key,
+			Bytes:     object.Size,
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(backups, func(a, b int) bool {
+		return backups[a].Key > backups[b].Key
+	})
+	return backups, nil
+}
+
+// Restore loads the backup stored under key into the database. Rows in the
+// backup replace the rows with the same ID and rows added since are kept, so
+// it undoes edits and deletions but not additions. The database must be
+// migrated at least as far as it was when the backup was taken.
+func (j *BackupJob) Restore(ctx context.Context, key string) (*RestoreReport, error) {
+	body, err := j.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a backup: %w", key, err)
+	}
+	dec := json.NewDecoder(gz)
+	var header backupHeader
+	if err := dec.Decode(&header); err != nil || header.Format != backupFormat {
+		return nil, fmt.Errorf("%s is not a backup", key)
+	}
+	version, err := j.backupRepo.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version < header.SchemaVersion {
+		return nil, fmt.Errorf("backup %s needs schema version %d, database is at %d; run migrate first", key, header.SchemaVersion, version)
+	}
+
+	tables, err := j.backupRepo.Restore(ctx, backupBatchSize, func() (string, repositories.BackupRow, error) {
+		var line restoreLine
+		// Decode returns io.EOF after the last row, which ends the restore.
+		if err := dec.Decode(&line); err != nil {
+			return "", nil, err
+		}
+		return line.Table, line.Row, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := &RestoreReport{
+		Key:           key,
+		SchemaVersion: header.SchemaVersion,
+		Tables:        tables,
+	}
+	j.logger.InfoContext(
+		ctx,
+		"Backup restored",
+		"key", key,
+		"schema_version", header.SchemaVersion,
+	)
+	return report, nil
+}
+
+// prune deletes the backups taken before the retention period. The backup
+// just taken is always within it, so at least one backup is kept.
+func (j *BackupJob) prune(ctx context.Context, now time.Time) ([]string, error) {
+	if j.retention <= 0 {
+		return nil, nil
+	}
+	backups, err := j.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-j.retention)
+	var deleted []string
+	for _, backup := range backups {
+		if !backup.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := j.store.Delete(ctx, backup.Key); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, backup.Key)
+	}
+	return deleted, nil
+}
+
+func (j *BackupJob) keyPrefix() string {
+	if j.prefix == "" {
+		return backupKeyPrefix
+	}
+	return j.prefix + "/" + backupKeyPrefix
+}
@@ -0,0 +1,269 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"book-management-system/pkg/objectstore"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// coverFetchTimeout bounds the download of one cover.
+	coverFetchTimeout = 30 * time.Second
+	// maxCoverImportFailures is how many failed rows a report lists; the
+	// counters still count them all.
+	maxCoverImportFailures = 100
+)
+
+// coverExtensions lists the image types a cover may have, by the type
+// sniffed from its content, with the extension of its object key.
+var coverExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+var errNoCoverBook = errors.New("no book has this ISBN")
+
+// CoverImportJob fetches cover images for books by ISBN, keeps a copy of each
+// in object storage and points the book's cover_url at the image's source.
+// It is meant for onboarding a catalog from a list of image URLs. Imports
+// are started by an admin and happen in the background, one at a time.
+type CoverImportJob struct {
+	bookRepo  repositories.BookRepo
+	coverRepo *repositories.BookCoverRepository
+	store     objectstore.Store
+	prefix    string
+	maxBytes  int64
+	client    *http.Client
+	logger    *slog.Logger
+
+	mu   sync.Mutex
+	last *CoverImportReport
+}
+
+// CoverImportEntry is one row of an import: the cover at URL belongs to the
+// book with ISBN. Line is the row's line in the uploaded file.
+type CoverImportEntry struct {
+	Line int
+	ISBN string
+	URL  string
+}
+
+// CoverImportReport counts the rows of an import as they are processed.
+// Unmatched rows name an ISBN no book has; Failures lists the first
+// unmatched and failed rows.
+type CoverImportReport struct {
+	Running    bool                 `json:"running"`
+	Total      int                  `json:"total"`
+	Processed  int                  `json:"processed"`
+	Stored     int                  `json:"stored"`
+	Unmatched  int                  `json:"unmatched"`
+	Failed     int                  `json:"failed"`
+	Failures   []CoverImportFailure `json:"failures"`
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+}
+
+type CoverImportFailure struct {
+	Line  int    `json:"line"`
+	ISBN  string `json:"isbn"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// NewCoverImportJob stores covers in store under prefix and skips images
+// larger than maxBytes.
+func NewCoverImportJob(bookRepo repositories.BookRepo, coverRepo *repositories.BookCoverRepository, store objectstore.Store, prefix string, maxBytes int64) *CoverImportJob {
+	return &CoverImportJob{
+		bookRepo:  bookRepo,
+		coverRepo: coverRepo,
+		store:     store,
+		prefix:    strings.Trim(prefix, "/"),
+		maxBytes:  maxBytes,
+		client:    &http.Client{Timeout: coverFetchTimeout},
+		logger:    logging.Module("jobs"),
+	}
+}
+
+// Trigger starts importing entries in the background and returns the
+// import's report, which fills in as it goes. ok is false, with the running
+// report, when an import is already in progress.
+func (j *CoverImportJob) Trigger(entries []CoverImportEntry) (report CoverImportReport, ok bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.last != nil && j.last.Running {
+		return j.copyReport(), false
+	}
+	j.last = &CoverImportReport{
+		Running:   true,
+		Total:     len(entries),
+		Failures:  []CoverImportFailure{},
+		StartedAt: time.Now().UTC(),
+	}
+	go j.run(context.Background(), entries)
+	return j.copyReport(), true
+}
+
+// Status returns the report of the current or last import, or nil before the
+// first import.
+func (j *CoverImportJob) Status() *CoverImportReport {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.last == nil {
+		return nil
+	}
+	report := j.copyReport()
+	return &report
+}
+
+// copyReport copies the last report, failures included, so the caller can
+// read it while the import goes on. The caller holds mu.
+func (j *CoverImportJob) copyReport() CoverImportReport {
+	report := *j.last
+	report.Failures = append([]CoverImportFailure{}, j.last.Failures...)
+	return report
+}
+
+func (j *CoverImportJob) run(ctx context.Context, entries []CoverImportEntry) {
+	for _, entry := range entries {
+		err := j.importCover(ctx, entry)
+		j.mu.Lock()
+		j.last.Processed++
+		switch {
+		case err == nil:
+			j.last.Stored++
+		case errors.Is(err, errNoCoverBook):
+			j.last.Unmatched++
+		default:
+			j.last.Failed++
+		}
+		if err != nil && len(j.last.Failures) < maxCoverImportFailures {
+			j.last.Failures = append(j.last.Failures, CoverImportFailure{
+				Line:  entry.Line,
+				ISBN:  entry.ISBN,
+				URL:   entry.URL,
+				Error: err.Error(),
+			})
+		}
+		j.mu.Unlock()
+		if err != nil && !errors.Is(err, errNoCoverBook) {
+			j.logger.WarnContext(ctx, "Cover import failed",
+				"isbn", entry.ISBN,
+				"error", err,
+			)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.last.Running = false
+	j.last.FinishedAt = &now
+	j.logger.InfoContext(ctx, "Cover import job completed",
+		"total", j.last.Total,
+		"stored", j.last.Stored,
+		"unmatched", j.last.Unmatched,
+		"failed", j.last.Failed,
+	)
+}
+
+// importCover fetches the entry's image, stores it as the book's cover and
+// sets the book's cover_url to it. A cover the book had is replaced.
+func (j *CoverImportJob) importCover(ctx context.Context, entry CoverImportEntry) error {
+	books, err := j.bookRepo.GetFiltered(ctx, repositories.BookFilter{ISBN: entry.ISBN}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(books) == 0 {
+		return errNoCoverBook
+	}
+	book := books[0]
+
+	data, err := j.fetch(ctx, entry.URL)
+	if err != nil {
+		return err
+	}
+	contentType := http.DetectContentType(data)
+	extension, ok := coverExtensions[contentType]
+	if !ok {
+		return fmt.Errorf("not an image: %s", contentType)
+	}
+	key := j.prefix + "/" + book.ID + "/" + uuid.New().String() + "." + extension
+	if err := j.store.Put(ctx, key, contentType, "", data); err != nil {
+		return err
+	}
+	cover := &models.BookCover{
+		ID:          uuid.New().String(),
+		BookID:      book.ID,
+		SourceURL:   entry.URL,
+		ObjectKey:   key,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+	}
+	replaced, err := j.coverRepo.Save(ctx, cover)
+	if err != nil {
+		j.deleteObject(ctx, key)
+		return err
+	}
+	if replaced != nil {
+		j.deleteObject(ctx, replaced.ObjectKey)
+	}
+
+	if book.CoverURL != nil && *book.CoverURL == entry.URL {
+		return nil
+	}
+	book.CoverURL = &entry.URL
+	return j.bookRepo.Update(ctx, &book)
+}
+
+// fetch downloads an image of at most maxBytes.
+func (j *CoverImportJob) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		// The report already names the URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, j.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > j.maxBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", j.maxBytes)
+	}
+	return data, nil
+}
+
+func (j *CoverImportJob) deleteObject(ctx context.Context, key string) {
+	if err := j.store.Delete(ctx, key); err != nil {
+		j.logger.WarnContext(ctx, "Failed to delete cover object",
+			"key", key,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyStatsJob keeps the current UTC day's row in daily_stats up to date so
+// reports read small summary rows instead of scanning the catalog. A day's
+// totals are final once the last run before midnight has stored them.
+type DailyStatsJob struct {
+	statsRepo *repositories.DailyStatRepository
+	logger    *slog.Logger
+}
+
+func NewDailyStatsJob(statsRepo *repositories.DailyStatRepository) *DailyStatsJob {
+	return &DailyStatsJob{
+		statsRepo: statsRepo,
+		logger:    logging.Module("jobs"),
+	}
+}
+
+// Start runs the job once immediately, so a fresh deployment has today's row,
+// and then on every tick.
+func (j *DailyStatsJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := j.Run(ctx); err != nil {
+			j.logger.ErrorContext(ctx, "Daily stats job failed",
+				"error", err,
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *DailyStatsJob) Run(ctx context.Context) (*models.DailyStat, error) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	stat, err := j.statsRepo.Compute(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	stat.ID = uuid.New().String()
+	if err := j.statsRepo.Upsert(ctx, stat); err != nil {
+		return nil, err
+	}
+	j.logger.InfoContext(
+		ctx,
+		"Daily stats job completed",
+		"stat_date", day.Format(time.DateOnly),
+		"titles", stat.Titles,
+		"members", stat.Members,
+	)
+	return stat, nil
+}
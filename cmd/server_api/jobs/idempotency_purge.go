@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IdempotencyPurgeJob struct {
+	idempotencyRepo *repositories.IdempotencyKeyRepository
+	logger          *slog.Logger
+}
+
+func NewIdempotencyPurgeJob(idempotencyRepo *repositories.IdempotencyKeyRepository) *IdempotencyPurgeJob {
+	return &IdempotencyPurgeJob{
+		idempotencyRepo: idempotencyRepo,
+		logger:          logging.Module("jobs"),
+	}
+}
+
+func (j *IdempotencyPurgeJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := j.idempotencyRepo.PurgeExpired(time.Now().UTC())
+			if err != nil {
+				j.logger.ErrorContext(ctx, "Idempotency key purge failed",
+					"error", err,
+				)
+				continue
+			}
+			if purged > 0 {
+				j.logger.InfoContext(ctx, "Idempotency keys purged",
+					"purged", purged,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	InactiveAccountActionFlag       = "flag"
+	InactiveAccountActionDeactivate = "deactivate"
+)
+
+type InactiveAccountJob struct {
+	userRepo     *repositories.UserRepository
+	inactiveDays int
+	action       string
+	dryRun       bool
+	logger       *slog.Logger
+}
+
+type InactiveAccountReport struct {
+	Action   string    `json:"action"`
+	DryRun   bool      `json:"dry_run"`
+	Cutoff   time.Time `json:"cutoff"`
+	UserIDs  []string  `json:"user_ids"`
+	Affected int       `json:"affected"`
+	RunAt    time.Time `json:"run_at"`
+}
+
+func NewInactiveAccountJob(userRepo *repositories.UserRepository, inactiveDays int, action string, dryRun bool) *InactiveAccountJob {
+	return &InactiveAccountJob{
+		userRepo:     userRepo,
+		inactiveDays: inactiveDays,
+		action:       action,
+		dryRun:       dryRun,
+		logger:       logging.Module("jobs"),
+	}
+}
+
+func (j *InactiveAccountJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := j.Run(ctx, j.dryRun)
+			if err != nil {
+				j.logger.ErrorContext(ctx, "Inactive account job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run selects active members whose last login (or registration, if they never
+// logged in) predates the cutoff. Admin accounts are never touched so the job
+// cannot lock staff out.
+func (j *InactiveAccountJob) Run(ctx context.Context, dryRun bool) (*InactiveAccountReport, error) {
+	now := time.Now().UTC()
+	cutoff := now.AddDate(0, 0, -j.inactiveDays)
+	users, err := j.userRepo.GetInactiveMembersSince(ctx, cutoff, j.action == InactiveAccountActionFlag)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	report := &InactiveAccountReport{
+		Action:  j.action,
+		DryRun:  dryRun,
+		Cutoff:  cutoff,
+		UserIDs: ids,
+		RunAt:   now,
+	}
+	if !dryRun && len(ids) > 0 {
+		var affected int64
+		if j.action == InactiveAccountActionDeactivate {
+			affected, err = j.userRepo.DeactivateUsers(ctx, ids)
+		} else {
+			affected, err = j.userRepo.FlagInactive(ctx, ids, now)
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Affected = int(affected)
+	}
+	j.logger.InfoContext(
+		ctx,
+		"Inactive account job completed",
+		"action", report.Action,
+		"dry_run", report.DryRun,
+		"cutoff", report.Cutoff,
+		"candidates", len(report.UserIDs),
+		"affected", report.Affected,
+	)
+	return report, nil
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/notifications"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	loanReminderBatchSize = 100
+	// LoanDueSoonWindow is how long before its due date a loan gets its due
+	// soon reminder.
+	LoanDueSoonWindow = 48 * time.Hour
+	// LoanOverdueWindow limits overdue reminders to loans that fell due this
+	// recently, so loans that ended before the job first ran, or while it
+	// was stopped, are not reminded about long afterwards.
+	LoanOverdueWindow = 7 * 24 * time.Hour
+)
+
+// LoanReminderJob sends due_soon notifications for digital loans falling due
+// within LoanDueSoonWindow and overdue notifications for loans that fell due
+// without being returned. Each reminder is recorded before it is sent, so a
+// loan gets each kind at most once however often the job runs, and on
+// however many instances.
+type LoanReminderJob struct {
+	loanRepo     *repositories.DigitalLoanRepository
+	reminderRepo *repositories.LoanReminderRepository
+	bookRepo     repositories.BookRepo
+	notifier     *notifications.Notifier
+	logger       *slog.Logger
+
+	mu sync.Mutex
+}
+
+type LoanReminderReport struct {
+	DueSoon int `json:"due_soon"`
+	Overdue int `json:"overdue"`
+	Failed  int `json:"failed"`
+}
+
+func NewLoanReminderJob(loanRepo *repositories.DigitalLoanRepository, reminderRepo *repositories.LoanReminderRepository, bookRepo repositories.BookRepo, notifier *notifications.Notifier) *LoanReminderJob {
+	return &LoanReminderJob{
+		loanRepo:     loanRepo,
+		reminderRepo: reminderRepo,
+		bookRepo:     bookRepo,
+		notifier:     notifier,
+		logger:       logging.Module("jobs"),
+	}
+}
+
+func (j *LoanReminderJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				j.logger.ErrorContext(ctx, "Loan reminder job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run sends the reminders that are due. A failed delivery is logged and
+// counted but not retried, since the in-app notification may already have
+// been stored.
+func (j *LoanReminderJob) Run(ctx context.Context) (*LoanReminderReport, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	report := &LoanReminderReport{}
+	sent, err := j.remind(ctx, notifications.EventDueSoon, now, now.Add(LoanDueSoonWindow), report)
+	if err != nil {
+		return nil, err
+	}
+	report.DueSoon = sent
+	sent, err = j.remind(ctx, notifications.EventOverdue, now.Add(-LoanOverdueWindow), now, report)
+	if err != nil {
+		return nil, err
+	}
+	report.Overdue = sent
+	if report.DueSoon > 0 || report.Overdue > 0 || report.Failed > 0 {
+		j.logger.InfoContext(ctx, "Loan reminder job completed",
+			"due_soon", report.DueSoon,
+			"overdue", report.Overdue,
+			"failed", report.Failed,
+		)
+	}
+	return report, nil
+}
+
+// remind sends eventType for the loans falling due after dueAfter and no
+// later than dueBefore, and returns how many were sent.
+func (j *LoanReminderJob) remind(ctx context.Context, eventType string, dueAfter, dueBefore time.Time, report *LoanReminderReport) (int, error) {
+	sent := 0
+	for {
+		loans, err := j.loanRepo.GetUnreminded(ctx, eventType, dueAfter, dueBefore, loanReminderBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for i := range loans {
+			loan := &loans[i]
+			err := j.reminderRepo.Create(ctx, &models.LoanReminder{
+				ID:        uuid.New().String(),
+				LoanID:    loan.ID,
+				EventType: eventType,
+			})
+			if errors.Is(err, repositories.ErrLoanReminderSent) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			book, err := j.bookRepo.GetByID(ctx, loan.BookID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			err = j.notifier.Emit(ctx, notifications.Event{
+				Type:   eventType,
+				UserID: loan.UserID,
+				Data: map[string]any{
+					"title":    book.Title,
+					"due_date": loan.DueDate,
+				},
+			})
+			if err != nil {
+				j.logger.ErrorContext(ctx, "Failed to send loan reminder",
+					"loan_id", loan.ID,
+					"event_type", eventType,
+					"error", err,
+				)
+				report.Failed++
+				continue
+			}
+			sent++
+		}
+		if len(loans) < loanReminderBatchSize {
+			return sent, nil
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/alerts"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLowStockLines caps the books listed in one alert.
+const maxLowStockLines = 20
+
+// LowStockAlertJob alerts when books run low on available copies. A book is
+// reported once each time it drops to the threshold: runs only alert on books
+// that no earlier alert reached a webhook about. The first run after a
+// restart reports every low book.
+type LowStockAlertJob struct {
+	bookRepo  repositories.BookRepo
+	alerter   *alerts.Alerter
+	threshold int
+	logger    *slog.Logger
+
+	mu sync.Mutex
+	// reported holds the IDs of the low books an alert has reached a webhook
+	// about.
+	reported map[string]bool
+}
+
+type LowStockReport struct {
+	LowStock int `json:"low_stock"`
+	Alerted  int `json:"alerted"`
+}
+
+func NewLowStockAlertJob(bookRepo repositories.BookRepo, alerter *alerts.Alerter, threshold int) *LowStockAlertJob {
+	return &LowStockAlertJob{
+		bookRepo:  bookRepo,
+		alerter:   alerter,
+		threshold: threshold,
+		logger:    logging.Module("jobs"),
+	}
+}
+
+func (j *LowStockAlertJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := j.Run(ctx); err != nil {
+			j.logger.ErrorContext(ctx, "Low stock alert job failed",
+				"error", err,
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run alerts on the low books not reported yet. Books stay unreported while
+// no webhook receives the alert, including while none is subscribed, so the
+// next run tries again.
+func (j *LowStockAlertJob) Run(ctx context.Context) (*LowStockReport, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	books, err := j.bookRepo.GetLowStock(ctx, j.threshold)
+	if err != nil {
+		return nil, err
+	}
+	reported := make(map[string]bool, len(books))
+	var unreported []models.Book
+	for _, book := range books {
+		if j.reported[book.ID] {
+			reported[book.ID] = true
+		} else {
+			unreported = append(unreported, book)
+		}
+	}
+	report := &LowStockReport{
+		LowStock: len(books),
+	}
+	if len(unreported) > 0 {
+		var delivered int
+		delivered, err = j.alerter.Send(ctx, lowStockAlert(unreported, j.threshold))
+		if delivered > 0 {
+			for _, book := range unreported {
+				reported[book.ID] = true
+			}
+			report.Alerted = len(unreported)
+		}
+	}
+	// Books that are no longer low drop out, so they are reported again
+	// the next time they run low.
+	j.reported = reported
+	if err != nil {
+		return nil, err
+	}
+	j.logger.InfoContext(ctx, "Low stock alert job completed",
+		"low_stock", report.LowStock,
+		"alerted", report.Alerted,
+	)
+	return report, nil
+}
+
+func lowStockAlert(books []models.Book, threshold int) alerts.Alert {
+	title := fmt.Sprintf("%d books are low on stock", len(books))
+	if len(books) == 1 {
+		title = "1 book is low on stock"
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "These books have %d or fewer copies available:\n", threshold)
+	for i, book := range books {
+		if i == maxLowStockLines {
+			fmt.Fprintf(&text, "...and %d more\n", len(books)-maxLowStockLines)
+			break
+		}
+		fmt.Fprintf(&text, "- %s (ID %s): %d of %d available\n", book.Title, book.ID, book.AvailableQuantity, book.Quantity)
+	}
+	return alerts.Alert{
+		Event: alerts.EventLowStock,
+		Title: title,
+		Text:  strings.TrimSuffix(text.String(), "\n"),
+	}
+}
@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/bookmeta"
+	"book-management-system/pkg/logging"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// enrichmentNoMatchRetry is how long a book the catalogs knew nothing more
+// about is left alone before it is looked up again.
+const enrichmentNoMatchRetry = 30 * 24 * time.Hour
+
+// MetadataEnrichmentJob looks up books missing a description, page count or
+// cover in external catalogs and records what they have as a pending
+// enrichment for an admin to review. It never changes a book itself. Runs are
+// started by an admin and happen in the background, one at a time.
+type MetadataEnrichmentJob struct {
+	enrichmentRepo *repositories.BookEnrichmentRepository
+	providers      []bookmeta.Provider
+	logger         *slog.Logger
+
+	mu   sync.Mutex
+	last *MetadataEnrichmentReport
+}
+
+type MetadataEnrichmentReport struct {
+	Running    bool       `json:"running"`
+	Limit      int        `json:"limit"`
+	Scanned    int        `json:"scanned"`
+	Proposed   int        `json:"proposed"`
+	NoMatch    int        `json:"no_match"`
+	Failed     int        `json:"failed"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewMetadataEnrichmentJob consults providers in order; the first with a
+// value for a missing field supplies it.
+func NewMetadataEnrichmentJob(enrichmentRepo *repositories.BookEnrichmentRepository, providers []bookmeta.Provider) *MetadataEnrichmentJob {
+	return &MetadataEnrichmentJob{
+		enrichmentRepo: enrichmentRepo,
+		providers:      providers,
+		logger:         logging.Module("jobs"),
+	}
+}
+
+// Trigger starts a run over at most limit books in the background and
+// returns its report, which fills in as the run goes. ok is false, with the
+// running report, when a run is already in progress.
+func (j *MetadataEnrichmentJob) Trigger(limit int) (report MetadataEnrichmentReport, ok bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.last != nil && j.last.Running {
+		return *j.last, false
+	}
+	j.last = &MetadataEnrichmentReport{
+		Running:   true,
+		Limit:     limit,
+		StartedAt: time.Now().UTC(),
+	}
+	go j.run(context.Background(), limit)
+	return *j.last, true
+}
+
+// Status returns the report of the current or last run, or nil before the
+// first run.
+func (j *MetadataEnrichmentJob) Status() *MetadataEnrichmentReport {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.last == nil {
+		return nil
+	}
+	report := *j.last
+	return &report
+}
+
+func (j *MetadataEnrichmentJob) run(ctx context.Context, limit int) {
+	err := j.enrich(ctx, limit)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.last.Running = false
+	j.last.FinishedAt = &now
+	if err != nil {
+		j.logger.ErrorContext(ctx, "Metadata enrichment job failed",
+			"error", err,
+		)
+		return
+	}
+	j.logger.InfoContext(ctx, "Metadata enrichment job completed",
+		"scanned", j.last.Scanned,
+		"proposed", j.last.Proposed,
+		"no_match", j.last.NoMatch,
+		"failed", j.last.Failed,
+	)
+}
+
+func (j *MetadataEnrichmentJob) enrich(ctx context.Context, limit int) error {
+	books, err := j.enrichmentRepo.GetCandidates(ctx, time.Now().UTC().Add(-enrichmentNoMatchRetry), limit)
+	if err != nil {
+		return err
+	}
+	for _, book := range books {
+		enrichment, err := j.lookup(ctx, book)
+		if err == nil {
+			err = j.enrichmentRepo.Create(ctx, enrichment)
+		}
+		j.mu.Lock()
+		j.last.Scanned++
+		switch {
+		case err != nil:
+			j.last.Failed++
+		case enrichment.Status == models.EnrichmentStatusNoMatch:
+			j.last.NoMatch++
+		default:
+			j.last.Proposed++
+		}
+		j.mu.Unlock()
+		if err != nil {
+			// A failed lookup writes nothing, so the next run retries it.
+			j.logger.WarnContext(ctx, "Metadata lookup failed",
+				"book_id", book.ID,
+				"error", err,
+			)
+		}
+	}
+	return nil
+}
+
+// lookup fills each field the book is missing from the first provider that
+// has it. A provider error fails the lookup rather than recording a partial
+// result or no match that later runs would not revisit.
+func (j *MetadataEnrichmentJob) lookup(ctx context.Context, book models.Book) (*models.BookEnrichment, error) {
+	enrichment := &models.BookEnrichment{
+		ID:     uuid.New().String(),
+		BookID: book.ID,
+		Status: models.EnrichmentStatusNoMatch,
+	}
+	needDescription := book.Description == nil || *book.Description == ""
+	needPages := book.Pages == nil
+	needCover := book.CoverURL == nil || *book.CoverURL == ""
+	for _, provider := range j.providers {
+		if !needDescription && !needPages && !needCover {
+			break
+		}
+		metadata, err := provider.Lookup(ctx, *book.ISBN)
+		if errors.Is(err, bookmeta.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		source := provider.Source()
+		if needDescription && metadata.Description != "" {
+			enrichment.Description = &metadata.Description
+			enrichment.DescriptionSource = &source
+			needDescription = false
+		}
+		if needPages && metadata.Pages > 0 {
+			enrichment.Pages = &metadata.Pages
+			enrichment.PagesSource = &source
+			needPages = false
+		}
+		if needCover && metadata.CoverURL != "" {
+			enrichment.CoverURL = &metadata.CoverURL
+			enrichment.CoverURLSource = &source
+			needCover = false
+		}
+	}
+	if enrichment.Description != nil || enrichment.Pages != nil || enrichment.CoverURL != nil {
+		enrichment.Status = models.EnrichmentStatusPending
+	}
+	return enrichment, nil
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/events"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+const outboxBatchSize = 100
+
+type OutboxRelay struct {
+	outboxRepo    *repositories.OutboxRepository
+	publisher     events.Publisher
+	subjectPrefix string
+	logger        *slog.Logger
+}
+
+type outboxMessage struct {
+	ID            string          `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+func NewOutboxRelay(outboxRepo *repositories.OutboxRepository, publisher events.Publisher, subjectPrefix string) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:    outboxRepo,
+		publisher:     publisher,
+		subjectPrefix: subjectPrefix,
+		logger:        logging.Module("jobs"),
+	}
+}
+
+func (j *OutboxRelay) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Run(ctx); err != nil {
+				j.logger.ErrorContext(ctx, "Outbox relay failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run publishes pending events oldest first and stops at the first failure,
+// so a failed event is retried before anything newer. Events are ordered by
+// created_date, which is set before their transaction commits, so an event
+// committed late can follow newer ones; consumers needing strict order should
+// compare occurred_at.
+func (j *OutboxRelay) Run(ctx context.Context) error {
+	pending, err := j.outboxRepo.GetUnpublished(outboxBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, event := range pending {
+		data, err := json.Marshal(outboxMessage{
+			ID:            event.ID,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			EventType:     event.EventType,
+			Payload:       json.RawMessage(event.Payload),
+			OccurredAt:    event.CreatedDate,
+		})
+		if err != nil {
+			return err
+		}
+		err = j.publisher.Publish(ctx, j.subjectPrefix+"."+event.EventType, event.ID, data)
+		if err != nil {
+			if markErr := j.outboxRepo.MarkFailed(event.ID, err); markErr != nil {
+				j.logger.ErrorContext(ctx, "Failed to record outbox publish failure",
+					"event_id", event.ID,
+					"error", markErr,
+				)
+			}
+			return err
+		}
+		if err := j.outboxRepo.MarkPublished(event.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ReadingHistoryPurgeJob deletes the loans of members with reading history
+// turned off once they have been returned or fallen due, so an ended loan
+// is kept for at most one interval.
+type ReadingHistoryPurgeJob struct {
+	loanRepo *repositories.DigitalLoanRepository
+	logger   *slog.Logger
+}
+
+func NewReadingHistoryPurgeJob(loanRepo *repositories.DigitalLoanRepository) *ReadingHistoryPurgeJob {
+	return &ReadingHistoryPurgeJob{
+		loanRepo: loanRepo,
+		logger:   logging.Module("jobs"),
+	}
+}
+
+func (j *ReadingHistoryPurgeJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := j.loanRepo.DeleteEndedWithoutHistory(ctx, time.Now().UTC())
+			if err != nil {
+				j.logger.ErrorContext(ctx, "Reading history purge failed",
+					"error", err,
+				)
+				continue
+			}
+			if purged > 0 {
+				j.logger.InfoContext(ctx, "Ended loans purged from disabled reading histories",
+					"purged", purged,
+				)
+			}
+		}
+	}
+}
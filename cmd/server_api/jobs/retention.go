@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const retentionBatchSize = 100
+
+// RetentionPolicy is how many days soft-deleted records are kept before the
+// retention job purges them. Zero keeps them forever.
+type RetentionPolicy struct {
+	BookDays int
+	UserDays int
+}
+
+// PurgeAfter returns when a record deleted at deletedDate becomes due for
+// purging under a retention of days, or nil if it is kept forever.
+func PurgeAfter(deletedDate *time.Time, days int) *time.Time {
+	if deletedDate == nil || days <= 0 {
+		return nil
+	}
+	purgeAfter := deletedDate.AddDate(0, 0, days)
+	return &purgeAfter
+}
+
+// RetentionJob permanently removes soft-deleted books and users once their
+// retention window has passed. Records that kept history still refers to
+// are skipped and counted as blocked.
+type RetentionJob struct {
+	retentionRepo *repositories.RetentionRepository
+	policy        RetentionPolicy
+	dryRun        bool
+	logger        *slog.Logger
+
+	mu sync.Mutex
+}
+
+type RetentionReport struct {
+	DryRun bool                  `json:"dry_run"`
+	Books  RetentionEntityReport `json:"books"`
+	Users  RetentionEntityReport `json:"users"`
+	RunAt  time.Time             `json:"run_at"`
+}
+
+// RetentionEntityReport counts the expired records of one kind. In a dry
+// run Purged is how many would have been purged.
+type RetentionEntityReport struct {
+	RetentionDays int        `json:"retention_days"`
+	Cutoff        *time.Time `json:"cutoff"`
+	Expired       int        `json:"expired"`
+	Purged        int        `json:"purged"`
+	Blocked       int        `json:"blocked"`
+}
+
+func NewRetentionJob(retentionRepo *repositories.RetentionRepository, policy RetentionPolicy, dryRun bool) *RetentionJob {
+	return &RetentionJob{
+		retentionRepo: retentionRepo,
+		policy:        policy,
+		dryRun:        dryRun,
+		logger:        logging.Module("jobs"),
+	}
+}
+
+func (j *RetentionJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx, j.dryRun); err != nil {
+				j.logger.ErrorContext(ctx, "Retention job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+func (j *RetentionJob) Run(ctx context.Context, dryRun bool) (*RetentionReport, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	report := &RetentionReport{
+		DryRun: dryRun,
+		RunAt:  now,
+	}
+	var err error
+	report.Books, err = j.purge(ctx, now, j.policy.BookDays, dryRun, j.retentionRepo.ExpiredBookIDs, j.retentionRepo.PurgeBook)
+	if err != nil {
+		return nil, err
+	}
+	report.Users, err = j.purge(ctx, now, j.policy.UserDays, dryRun, j.retentionRepo.ExpiredUserIDs, j.retentionRepo.PurgeUser)
+	if err != nil {
+		return nil, err
+	}
+	j.logger.InfoContext(ctx, "Retention job completed",
+		"dry_run", report.DryRun,
+		"books_purged", report.Books.Purged,
+		"books_blocked", report.Books.Blocked,
+		"users_purged", report.Users.Purged,
+		"users_blocked", report.Users.Blocked,
+	)
+	return report, nil
+}
+
+func (j *RetentionJob) purge(
+	ctx context.Context,
+	now time.Time,
+	days int,
+	dryRun bool,
+	expiredIDs func(ctx context.Context, cutoff time.Time, afterID string, limit int) ([]string, error),
+	purge func(ctx context.Context, id string, dryRun bool) error,
+) (RetentionEntityReport, error) {
+	report := RetentionEntityReport{RetentionDays: days}
+	if days <= 0 {
+		return report, nil
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	report.Cutoff = &cutoff
+	afterID := ""
+	for {
+		ids, err := expiredIDs(ctx, cutoff, afterID, retentionBatchSize)
+		if err != nil {
+			return report, err
+		}
+		for _, id := range ids {
+			err := purge(ctx, id, dryRun)
+			switch {
+			case err == nil:
+				report.Purged++
+			case errors.Is(err, repositories.ErrPurgeBlocked):
+				report.Blocked++
+			case err == gorm.ErrRecordNotFound:
+				// Restored since it was listed.
+				continue
+			default:
+				return report, err
+			}
+			report.Expired++
+		}
+		if len(ids) < retentionBatchSize {
+			return report, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
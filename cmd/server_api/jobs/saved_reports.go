@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"time"
+)
+
+const savedReportBatchSize = 50
+
+// SavedReportRunner runs a saved report and returns the HTTP status and body
+// its report endpoint produced.
+type SavedReportRunner interface {
+	RunSaved(ctx context.Context, report *models.SavedReport) (int, []byte, error)
+}
+
+// SavedReportJob runs scheduled saved reports that are due and stores each
+// result on the report for its owner to read later.
+type SavedReportJob struct {
+	savedRepo *repositories.SavedReportRepository
+	runner    SavedReportRunner
+	logger    *slog.Logger
+}
+
+func NewSavedReportJob(savedRepo *repositories.SavedReportRepository, runner SavedReportRunner) *SavedReportJob {
+	return &SavedReportJob{
+		savedRepo: savedRepo,
+		runner:    runner,
+		logger:    logging.Module("jobs"),
+	}
+}
+
+func (j *SavedReportJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Run(ctx); err != nil {
+				j.logger.ErrorContext(ctx, "Saved report job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run runs every due report once. A report that cannot be run is retried on
+// the next poll; one that runs but fails, such as a 400 for parameters that
+// no longer validate, keeps that response as its result until the next
+// scheduled run.
+func (j *SavedReportJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	reports, err := j.savedRepo.GetDue(ctx, now, savedReportBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range reports {
+		report := &reports[i]
+		status, body, err := j.runner.RunSaved(ctx, report)
+		if err != nil {
+			j.logger.ErrorContext(ctx, "Failed to run saved report",
+				"saved_report_id", report.ID,
+				"error", err,
+			)
+			continue
+		}
+		next := now.Add(time.Duration(*report.ScheduleHours) * time.Hour)
+		if err := j.savedRepo.RecordRun(ctx, report.ID, now, status, string(body), &next); err != nil {
+			return err
+		}
+	}
+	if len(reports) > 0 {
+		j.logger.InfoContext(
+			ctx,
+			"Saved report job completed",
+			"reports", len(reports),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/notifications"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const savedSearchBatchSize = 100
+
+// SavedSearchAlertJob notifies members when books added since the last run
+// match one of their saved searches. Each search keeps its own watermark, so
+// a book is matched once per search however often the job runs.
+type SavedSearchAlertJob struct {
+	searchRepo *repositories.SavedSearchRepository
+	bookRepo   repositories.BookRepo
+	notifier   *notifications.Notifier
+	logger     *slog.Logger
+
+	mu sync.Mutex
+}
+
+type SavedSearchAlertReport struct {
+	Checked  int `json:"checked"`
+	Notified int `json:"notified"`
+}
+
+func NewSavedSearchAlertJob(searchRepo *repositories.SavedSearchRepository, bookRepo repositories.BookRepo, notifier *notifications.Notifier) *SavedSearchAlertJob {
+	return &SavedSearchAlertJob{
+		searchRepo: searchRepo,
+		bookRepo:   bookRepo,
+		notifier:   notifier,
+		logger:     logging.Module("jobs"),
+	}
+}
+
+func (j *SavedSearchAlertJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := j.Run(ctx); err != nil {
+				j.logger.ErrorContext(ctx, "Saved search alert job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run checks every saved search against the books added since its last
+// check. A failed delivery is logged and not retried, since the in-app
+// notification may already have been stored.
+func (j *SavedSearchAlertJob) Run(ctx context.Context) (*SavedSearchAlertReport, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	report := &SavedSearchAlertReport{}
+	for {
+		searches, err := j.searchRepo.GetDue(ctx, now, savedSearchBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range searches {
+			search := &searches[i]
+			filter := repositories.SavedSearchFilter(search)
+			filter.AddedSince = search.LastCheckedAt
+			filter.AddedBefore = now
+			books, err := j.bookRepo.GetFiltered(ctx, filter, 1, 0)
+			if err != nil {
+				return nil, err
+			}
+			var matchedAt *time.Time
+			if len(books) > 0 {
+				count, err := j.bookRepo.CountFiltered(ctx, filter)
+				if err != nil {
+					return nil, err
+				}
+				err = j.notifier.Emit(ctx, notifications.Event{
+					Type:   notifications.EventSavedSearchMatch,
+					UserID: search.UserID,
+					Data: map[string]any{
+						"name":  search.Name,
+						"count": int(count),
+						"title": books[0].Title,
+					},
+				})
+				if err != nil {
+					j.logger.ErrorContext(ctx, "Failed to send saved search alert",
+						"saved_search_id", search.ID,
+						"error", err,
+					)
+				}
+				matchedAt = &now
+				report.Notified++
+			}
+			if err := j.searchRepo.RecordCheck(ctx, search.ID, now, matchedAt); err != nil {
+				return nil, err
+			}
+			report.Checked++
+		}
+		if len(searches) < savedSearchBatchSize {
+			break
+		}
+	}
+	if report.Checked > 0 {
+		j.logger.InfoContext(ctx, "Saved search alert job completed",
+			"checked", report.Checked,
+			"notified", report.Notified,
+		)
+	}
+	return report, nil
+}
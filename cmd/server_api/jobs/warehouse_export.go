@@ -0,0 +1,255 @@
+package jobs
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/logging"
+	"book-management-system/pkg/objectstore"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const warehouseBatchSize = 500
+
+// WarehouseExportJob dumps the catalog and one UTC day of events to object
+// storage as gzipped NDJSON, partitioned as <prefix>/<table>/dt=<day>/, so
+// analysts can load them into a warehouse without querying production.
+// Login events are pseudonymised: user IDs become keyed hashes and IP
+// addresses and user agents are dropped.
+type WarehouseExportJob struct {
+	exportRepo       *repositories.WarehouseExportRepository
+	store            objectstore.Store
+	prefix           string
+	anonymizationKey []byte
+	logger           *slog.Logger
+}
+
+type WarehouseExportFile struct {
+	Key   string `json:"key"`
+	Rows  int    `json:"rows"`
+	Bytes int    `json:"bytes"`
+}
+
+type WarehouseExportReport struct {
+	Date  string                `json:"date"`
+	Files []WarehouseExportFile `json:"files"`
+	RunAt time.Time             `json:"run_at"`
+}
+
+type warehouseBook struct {
+	ID                string     `json:"id"`
+	Title             string     `json:"title"`
+	Author            string     `json:"author"`
+	ISBN              *string    `json:"isbn"`
+	Publisher         *string    `json:"publisher"`
+	PublicationYear   *int       `json:"publication_year"`
+	Genre             *string    `json:"genre"`
+	Pages             *int       `json:"pages"`
+	Language          string     `json:"language"`
+	Price             *float64   `json:"price"`
+	Quantity          int        `json:"quantity"`
+	AvailableQuantity int        `json:"available_quantity"`
+	Status            string     `json:"status"`
+	RatingAverage     float64    `json:"rating_average"`
+	RatingCount       int        `json:"rating_count"`
+	CreatedDate       time.Time  `json:"created_date"`
+	UpdatedDate       time.Time  `json:"updated_date"`
+	DeletedDate       *time.Time `json:"deleted_date"`
+}
+
+type warehouseLoginEvent struct {
+	ID          string    `json:"id"`
+	UserKey     string    `json:"user_key"`
+	CreatedDate time.Time `json:"created_date"`
+}
+
+// ndjsonFile gzips one JSON document per line into memory.
+type ndjsonFile struct {
+	buf  bytes.Buffer
+	gz   *gzip.Writer
+	enc  *json.Encoder
+	rows int
+}
+
+func newNDJSONFile() *ndjsonFile {
+	f := &ndjsonFile{}
+	f.gz = gzip.NewWriter(&f.buf)
+	f.enc = json.NewEncoder(f.gz)
+	return f
+}
+
+func (f *ndjsonFile) write(row any) error {
+	f.rows++
+	return f.enc.Encode(row)
+}
+
+func NewWarehouseExportJob(exportRepo *repositories.WarehouseExportRepository, store objectstore.Store, prefix string, anonymizationKey string) *WarehouseExportJob {
+	return &WarehouseExportJob{
+		exportRepo:       exportRepo,
+		store:            store,
+		prefix:           strings.Trim(prefix, "/"),
+		anonymizationKey: []byte(anonymizationKey),
+		logger:           logging.Module("jobs"),
+	}
+}
+
+// Start exports the previous UTC day on every tick. Re-running a day
+// overwrites its files, so a missed or failed run is fixed by the next one
+// or by a manual run for that date.
+func (j *WarehouseExportJob) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			day := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+			if _, err := j.Run(ctx, day); err != nil {
+				j.logger.ErrorContext(ctx, "Warehouse export job failed",
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// Run exports the catalog as it stands now and the events created on day, a
+// UTC midnight.
+func (j *WarehouseExportJob) Run(ctx context.Context, day time.Time) (*WarehouseExportReport, error) {
+	next := day.AddDate(0, 0, 1)
+	report := &WarehouseExportReport{
+		Date:  day.Format(time.DateOnly),
+		Files: []WarehouseExportFile{},
+		RunAt: time.Now().UTC(),
+	}
+
+	books := newNDJSONFile()
+	err := j.exportRepo.EachBook(ctx, warehouseBatchSize, func(batch []models.Book) error {
+		for i := range batch {
+			if err := books.write(toWarehouseBook(&batch[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := j.upload(ctx, report, "books", books); err != nil {
+		return nil, err
+	}
+
+	catalogEvents := newNDJSONFile()
+	err = j.exportRepo.EachOutboxEvent(ctx, day, next, warehouseBatchSize, func(batch []models.OutboxEvent) error {
+		for _, event := range batch {
+			err := catalogEvents.write(outboxMessage{
+				ID:            event.ID,
+				AggregateType: event.AggregateType,
+				AggregateID:   event.AggregateID,
+				EventType:     event.EventType,
+				Payload:       json.RawMessage(event.Payload),
+				OccurredAt:    event.CreatedDate,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := j.upload(ctx, report, "catalog_events", catalogEvents); err != nil {
+		return nil, err
+	}
+
+	loginEvents := newNDJSONFile()
+	err = j.exportRepo.EachLoginEvent(ctx, day, next, warehouseBatchSize, func(batch []models.LoginEvent) error {
+		for _, event := range batch {
+			err := loginEvents.write(warehouseLoginEvent{
+				ID:          event.ID,
+				UserKey:     j.pseudonym(event.UserID),
+				CreatedDate: event.CreatedDate,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := j.upload(ctx, report, "login_events", loginEvents); err != nil {
+		return nil, err
+	}
+
+	j.logger.InfoContext(
+		ctx,
+		"Warehouse export job completed",
+		"date", report.Date,
+		"files", len(report.Files),
+	)
+	return report, nil
+}
+
+func (j *WarehouseExportJob) upload(ctx context.Context, report *WarehouseExportReport, table string, file *ndjsonFile) error {
+	if err := file.gz.Close(); err != nil {
+		return err
+	}
+	key := table + "/dt=" + report.Date + "/" + table + ".ndjson.gz"
+	if j.prefix != "" {
+		key = j.prefix + "/" + key
+	}
+	err := j.store.Put(ctx, key, "application/x-ndjson", "gzip", file.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	report.Files = append(report.Files, WarehouseExportFile{
+		Key:   key,
+		Rows:  file.rows,
+		Bytes: file.buf.Len(),
+	})
+	return nil
+}
+
+// pseudonym is stable across exports, so analysts can still count distinct
+// users, but cannot be reversed without the anonymization key.
+func (j *WarehouseExportJob) pseudonym(userID string) string {
+	mac := hmac.New(sha256.New, j.anonymizationKey)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toWarehouseBook(book *models.Book) warehouseBook {
+	return warehouseBook{
+		ID:                book.ID,
+		Title:             book.Title,
+		Author:            book.Author,
+		ISBN:              book.ISBN,
+		Publisher:         book.Publisher,
+		PublicationYear:   book.PublicationYear,
+		Genre:             book.Genre,
+		Pages:             book.Pages,
+		Language:          book.Language,
+		Price:             book.Price,
+		Quantity:          book.Quantity,
+		AvailableQuantity: book.AvailableQuantity,
+		Status:            book.Status,
+		RatingAverage:     book.RatingAverage,
+		RatingCount:       book.RatingCount,
+		CreatedDate:       book.CreatedDate,
+		UpdatedDate:       book.UpdatedDate,
+		DeletedDate:       book.DeletedDate,
+	}
+}
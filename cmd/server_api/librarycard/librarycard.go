@@ -0,0 +1,83 @@
+package librarycard
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// Card numbers are 14 digits: a fixed institution prefix, 11 random digits and
+// a trailing Luhn check digit. Digit-only numbers of fixed length print as
+// Codabar or Code 128 barcodes, which is what most desk scanners expect.
+const (
+	prefix        = "29"
+	randomDigits  = 11
+	Length        = len(prefix) + randomDigits + 1
+	issueAttempts = 5
+)
+
+func Generate() (string, error) {
+	digits := make([]byte, 0, Length)
+	digits = append(digits, prefix...)
+	for i := 0; i < randomDigits; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits = append(digits, byte('0'+n.Int64()))
+	}
+	digits = append(digits, checkDigit(digits))
+	return string(digits), nil
+}
+
+func Issue(ctx context.Context, exists func(ctx context.Context, number string) (bool, error)) (string, error) {
+	var lastErr error
+	for i := 0; i < issueAttempts; i++ {
+		number, err := Generate()
+		if err != nil {
+			return "", err
+		}
+		taken, err := exists(ctx, number)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !taken {
+			return number, nil
+		}
+	}
+	if lastErr != nil {
+		return "", lastErr
+	}
+	return "", errors.New("unable to allocate a unique card number")
+}
+
+func Valid(number string) bool {
+	if len(number) != Length {
+		return false
+	}
+	for i := 0; i < len(number); i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+	}
+	return checkDigit([]byte(number[:Length-1])) == number[Length-1]
+}
+
+func checkDigit(payload []byte) byte {
+	sum := 0
+	double := true
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
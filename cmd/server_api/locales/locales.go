@@ -0,0 +1,9 @@
+// Package locales embeds the response message catalogs. en.json lists every
+// message the API can return; other catalogs translate them. Messages missing
+// from a catalog are returned in English.
+package locales
+
+import "embed"
+
+//go:embed *.json
+var Files embed.FS
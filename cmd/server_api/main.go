@@ -1,49 +1,205 @@
 package main
 
 import (
+	"book-management-system/cmd/server_api/alerts"
 	"book-management-system/cmd/server_api/apis"
+	"book-management-system/cmd/server_api/events"
+	"book-management-system/cmd/server_api/grpcapi"
+	"book-management-system/cmd/server_api/jobs"
+	"book-management-system/cmd/server_api/locales"
+	"book-management-system/cmd/server_api/migrations"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/notifications"
 	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/cmd/server_api/seed"
 	"book-management-system/pkg/auth"
+	"book-management-system/pkg/bodylimit"
+	"book-management-system/pkg/bookmeta"
+	"book-management-system/pkg/circuitbreaker"
+	"book-management-system/pkg/configfile"
+	"book-management-system/pkg/httpcompress"
+	"book-management-system/pkg/i18n"
+	"book-management-system/pkg/logging"
+	"book-management-system/pkg/mailer"
+	"book-management-system/pkg/objectstore"
+	bookmsv1 "book-management-system/pkg/pb/bookms/v1"
+	"book-management-system/pkg/querystats"
+	"book-management-system/pkg/ratelimit"
+	"book-management-system/pkg/secrets"
+	"book-management-system/pkg/telemetry"
+	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
+	"github.com/glebarez/sqlite"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	slogGorm "github.com/orandin/slog-gorm"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+	"golang.org/x/crypto/acme/autocert"
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
+)
+
+const (
+	tlsModeOff      = "off"
+	tlsModeFile     = "file"
+	tlsModeAutocert = "autocert"
 )
 
 type Config struct {
-	DBHost                string `envconfig:"DB_HOST" required:"true"`
-	DBPort                int    `envconfig:"DB_PORT" required:"true"`
-	DBUser                string `envconfig:"DB_USER" required:"true"`
-	DBPassword            string `envconfig:"DB_PASSWORD" required:"true"`
-	DBName                string `envconfig:"DB_NAME" required:"true"`
-	DBMaxOpenConns        int    `envconfig:"DB_MAX_OPEN_CONNS" required:"true"`
-	DBMaxIdleConns        int    `envconfig:"DB_MAX_IDLE_CONNS" required:"true"`
-	DBConnMaxLifetime     int    `envconfig:"DB_CONN_MAX_LIFETIME" required:"true"`
-	ServerHost            string `envconfig:"SERVER_HOST" required:"true"`
-	ServerPort            string `envconfig:"SERVER_PORT" required:"true"`
-	JWTSecret             string `envconfig:"JWT_SECRET" required:"true"`
-	JWTExpiryHours        int    `envconfig:"JWT_EXPIRY_HOURS" required:"true"`
-	JWTRefreshExpiryHours int    `envconfig:"JWT_REFRESH_EXPIRY_HOURS" required:"true"`
+	DBDriver                     string  `envconfig:"DB_DRIVER" default:"postgres"`
+	DBHost                       string  `envconfig:"DB_HOST" default:"localhost"`
+	DBPort                       int     `envconfig:"DB_PORT" default:"5432"`
+	DBUser                       string  `envconfig:"DB_USER" default:"postgres"`
+	DBPassword                   string  `envconfig:"DB_PASSWORD"`
+	DBName                       string  `envconfig:"DB_NAME" default:"myapp"`
+	DBMaxOpenConns               int     `envconfig:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns               int     `envconfig:"DB_MAX_IDLE_CONNS" default:"5"`
+	DBConnMaxLifetime            int     `envconfig:"DB_CONN_MAX_LIFETIME" default:"300"`
+	DBStatementTimeoutSeconds    int     `envconfig:"DB_STATEMENT_TIMEOUT_SECONDS" default:"30"`
+	DBBreakerEnabled             bool    `envconfig:"DB_BREAKER_ENABLED" default:"true"`
+	DBBreakerFailureThreshold    int     `envconfig:"DB_BREAKER_FAILURE_THRESHOLD" default:"5"`
+	DBBreakerCooldownSeconds     int     `envconfig:"DB_BREAKER_COOLDOWN_SECONDS" default:"30"`
+	DBSlowQueryThresholdMs       int     `envconfig:"DB_SLOW_QUERY_THRESHOLD_MS" default:"200"`
+	QueryStatsMaxStatements      int     `envconfig:"QUERY_STATS_MAX_STATEMENTS" default:"500"`
+	MigrateOnStartup             bool    `envconfig:"MIGRATE_ON_STARTUP" default:"false"`
+	ServerHost                   string  `envconfig:"SERVER_HOST" default:"0.0.0.0"`
+	ServerPort                   string  `envconfig:"SERVER_PORT" default:"8080"`
+	MaxBodyBytes                 int64   `envconfig:"MAX_BODY_BYTES" default:"1048576"`
+	MaxPageSize                  int     `envconfig:"MAX_PAGE_SIZE" default:"100"`
+	TLSMode                      string  `envconfig:"TLS_MODE" default:"off"`
+	TLSCertFile                  string  `envconfig:"TLS_CERT_FILE"`
+	TLSKeyFile                   string  `envconfig:"TLS_KEY_FILE"`
+	TLSAutocertDomains           string  `envconfig:"TLS_AUTOCERT_DOMAINS"`
+	TLSAutocertEmail             string  `envconfig:"TLS_AUTOCERT_EMAIL"`
+	TLSAutocertCacheDir          string  `envconfig:"TLS_AUTOCERT_CACHE_DIR"`
+	GRPCPort                     string  `envconfig:"GRPC_PORT" default:"9090"`
+	JWTSecret                    string  `envconfig:"JWT_SECRET" required:"true"`
+	AdminEmail                   string  `envconfig:"ADMIN_EMAIL"`
+	AdminPassword                string  `envconfig:"ADMIN_PASSWORD"`
+	JWTExpiryHours               int     `envconfig:"JWT_EXPIRY_HOURS" default:"24"`
+	JWTRefreshExpiryHours        int     `envconfig:"JWT_REFRESH_EXPIRY_HOURS" default:"168"`
+	JWTIssuer                    string  `envconfig:"JWT_ISSUER" default:"book-management-system"`
+	JWTAudience                  string  `envconfig:"JWT_AUDIENCE" default:"book-management-system"`
+	InactiveAccountDays          int     `envconfig:"INACTIVE_ACCOUNT_DAYS" default:"180"`
+	InactiveAccountAction        string  `envconfig:"INACTIVE_ACCOUNT_ACTION" default:"flag"`
+	InactiveAccountDryRun        bool    `envconfig:"INACTIVE_ACCOUNT_DRY_RUN" default:"true"`
+	InactiveAccountIntervalHours int     `envconfig:"INACTIVE_ACCOUNT_INTERVAL_HOURS" default:"24"`
+	RetentionBookDays            int     `envconfig:"RETENTION_BOOK_DAYS" default:"90"`
+	RetentionUserDays            int     `envconfig:"RETENTION_USER_DAYS" default:"365"`
+	RetentionDryRun              bool    `envconfig:"RETENTION_DRY_RUN" default:"true"`
+	RetentionIntervalHours       int     `envconfig:"RETENTION_INTERVAL_HOURS" default:"24"`
+	DailyStatsIntervalHours      int     `envconfig:"DAILY_STATS_INTERVAL_HOURS" default:"1"`
+	SavedReportPollMinutes       int     `envconfig:"SAVED_REPORT_POLL_MINUTES" default:"5"`
+	LowStockThreshold            int     `envconfig:"LOW_STOCK_THRESHOLD" default:"1"`
+	LowStockCheckMinutes         int     `envconfig:"LOW_STOCK_CHECK_MINUTES" default:"15"`
+	SavedSearchAlertMinutes      int     `envconfig:"SAVED_SEARCH_ALERT_MINUTES" default:"60"`
+	LoanReminderMinutes          int     `envconfig:"LOAN_REMINDER_MINUTES" default:"60"`
+	EnrichmentProviders          string  `envconfig:"ENRICHMENT_PROVIDERS" default:"google_books,open_library"`
+	GoogleBooksAPIKey            string  `envconfig:"GOOGLE_BOOKS_API_KEY"`
+	WarehouseExportEnabled       bool    `envconfig:"WAREHOUSE_EXPORT_ENABLED" default:"false"`
+	WarehouseExportIntervalHours int     `envconfig:"WAREHOUSE_EXPORT_INTERVAL_HOURS" default:"24"`
+	WarehouseExportPrefix        string  `envconfig:"WAREHOUSE_EXPORT_PREFIX" default:"bookms"`
+	WarehouseAnonymizationKey    string  `envconfig:"WAREHOUSE_ANONYMIZATION_KEY"`
+	BackupEnabled                bool    `envconfig:"BACKUP_ENABLED" default:"false"`
+	BackupIntervalHours          int     `envconfig:"BACKUP_INTERVAL_HOURS" default:"24"`
+	BackupPrefix                 string  `envconfig:"BACKUP_PREFIX" default:"backups"`
+	BackupRetentionDays          int     `envconfig:"BACKUP_RETENTION_DAYS" default:"30"`
+	EbooksEnabled                bool    `envconfig:"EBOOKS_ENABLED" default:"false"`
+	EbookPrefix                  string  `envconfig:"EBOOK_PREFIX" default:"ebooks"`
+	EbookMaxBytes                int64   `envconfig:"EBOOK_MAX_BYTES" default:"104857600"`
+	EbookDownloadURLMinutes      int     `envconfig:"EBOOK_DOWNLOAD_URL_MINUTES" default:"15"`
+	CoversEnabled                bool    `envconfig:"COVERS_ENABLED" default:"false"`
+	CoverPrefix                  string  `envconfig:"COVER_PREFIX" default:"covers"`
+	CoverMaxBytes                int64   `envconfig:"COVER_MAX_BYTES" default:"5242880"`
+	ReservationWindowDays        int     `envconfig:"RESERVATION_WINDOW_DAYS" default:"14"`
+	DeviceTokenExpiryDays        int     `envconfig:"DEVICE_TOKEN_EXPIRY_DAYS" default:"365"`
+	S3Endpoint                   string  `envconfig:"S3_ENDPOINT"`
+	S3Region                     string  `envconfig:"S3_REGION" default:"us-east-1"`
+	S3Bucket                     string  `envconfig:"S3_BUCKET"`
+	S3AccessKeyID                string  `envconfig:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey            string  `envconfig:"S3_SECRET_ACCESS_KEY"`
+	S3PathStyle                  bool    `envconfig:"S3_PATH_STYLE" default:"false"`
+	MailerDriver                 string  `envconfig:"MAILER_DRIVER" default:"off"`
+	MailerFrom                   string  `envconfig:"MAILER_FROM"`
+	SMTPHost                     string  `envconfig:"SMTP_HOST" default:"localhost"`
+	SMTPPort                     int     `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername                 string  `envconfig:"SMTP_USERNAME"`
+	SMTPPassword                 string  `envconfig:"SMTP_PASSWORD"`
+	SESEndpoint                  string  `envconfig:"SES_ENDPOINT"`
+	SESRegion                    string  `envconfig:"SES_REGION" default:"us-east-1"`
+	SESAccessKeyID               string  `envconfig:"SES_ACCESS_KEY_ID"`
+	SESSecretAccessKey           string  `envconfig:"SES_SECRET_ACCESS_KEY"`
+	SendGridAPIKey               string  `envconfig:"SENDGRID_API_KEY"`
+	NATSURL                      string  `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	OutboxSubjectPrefix          string  `envconfig:"OUTBOX_SUBJECT_PREFIX" default:"bookms"`
+	OutboxPollIntervalSeconds    int     `envconfig:"OUTBOX_POLL_INTERVAL_SECONDS" default:"5"`
+	IdempotencyKeyTTLHours       int     `envconfig:"IDEMPOTENCY_KEY_TTL_HOURS" default:"24"`
+	TracingEnabled               bool    `envconfig:"TRACING_ENABLED" default:"false"`
+	OTLPEndpoint                 string  `envconfig:"OTLP_ENDPOINT" default:"localhost:4317"`
+	OTLPInsecure                 bool    `envconfig:"OTLP_INSECURE" default:"false"`
+	TracingSampleRatio           float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+	PprofEnabled                 bool    `envconfig:"PPROF_ENABLED" default:"false"`
+	RateLimitEnabled             bool    `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+	RateLimitAuthPerMinute       int     `envconfig:"RATE_LIMIT_AUTH_PER_MINUTE" default:"5"`
+	RateLimitUsersPerMinute      int     `envconfig:"RATE_LIMIT_USERS_PER_MINUTE" default:"100"`
+	RateLimitPerMinute           int     `envconfig:"RATE_LIMIT_PER_MINUTE" default:"200"`
+	PublicRateLimitPerMinute     int     `envconfig:"PUBLIC_RATE_LIMIT_PER_MINUTE" default:"60"`
+	PublicCacheMaxAgeSeconds     int     `envconfig:"PUBLIC_CACHE_MAX_AGE_SECONDS" default:"300"`
+	DefaultLanguage              string  `envconfig:"DEFAULT_LANGUAGE" default:"en"`
+	LocalesDir                   string  `envconfig:"LOCALES_DIR"`
+	LogLevel                     string  `envconfig:"LOG_LEVEL" default:"info"`
+	LogFormat                    string  `envconfig:"LOG_FORMAT" default:"text"`
+	LogModuleLevels              string  `envconfig:"LOG_MODULE_LEVELS"`
 }
 
 func (c *Config) DSN() string {
 	return fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC statement_timeout=%d",
 		c.DBHost,
 		c.DBPort,
 		c.DBUser,
 		c.DBPassword,
 		c.DBName,
+		c.DBStatementTimeoutSeconds*1000,
 	)
 }
 
+func (c *Config) SQLiteDSN() string {
+	separator := "?"
+	if strings.Contains(c.DBName, "?") {
+		separator = "&"
+	}
+	return c.DBName + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+func (c *Config) Dialector() (gorm.Dialector, error) {
+	switch c.DBDriver {
+	case migrations.DriverPostgres:
+		return postgres.Open(
+			c.DSN(),
+		), nil
+	case migrations.DriverSQLite:
+		return sqlite.Open(
+			c.SQLiteDSN(),
+		), nil
+	default:
+		return nil, fmt.Errorf("invalid DB_DRIVER %q, expected postgres or sqlite", c.DBDriver)
+	}
+}
+
 func (c *Config) ServerAddress() string {
 	return fmt.Sprintf(
 		"%s:%s",
@@ -52,29 +208,418 @@ func (c *Config) ServerAddress() string {
 	)
 }
 
+func (c *Config) AutocertDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(c.TLSAutocertDomains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// ObjectStore returns the S3 bucket the warehouse export and backups write
+// to.
+func (c *Config) ObjectStore() (*objectstore.S3, error) {
+	return objectstore.NewS3(objectstore.S3Config{
+		Endpoint:        c.S3Endpoint,
+		Region:          c.S3Region,
+		Bucket:          c.S3Bucket,
+		AccessKeyID:     c.S3AccessKeyID,
+		SecretAccessKey: c.S3SecretAccessKey,
+		PathStyle:       c.S3PathStyle,
+	})
+}
+
+// Mailer returns the MAILER_DRIVER mailer, or nil when it is off.
+func (c *Config) Mailer() (mailer.Mailer, error) {
+	switch c.MailerDriver {
+	case mailer.DriverOff:
+		return nil, nil
+	case mailer.DriverLog:
+		return mailer.NewLog(
+			logging.Module("mailer"),
+		), nil
+	case mailer.DriverSMTP:
+		return mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     c.SMTPHost,
+			Port:     c.SMTPPort,
+			Username: c.SMTPUsername,
+			Password: c.SMTPPassword,
+			From:     c.MailerFrom,
+		})
+	case mailer.DriverSES:
+		return mailer.NewSES(mailer.SESConfig{
+			Endpoint:        c.SESEndpoint,
+			Region:          c.SESRegion,
+			AccessKeyID:     c.SESAccessKeyID,
+			SecretAccessKey: c.SESSecretAccessKey,
+			From:            c.MailerFrom,
+		})
+	case mailer.DriverSendGrid:
+		return mailer.NewSendGrid(mailer.SendGridConfig{
+			APIKey: c.SendGridAPIKey,
+			From:   c.MailerFrom,
+		})
+	default:
+		return nil, fmt.Errorf("invalid MAILER_DRIVER %q, expected off, log, smtp, ses or sendgrid", c.MailerDriver)
+	}
+}
+
+// MetadataProviders returns the ENRICHMENT_PROVIDERS catalogs in the order
+// the enrichment job consults them.
+func (c *Config) MetadataProviders() ([]bookmeta.Provider, error) {
+	var providers []bookmeta.Provider
+	for _, name := range strings.Split(c.EnrichmentProviders, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case bookmeta.SourceGoogleBooks:
+			providers = append(providers, bookmeta.NewGoogleBooks(c.GoogleBooksAPIKey))
+		case bookmeta.SourceOpenLibrary:
+			providers = append(providers, bookmeta.NewOpenLibrary())
+		default:
+			return nil, fmt.Errorf("invalid ENRICHMENT_PROVIDERS entry %q, expected google_books or open_library", name)
+		}
+	}
+	return providers, nil
+}
+
+func (c *Config) GRPCAddress() string {
+	return fmt.Sprintf(
+		"%s:%s",
+		c.ServerHost,
+		c.GRPCPort,
+	)
+}
+
+func runSeed(db *gorm.DB) error {
+	report, err := seed.NewSeeder(
+		repositories.NewUserRepository(db),
+		repositories.NewMembershipPlanRepository(db),
+		repositories.NewBookRepository(db),
+	).Run(
+		context.Background(),
+	)
+	if err != nil {
+		return err
+	}
+	slog.Info(
+		"Seed completed",
+		"users_created", report.UsersCreated,
+		"users_skipped", report.UsersSkipped,
+		"books_created", report.BooksCreated,
+		"books_skipped", report.BooksSkipped,
+	)
+	return nil
+}
+
+// runRestore loads a backup from the object store. The database must have
+// been migrated at least as far as the backup's schema version.
+func runRestore(cfg *Config, db *gorm.DB, key string) error {
+	store, err := cfg.ObjectStore()
+	if err != nil {
+		return err
+	}
+	report, err := jobs.NewBackupJob(
+		repositories.NewBackupRepository(db),
+		store,
+		cfg.BackupPrefix,
+		0,
+	).Restore(
+		context.Background(),
+		key,
+	)
+	if err != nil {
+		return err
+	}
+	attrs := []any{
+		"key", report.Key,
+		"schema_version", report.SchemaVersion,
+	}
+	for _, table := range repositories.BackupTables {
+		attrs = append(attrs, table, report.Tables[table])
+	}
+	slog.Info(
+		"Restore completed",
+		attrs...,
+	)
+	return nil
+}
+
 func init() {
 	os.Setenv("TZ", "UTC")
 }
 
+// secretNames may also be supplied as BOOKMS_<NAME>_FILE or, apart from the
+// Vault token itself, from the Vault secret at BOOKMS_VAULT_SECRET_PATH.
+var secretNames = []string{
+	"DB_PASSWORD",
+	"JWT_SECRET",
+	"ADMIN_PASSWORD",
+	"S3_SECRET_ACCESS_KEY",
+	"WAREHOUSE_ANONYMIZATION_KEY",
+	"SMTP_PASSWORD",
+	"SES_SECRET_ACCESS_KEY",
+	"SENDGRID_API_KEY",
+	"GOOGLE_BOOKS_API_KEY",
+}
+
 func main() {
 
+	err := secrets.LoadFiles(
+		"BOOKMS",
+		append(secretNames, "VAULT_TOKEN")...,
+	)
+	if err != nil {
+		panic(err)
+	}
+	if addr := os.Getenv("BOOKMS_VAULT_ADDR"); addr != "" {
+		err := secrets.LoadVault(
+			context.Background(),
+			addr,
+			os.Getenv("BOOKMS_VAULT_TOKEN"),
+			os.Getenv("BOOKMS_VAULT_SECRET_PATH"),
+			"BOOKMS",
+			secretNames...,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if path := os.Getenv("BOOKMS_CONFIG_FILE"); path != "" {
+		err := configfile.Load(
+			path,
+			"BOOKMS",
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	var cfg Config
-	err := envconfig.Process(
+	err = envconfig.Process(
 		"BOOKMS",
 		&cfg,
 	)
 	if err != nil {
 		panic(err)
 	}
+	if cfg.DBDriver == migrations.DriverPostgres && cfg.DBPassword == "" {
+		panic(fmt.Errorf("DB_PASSWORD is required when DB_DRIVER is postgres"))
+	}
+	if cfg.InactiveAccountAction != jobs.InactiveAccountActionFlag && cfg.InactiveAccountAction != jobs.InactiveAccountActionDeactivate {
+		panic(fmt.Errorf("invalid INACTIVE_ACCOUNT_ACTION %q", cfg.InactiveAccountAction))
+	}
+	if cfg.InactiveAccountDays <= 0 || cfg.InactiveAccountIntervalHours <= 0 {
+		panic(fmt.Errorf("INACTIVE_ACCOUNT_DAYS and INACTIVE_ACCOUNT_INTERVAL_HOURS must be positive"))
+	}
+	if cfg.RetentionBookDays < 0 || cfg.RetentionUserDays < 0 {
+		panic(fmt.Errorf("RETENTION_BOOK_DAYS and RETENTION_USER_DAYS must not be negative"))
+	}
+	if cfg.RetentionIntervalHours <= 0 {
+		panic(fmt.Errorf("RETENTION_INTERVAL_HOURS must be positive"))
+	}
+	if cfg.DailyStatsIntervalHours <= 0 {
+		panic(fmt.Errorf("DAILY_STATS_INTERVAL_HOURS must be positive"))
+	}
+	if cfg.SavedReportPollMinutes <= 0 {
+		panic(fmt.Errorf("SAVED_REPORT_POLL_MINUTES must be positive"))
+	}
+	if cfg.LowStockThreshold < 0 {
+		panic(fmt.Errorf("LOW_STOCK_THRESHOLD must not be negative"))
+	}
+	if cfg.LowStockCheckMinutes <= 0 {
+		panic(fmt.Errorf("LOW_STOCK_CHECK_MINUTES must be positive"))
+	}
+	if cfg.SavedSearchAlertMinutes <= 0 {
+		panic(fmt.Errorf("SAVED_SEARCH_ALERT_MINUTES must be positive"))
+	}
+	if cfg.LoanReminderMinutes <= 0 {
+		panic(fmt.Errorf("LOAN_REMINDER_MINUTES must be positive"))
+	}
+	if cfg.WarehouseExportEnabled {
+		if cfg.WarehouseExportIntervalHours <= 0 {
+			panic(fmt.Errorf("WAREHOUSE_EXPORT_INTERVAL_HOURS must be positive"))
+		}
+		if len(cfg.WarehouseAnonymizationKey) < 32 {
+			panic(fmt.Errorf("WAREHOUSE_ANONYMIZATION_KEY must be at least 32 characters when WAREHOUSE_EXPORT_ENABLED is true"))
+		}
+		if cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			panic(fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when WAREHOUSE_EXPORT_ENABLED is true"))
+		}
+	}
+	if cfg.BackupEnabled {
+		if cfg.BackupIntervalHours <= 0 {
+			panic(fmt.Errorf("BACKUP_INTERVAL_HOURS must be positive"))
+		}
+		if cfg.BackupRetentionDays < 0 {
+			panic(fmt.Errorf("BACKUP_RETENTION_DAYS must not be negative"))
+		}
+		if cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			panic(fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when BACKUP_ENABLED is true"))
+		}
+	}
+	if cfg.EbooksEnabled {
+		if cfg.EbookMaxBytes <= 0 {
+			panic(fmt.Errorf("EBOOK_MAX_BYTES must be positive"))
+		}
+		// S3 rejects presigned URLs valid for more than seven days.
+		if cfg.EbookDownloadURLMinutes <= 0 || cfg.EbookDownloadURLMinutes > 7*24*60 {
+			panic(fmt.Errorf("EBOOK_DOWNLOAD_URL_MINUTES must be between 1 and 10080"))
+		}
+		if cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			panic(fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when EBOOKS_ENABLED is true"))
+		}
+	}
+	if cfg.CoversEnabled {
+		if cfg.CoverMaxBytes <= 0 {
+			panic(fmt.Errorf("COVER_MAX_BYTES must be positive"))
+		}
+		if cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			panic(fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required when COVERS_ENABLED is true"))
+		}
+	}
+	if cfg.ReservationWindowDays < 1 || cfg.ReservationWindowDays > 365 {
+		panic(fmt.Errorf("RESERVATION_WINDOW_DAYS must be between 1 and 365"))
+	}
+	if cfg.DeviceTokenExpiryDays < 1 || cfg.DeviceTokenExpiryDays > 3650 {
+		panic(fmt.Errorf("DEVICE_TOKEN_EXPIRY_DAYS must be between 1 and 3650"))
+	}
+	if (cfg.AdminEmail == "") != (cfg.AdminPassword == "") {
+		panic(fmt.Errorf("ADMIN_EMAIL and ADMIN_PASSWORD must be set together"))
+	}
+	if cfg.AdminPassword != "" && len(cfg.AdminPassword) < 8 {
+		panic(fmt.Errorf("ADMIN_PASSWORD must be at least 8 characters"))
+	}
+	switch cfg.TLSMode {
+	case tlsModeOff:
+	case tlsModeFile:
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			panic(fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE are required when TLS_MODE is file"))
+		}
+	case tlsModeAutocert:
+		if len(cfg.AutocertDomains()) == 0 || cfg.TLSAutocertCacheDir == "" {
+			panic(fmt.Errorf("TLS_AUTOCERT_DOMAINS and TLS_AUTOCERT_CACHE_DIR are required when TLS_MODE is autocert"))
+		}
+	default:
+		panic(fmt.Errorf("invalid TLS_MODE %q, expected off, file or autocert", cfg.TLSMode))
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		panic(fmt.Errorf("MAX_BODY_BYTES must be positive"))
+	}
+	if cfg.MaxPageSize <= 0 {
+		panic(fmt.Errorf("MAX_PAGE_SIZE must be positive"))
+	}
+	if cfg.OutboxPollIntervalSeconds <= 0 {
+		panic(fmt.Errorf("OUTBOX_POLL_INTERVAL_SECONDS must be positive"))
+	}
+	if cfg.DBStatementTimeoutSeconds < 0 {
+		panic(fmt.Errorf("DB_STATEMENT_TIMEOUT_SECONDS must not be negative"))
+	}
+	if cfg.DBBreakerEnabled && (cfg.DBBreakerFailureThreshold <= 0 || cfg.DBBreakerCooldownSeconds <= 0) {
+		panic(fmt.Errorf("DB_BREAKER_FAILURE_THRESHOLD and DB_BREAKER_COOLDOWN_SECONDS must be positive"))
+	}
+	if cfg.DBSlowQueryThresholdMs < 0 {
+		panic(fmt.Errorf("DB_SLOW_QUERY_THRESHOLD_MS must not be negative"))
+	}
+	if cfg.QueryStatsMaxStatements <= 0 {
+		panic(fmt.Errorf("QUERY_STATS_MAX_STATEMENTS must be positive"))
+	}
+	if cfg.IdempotencyKeyTTLHours <= 0 {
+		panic(fmt.Errorf("IDEMPOTENCY_KEY_TTL_HOURS must be positive"))
+	}
+	if cfg.RateLimitEnabled && (cfg.RateLimitAuthPerMinute <= 0 || cfg.RateLimitUsersPerMinute <= 0 || cfg.RateLimitPerMinute <= 0 || cfg.PublicRateLimitPerMinute <= 0) {
+		panic(fmt.Errorf("RATE_LIMIT_*_PER_MINUTE values must be positive"))
+	}
+	if cfg.PublicCacheMaxAgeSeconds < 0 {
+		panic(fmt.Errorf("PUBLIC_CACHE_MAX_AGE_SECONDS must not be negative"))
+	}
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		panic(fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1"))
+	}
+
+	logLevel, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		panic(fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+	moduleLevels, err := logging.ParseModuleLevels(cfg.LogModuleLevels)
+	if err != nil {
+		panic(fmt.Errorf("LOG_MODULE_LEVELS: %w", err))
+	}
+	logLevels := logging.NewLevels(
+		logLevel,
+		moduleLevels,
+	)
+	logHandler, err := logging.NewHandler(
+		os.Stderr,
+		cfg.LogFormat,
+		logLevels,
+	)
+	if err != nil {
+		panic(fmt.Errorf("LOG_FORMAT: %w", err))
+	}
+	slog.SetDefault(
+		slog.New(logHandler),
+	)
+
+	emailMailer, err := cfg.Mailer()
+	if err != nil {
+		panic(err)
+	}
 
-	gormLogger := slogGorm.New()
+	metadataProviders, err := cfg.MetadataProviders()
+	if err != nil {
+		panic(err)
+	}
 
-	db, err := gorm.Open(
-		postgres.Open(
-			cfg.DSN(),
+	catalog, err := i18n.Load(locales.Files, cfg.DefaultLanguage)
+	if err != nil {
+		panic(err)
+	}
+	if cfg.LocalesDir != "" {
+		if err := catalog.Merge(os.DirFS(cfg.LocalesDir)); err != nil {
+			panic(err)
+		}
+	}
+	if !slices.Contains(catalog.Languages(), catalog.DefaultLanguage()) {
+		panic(fmt.Errorf("DEFAULT_LANGUAGE %q has no message catalog", cfg.DefaultLanguage))
+	}
+
+	if cfg.TracingEnabled {
+		shutdownTracing, err := telemetry.SetupTracing(
+			context.Background(),
+			"server_api",
+			cfg.OTLPEndpoint,
+			cfg.OTLPInsecure,
+			cfg.TracingSampleRatio,
+		)
+		if err != nil {
+			panic(err)
+		}
+		defer shutdownTracing(context.Background())
+	}
+
+	slowQueryThreshold := time.Duration(cfg.DBSlowQueryThresholdMs) * time.Millisecond
+	gormLogger := slogGorm.New(
+		slogGorm.WithHandler(
+			logging.Module("gorm").Handler(),
+		),
+		slogGorm.WithSlowThreshold(
+			slowQueryThreshold,
 		),
+	)
+
+	dialector, err := cfg.Dialector()
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := gorm.Open(
+		dialector,
 		&gorm.Config{
-			Logger: gormLogger,
+			Logger:         gormLogger,
+			TranslateError: true,
 			NowFunc: func() time.Time {
 				return time.Now().UTC()
 			},
@@ -84,6 +629,18 @@ func main() {
 		panic(err)
 	}
 
+	if cfg.TracingEnabled {
+		err = db.Use(
+			tracing.NewPlugin(
+				tracing.WithoutMetrics(),
+				tracing.WithoutQueryVariables(),
+			),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		panic(err)
@@ -115,7 +672,93 @@ func main() {
 		"conn_max_lifetime", cfg.DBConnMaxLifetime,
 	)
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			command := "up"
+			if len(os.Args) > 2 {
+				command = os.Args[2]
+			}
+			err = migrations.Run(
+				context.Background(),
+				sqlDB,
+				cfg.DBDriver,
+				command,
+			)
+		case "seed":
+			err = runSeed(db)
+		case "restore":
+			if len(os.Args) < 3 {
+				err = fmt.Errorf("restore needs the key of the backup to restore")
+				break
+			}
+			err = runRestore(&cfg, db, os.Args[2])
+		default:
+			err = fmt.Errorf("unknown command %q, expected migrate, seed or restore", os.Args[1])
+		}
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if cfg.MigrateOnStartup {
+		err = migrations.Up(
+			context.Background(),
+			sqlDB,
+			cfg.DBDriver,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	queryStats := querystats.NewRecorder(
+		slowQueryThreshold,
+		cfg.QueryStatsMaxStatements,
+	)
+	err = repositories.RegisterQueryStats(
+		db,
+		queryStats,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	var dbBreaker *circuitbreaker.Breaker
+	if cfg.DBBreakerEnabled {
+		dbBreaker = circuitbreaker.New(
+			cfg.DBBreakerFailureThreshold,
+			time.Duration(cfg.DBBreakerCooldownSeconds)*time.Second,
+		)
+		err = repositories.RegisterCircuitBreaker(
+			db,
+			dbBreaker,
+		)
+		if err != nil {
+			panic(err)
+		}
+		expvar.Publish(
+			"db_circuit_breaker",
+			expvar.Func(func() any {
+				return dbBreaker.Snapshot()
+			}),
+		)
+	}
+
 	e := echo.New()
+	e.Validator = apis.NewRequestValidator()
+	e.JSONSerializer = apis.NewStrictJSONSerializer()
+	e.HTTPErrorHandler = apis.HTTPErrorHandler
+	e.Use(
+		apis.Localize(catalog),
+	)
+	if cfg.TracingEnabled {
+		e.Use(
+			otelecho.Middleware("server_api"),
+		)
+	}
+	httpLogger := logging.Module("http")
 	e.Use(
 		middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 			LogStatus:   true,
@@ -125,8 +768,10 @@ func main() {
 			LogMethod:   true,
 			LogRemoteIP: true,
 			LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-				if v.Error == nil {
-					slog.InfoContext(c.Request().Context(), "request",
+				// Unmatched routes and auth rejections come back as errors
+				// too; only server errors are worth an error log.
+				if v.Error == nil || v.Status < http.StatusInternalServerError {
+					httpLogger.InfoContext(c.Request().Context(), "request",
 						"method", v.Method,
 						"uri", v.URI,
 						"status", v.Status,
@@ -134,7 +779,7 @@ func main() {
 						"remote_ip", v.RemoteIP,
 					)
 				} else {
-					slog.ErrorContext(c.Request().Context(), "request_error",
+					httpLogger.ErrorContext(c.Request().Context(), "request_error",
 						"method", v.Method,
 						"uri", v.URI,
 						"status", v.Status,
@@ -150,55 +795,955 @@ func main() {
 	e.Use(
 		middleware.Recover(),
 	)
+	e.Use(
+		bodylimit.MiddlewareWithSkipper(
+			cfg.MaxBodyBytes,
+			// Ebook uploads are limited to EBOOK_MAX_BYTES by their route.
+			func(c echo.Context) bool {
+				return c.Request().Method == http.MethodPut && c.Path() == "/api/v1/admin/books/:id/files/:format"
+			},
+		),
+	)
+	e.Use(
+		httpcompress.Middleware(func(c echo.Context) bool {
+			path := c.Request().URL.Path
+			return strings.HasPrefix(path, "/debug/") || strings.HasSuffix(path, "/events/stream")
+		}),
+	)
 
 	userRepo := repositories.NewUserRepository(db)
 	bookRepo := repositories.NewBookRepository(db)
+	planRepo := repositories.NewMembershipPlanRepository(db)
+	userNoteRepo := repositories.NewUserNoteRepository(db)
+	userMergeRepo := repositories.NewUserMergeRepository(db)
+	retentionRepo := repositories.NewRetentionRepository(db)
+	loginEventRepo := repositories.NewLoginEventRepository(db)
+	listRepo := repositories.NewListRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	suggestionRepo := repositories.NewSuggestionRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyKeyRepository(db)
+	branchRepo := repositories.NewBranchRepository(db)
+	transferRepo := repositories.NewTransferRepository(db)
+	cashDrawerRepo := repositories.NewCashDrawerRepository(db)
+	catalogSnapshotRepo := repositories.NewCatalogSnapshotRepository(db)
+	dailyStatRepo := repositories.NewDailyStatRepository(db)
+	auditLogRepo := repositories.NewAuditLogRepository(db)
+	warehouseExportRepo := repositories.NewWarehouseExportRepository(db)
+	exportRepo := repositories.NewExportRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	backupRepo := repositories.NewBackupRepository(db)
+	bookFileRepo := repositories.NewBookFileRepository(db)
+	bookCoverRepo := repositories.NewBookCoverRepository(db)
+	digitalLoanRepo := repositories.NewDigitalLoanRepository(db)
+	loanReminderRepo := repositories.NewLoanReminderRepository(db)
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(db)
+	libraryEventRepo := repositories.NewLibraryEventRepository(db)
+	resourceRepo := repositories.NewResourceRepository(db)
+	reservationRepo := repositories.NewReservationRepository(db)
+	deviceRepo := repositories.NewDeviceRepository(db)
+	searchTermRepo := repositories.NewSearchTermRepository(db)
+	savedReportRepo := repositories.NewSavedReportRepository(db)
+	savedSearchRepo := repositories.NewSavedSearchRepository(db)
+	alertWebhookRepo := repositories.NewAlertWebhookRepository(db)
+	bookEnrichmentRepo := repositories.NewBookEnrichmentRepository(db)
 	jwtAuth := auth.NewJWT(
 		cfg.JWTSecret,
 		cfg.JWTExpiryHours,
 		cfg.JWTRefreshExpiryHours,
+		auth.WithIssuer(cfg.JWTIssuer),
+		auth.WithAudience(cfg.JWTAudience),
+	)
+
+	natsPublisher, err := events.NewNATSPublisher(
+		cfg.NATSURL,
 	)
+	if err != nil {
+		panic(err)
+	}
+	defer natsPublisher.Close()
 
 	rootg := e.Group("")
-	apis.NewHealthzAPI(
+	healthzAPI := apis.NewHealthzAPI(
 		db,
-	).Setup(
+	).AddCheck(
+		"nats",
+		natsPublisher.Ping,
+	)
+	if dbBreaker != nil {
+		healthzAPI.AddCircuitBreaker(
+			"database",
+			dbBreaker,
+		)
+	}
+	healthzAPI.Setup(
 		rootg,
 	)
 
+	authMw := auth.NewMiddleware(
+		jwtAuth,
+		auth.WithDeviceCheck(
+			apis.CheckDevice(
+				deviceRepo,
+			),
+		),
+	)
+	idempotency := apis.NewIdempotency(
+		idempotencyRepo,
+		authMw,
+		time.Duration(cfg.IdempotencyKeyTTLHours)*time.Hour,
+	)
+
+	var v1Limits, authLimits, usersLimits, publicLimits []echo.MiddlewareFunc
+	if cfg.RateLimitEnabled {
+		v1Limits = append(
+			v1Limits,
+			ratelimit.PerUser(
+				cfg.RateLimitPerMinute,
+				authMw.UserID,
+			),
+		)
+		authLimits = append(
+			authLimits,
+			ratelimit.PerIP(
+				cfg.RateLimitAuthPerMinute,
+			),
+		)
+		usersLimits = append(
+			usersLimits,
+			ratelimit.PerUser(
+				cfg.RateLimitUsersPerMinute,
+				authMw.UserID,
+			),
+		)
+		publicLimits = append(
+			publicLimits,
+			ratelimit.PerIP(
+				cfg.PublicRateLimitPerMinute,
+			),
+		)
+	}
+
 	apiGroup := e.Group("/api")
-	v1Group := apiGroup.Group("/v1")
+	v1Group := apiGroup.Group("/v1", v1Limits...)
+	v1Group.Use(
+		apis.UserTimezone(
+			userRepo,
+			authMw,
+		),
+	)
+	v1Group.Use(
+		apis.PageSize(
+			cfg.MaxPageSize,
+		),
+	)
+	if dbBreaker != nil {
+		v1Group.Use(
+			dbBreaker.Middleware(),
+		)
+	}
+	v1Group.Use(
+		apis.Audit(
+			auditLogRepo,
+			authMw,
+		),
+	)
+
+	if cfg.PprofEnabled {
+		debugGroup := e.Group(
+			"/debug",
+			authMw.RequireAuth(),
+			authMw.RequireAdmin(),
+		)
+		apis.NewDebugAPI().Setup(
+			debugGroup,
+		)
+	}
 
-	authMw := auth.NewMiddleware(jwtAuth)
+	setupAPI := apis.NewSetupAPI(
+		userRepo,
+		planRepo,
+	)
+	setupToken, err := setupAPI.Bootstrap(
+		context.Background(),
+		cfg.AdminEmail,
+		cfg.AdminPassword,
+	)
+	if err != nil {
+		panic(err)
+	}
+	if setupToken != "" {
+		slog.Warn(
+			"No users exist; create the first admin with POST /api/v1/auth/setup",
+			"setup_token", setupToken,
+		)
+	}
 
-	authGroup := v1Group.Group("/auth")
+	branchAccess := apis.NewBranchAccess(
+		branchRepo,
+		userRepo,
+		authMw,
+	)
+
+	authGroup := v1Group.Group("/auth", authLimits...)
+	setupAPI.Setup(
+		authGroup,
+	)
 	apis.NewAuthAPI(
 		userRepo,
+		planRepo,
+		loginEventRepo,
+		branchRepo,
 		jwtAuth,
+		idempotency,
 	).Setup(
 		authGroup,
 	)
 
-	usersGroup := v1Group.Group("/users")
+	usersGroup := v1Group.Group("/users", usersLimits...)
 	apis.NewUserAPI(
 		userRepo,
+		planRepo,
+		userNoteRepo,
+		loginEventRepo,
 		authMw,
+		branchAccess,
 	).Setup(
 		usersGroup,
 	)
 
 	booksGroup := v1Group.Group("/books")
-	apis.NewBookAPI(
+	bookAPI := apis.NewBookAPI(
+		bookRepo,
+		searchTermRepo,
+		authMw,
+		idempotency,
+		branchAccess,
+	)
+	bookAPI.Setup(
+		booksGroup,
+	)
+
+	searchGroup := v1Group.Group("/search")
+	apis.NewSearchAPI(
+		bookRepo,
+		searchRepo,
+		branchAccess,
+	).Setup(
+		searchGroup,
+	)
+
+	notificationChannels := []notifications.Channel{
+		notifications.NewInAppChannel(notificationRepo),
+	}
+	if emailMailer != nil {
+		notificationChannels = append(
+			notificationChannels,
+			notifications.NewEmailChannel(userRepo, emailMailer),
+		)
+	}
+	notifier := notifications.NewNotifier(
+		notificationRepo,
+		userRepo,
+		notificationChannels...,
+	)
+
+	var ebookStore objectstore.Store
+	if cfg.EbooksEnabled {
+		store, err := cfg.ObjectStore()
+		if err != nil {
+			panic(err)
+		}
+		ebookStore = store
+	}
+	ebookAPI := apis.NewEbookAPI(
+		bookFileRepo,
+		digitalLoanRepo,
+		bookRepo,
+		userRepo,
+		planRepo,
+		ebookStore,
+		cfg.EbookPrefix,
+		cfg.EbookMaxBytes,
+		time.Duration(cfg.EbookDownloadURLMinutes)*time.Minute,
+		notifier,
+		authMw,
+	)
+	ebookAPI.SetupAvailability(
+		booksGroup,
+	)
+
+	var coverStore objectstore.Store
+	var coverImportJob *jobs.CoverImportJob
+	if cfg.CoversEnabled {
+		store, err := cfg.ObjectStore()
+		if err != nil {
+			panic(err)
+		}
+		coverStore = store
+		coverImportJob = jobs.NewCoverImportJob(
+			bookRepo,
+			bookCoverRepo,
+			coverStore,
+			cfg.CoverPrefix,
+			cfg.CoverMaxBytes,
+		)
+	}
+	bookCoverAPI := apis.NewBookCoverAPI(
+		bookCoverRepo,
+		bookRepo,
+		coverStore,
+		coverImportJob,
+	)
+	bookCoverAPI.SetupCover(
+		booksGroup,
+	)
+
+	branchAPI := apis.NewBranchAPI(
+		branchRepo,
+		bookRepo,
+		transferRepo,
+		branchAccess,
+		authMw,
+	)
+	branchAPI.SetupHoldings(
+		booksGroup,
+	)
+
+	branchesGroup := v1Group.Group("/branches")
+	branchAPI.Setup(
+		branchesGroup,
+	)
+
+	libraryEventAPI := apis.NewLibraryEventAPI(
+		libraryEventRepo,
+		userRepo,
+		branchAccess,
+		authMw,
+	)
+
+	libraryEventsGroup := v1Group.Group("/library-events")
+	libraryEventAPI.Setup(
+		libraryEventsGroup,
+	)
+
+	resourceAPI := apis.NewResourceAPI(
+		resourceRepo,
+		reservationRepo,
+		userRepo,
+		planRepo,
+		branchAccess,
+		time.Duration(cfg.ReservationWindowDays)*24*time.Hour,
+		authMw,
+	)
+
+	resourcesGroup := v1Group.Group("/resources")
+	resourceAPI.Setup(
+		resourcesGroup,
+	)
+
+	// Kiosks authenticate with device tokens, which RequireAuth refuses, so
+	// they reach nothing outside this group.
+	kioskGroup := v1Group.Group(
+		"/kiosk",
+		authMw.RequireDevice(models.DeviceScopeSearch),
+	)
+	kioskBooksGroup := kioskGroup.Group("/books")
+	bookAPI.SetupKiosk(
+		kioskBooksGroup,
+	)
+
+	// The public catalog sits beside /v1 rather than under it, so it skips
+	// the per-user limit and audit log and is limited per IP instead.
+	// PublicCatalog comes first so rate-limited responses are not cached.
+	publicGroup := apiGroup.Group(
+		"/v1/public",
+		apis.PublicCatalog(
+			time.Duration(cfg.PublicCacheMaxAgeSeconds)*time.Second,
+		),
+	)
+	publicGroup.Use(
+		publicLimits...,
+	)
+	publicGroup.Use(
+		apis.PageSize(
+			cfg.MaxPageSize,
+		),
+	)
+	if dbBreaker != nil {
+		publicGroup.Use(
+			dbBreaker.Middleware(),
+		)
+	}
+	publicBooksGroup := publicGroup.Group("/books")
+	bookAPI.SetupPublic(
+		publicBooksGroup,
+	)
+	ebookAPI.SetupAvailability(
+		publicBooksGroup,
+	)
+	bookCoverAPI.SetupCover(
+		publicBooksGroup,
+	)
+	branchAPI.SetupPublic(
+		publicGroup,
+	)
+	libraryEventAPI.SetupPublic(
+		publicGroup,
+	)
+	apis.NewReviewAPI(
+		reviewRepo,
 		bookRepo,
+		userRepo,
+		digitalLoanRepo,
 		authMw,
 	).Setup(
 		booksGroup,
 	)
 
-	slog.Info("Server starting", "address", cfg.ServerAddress())
-	err = e.Start(
-		cfg.ServerAddress(),
+	feedsGroup := v1Group.Group("/feeds")
+	apis.NewFeedAPI(
+		bookRepo,
+	).Setup(
+		feedsGroup,
+	)
+
+	sruGroup := v1Group.Group("/sru")
+	apis.NewSRUAPI(
+		bookRepo,
+	).Setup(
+		sruGroup,
+	)
+
+	suggestionAPI := apis.NewSuggestionAPI(
+		suggestionRepo,
+		bookRepo,
+		authMw,
+	)
+
+	suggestionsGroup := v1Group.Group(
+		"/suggestions",
+		authMw.RequireAuth(),
+	)
+	suggestionAPI.Setup(
+		suggestionsGroup,
+	)
+
+	listAPI := apis.NewListAPI(
+		listRepo,
+		bookRepo,
+		authMw,
+	)
+
+	meGroup := v1Group.Group(
+		"/me",
+		authMw.RequireAuth(),
+	)
+
+	meListsGroup := meGroup.Group("/lists")
+	listAPI.Setup(
+		meListsGroup,
+	)
+
+	meNotificationsGroup := meGroup.Group("/notifications")
+	apis.NewNotificationAPI(
+		notificationRepo,
+		notifier,
+		authMw,
+	).Setup(
+		meNotificationsGroup,
+	)
+
+	meDigitalLoansGroup := meGroup.Group("/digital-loans")
+	ebookAPI.SetupLoans(
+		meDigitalLoansGroup,
+	)
+
+	calendarFeedAPI := apis.NewCalendarFeedAPI(
+		calendarFeedRepo,
+		digitalLoanRepo,
+		bookRepo,
+		userRepo,
+		authMw,
+	)
+	meCalendarFeedGroup := meGroup.Group("/calendar-feed")
+	calendarFeedAPI.Setup(
+		meCalendarFeedGroup,
+	)
+	calendarFeedAPI.SetupFeed(
+		v1Group,
+	)
+
+	meHistoryGroup := meGroup.Group("/history")
+	apis.NewReadingHistoryAPI(
+		digitalLoanRepo,
+		bookRepo,
+		userRepo,
+		authMw,
+	).Setup(
+		meHistoryGroup,
+	)
+
+	meRSVPsGroup := meGroup.Group("/rsvps")
+	libraryEventAPI.SetupRSVPs(
+		meRSVPsGroup,
 	)
+
+	meReservationsGroup := meGroup.Group("/reservations")
+	resourceAPI.SetupReservations(
+		meReservationsGroup,
+	)
+
+	meSavedSearchesGroup := meGroup.Group("/saved-searches")
+	apis.NewSavedSearchAPI(
+		savedSearchRepo,
+		bookRepo,
+		authMw,
+	).Setup(
+		meSavedSearchesGroup,
+	)
+	savedSearchAlertJob := jobs.NewSavedSearchAlertJob(
+		savedSearchRepo,
+		bookRepo,
+		notifier,
+	)
+	go savedSearchAlertJob.Start(
+		context.Background(),
+		time.Duration(cfg.SavedSearchAlertMinutes)*time.Minute,
+	)
+	loanReminderJob := jobs.NewLoanReminderJob(
+		digitalLoanRepo,
+		loanReminderRepo,
+		bookRepo,
+		notifier,
+	)
+	go loanReminderJob.Start(
+		context.Background(),
+		time.Duration(cfg.LoanReminderMinutes)*time.Minute,
+	)
+
+	sharedListsGroup := v1Group.Group("/lists/shared")
+	listAPI.SetupShared(
+		sharedListsGroup,
+	)
+
+	adminGroup := v1Group.Group(
+		"/admin",
+		authMw.RequireAuth(),
+		authMw.RequireAdmin(),
+	)
+
+	inactiveAccountJob := jobs.NewInactiveAccountJob(
+		userRepo,
+		cfg.InactiveAccountDays,
+		cfg.InactiveAccountAction,
+		cfg.InactiveAccountDryRun,
+	)
+	go inactiveAccountJob.Start(
+		context.Background(),
+		time.Duration(cfg.InactiveAccountIntervalHours)*time.Hour,
+	)
+
+	retentionPolicy := jobs.RetentionPolicy{
+		BookDays: cfg.RetentionBookDays,
+		UserDays: cfg.RetentionUserDays,
+	}
+	retentionJob := jobs.NewRetentionJob(
+		retentionRepo,
+		retentionPolicy,
+		cfg.RetentionDryRun,
+	)
+	go retentionJob.Start(
+		context.Background(),
+		time.Duration(cfg.RetentionIntervalHours)*time.Hour,
+	)
+
+	dailyStatsJob := jobs.NewDailyStatsJob(
+		dailyStatRepo,
+	)
+	go dailyStatsJob.Start(
+		context.Background(),
+		time.Duration(cfg.DailyStatsIntervalHours)*time.Hour,
+	)
+
+	var warehouseExportJob *jobs.WarehouseExportJob
+	if cfg.WarehouseExportEnabled {
+		store, err := cfg.ObjectStore()
+		if err != nil {
+			panic(err)
+		}
+		warehouseExportJob = jobs.NewWarehouseExportJob(
+			warehouseExportRepo,
+			store,
+			cfg.WarehouseExportPrefix,
+			cfg.WarehouseAnonymizationKey,
+		)
+		go warehouseExportJob.Start(
+			context.Background(),
+			time.Duration(cfg.WarehouseExportIntervalHours)*time.Hour,
+		)
+	}
+
+	var backupJob *jobs.BackupJob
+	if cfg.BackupEnabled {
+		store, err := cfg.ObjectStore()
+		if err != nil {
+			panic(err)
+		}
+		backupJob = jobs.NewBackupJob(
+			backupRepo,
+			store,
+			cfg.BackupPrefix,
+			time.Duration(cfg.BackupRetentionDays)*24*time.Hour,
+		)
+		go backupJob.Start(
+			context.Background(),
+			time.Duration(cfg.BackupIntervalHours)*time.Hour,
+		)
+	}
+
+	go jobs.NewIdempotencyPurgeJob(
+		idempotencyRepo,
+	).Start(
+		context.Background(),
+		time.Hour,
+	)
+
+	go jobs.NewReadingHistoryPurgeJob(
+		digitalLoanRepo,
+	).Start(
+		context.Background(),
+		time.Hour,
+	)
+
+	outboxRelay := jobs.NewOutboxRelay(
+		outboxRepo,
+		natsPublisher,
+		cfg.OutboxSubjectPrefix,
+	)
+	go outboxRelay.Start(
+		context.Background(),
+		time.Duration(cfg.OutboxPollIntervalSeconds)*time.Second,
+	)
+
+	broker := events.NewBroker()
+	bookEventsPrefix := cfg.OutboxSubjectPrefix + "."
+	unsubscribeBookEvents, err := natsPublisher.Subscribe(
+		bookEventsPrefix+"book.>",
+		func(subject string, data []byte) {
+			broker.Broadcast(events.Message{
+				Event: strings.TrimPrefix(subject, bookEventsPrefix),
+				Data:  data,
+			})
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer unsubscribeBookEvents()
+
+	eventsGroup := v1Group.Group("/events")
+	apis.NewEventAPI(
+		broker,
+	).Setup(
+		eventsGroup,
+	)
+
+	alerter := alerts.NewAlerter(
+		alertWebhookRepo,
+	)
+
+	adminAlertWebhooksGroup := adminGroup.Group("/alerts/webhooks")
+	apis.NewAlertWebhookAPI(
+		alertWebhookRepo,
+		alerter,
+	).Setup(
+		adminAlertWebhooksGroup,
+	)
+
+	lowStockAlertJob := jobs.NewLowStockAlertJob(
+		bookRepo,
+		alerter,
+		cfg.LowStockThreshold,
+	)
+	go lowStockAlertJob.Start(
+		context.Background(),
+		time.Duration(cfg.LowStockCheckMinutes)*time.Minute,
+	)
+
+	metadataEnrichmentJob := jobs.NewMetadataEnrichmentJob(
+		bookEnrichmentRepo,
+		metadataProviders,
+	)
+
+	adminEnrichmentsGroup := adminGroup.Group("/enrichments")
+	apis.NewBookEnrichmentAPI(
+		bookEnrichmentRepo,
+		bookRepo,
+		authMw,
+	).Setup(
+		adminEnrichmentsGroup,
+	)
+
+	jobsGroup := adminGroup.Group("/jobs")
+	apis.NewJobAPI(
+		inactiveAccountJob,
+		dailyStatsJob,
+		warehouseExportJob,
+		lowStockAlertJob,
+		metadataEnrichmentJob,
+		savedSearchAlertJob,
+		loanReminderJob,
+		retentionJob,
+	).Setup(
+		jobsGroup,
+	)
+
+	adminSuggestionsGroup := adminGroup.Group("/suggestions")
+	suggestionAPI.SetupAdmin(
+		adminSuggestionsGroup,
+	)
+
+	adminBooksGroup := adminGroup.Group("/books")
+	ebookAPI.SetupFiles(
+		adminBooksGroup,
+	)
+	bookCoverAPI.SetupImport(
+		adminBooksGroup,
+	)
+
+	adminBranchesGroup := adminGroup.Group("/branches")
+	branchAPI.SetupAdmin(
+		adminBranchesGroup,
+	)
+
+	adminLibraryEventsGroup := adminGroup.Group("/library-events")
+	libraryEventAPI.SetupAdmin(
+		adminLibraryEventsGroup,
+	)
+
+	adminResourcesGroup := adminGroup.Group("/resources")
+	resourceAPI.SetupAdmin(
+		adminResourcesGroup,
+	)
+
+	adminUsersGroup := adminGroup.Group("/users")
+	apis.NewUserMergeAPI(
+		userMergeRepo,
+		branchAccess,
+	).Setup(
+		adminUsersGroup,
+	)
+
+	adminTrashGroup := adminGroup.Group("/trash")
+	apis.NewRetentionAPI(
+		retentionRepo,
+		branchAccess,
+		retentionPolicy,
+	).Setup(
+		adminTrashGroup,
+	)
+
+	adminExportsGroup := adminGroup.Group("/exports")
+	apis.NewExportAPI(
+		exportRepo,
+		branchAccess,
+	).Setup(
+		adminExportsGroup,
+	)
+
+	adminDevicesGroup := adminGroup.Group("/devices")
+	apis.NewDeviceAPI(
+		deviceRepo,
+		branchAccess,
+		jwtAuth,
+		time.Duration(cfg.DeviceTokenExpiryDays)*24*time.Hour,
+		authMw,
+	).Setup(
+		adminDevicesGroup,
+	)
+
+	cashDrawerAPI := apis.NewCashDrawerAPI(
+		cashDrawerRepo,
+		userRepo,
+		branchAccess,
+		authMw,
+	)
+	adminCashDrawersGroup := adminGroup.Group("/cash-drawers")
+	cashDrawerAPI.Setup(
+		adminCashDrawersGroup,
+	)
+
+	catalogSnapshotAPI := apis.NewCatalogSnapshotAPI(
+		catalogSnapshotRepo,
+		branchAccess,
+		authMw,
+	)
+	adminCatalogSnapshotsGroup := adminGroup.Group("/catalog-snapshots")
+	catalogSnapshotAPI.Setup(
+		adminCatalogSnapshotsGroup,
+	)
+
+	transfersGroup := adminGroup.Group("/transfers")
+	apis.NewTransferAPI(
+		transferRepo,
+		bookRepo,
+		branchAccess,
+		authMw,
+	).Setup(
+		transfersGroup,
+	)
+
+	reportsGroup := v1Group.Group(
+		"/reports",
+		authMw.RequireAuth(),
+		authMw.RequireAdmin(),
+	)
+	reportAPI := apis.NewReportAPI(
+		dailyStatRepo,
+		searchTermRepo,
+		auditLogRepo,
+		branchAccess,
+	)
+	reportAPI.Setup(
+		reportsGroup,
+	)
+	cashDrawerAPI.SetupReports(
+		reportsGroup,
+	)
+	catalogSnapshotAPI.SetupReports(
+		reportsGroup,
+	)
+
+	savedReportAPI := apis.NewSavedReportAPI(
+		savedReportRepo,
+		userRepo,
+		reportAPI,
+		authMw,
+		e,
+	)
+	savedReportsGroup := reportsGroup.Group("/saved")
+	savedReportAPI.Setup(
+		savedReportsGroup,
+	)
+	go jobs.NewSavedReportJob(
+		savedReportRepo,
+		savedReportAPI,
+	).Start(
+		context.Background(),
+		time.Duration(cfg.SavedReportPollMinutes)*time.Minute,
+	)
+
+	backupGroup := adminGroup.Group("/backup")
+	apis.NewBackupAPI(
+		backupJob,
+		branchAccess,
+	).Setup(
+		backupGroup,
+	)
+
+	auditGroup := adminGroup.Group("/audit")
+	apis.NewAuditAPI(
+		auditLogRepo,
+		branchAccess,
+	).Setup(
+		auditGroup,
+	)
+
+	queryStatsGroup := adminGroup.Group("/query-stats")
+	apis.NewQueryStatsAPI(
+		queryStats,
+		branchAccess,
+	).Setup(
+		queryStatsGroup,
+	)
+
+	loggingGroup := adminGroup.Group("/logging")
+	apis.NewLoggingAPI(
+		logLevels,
+		branchAccess,
+		authMw,
+	).Setup(
+		loggingGroup,
+	)
+
+	membershipPlansGroup := adminGroup.Group("/membership-plans")
+	apis.NewMembershipPlanAPI(
+		planRepo,
+		userRepo,
+	).Setup(
+		membershipPlansGroup,
+	)
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(
+			grpcapi.AuthInterceptor(jwtAuth),
+		),
+	)
+	bookmsv1.RegisterBookServiceServer(
+		grpcServer,
+		grpcapi.NewBookServer(
+			bookRepo,
+			cfg.MaxPageSize,
+		),
+	)
+	bookmsv1.RegisterUserServiceServer(
+		grpcServer,
+		grpcapi.NewUserServer(userRepo),
+	)
+	bookmsv1.RegisterLoanServiceServer(
+		grpcServer,
+		grpcapi.NewLoanServer(
+			digitalLoanRepo,
+			bookFileRepo,
+			bookRepo,
+			userRepo,
+			planRepo,
+			ebookStore != nil,
+		),
+	)
+
+	grpcListener, err := net.Listen(
+		"tcp",
+		cfg.GRPCAddress(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		slog.Info("gRPC server starting", "address", cfg.GRPCAddress())
+		err := grpcServer.Serve(grpcListener)
+		if err != nil {
+			panic(err)
+		}
+	}()
+	defer grpcServer.GracefulStop()
+
+	slog.Info("Server starting", "address", cfg.ServerAddress(), "tls_mode", cfg.TLSMode)
+	switch cfg.TLSMode {
+	case tlsModeFile:
+		err = e.StartTLS(
+			cfg.ServerAddress(),
+			cfg.TLSCertFile,
+			cfg.TLSKeyFile,
+		)
+	case tlsModeAutocert:
+		e.AutoTLSManager.HostPolicy = autocert.HostWhitelist(
+			cfg.AutocertDomains()...,
+		)
+		e.AutoTLSManager.Cache = autocert.DirCache(
+			cfg.TLSAutocertCacheDir,
+		)
+		e.AutoTLSManager.Email = cfg.TLSAutocertEmail
+		err = e.StartAutoTLS(
+			cfg.ServerAddress(),
+		)
+	default:
+		err = e.Start(
+			cfg.ServerAddress(),
+		)
+	}
 	if err != nil {
 		panic(err)
 	}
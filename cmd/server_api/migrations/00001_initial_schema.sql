@@ -0,0 +1,245 @@
+-- Book Management System Database Schema
+-- Baseline schema
+
+-- +goose Up
+
+-- Create membership_plans table
+CREATE TABLE membership_plans (
+    id VARCHAR(100) PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    max_loans INTEGER NOT NULL,
+    loan_period_days INTEGER NOT NULL,
+    max_holds INTEGER NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for membership_plans table
+CREATE UNIQUE INDEX idx_membership_plans_code ON membership_plans(code) WHERE deleted_date IS NULL;
+
+-- Seed standard membership plans
+INSERT INTO membership_plans (id, code, name, max_loans, loan_period_days, max_holds, created_date, updated_date) VALUES
+    ('plan_basic', 'basic', 'Basic', 5, 14, 3, NOW(), NOW()),
+    ('plan_premium', 'premium', 'Premium', 15, 28, 10, NOW(), NOW()),
+    ('plan_student', 'student', 'Student', 8, 21, 5, NOW(), NOW());
+
+-- Create users table
+CREATE TABLE users (
+    id VARCHAR(100) PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    membership_plan_id VARCHAR(100) NOT NULL REFERENCES membership_plans(id),
+    card_number VARCHAR(20) NOT NULL,
+    last_login_at timestamptz,
+    flagged_inactive_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for users table
+CREATE UNIQUE INDEX idx_users_email ON users(LOWER(email)) WHERE deleted_date IS NULL;
+CREATE INDEX idx_users_role ON users(role);
+CREATE INDEX idx_users_status ON users(status);
+CREATE INDEX idx_users_membership_plan_id ON users(membership_plan_id);
+CREATE UNIQUE INDEX idx_users_card_number ON users(card_number);
+CREATE INDEX idx_users_last_login_at ON users(last_login_at);
+
+-- Create user_notes table
+CREATE TABLE user_notes (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    author_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for user_notes table
+CREATE INDEX idx_user_notes_user_id ON user_notes(user_id);
+
+-- Create login_events table
+CREATE TABLE login_events (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for login_events table
+CREATE INDEX idx_login_events_user_id_created_date ON login_events(user_id, created_date);
+
+-- Create books table
+CREATE TABLE books (
+    id VARCHAR(100) PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    isbn VARCHAR(20) UNIQUE,
+    publisher VARCHAR(255),
+    publication_year INTEGER,
+    genre VARCHAR(100),
+    description TEXT,
+    pages INTEGER,
+    language VARCHAR(50) NOT NULL,
+    price DECIMAL(10,2),
+    quantity INTEGER NOT NULL,
+    available_quantity INTEGER NOT NULL,
+    location VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    rating_average DECIMAL(3,2) NOT NULL,
+    rating_count INTEGER NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for books table
+CREATE INDEX idx_books_title ON books(title);
+CREATE INDEX idx_books_author ON books(author);
+CREATE UNIQUE INDEX idx_books_isbn ON books(isbn) WHERE isbn IS NOT NULL;
+CREATE INDEX idx_books_genre ON books(genre);
+CREATE INDEX idx_books_status ON books(status);
+
+-- Create reading_lists table
+CREATE TABLE reading_lists (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    name VARCHAR(255) NOT NULL,
+    share_token VARCHAR(64),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for reading_lists table
+CREATE INDEX idx_reading_lists_user_id ON reading_lists(user_id);
+CREATE UNIQUE INDEX idx_reading_lists_share_token ON reading_lists(share_token) WHERE share_token IS NOT NULL;
+
+-- Create reading_list_items table
+CREATE TABLE reading_list_items (
+    id VARCHAR(100) PRIMARY KEY,
+    list_id VARCHAR(100) NOT NULL REFERENCES reading_lists(id),
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    position INTEGER NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for reading_list_items table
+CREATE INDEX idx_reading_list_items_list_id_position ON reading_list_items(list_id, position);
+CREATE UNIQUE INDEX idx_reading_list_items_list_book ON reading_list_items(list_id, book_id) WHERE deleted_date IS NULL;
+
+-- Create reviews table
+CREATE TABLE reviews (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for reviews table
+CREATE INDEX idx_reviews_book_id_created_date ON reviews(book_id, created_date);
+CREATE UNIQUE INDEX idx_reviews_book_user ON reviews(book_id, user_id) WHERE deleted_date IS NULL;
+
+-- Create notifications table
+CREATE TABLE notifications (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    read_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for notifications table
+CREATE INDEX idx_notifications_user_id_created_date ON notifications(user_id, created_date);
+
+-- Create notification_preferences table
+CREATE TABLE notification_preferences (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    event_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for notification_preferences table
+CREATE UNIQUE INDEX idx_notification_preferences_user_event_channel ON notification_preferences(user_id, event_type, channel) WHERE deleted_date IS NULL;
+
+-- Create suggestions table
+CREATE TABLE suggestions (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    isbn VARCHAR(20),
+    note TEXT,
+    status VARCHAR(20) NOT NULL,
+    rejection_reason TEXT,
+    merged_into_id VARCHAR(100) REFERENCES suggestions(id),
+    book_id VARCHAR(100) REFERENCES books(id),
+    reviewed_by VARCHAR(100) REFERENCES users(id),
+    reviewed_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for suggestions table
+CREATE INDEX idx_suggestions_status_created_date ON suggestions(status, created_date);
+CREATE INDEX idx_suggestions_user_id ON suggestions(user_id);
+CREATE INDEX idx_suggestions_merged_into_id ON suggestions(merged_into_id);
+
+-- Create outbox_events table
+CREATE TABLE outbox_events (
+    id VARCHAR(100) PRIMARY KEY,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    published_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for outbox_events table
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events(created_date) WHERE published_at IS NULL AND deleted_date IS NULL;
+
+-- +goose Down
+DROP TABLE outbox_events;
+DROP TABLE suggestions;
+DROP TABLE notification_preferences;
+DROP TABLE notifications;
+DROP TABLE reviews;
+DROP TABLE reading_list_items;
+DROP TABLE reading_lists;
+DROP TABLE books;
+DROP TABLE login_events;
+DROP TABLE user_notes;
+DROP TABLE users;
+DROP TABLE membership_plans;
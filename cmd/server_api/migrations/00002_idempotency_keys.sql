@@ -0,0 +1,27 @@
+-- Idempotency-Key response cache for retried write requests
+
+-- +goose Up
+
+-- Create idempotency_keys table
+CREATE TABLE idempotency_keys (
+    id VARCHAR(100) PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    response_body TEXT NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for idempotency_keys table
+CREATE UNIQUE INDEX idx_idempotency_keys_key ON idempotency_keys(user_id, method, path, idempotency_key) WHERE deleted_date IS NULL;
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,45 @@
+-- Library branches, per-branch book holdings and member home branches
+
+-- +goose Up
+
+-- Create branches table
+CREATE TABLE branches (
+    id VARCHAR(100) PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address TEXT,
+    visibility VARCHAR(20) NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for branches table
+CREATE UNIQUE INDEX idx_branches_code ON branches(code) WHERE deleted_date IS NULL;
+
+-- Create book_holdings table
+CREATE TABLE book_holdings (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    branch_id VARCHAR(100) NOT NULL REFERENCES branches(id),
+    quantity INTEGER NOT NULL,
+    available_quantity INTEGER NOT NULL,
+    location VARCHAR(100),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for book_holdings table
+CREATE UNIQUE INDEX idx_book_holdings_book_branch ON book_holdings(book_id, branch_id) WHERE deleted_date IS NULL;
+CREATE INDEX idx_book_holdings_branch_id ON book_holdings(branch_id);
+
+-- Home branch for members, and the branch a branch-scoped admin manages
+ALTER TABLE users ADD COLUMN branch_id VARCHAR(100) REFERENCES branches(id);
+CREATE INDEX idx_users_branch_id ON users(branch_id);
+
+-- +goose Down
+DROP INDEX idx_users_branch_id;
+ALTER TABLE users DROP COLUMN branch_id;
+DROP TABLE book_holdings;
+DROP TABLE branches;
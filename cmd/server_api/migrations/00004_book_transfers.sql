@@ -0,0 +1,33 @@
+-- Inter-branch transfers of book copies
+
+-- +goose Up
+
+-- Create book_transfers table
+CREATE TABLE book_transfers (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    from_branch_id VARCHAR(100) NOT NULL REFERENCES branches(id),
+    to_branch_id VARCHAR(100) NOT NULL REFERENCES branches(id),
+    quantity INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    note TEXT,
+    requested_by VARCHAR(100) NOT NULL REFERENCES users(id),
+    shipped_by VARCHAR(100) REFERENCES users(id),
+    shipped_at timestamptz,
+    received_by VARCHAR(100) REFERENCES users(id),
+    received_at timestamptz,
+    cancelled_by VARCHAR(100) REFERENCES users(id),
+    cancelled_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for book_transfers table
+CREATE INDEX idx_book_transfers_book_id ON book_transfers(book_id);
+CREATE INDEX idx_book_transfers_from_branch_id ON book_transfers(from_branch_id);
+CREATE INDEX idx_book_transfers_to_branch_id ON book_transfers(to_branch_id);
+CREATE INDEX idx_book_transfers_status ON book_transfers(status);
+
+-- +goose Down
+DROP TABLE book_transfers;
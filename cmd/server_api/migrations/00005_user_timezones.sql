@@ -0,0 +1,9 @@
+-- Display time zone per user; timestamps stay UTC in storage
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64);
+UPDATE users SET timezone = 'UTC';
+ALTER TABLE users ALTER COLUMN timezone SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN timezone;
@@ -0,0 +1,23 @@
+-- Pre-computed daily statistics for reports
+
+-- +goose Up
+
+-- Create daily_stats table
+CREATE TABLE daily_stats (
+    id VARCHAR(100) PRIMARY KEY,
+    stat_date DATE NOT NULL,
+    titles INTEGER NOT NULL,
+    copies INTEGER NOT NULL,
+    available_copies INTEGER NOT NULL,
+    new_titles INTEGER NOT NULL,
+    members INTEGER NOT NULL,
+    new_members INTEGER NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for daily_stats table
+CREATE UNIQUE INDEX idx_daily_stats_stat_date ON daily_stats(stat_date);
+
+-- +goose Down
+DROP TABLE daily_stats;
@@ -0,0 +1,28 @@
+-- Append-only audit trail of admin mutations
+
+-- +goose Up
+
+-- Create audit_logs table
+CREATE TABLE audit_logs (
+    id VARCHAR(100) PRIMARY KEY,
+    actor_id VARCHAR(100) NOT NULL,
+    actor_email VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100),
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    status_code INTEGER NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    before_data TEXT,
+    after_data TEXT,
+    created_date timestamptz NOT NULL
+);
+
+-- Create indexes for audit_logs table
+CREATE INDEX idx_audit_logs_created_date ON audit_logs(created_date);
+CREATE INDEX idx_audit_logs_actor_id_created_date ON audit_logs(actor_id, created_date);
+CREATE INDEX idx_audit_logs_entity ON audit_logs(entity_type, entity_id);
+
+-- +goose Down
+DROP TABLE audit_logs;
@@ -0,0 +1,21 @@
+-- Catalog searches that found nothing, counted per query and UTC day
+
+-- +goose Up
+
+-- Create search_misses table
+CREATE TABLE search_misses (
+    id VARCHAR(100) PRIMARY KEY,
+    query VARCHAR(200) NOT NULL,
+    miss_date DATE NOT NULL,
+    searches INTEGER NOT NULL,
+    last_searched_at timestamptz NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for search_misses table
+CREATE UNIQUE INDEX idx_search_misses_query_miss_date ON search_misses(query, miss_date);
+CREATE INDEX idx_search_misses_miss_date ON search_misses(miss_date);
+
+-- +goose Down
+DROP TABLE search_misses;
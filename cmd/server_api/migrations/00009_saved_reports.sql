@@ -0,0 +1,27 @@
+-- Named report definitions that admins can re-run or schedule
+
+-- +goose Up
+
+-- Create saved_reports table
+CREATE TABLE saved_reports (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    report_type VARCHAR(50) NOT NULL,
+    params TEXT NOT NULL,
+    schedule_hours INTEGER,
+    next_run_at timestamptz,
+    last_run_at timestamptz,
+    last_status INTEGER,
+    last_result TEXT,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for saved_reports table
+CREATE UNIQUE INDEX idx_saved_reports_user_id_name ON saved_reports(user_id, name) WHERE deleted_date IS NULL;
+CREATE INDEX idx_saved_reports_next_run_at ON saved_reports(next_run_at);
+
+-- +goose Down
+DROP TABLE saved_reports;
@@ -0,0 +1,13 @@
+-- Edit counters on books and users for optimistic locking
+
+-- +goose Up
+ALTER TABLE books ADD COLUMN version INTEGER;
+UPDATE books SET version = 1;
+ALTER TABLE books ALTER COLUMN version SET NOT NULL;
+ALTER TABLE users ADD COLUMN version INTEGER;
+UPDATE users SET version = 1;
+ALTER TABLE users ALTER COLUMN version SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN version;
+ALTER TABLE books DROP COLUMN version;
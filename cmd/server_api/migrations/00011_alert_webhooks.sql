@@ -0,0 +1,24 @@
+-- Slack and Discord webhooks that receive operational alerts
+
+-- +goose Up
+
+-- Create alert_webhooks table
+CREATE TABLE alert_webhooks (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    last_delivered_at timestamptz,
+    last_error TEXT,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for alert_webhooks table
+CREATE UNIQUE INDEX idx_alert_webhooks_name ON alert_webhooks(name) WHERE deleted_date IS NULL;
+
+-- +goose Down
+DROP TABLE alert_webhooks;
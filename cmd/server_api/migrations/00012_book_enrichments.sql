@@ -0,0 +1,30 @@
+-- Book covers, and metadata from external catalogs awaiting admin review
+
+-- +goose Up
+ALTER TABLE books ADD COLUMN cover_url TEXT;
+
+-- Create book_enrichments table
+CREATE TABLE book_enrichments (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    description TEXT,
+    description_source VARCHAR(20),
+    pages INTEGER,
+    pages_source VARCHAR(20),
+    cover_url TEXT,
+    cover_url_source VARCHAR(20),
+    reviewed_by VARCHAR(100),
+    reviewed_date timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for book_enrichments table
+CREATE INDEX idx_book_enrichments_book_id ON book_enrichments(book_id);
+CREATE INDEX idx_book_enrichments_status ON book_enrichments(status);
+CREATE UNIQUE INDEX idx_book_enrichments_pending ON book_enrichments(book_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE book_enrichments;
+ALTER TABLE books DROP COLUMN cover_url;
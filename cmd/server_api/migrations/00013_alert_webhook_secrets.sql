@@ -0,0 +1,7 @@
+-- Per-webhook secrets for signing alert deliveries
+
+-- +goose Up
+ALTER TABLE alert_webhooks ADD COLUMN signing_secret TEXT;
+
+-- +goose Down
+ALTER TABLE alert_webhooks DROP COLUMN signing_secret;
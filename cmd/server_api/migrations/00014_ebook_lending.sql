@@ -0,0 +1,45 @@
+-- Ebook files per book and time-limited digital loans
+
+-- +goose Up
+ALTER TABLE books ADD COLUMN digital_loan_limit INTEGER;
+UPDATE books SET digital_loan_limit = 1;
+ALTER TABLE books ALTER COLUMN digital_loan_limit SET NOT NULL;
+
+-- Create book_files table
+CREATE TABLE book_files (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    format VARCHAR(10) NOT NULL,
+    object_key TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    uploaded_by VARCHAR(100) REFERENCES users(id),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for book_files table
+CREATE UNIQUE INDEX idx_book_files_book_format ON book_files(book_id, format);
+
+-- Create digital_loans table
+CREATE TABLE digital_loans (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    due_date timestamptz NOT NULL,
+    returned_date timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for digital_loans table
+CREATE INDEX idx_digital_loans_book_id ON digital_loans(book_id);
+CREATE INDEX idx_digital_loans_user_id ON digital_loans(user_id);
+CREATE INDEX idx_digital_loans_due_date ON digital_loans(due_date);
+
+-- +goose Down
+DROP TABLE digital_loans;
+DROP TABLE book_files;
+ALTER TABLE books DROP COLUMN digital_loan_limit;
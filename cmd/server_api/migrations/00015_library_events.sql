@@ -0,0 +1,40 @@
+-- Library events and announcements with optional member RSVPs
+
+-- +goose Up
+-- Create library_events table
+CREATE TABLE library_events (
+    id VARCHAR(100) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    branch_id VARCHAR(100) REFERENCES branches(id),
+    location TEXT,
+    starts_at timestamptz NOT NULL,
+    ends_at timestamptz,
+    rsvp_enabled BOOLEAN NOT NULL,
+    capacity INTEGER,
+    created_by VARCHAR(100) REFERENCES users(id),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for library_events table
+CREATE INDEX idx_library_events_starts_at ON library_events(starts_at);
+CREATE INDEX idx_library_events_branch_id ON library_events(branch_id);
+
+-- Create event_rsvps table
+CREATE TABLE event_rsvps (
+    id VARCHAR(100) PRIMARY KEY,
+    event_id VARCHAR(100) NOT NULL REFERENCES library_events(id),
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    created_date timestamptz NOT NULL
+);
+
+-- Create indexes for event_rsvps table
+CREATE UNIQUE INDEX idx_event_rsvps_event_user ON event_rsvps(event_id, user_id);
+CREATE INDEX idx_event_rsvps_user_id ON event_rsvps(user_id);
+
+-- +goose Down
+DROP TABLE event_rsvps;
+DROP TABLE library_events;
@@ -0,0 +1,47 @@
+-- Bookable rooms and equipment and member reservations
+
+-- +goose Up
+ALTER TABLE membership_plans ADD COLUMN max_reservations INTEGER;
+UPDATE membership_plans SET max_reservations = 2;
+ALTER TABLE membership_plans ALTER COLUMN max_reservations SET NOT NULL;
+
+-- Create resources table
+CREATE TABLE resources (
+    id VARCHAR(100) PRIMARY KEY,
+    branch_id VARCHAR(100) NOT NULL REFERENCES branches(id),
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    location TEXT,
+    seats INTEGER,
+    max_minutes INTEGER NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for resources table
+CREATE INDEX idx_resources_branch_id ON resources(branch_id);
+
+-- Create reservations table
+CREATE TABLE reservations (
+    id VARCHAR(100) PRIMARY KEY,
+    resource_id VARCHAR(100) NOT NULL REFERENCES resources(id),
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    starts_at timestamptz NOT NULL,
+    ends_at timestamptz NOT NULL,
+    note TEXT,
+    cancelled_date timestamptz,
+    cancelled_by VARCHAR(100) REFERENCES users(id),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for reservations table
+CREATE INDEX idx_reservations_resource_starts_at ON reservations(resource_id, starts_at);
+CREATE INDEX idx_reservations_user_id ON reservations(user_id);
+
+-- +goose Down
+DROP TABLE reservations;
+DROP TABLE resources;
+ALTER TABLE membership_plans DROP COLUMN max_reservations;
@@ -0,0 +1,23 @@
+-- Kiosk devices with scope-limited tokens
+
+-- +goose Up
+-- Create devices table
+CREATE TABLE devices (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    branch_id VARCHAR(100) REFERENCES branches(id),
+    scopes VARCHAR(200) NOT NULL,
+    token_expires_date timestamptz NOT NULL,
+    last_seen_date timestamptz,
+    created_by VARCHAR(100) NOT NULL REFERENCES users(id),
+    revoked_date timestamptz,
+    revoked_by VARCHAR(100) REFERENCES users(id),
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for devices table
+CREATE INDEX idx_devices_branch_id ON devices(branch_id);
+
+-- +goose Down
+DROP TABLE devices;
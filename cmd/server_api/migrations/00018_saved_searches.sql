@@ -0,0 +1,25 @@
+-- Saved book searches with alerts for newly added matches
+
+-- +goose Up
+-- Create saved_searches table
+CREATE TABLE saved_searches (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(200),
+    title VARCHAR(200),
+    author VARCHAR(200),
+    genre VARCHAR(100),
+    last_checked_at timestamptz NOT NULL,
+    last_matched_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for saved_searches table
+CREATE INDEX idx_saved_searches_user_id ON saved_searches(user_id);
+CREATE INDEX idx_saved_searches_last_checked_at ON saved_searches(last_checked_at);
+
+-- +goose Down
+DROP TABLE saved_searches;
@@ -0,0 +1,11 @@
+-- Per-book loan policies: reference-only and high-demand titles
+
+-- +goose Up
+ALTER TABLE books ADD COLUMN loan_policy VARCHAR(20);
+UPDATE books SET loan_policy = 'standard';
+ALTER TABLE books ALTER COLUMN loan_policy SET NOT NULL;
+ALTER TABLE books ADD COLUMN max_loan_days INTEGER;
+
+-- +goose Down
+ALTER TABLE books DROP COLUMN max_loan_days;
+ALTER TABLE books DROP COLUMN loan_policy;
@@ -0,0 +1,42 @@
+-- Desk cash drawer sessions and the payments taken during them
+
+-- +goose Up
+-- Create cash_drawer_sessions table
+CREATE TABLE cash_drawer_sessions (
+    id VARCHAR(100) PRIMARY KEY,
+    branch_id VARCHAR(100) NOT NULL REFERENCES branches(id),
+    opening_float DECIMAL(10,2) NOT NULL,
+    opened_by VARCHAR(100) NOT NULL REFERENCES users(id),
+    opened_at timestamptz NOT NULL,
+    expected_cash DECIMAL(10,2),
+    counted_total DECIMAL(10,2),
+    note TEXT,
+    closed_by VARCHAR(100) REFERENCES users(id),
+    closed_at timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for cash_drawer_sessions table
+CREATE UNIQUE INDEX idx_cash_drawer_sessions_open_branch ON cash_drawer_sessions(branch_id) WHERE closed_at IS NULL;
+CREATE INDEX idx_cash_drawer_sessions_opened_at ON cash_drawer_sessions(opened_at);
+
+-- Create cash_drawer_payments table
+CREATE TABLE cash_drawer_payments (
+    id VARCHAR(100) PRIMARY KEY,
+    session_id VARCHAR(100) NOT NULL REFERENCES cash_drawer_sessions(id),
+    user_id VARCHAR(100) REFERENCES users(id),
+    amount DECIMAL(10,2) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    description TEXT,
+    recorded_by VARCHAR(100) NOT NULL REFERENCES users(id),
+    created_date timestamptz NOT NULL
+);
+
+-- Create indexes for cash_drawer_payments table
+CREATE INDEX idx_cash_drawer_payments_session_id ON cash_drawer_payments(session_id);
+CREATE INDEX idx_cash_drawer_payments_user_id ON cash_drawer_payments(user_id);
+
+-- +goose Down
+DROP TABLE cash_drawer_payments;
+DROP TABLE cash_drawer_sessions;
@@ -0,0 +1,20 @@
+-- Book cover images fetched into object storage
+
+-- +goose Up
+-- Create book_covers table
+CREATE TABLE book_covers (
+    id VARCHAR(100) PRIMARY KEY,
+    book_id VARCHAR(100) NOT NULL REFERENCES books(id),
+    source_url TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for book_covers table
+CREATE UNIQUE INDEX idx_book_covers_book_id ON book_covers(book_id);
+
+-- +goose Down
+DROP TABLE book_covers;
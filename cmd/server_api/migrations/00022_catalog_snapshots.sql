@@ -0,0 +1,30 @@
+-- Stored catalog summaries to compare the catalog against later
+
+-- +goose Up
+-- Create catalog_snapshots table
+CREATE TABLE catalog_snapshots (
+    id VARCHAR(100) PRIMARY KEY,
+    note VARCHAR(200),
+    books INTEGER NOT NULL,
+    copies INTEGER NOT NULL,
+    taken_by VARCHAR(100) REFERENCES users(id),
+    created_date timestamptz NOT NULL
+);
+
+-- Create indexes for catalog_snapshots table
+CREATE INDEX idx_catalog_snapshots_created_date ON catalog_snapshots(created_date);
+
+-- Create catalog_snapshot_books table
+CREATE TABLE catalog_snapshot_books (
+    snapshot_id VARCHAR(100) NOT NULL REFERENCES catalog_snapshots(id),
+    book_id VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    author VARCHAR(255) NOT NULL,
+    isbn VARCHAR(20),
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (snapshot_id, book_id)
+);
+
+-- +goose Down
+DROP TABLE catalog_snapshot_books;
+DROP TABLE catalog_snapshots;
@@ -0,0 +1,43 @@
+-- Catalog searches counted per query and UTC day, replacing search_misses
+
+-- +goose Up
+-- Create search_terms table
+CREATE TABLE search_terms (
+    id VARCHAR(100) PRIMARY KEY,
+    query VARCHAR(200) NOT NULL,
+    search_date DATE NOT NULL,
+    searches INTEGER NOT NULL,
+    zero_results INTEGER NOT NULL,
+    last_searched_at timestamptz NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+-- Create indexes for search_terms table
+CREATE UNIQUE INDEX idx_search_terms_query_search_date ON search_terms(query, search_date);
+CREATE INDEX idx_search_terms_search_date ON search_terms(search_date);
+
+-- Only empty searches were counted before, so each was a zero-result search
+INSERT INTO search_terms (id, query, search_date, searches, zero_results, last_searched_at, created_date, updated_date)
+SELECT id, query, miss_date, searches, searches, last_searched_at, created_date, updated_date FROM search_misses;
+
+DROP TABLE search_misses;
+
+-- +goose Down
+CREATE TABLE search_misses (
+    id VARCHAR(100) PRIMARY KEY,
+    query VARCHAR(200) NOT NULL,
+    miss_date DATE NOT NULL,
+    searches INTEGER NOT NULL,
+    last_searched_at timestamptz NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_search_misses_query_miss_date ON search_misses(query, miss_date);
+CREATE INDEX idx_search_misses_miss_date ON search_misses(miss_date);
+
+INSERT INTO search_misses (id, query, miss_date, searches, last_searched_at, created_date, updated_date)
+SELECT id, query, search_date, zero_results, last_searched_at, created_date, updated_date FROM search_terms WHERE zero_results > 0;
+
+DROP TABLE search_terms;
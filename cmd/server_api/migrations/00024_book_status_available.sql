@@ -0,0 +1,10 @@
+-- Seeded books had status 'available', which the available-books queries do
+-- not match; they are active
+
+-- +goose Up
+UPDATE books SET status = 'active' WHERE status = 'available';
+
+-- +goose Down
+-- Nothing to undo: the seeded books cannot be told apart from other active
+-- books
+SELECT 1;
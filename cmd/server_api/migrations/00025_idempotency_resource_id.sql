@@ -0,0 +1,14 @@
+-- Replay register by the created user instead of a cached body holding
+-- tokens
+
+-- +goose Up
+ALTER TABLE idempotency_keys ADD COLUMN resource_id VARCHAR(100);
+UPDATE idempotency_keys SET resource_id = '';
+ALTER TABLE idempotency_keys ALTER COLUMN resource_id SET NOT NULL;
+
+-- Cached register responses hold access and refresh tokens in plain text.
+-- A retry of one of them within the TTL runs the handler again.
+DELETE FROM idempotency_keys WHERE path LIKE '%/auth/register';
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN resource_id;
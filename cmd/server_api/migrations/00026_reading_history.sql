@@ -0,0 +1,10 @@
+-- Members may turn off reading history, which stops ended loans from being
+-- kept
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN reading_history_enabled BOOLEAN;
+UPDATE users SET reading_history_enabled = TRUE;
+ALTER TABLE users ALTER COLUMN reading_history_enabled SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN reading_history_enabled;
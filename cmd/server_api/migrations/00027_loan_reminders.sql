@@ -0,0 +1,18 @@
+-- Due soon and overdue reminders sent for each digital loan
+
+-- +goose Up
+-- Create loan_reminders table
+CREATE TABLE loan_reminders (
+    id VARCHAR(100) PRIMARY KEY,
+    loan_id VARCHAR(100) NOT NULL REFERENCES digital_loans(id),
+    event_type VARCHAR(50) NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for loan_reminders table
+CREATE UNIQUE INDEX idx_loan_reminders_loan_id_event_type ON loan_reminders(loan_id, event_type);
+
+-- +goose Down
+DROP TABLE loan_reminders;
@@ -0,0 +1,19 @@
+-- Secret calendar subscription URLs for members' loan due dates
+
+-- +goose Up
+-- Create calendar_feeds table
+CREATE TABLE calendar_feeds (
+    id VARCHAR(100) PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL REFERENCES users(id),
+    token VARCHAR(64) NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz
+);
+
+-- Create indexes for calendar_feeds table
+CREATE UNIQUE INDEX idx_calendar_feeds_token ON calendar_feeds(token);
+CREATE UNIQUE INDEX idx_calendar_feeds_user_id ON calendar_feeds(user_id) WHERE deleted_date IS NULL;
+
+-- +goose Down
+DROP TABLE calendar_feeds;
@@ -0,0 +1,196 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing/fstest"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+var sqliteReplacer = strings.NewReplacer(
+	"timestamptz", "TIMESTAMP",
+	"NOW()", "CURRENT_TIMESTAMP",
+)
+
+// sqliteSetNotNull matches the statements that tighten a column added
+// without a default. SQLite cannot alter a column's constraints, so those
+// columns stay nullable there.
+var sqliteSetNotNull = regexp.MustCompile(`(?m)^ALTER TABLE \w+ ALTER COLUMN \w+ SET NOT NULL;\n`)
+
+func NewProvider(db *sql.DB, driver string) (*goose.Provider, error) {
+	if driver != DriverSQLite {
+		return goose.NewProvider(
+			goose.DialectPostgres,
+			db,
+			files,
+		)
+	}
+	sqliteFiles, err := sqliteMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(
+		goose.DialectSQLite3,
+		db,
+		sqliteFiles,
+	)
+}
+
+// sqliteMigrations rewrites the PostgreSQL migrations for SQLite. The driver
+// only parses TIMESTAMP columns into time.Time, SQLite has no NOW(), and it
+// cannot add NOT NULL to an existing column.
+func sqliteMigrations() (fs.FS, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+	rewritten := fstest.MapFS{}
+	for _, entry := range entries {
+		data, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		rewritten[entry.Name()] = &fstest.MapFile{
+			Data: []byte(sqliteSetNotNull.ReplaceAllString(sqliteReplacer.Replace(string(data)), "")),
+		}
+	}
+	return rewritten, nil
+}
+
+func Up(ctx context.Context, db *sql.DB, driver string) error {
+	if err := baseline(ctx, db, driver); err != nil {
+		return fmt.Errorf("baseline existing schema: %w", err)
+	}
+	provider, err := NewProvider(db, driver)
+	if err != nil {
+		return err
+	}
+	results, err := provider.Up(ctx)
+	for _, result := range results {
+		slog.Info(
+			"Migration applied",
+			"version", result.Source.Version,
+			"path", result.Source.Path,
+			"duration", result.Duration,
+		)
+	}
+	return err
+}
+
+// baseline records 00001 as applied on a database whose schema was created
+// by the old init/init.sql, which 00001 replaced: books exists but goose has
+// never run. Applying 00001 there would fail on the existing tables.
+// Databases created by goose, and empty ones, are left alone.
+func baseline(ctx context.Context, db *sql.DB, driver string) error {
+	dialect := database.DialectPostgres
+	if driver == DriverSQLite {
+		dialect = database.DialectSQLite3
+	}
+	store, err := database.NewStore(dialect, goose.DefaultTablename)
+	if err != nil {
+		return err
+	}
+	versioned, err := tableExists(ctx, db, driver, store.Tablename())
+	if err != nil || versioned {
+		return err
+	}
+	hasBooks, err := tableExists(ctx, db, driver, "books")
+	if err != nil || !hasBooks {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := store.CreateVersionTable(ctx, tx); err != nil {
+		return err
+	}
+	// goose starts every version table with version 0.
+	for _, version := range []int64{0, 1} {
+		if err := store.Insert(ctx, tx, database.InsertRequest{Version: version}); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.Info("Existing schema baselined", "version", 1)
+	return nil
+}
+
+func tableExists(ctx context.Context, db *sql.DB, driver, table string) (bool, error) {
+	query := "SELECT to_regclass($1) IS NOT NULL"
+	if driver == DriverSQLite {
+		query = "SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?"
+	}
+	var exists bool
+	err := db.QueryRowContext(ctx, query, table).Scan(&exists)
+	return exists, err
+}
+
+// Version returns the latest migration applied to the database.
+func Version(ctx context.Context, db *sql.DB, driver string) (int64, error) {
+	provider, err := NewProvider(db, driver)
+	if err != nil {
+		return 0, err
+	}
+	return provider.GetDBVersion(ctx)
+}
+
+func Run(ctx context.Context, db *sql.DB, driver, command string) error {
+	provider, err := NewProvider(db, driver)
+	if err != nil {
+		return err
+	}
+	switch command {
+	case "up":
+		return Up(ctx, db, driver)
+	case "down":
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info(
+			"Migration rolled back",
+			"version", result.Source.Version,
+			"path", result.Source.Path,
+			"duration", result.Duration,
+		)
+		return nil
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			fmt.Printf("%-8d %-10s %s\n", status.Source.Version, status.State, status.Source.Path)
+		}
+		return nil
+	case "version":
+		version, err := Version(ctx, db, driver)
+		if err != nil {
+			return err
+		}
+		fmt.Println(version)
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down, status or version", command)
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	AlertProviderSlack   = "slack"
+	AlertProviderDiscord = "discord"
+	AlertProviderGeneric = "generic"
+)
+
+// AlertWebhook is a Slack or Discord incoming webhook, or a generic endpoint
+// taking JSON, that receives the operational alerts listed in Events, a
+// comma-separated list. URL embeds the webhook's credentials and is never
+// returned in full. SigningSecret signs each delivery; webhooks created
+// before signing was added have none until it is rotated.
+type AlertWebhook struct {
+	ID              string     `gorm:"column:id"`
+	Name            string     `gorm:"column:name"`
+	Provider        string     `gorm:"column:provider"`
+	URL             string     `gorm:"column:url"`
+	Events          string     `gorm:"column:events"`
+	Enabled         bool       `gorm:"column:enabled"`
+	LastDeliveredAt *time.Time `gorm:"column:last_delivered_at"`
+	LastError       *string    `gorm:"column:last_error"`
+	SigningSecret   *string    `gorm:"column:signing_secret"`
+	CreatedDate     time.Time  `gorm:"column:created_date"`
+	UpdatedDate     time.Time  `gorm:"column:updated_date"`
+	DeletedDate     *time.Time `gorm:"column:deleted_date"`
+}
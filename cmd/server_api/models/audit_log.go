@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog records one admin mutation. Rows are append-only: they are never
+// updated or deleted, so there is no updated_date or deleted_date. BeforeData
+// and AfterData hold the entity as JSON when the handler supplied it.
+type AuditLog struct {
+	ID          string    `gorm:"column:id"`
+	ActorID     string    `gorm:"column:actor_id"`
+	ActorEmail  string    `gorm:"column:actor_email"`
+	Action      string    `gorm:"column:action"`
+	EntityType  string    `gorm:"column:entity_type"`
+	EntityID    *string   `gorm:"column:entity_id"`
+	Method      string    `gorm:"column:method"`
+	Path        string    `gorm:"column:path"`
+	StatusCode  int       `gorm:"column:status_code"`
+	IPAddress   string    `gorm:"column:ip_address"`
+	BeforeData  *string   `gorm:"column:before_data"`
+	AfterData   *string   `gorm:"column:after_data"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+}
@@ -2,6 +2,27 @@ package models
 
 import "time"
 
+// Book loan policies. Reference-only books cannot be borrowed, and loans of
+// high-demand books last at most HighDemandLoanDays.
+const (
+	BookLoanPolicyStandard      = "standard"
+	BookLoanPolicyHighDemand    = "high_demand"
+	BookLoanPolicyReferenceOnly = "reference_only"
+	HighDemandLoanDays          = 7
+)
+
+// DefaultDigitalLoanLimit is how many members can borrow a new book's ebook
+// at once until staff change it.
+const DefaultDigitalLoanLimit = 1
+
+// Book statuses. Only active books are offered as available; books on order
+// were accepted from a suggestion and have not arrived yet.
+const (
+	BookStatusActive   = "active"
+	BookStatusInactive = "inactive"
+	BookStatusOnOrder  = "on_order"
+)
+
 type Book struct {
 	ID                string     `gorm:"column:id"`
 	Title             string     `gorm:"column:title"`
@@ -17,8 +38,29 @@ type Book struct {
 	Quantity          int        `gorm:"column:quantity"`
 	AvailableQuantity int        `gorm:"column:available_quantity"`
 	Location          *string    `gorm:"column:location"`
+	CoverURL          *string    `gorm:"column:cover_url"`
 	Status            string     `gorm:"column:status"`
+	RatingAverage     float64    `gorm:"column:rating_average"`
+	RatingCount       int        `gorm:"column:rating_count"`
+	DigitalLoanLimit  int        `gorm:"column:digital_loan_limit"`
+	LoanPolicy        string     `gorm:"column:loan_policy"`
+	MaxLoanDays       *int       `gorm:"column:max_loan_days"`
+	Version           int        `gorm:"column:version"`
 	CreatedDate       time.Time  `gorm:"column:created_date"`
 	UpdatedDate       time.Time  `gorm:"column:updated_date"`
 	DeletedDate       *time.Time `gorm:"column:deleted_date"`
+}
+
+// LoanDays returns how many days the book is lent for to a member whose plan
+// lends for planDays: the plan's period, capped by the high-demand policy
+// and by MaxLoanDays.
+func (b *Book) LoanDays(planDays int) int {
+	days := planDays
+	if b.LoanPolicy == BookLoanPolicyHighDemand {
+		days = min(days, HighDemandLoanDays)
+	}
+	if b.MaxLoanDays != nil {
+		days = min(days, *b.MaxLoanDays)
+	}
+	return days
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// BookCover is a copy of a book's cover image kept in object storage under
+// ObjectKey. SourceURL is where it was fetched from; the copy stands for the
+// book's cover only while the book's cover_url is still that URL.
+type BookCover struct {
+	ID          string    `gorm:"column:id"`
+	BookID      string    `gorm:"column:book_id"`
+	SourceURL   string    `gorm:"column:source_url"`
+	ObjectKey   string    `gorm:"column:object_key"`
+	ContentType string    `gorm:"column:content_type"`
+	SizeBytes   int64     `gorm:"column:size_bytes"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+	UpdatedDate time.Time `gorm:"column:updated_date"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	EnrichmentStatusPending  = "pending"
+	EnrichmentStatusApplied  = "applied"
+	EnrichmentStatusRejected = "rejected"
+	// EnrichmentStatusNoMatch records a lookup that found nothing for the
+	// book's missing fields, so it isn't looked up again on every run.
+	EnrichmentStatusNoMatch = "no_match"
+)
+
+// BookEnrichment holds values external catalogs proposed for fields a book
+// was missing, until an admin applies or rejects them. A nil field has no
+// proposal; each proposed field records the catalog it came from.
+type BookEnrichment struct {
+	ID                string     `gorm:"column:id"`
+	BookID            string     `gorm:"column:book_id"`
+	Status            string     `gorm:"column:status"`
+	Description       *string    `gorm:"column:description"`
+	DescriptionSource *string    `gorm:"column:description_source"`
+	Pages             *int       `gorm:"column:pages"`
+	PagesSource       *string    `gorm:"column:pages_source"`
+	CoverURL          *string    `gorm:"column:cover_url"`
+	CoverURLSource    *string    `gorm:"column:cover_url_source"`
+	ReviewedBy        *string    `gorm:"column:reviewed_by"`
+	ReviewedDate      *time.Time `gorm:"column:reviewed_date"`
+	CreatedDate       time.Time  `gorm:"column:created_date"`
+	UpdatedDate       time.Time  `gorm:"column:updated_date"`
+}
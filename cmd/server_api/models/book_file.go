@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	BookFileFormatEPUB = "epub"
+	BookFileFormatPDF  = "pdf"
+)
+
+// BookFile is an ebook of a book, kept in object storage under ObjectKey.
+// A book has at most one file per format.
+type BookFile struct {
+	ID          string    `gorm:"column:id"`
+	BookID      string    `gorm:"column:book_id"`
+	Format      string    `gorm:"column:format"`
+	ObjectKey   string    `gorm:"column:object_key"`
+	ContentType string    `gorm:"column:content_type"`
+	SizeBytes   int64     `gorm:"column:size_bytes"`
+	SHA256      string    `gorm:"column:sha256"`
+	UploadedBy  *string   `gorm:"column:uploaded_by"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+	UpdatedDate time.Time `gorm:"column:updated_date"`
+}
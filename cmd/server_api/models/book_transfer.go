@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// BookTransfer moves copies of a book from one branch to another. Copies
+// leave the source holding when shipped and join the destination holding
+// when received.
+type BookTransfer struct {
+	ID           string     `gorm:"column:id"`
+	BookID       string     `gorm:"column:book_id"`
+	FromBranchID string     `gorm:"column:from_branch_id"`
+	ToBranchID   string     `gorm:"column:to_branch_id"`
+	Quantity     int        `gorm:"column:quantity"`
+	Status       string     `gorm:"column:status"`
+	Note         *string    `gorm:"column:note"`
+	RequestedBy  string     `gorm:"column:requested_by"`
+	ShippedBy    *string    `gorm:"column:shipped_by"`
+	ShippedAt    *time.Time `gorm:"column:shipped_at"`
+	ReceivedBy   *string    `gorm:"column:received_by"`
+	ReceivedAt   *time.Time `gorm:"column:received_at"`
+	CancelledBy  *string    `gorm:"column:cancelled_by"`
+	CancelledAt  *time.Time `gorm:"column:cancelled_at"`
+	CreatedDate  time.Time  `gorm:"column:created_date"`
+	UpdatedDate  time.Time  `gorm:"column:updated_date"`
+	DeletedDate  *time.Time `gorm:"column:deleted_date"`
+}
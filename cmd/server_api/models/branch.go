@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	BranchVisibilityNetwork = "network"
+	BranchVisibilityLocal   = "local"
+)
+
+// Branch is a physical library location. Holdings at a network branch are
+// visible to everyone; holdings at a local branch only to its own members
+// and staff.
+type Branch struct {
+	ID          string     `gorm:"column:id"`
+	Code        string     `gorm:"column:code"`
+	Name        string     `gorm:"column:name"`
+	Address     *string    `gorm:"column:address"`
+	Visibility  string     `gorm:"column:visibility"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
+
+type BookHolding struct {
+	ID                string     `gorm:"column:id"`
+	BookID            string     `gorm:"column:book_id"`
+	BranchID          string     `gorm:"column:branch_id"`
+	Quantity          int        `gorm:"column:quantity"`
+	AvailableQuantity int        `gorm:"column:available_quantity"`
+	Location          *string    `gorm:"column:location"`
+	CreatedDate       time.Time  `gorm:"column:created_date"`
+	UpdatedDate       time.Time  `gorm:"column:updated_date"`
+	DeletedDate       *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CalendarFeed is a member's subscription to their loan due dates. Token is
+// the secret in the feed URL; calendar apps cannot send a bearer token.
+type CalendarFeed struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	Token       string     `gorm:"column:token"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const (
+	PaymentMethodCash = "cash"
+	PaymentMethodCard = "card"
+)
+
+// CashDrawerSession is a desk's cash drawer from opening to close. A branch
+// has at most one open session. ExpectedCash, the opening float plus the cash
+// taken, is fixed when the session is closed with the CountedTotal found in
+// the drawer.
+type CashDrawerSession struct {
+	ID           string     `gorm:"column:id"`
+	BranchID     string     `gorm:"column:branch_id"`
+	OpeningFloat float64    `gorm:"column:opening_float"`
+	OpenedBy     string     `gorm:"column:opened_by"`
+	OpenedAt     time.Time  `gorm:"column:opened_at"`
+	ExpectedCash *float64   `gorm:"column:expected_cash"`
+	CountedTotal *float64   `gorm:"column:counted_total"`
+	Note         *string    `gorm:"column:note"`
+	ClosedBy     *string    `gorm:"column:closed_by"`
+	ClosedAt     *time.Time `gorm:"column:closed_at"`
+	CreatedDate  time.Time  `gorm:"column:created_date"`
+	UpdatedDate  time.Time  `gorm:"column:updated_date"`
+}
+
+// CashDrawerPayment is a payment taken at the desk during a session. UserID
+// is the paying member, when known. Payments are not changed once recorded.
+type CashDrawerPayment struct {
+	ID          string    `gorm:"column:id"`
+	SessionID   string    `gorm:"column:session_id"`
+	UserID      *string   `gorm:"column:user_id"`
+	Amount      float64   `gorm:"column:amount"`
+	Method      string    `gorm:"column:method"`
+	Description *string   `gorm:"column:description"`
+	RecordedBy  string    `gorm:"column:recorded_by"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+}
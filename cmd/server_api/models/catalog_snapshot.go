@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// CatalogSnapshot is a stored summary of the live catalog at CreatedDate:
+// how many books and copies it had, and the quantity of each book. Later
+// catalogs are compared against it.
+type CatalogSnapshot struct {
+	ID          string    `gorm:"column:id"`
+	Note        *string   `gorm:"column:note"`
+	Books       int       `gorm:"column:books"`
+	Copies      int       `gorm:"column:copies"`
+	TakenBy     *string   `gorm:"column:taken_by"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+}
+
+// CatalogSnapshotBook is a book as a snapshot recorded it. BookID does not
+// reference books, so snapshots outlive purged books.
+type CatalogSnapshotBook struct {
+	SnapshotID string  `gorm:"column:snapshot_id"`
+	BookID     string  `gorm:"column:book_id"`
+	Title      string  `gorm:"column:title"`
+	Author     string  `gorm:"column:author"`
+	ISBN       *string `gorm:"column:isbn"`
+	Quantity   int     `gorm:"column:quantity"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// DailyStat is a snapshot of catalog and membership totals for one UTC day.
+// The row for the current day is refreshed until the day ends.
+type DailyStat struct {
+	ID              string    `gorm:"column:id"`
+	StatDate        time.Time `gorm:"column:stat_date"`
+	Titles          int       `gorm:"column:titles"`
+	Copies          int       `gorm:"column:copies"`
+	AvailableCopies int       `gorm:"column:available_copies"`
+	NewTitles       int       `gorm:"column:new_titles"`
+	Members         int       `gorm:"column:members"`
+	NewMembers      int       `gorm:"column:new_members"`
+	CreatedDate     time.Time `gorm:"column:created_date"`
+	UpdatedDate     time.Time `gorm:"column:updated_date"`
+}
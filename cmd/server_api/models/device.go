@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// DeviceScopeSearch lets a device search the catalog. It is the only scope
+// so far; self-checkout will get its own once physical loans exist.
+const DeviceScopeSearch = "search"
+
+// Device is an unattended kiosk holding a long-lived token limited to
+// Scopes, a space-separated list. Its token stops working once it is revoked,
+// even before TokenExpiresDate.
+type Device struct {
+	ID               string     `gorm:"column:id"`
+	Name             string     `gorm:"column:name"`
+	BranchID         *string    `gorm:"column:branch_id"`
+	Scopes           string     `gorm:"column:scopes"`
+	TokenExpiresDate time.Time  `gorm:"column:token_expires_date"`
+	LastSeenDate     *time.Time `gorm:"column:last_seen_date"`
+	CreatedBy        string     `gorm:"column:created_by"`
+	RevokedDate      *time.Time `gorm:"column:revoked_date"`
+	RevokedBy        *string    `gorm:"column:revoked_by"`
+	CreatedDate      time.Time  `gorm:"column:created_date"`
+	UpdatedDate      time.Time  `gorm:"column:updated_date"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// DigitalLoan lends a book's ebook files to a member. It is active until it
+// is returned or its due date passes, whichever comes first; nothing has to
+// run for an overdue loan to end.
+type DigitalLoan struct {
+	ID           string     `gorm:"column:id"`
+	BookID       string     `gorm:"column:book_id"`
+	UserID       string     `gorm:"column:user_id"`
+	DueDate      time.Time  `gorm:"column:due_date"`
+	ReturnedDate *time.Time `gorm:"column:returned_date"`
+	CreatedDate  time.Time  `gorm:"column:created_date"`
+	UpdatedDate  time.Time  `gorm:"column:updated_date"`
+	DeletedDate  *time.Time `gorm:"column:deleted_date"`
+}
+
+// Active reports whether the loan still grants access at now.
+func (l *DigitalLoan) Active(now time.Time) bool {
+	return l.ReturnedDate == nil && now.Before(l.DueDate)
+}
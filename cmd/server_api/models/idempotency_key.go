@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// IdempotencyKey caches the first response to a write request. StatusCode is
+// zero while the original request is still being processed. ResourceID is
+// set instead of ResponseBody when the response is rebuilt from the created
+// resource on replay.
+type IdempotencyKey struct {
+	ID             string     `gorm:"column:id"`
+	IdempotencyKey string     `gorm:"column:idempotency_key"`
+	UserID         string     `gorm:"column:user_id"`
+	Method         string     `gorm:"column:method"`
+	Path           string     `gorm:"column:path"`
+	RequestHash    string     `gorm:"column:request_hash"`
+	StatusCode     int        `gorm:"column:status_code"`
+	ContentType    string     `gorm:"column:content_type"`
+	ResponseBody   string     `gorm:"column:response_body"`
+	ResourceID     string     `gorm:"column:resource_id"`
+	ExpiresAt      time.Time  `gorm:"column:expires_at"`
+	CreatedDate    time.Time  `gorm:"column:created_date"`
+	UpdatedDate    time.Time  `gorm:"column:updated_date"`
+	DeletedDate    *time.Time `gorm:"column:deleted_date"`
+}
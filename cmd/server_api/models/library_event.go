@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	LibraryEventKindEvent        = "event"
+	LibraryEventKindAnnouncement = "announcement"
+)
+
+// LibraryEvent is something the library puts on, such as a book club or an
+// author talk, or an announcement. An event runs from StartsAt to EndsAt, or
+// is over once it starts when EndsAt is nil; an announcement is shown from
+// StartsAt until EndsAt, or indefinitely. Events without a branch are
+// network-wide.
+type LibraryEvent struct {
+	ID          string     `gorm:"column:id"`
+	Kind        string     `gorm:"column:kind"`
+	Title       string     `gorm:"column:title"`
+	Description *string    `gorm:"column:description"`
+	BranchID    *string    `gorm:"column:branch_id"`
+	Location    *string    `gorm:"column:location"`
+	StartsAt    time.Time  `gorm:"column:starts_at"`
+	EndsAt      *time.Time `gorm:"column:ends_at"`
+	RSVPEnabled bool       `gorm:"column:rsvp_enabled"`
+	Capacity    *int       `gorm:"column:capacity"`
+	CreatedBy   *string    `gorm:"column:created_by"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
+
+// EventRSVP records that a member plans to attend an event.
+type EventRSVP struct {
+	ID          string    `gorm:"column:id"`
+	EventID     string    `gorm:"column:event_id"`
+	UserID      string    `gorm:"column:user_id"`
+	CreatedDate time.Time `gorm:"column:created_date"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LoanReminder records that a reminder of EventType was sent for a loan, so
+// the reminder job sends each one once.
+type LoanReminder struct {
+	ID          string     `gorm:"column:id"`
+	LoanID      string     `gorm:"column:loan_id"`
+	EventType   string     `gorm:"column:event_type"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type LoginEvent struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	IPAddress   string     `gorm:"column:ip_address"`
+	UserAgent   string     `gorm:"column:user_agent"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type MembershipPlan struct {
+	ID              string     `gorm:"column:id"`
+	Code            string     `gorm:"column:code"`
+	Name            string     `gorm:"column:name"`
+	MaxLoans        int        `gorm:"column:max_loans"`
+	LoanPeriodDays  int        `gorm:"column:loan_period_days"`
+	MaxHolds        int        `gorm:"column:max_holds"`
+	MaxReservations int        `gorm:"column:max_reservations"`
+	CreatedDate     time.Time  `gorm:"column:created_date"`
+	UpdatedDate     time.Time  `gorm:"column:updated_date"`
+	DeletedDate     *time.Time `gorm:"column:deleted_date"`
+}
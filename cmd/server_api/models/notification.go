@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Notification struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	EventType   string     `gorm:"column:event_type"`
+	Channel     string     `gorm:"column:channel"`
+	Title       string     `gorm:"column:title"`
+	Body        string     `gorm:"column:body"`
+	ReadAt      *time.Time `gorm:"column:read_at"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
+
+type NotificationPreference struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	EventType   string     `gorm:"column:event_type"`
+	Channel     string     `gorm:"column:channel"`
+	Enabled     bool       `gorm:"column:enabled"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type OutboxEvent struct {
+	ID            string     `gorm:"column:id"`
+	AggregateType string     `gorm:"column:aggregate_type"`
+	AggregateID   string     `gorm:"column:aggregate_id"`
+	EventType     string     `gorm:"column:event_type"`
+	Payload       string     `gorm:"column:payload"`
+	Attempts      int        `gorm:"column:attempts"`
+	LastError     *string    `gorm:"column:last_error"`
+	PublishedAt   *time.Time `gorm:"column:published_at"`
+	CreatedDate   time.Time  `gorm:"column:created_date"`
+	UpdatedDate   time.Time  `gorm:"column:updated_date"`
+	DeletedDate   *time.Time `gorm:"column:deleted_date"`
+}
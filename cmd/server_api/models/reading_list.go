@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type ReadingList struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	Name        string     `gorm:"column:name"`
+	ShareToken  *string    `gorm:"column:share_token"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
+
+type ReadingListItem struct {
+	ID          string     `gorm:"column:id"`
+	ListID      string     `gorm:"column:list_id"`
+	BookID      string     `gorm:"column:book_id"`
+	Position    int        `gorm:"column:position"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
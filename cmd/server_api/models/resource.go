@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	ResourceKindRoom      = "room"
+	ResourceKindEquipment = "equipment"
+)
+
+// Resource is a room or piece of equipment members can reserve at a branch.
+// A single reservation may last at most MaxMinutes.
+type Resource struct {
+	ID          string     `gorm:"column:id"`
+	BranchID    string     `gorm:"column:branch_id"`
+	Kind        string     `gorm:"column:kind"`
+	Name        string     `gorm:"column:name"`
+	Description *string    `gorm:"column:description"`
+	Location    *string    `gorm:"column:location"`
+	Seats       *int       `gorm:"column:seats"`
+	MaxMinutes  int        `gorm:"column:max_minutes"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
+
+// Reservation holds a resource for a member from StartsAt to EndsAt. It
+// stops counting against the resource and the member's limit once it is
+// cancelled or has ended.
+type Reservation struct {
+	ID            string     `gorm:"column:id"`
+	ResourceID    string     `gorm:"column:resource_id"`
+	UserID        string     `gorm:"column:user_id"`
+	StartsAt      time.Time  `gorm:"column:starts_at"`
+	EndsAt        time.Time  `gorm:"column:ends_at"`
+	Note          *string    `gorm:"column:note"`
+	CancelledDate *time.Time `gorm:"column:cancelled_date"`
+	CancelledBy   *string    `gorm:"column:cancelled_by"`
+	CreatedDate   time.Time  `gorm:"column:created_date"`
+	UpdatedDate   time.Time  `gorm:"column:updated_date"`
+}
+
+// Active reports whether the reservation still holds the resource at now.
+func (r *Reservation) Active(now time.Time) bool {
+	return r.CancelledDate == nil && now.Before(r.EndsAt)
+}
@@ -1,6 +1,102 @@
 package models
 
+const (
+	ErrCodeInvalidRequest          = "INVALID_REQUEST"
+	ErrCodeNotFound                = "NOT_FOUND"
+	ErrCodeMethodNotAllowed        = "METHOD_NOT_ALLOWED"
+	ErrCodeValidation              = "VALIDATION_ERROR"
+	ErrCodePayloadTooLarge         = "PAYLOAD_TOO_LARGE"
+	ErrCodeInvalidCredentials      = "INVALID_CREDENTIALS"
+	ErrCodeAccountInactive         = "ACCOUNT_INACTIVE"
+	ErrCodeAuthenticationRequired  = "AUTHENTICATION_REQUIRED"
+	ErrCodeInvalidToken            = "INVALID_TOKEN"
+	ErrCodeTokenExpired            = "TOKEN_EXPIRED"
+	ErrCodeInvalidSetupToken       = "INVALID_SETUP_TOKEN"
+	ErrCodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	ErrCodeSetupCompleted          = "SETUP_ALREADY_COMPLETED"
+	ErrCodeEmailExists             = "EMAIL_ALREADY_EXISTS"
+	ErrCodeISBNExists              = "ISBN_ALREADY_EXISTS"
+	ErrCodeUserNotFound            = "USER_NOT_FOUND"
+	ErrCodeUserNoteNotFound        = "USER_NOTE_NOT_FOUND"
+	ErrCodeUserMergeNotAllowed     = "USER_MERGE_NOT_ALLOWED"
+	ErrCodePurgeBlocked            = "PURGE_BLOCKED"
+	ErrCodeBookNotFound            = "BOOK_NOT_FOUND"
+	ErrCodeMembershipPlanNotFound  = "MEMBERSHIP_PLAN_NOT_FOUND"
+	ErrCodeMembershipPlanExists    = "MEMBERSHIP_PLAN_CODE_EXISTS"
+	ErrCodeMembershipPlanInUse     = "MEMBERSHIP_PLAN_IN_USE"
+	ErrCodeReadingListNotFound     = "READING_LIST_NOT_FOUND"
+	ErrCodeReadingListItemNotFound = "READING_LIST_ITEM_NOT_FOUND"
+	ErrCodeReadingListItemExists   = "READING_LIST_ITEM_EXISTS"
+	ErrCodeReviewExists            = "REVIEW_ALREADY_EXISTS"
+	ErrCodeBookNotBorrowed         = "BOOK_NOT_BORROWED"
+	ErrCodeNotificationNotFound    = "NOTIFICATION_NOT_FOUND"
+	ErrCodeSuggestionNotFound      = "SUGGESTION_NOT_FOUND"
+	ErrCodeSuggestionResolved      = "SUGGESTION_ALREADY_RESOLVED"
+	ErrCodeBranchNotFound          = "BRANCH_NOT_FOUND"
+	ErrCodeBranchExists            = "BRANCH_CODE_EXISTS"
+	ErrCodeBranchInUse             = "BRANCH_IN_USE"
+	ErrCodeBranchAccessDenied      = "BRANCH_ACCESS_DENIED"
+	ErrCodeBookHasHoldings         = "BOOK_HAS_HOLDINGS"
+	ErrCodeHoldingNotFound         = "HOLDING_NOT_FOUND"
+	ErrCodeTransferNotFound        = "TRANSFER_NOT_FOUND"
+	ErrCodeTransferInvalidStatus   = "TRANSFER_INVALID_STATUS"
+	ErrCodeInsufficientCopies      = "INSUFFICIENT_COPIES"
+	ErrCodeSavedReportNotFound     = "SAVED_REPORT_NOT_FOUND"
+	ErrCodeSavedReportExists       = "SAVED_REPORT_NAME_EXISTS"
+	ErrCodeSavedSearchNotFound     = "SAVED_SEARCH_NOT_FOUND"
+	ErrCodeSavedSearchExists       = "SAVED_SEARCH_NAME_EXISTS"
+	ErrCodeSavedSearchLimitReached = "SAVED_SEARCH_LIMIT_REACHED"
+	ErrCodeAlertWebhookNotFound    = "ALERT_WEBHOOK_NOT_FOUND"
+	ErrCodeAlertWebhookExists      = "ALERT_WEBHOOK_NAME_EXISTS"
+	ErrCodeAlertDeliveryFailed     = "ALERT_DELIVERY_FAILED"
+	ErrCodeEnrichmentNotFound      = "ENRICHMENT_NOT_FOUND"
+	ErrCodeEnrichmentReviewed      = "ENRICHMENT_ALREADY_REVIEWED"
+	ErrCodeBookFileNotFound        = "BOOK_FILE_NOT_FOUND"
+	ErrCodeBookCoverNotFound       = "BOOK_COVER_NOT_FOUND"
+	ErrCodeDigitalLoanNotFound     = "DIGITAL_LOAN_NOT_FOUND"
+	ErrCodeDigitalLoanExists       = "DIGITAL_LOAN_EXISTS"
+	ErrCodeDigitalLoanEnded        = "DIGITAL_LOAN_ENDED"
+	ErrCodeCalendarFeedNotFound    = "CALENDAR_FEED_NOT_FOUND"
+	ErrCodeNoDigitalCopies         = "NO_DIGITAL_COPIES_AVAILABLE"
+	ErrCodeLoanLimitReached        = "LOAN_LIMIT_REACHED"
+	ErrCodeBookReferenceOnly       = "BOOK_REFERENCE_ONLY"
+	ErrCodeCashDrawerNotFound      = "CASH_DRAWER_NOT_FOUND"
+	ErrCodeCashDrawerOpen          = "CASH_DRAWER_ALREADY_OPEN"
+	ErrCodeCashDrawerClosed        = "CASH_DRAWER_CLOSED"
+	ErrCodeCatalogSnapshotNotFound = "CATALOG_SNAPSHOT_NOT_FOUND"
+	ErrCodeEventNotFound           = "EVENT_NOT_FOUND"
+	ErrCodeEventFull               = "EVENT_FULL"
+	ErrCodeRSVPClosed              = "RSVP_CLOSED"
+	ErrCodeRSVPExists              = "RSVP_EXISTS"
+	ErrCodeRSVPNotFound            = "RSVP_NOT_FOUND"
+	ErrCodeResourceNotFound        = "RESOURCE_NOT_FOUND"
+	ErrCodeResourceInUse           = "RESOURCE_IN_USE"
+	ErrCodeReservationNotFound     = "RESERVATION_NOT_FOUND"
+	ErrCodeReservationConflict     = "RESERVATION_CONFLICT"
+	ErrCodeReservationLimitReached = "RESERVATION_LIMIT_REACHED"
+	ErrCodeReservationEnded        = "RESERVATION_ENDED"
+	ErrCodeDeviceNotFound          = "DEVICE_NOT_FOUND"
+	ErrCodeDeviceRevoked           = "DEVICE_REVOKED"
+	ErrCodeJobRunning              = "JOB_ALREADY_RUNNING"
+	ErrCodeVersionConflict         = "VERSION_CONFLICT"
+	ErrCodeIdempotencyInProgress   = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	ErrCodeIdempotencyKeyReused    = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeInternal                = "INTERNAL_ERROR"
+	ErrCodeServiceUnavailable      = "SERVICE_UNAVAILABLE"
+	ErrCodeRateLimitExceeded       = "RATE_LIMIT_EXCEEDED"
+)
+
 type Response struct {
+	Message   string       `json:"message"`
+	ErrorCode string       `json:"error_code,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Data      any          `json:"data,omitempty"`
+}
+
+// FieldError names a request field that was rejected. Rule is the check it
+// failed, such as required, min or oneof, for validation errors.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
 	Message string `json:"message"`
-	Data    any    `json:"data,omitempty"`
 }
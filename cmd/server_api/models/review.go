@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Review struct {
+	ID          string     `gorm:"column:id"`
+	BookID      string     `gorm:"column:book_id"`
+	UserID      string     `gorm:"column:user_id"`
+	Rating      int        `gorm:"column:rating"`
+	Body        string     `gorm:"column:body"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedReport is a report type with fixed query parameters, owned by the
+// admin who saved it. Params is URL-encoded, e.g. "cohorts=true&dormant_days=60".
+// A scheduled report has ScheduleHours and NextRunAt set.
+type SavedReport struct {
+	ID            string     `gorm:"column:id"`
+	UserID        string     `gorm:"column:user_id"`
+	Name          string     `gorm:"column:name"`
+	ReportType    string     `gorm:"column:report_type"`
+	Params        string     `gorm:"column:params"`
+	ScheduleHours *int       `gorm:"column:schedule_hours"`
+	NextRunAt     *time.Time `gorm:"column:next_run_at"`
+	LastRunAt     *time.Time `gorm:"column:last_run_at"`
+	LastStatus    *int       `gorm:"column:last_status"`
+	LastResult    *string    `gorm:"column:last_result"`
+	CreatedDate   time.Time  `gorm:"column:created_date"`
+	UpdatedDate   time.Time  `gorm:"column:updated_date"`
+	DeletedDate   *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedSearch is a member's book search, kept to be run again and to alert
+// them when books added later match it. Empty criteria match everything; at
+// least one is set. Books added since LastCheckedAt have not been matched
+// yet.
+type SavedSearch struct {
+	ID            string     `gorm:"column:id"`
+	UserID        string     `gorm:"column:user_id"`
+	Name          string     `gorm:"column:name"`
+	Query         *string    `gorm:"column:query"`
+	Title         *string    `gorm:"column:title"`
+	Author        *string    `gorm:"column:author"`
+	Genre         *string    `gorm:"column:genre"`
+	LastCheckedAt time.Time  `gorm:"column:last_checked_at"`
+	LastMatchedAt *time.Time `gorm:"column:last_matched_at"`
+	CreatedDate   time.Time  `gorm:"column:created_date"`
+	UpdatedDate   time.Time  `gorm:"column:updated_date"`
+	DeletedDate   *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SearchTerm counts the catalog searches for one normalized query on one UTC
+// day, and how many of them found no books. Queries are stored without who
+// searched for them.
+type SearchTerm struct {
+	ID             string    `gorm:"column:id"`
+	Query          string    `gorm:"column:query"`
+	SearchDate     time.Time `gorm:"column:search_date"`
+	Searches       int       `gorm:"column:searches"`
+	ZeroResults    int       `gorm:"column:zero_results"`
+	LastSearchedAt time.Time `gorm:"column:last_searched_at"`
+	CreatedDate    time.Time `gorm:"column:created_date"`
+	UpdatedDate    time.Time `gorm:"column:updated_date"`
+}
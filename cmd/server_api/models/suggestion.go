@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	SuggestionStatusPending  = "pending"
+	SuggestionStatusAccepted = "accepted"
+	SuggestionStatusRejected = "rejected"
+	SuggestionStatusMerged   = "merged"
+)
+
+type Suggestion struct {
+	ID              string     `gorm:"column:id"`
+	UserID          string     `gorm:"column:user_id"`
+	Title           string     `gorm:"column:title"`
+	Author          string     `gorm:"column:author"`
+	ISBN            *string    `gorm:"column:isbn"`
+	Note            *string    `gorm:"column:note"`
+	Status          string     `gorm:"column:status"`
+	RejectionReason *string    `gorm:"column:rejection_reason"`
+	MergedIntoID    *string    `gorm:"column:merged_into_id"`
+	BookID          *string    `gorm:"column:book_id"`
+	ReviewedBy      *string    `gorm:"column:reviewed_by"`
+	ReviewedAt      *time.Time `gorm:"column:reviewed_at"`
+	CreatedDate     time.Time  `gorm:"column:created_date"`
+	UpdatedDate     time.Time  `gorm:"column:updated_date"`
+	DeletedDate     *time.Time `gorm:"column:deleted_date"`
+}
@@ -2,17 +2,28 @@ package models
 
 import "time"
 
+// DefaultTimezone is used for users who have not picked a time zone.
+const DefaultTimezone = "UTC"
+
 type User struct {
-	ID           string     `gorm:"column:id"`
-	Email        string     `gorm:"column:email"`
-	PasswordHash string     `gorm:"column:password_hash"`
-	FirstName    string     `gorm:"column:first_name"`
-	LastName     string     `gorm:"column:last_name"`
-	Role         string     `gorm:"column:role"`
-	Status       string     `gorm:"column:status"`
-	CreatedDate  time.Time  `gorm:"column:created_date"`
-	UpdatedDate  time.Time  `gorm:"column:updated_date"`
-	DeletedDate  *time.Time `gorm:"column:deleted_date"`
+	ID                    string     `gorm:"column:id"`
+	Email                 string     `gorm:"column:email"`
+	PasswordHash          string     `gorm:"column:password_hash"`
+	FirstName             string     `gorm:"column:first_name"`
+	LastName              string     `gorm:"column:last_name"`
+	Role                  string     `gorm:"column:role"`
+	Status                string     `gorm:"column:status"`
+	MembershipPlanID      string     `gorm:"column:membership_plan_id"`
+	CardNumber            string     `gorm:"column:card_number"`
+	BranchID              *string    `gorm:"column:branch_id"`
+	Timezone              string     `gorm:"column:timezone"`
+	ReadingHistoryEnabled bool       `gorm:"column:reading_history_enabled"`
+	LastLoginAt           *time.Time `gorm:"column:last_login_at"`
+	FlaggedInactiveAt     *time.Time `gorm:"column:flagged_inactive_at"`
+	Version               int        `gorm:"column:version"`
+	CreatedDate           time.Time  `gorm:"column:created_date"`
+	UpdatedDate           time.Time  `gorm:"column:updated_date"`
+	DeletedDate           *time.Time `gorm:"column:deleted_date"`
 }
 
 func (u *User) GetID() string {
@@ -25,4 +36,17 @@ func (u *User) GetEmail() string {
 
 func (u *User) GetRole() string {
 	return u.Role
-}
\ No newline at end of file
+}
+
+// Location returns the user's display time zone, falling back to UTC when it
+// is unset or unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type UserNote struct {
+	ID          string     `gorm:"column:id"`
+	UserID      string     `gorm:"column:user_id"`
+	AuthorID    string     `gorm:"column:author_id"`
+	Body        string     `gorm:"column:body"`
+	CreatedDate time.Time  `gorm:"column:created_date"`
+	UpdatedDate time.Time  `gorm:"column:updated_date"`
+	DeletedDate *time.Time `gorm:"column:deleted_date"`
+}
@@ -0,0 +1,299 @@
+package notifications
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"book-management-system/pkg/mailer"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	EventDueSoon          = "due_soon"
+	EventOverdue          = "overdue"
+	EventHoldReady        = "hold_ready"
+	EventFineAccrued      = "fine_accrued"
+	EventSavedSearchMatch = "saved_search_match"
+	EventLoanReceipt      = "loan_receipt"
+)
+
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// timeLayout renders time values such as due_date in notification text.
+const timeLayout = "Mon, 2 Jan 2006 15:04 MST"
+
+type Event struct {
+	Type   string
+	UserID string
+	Data   map[string]any
+}
+
+// Channel delivers a rendered notification. data is the event data the
+// notification was rendered from, for channels with their own templates.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, notification *models.Notification, data map[string]any) error
+}
+
+type messageTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+var templates = map[string]messageTemplate{
+	EventDueSoon: {
+		title: template.Must(template.New("due_soon_title").Parse(`"{{.title}}" is due soon`)),
+		body:  template.Must(template.New("due_soon_body").Parse(`Your loan of "{{.title}}" is due on {{.due_date}}.`)),
+	},
+	EventOverdue: {
+		title: template.Must(template.New("overdue_title").Parse(`Your loan of "{{.title}}" has ended`)),
+		body:  template.Must(template.New("overdue_body").Parse(`Your loan of "{{.title}}" was due on {{.due_date}} and has ended.`)),
+	},
+	EventHoldReady: {
+		title: template.Must(template.New("hold_ready_title").Parse(`"{{.title}}" is ready for pickup`)),
+		body:  template.Must(template.New("hold_ready_body").Parse(`Your hold on "{{.title}}" is ready. Pick it up by {{.pickup_by}}.`)),
+	},
+	EventFineAccrued: {
+		title: template.Must(template.New("fine_accrued_title").Parse(`A fine was added to your account`)),
+		body:  template.Must(template.New("fine_accrued_body").Parse(`A fine of {{.amount}} was added for "{{.title}}".`)),
+	},
+	EventSavedSearchMatch: {
+		title: template.Must(template.New("saved_search_match_title").Parse(`New books match "{{.name}}"`)),
+		body:  template.Must(template.New("saved_search_match_body").Parse(`{{if eq .count 1}}"{{.title}}" was just added and matches your saved search "{{.name}}".{{else}}{{.count}} books were just added that match your saved search "{{.name}}", including "{{.title}}".{{end}}`)),
+	},
+	EventLoanReceipt: {
+		title: template.Must(template.New("loan_receipt_title").Parse(`{{if eq .action "return"}}Return{{else}}Checkout{{end}} receipt for "{{.title}}"`)),
+		body:  template.Must(template.New("loan_receipt_body").Parse(`{{if eq .action "return"}}You returned "{{.title}}" on {{.returned_date}}.{{else}}You borrowed "{{.title}}", due on {{.due_date}}.{{end}}{{with .loans}} On loan now: {{range $i, $loan := .}}{{if $i}}, {{end}}"{{$loan.title}}" (due {{$loan.due_date}}){{end}}.{{else}} You have nothing else on loan.{{end}}`)),
+	},
+}
+
+// optInEvents are off on every channel until the user turns them on.
+var optInEvents = map[string]bool{
+	EventLoanReceipt: true,
+}
+
+func EventTypes() []string {
+	return []string{
+		EventDueSoon,
+		EventOverdue,
+		EventHoldReady,
+		EventFineAccrued,
+		EventSavedSearchMatch,
+		EventLoanReceipt,
+	}
+}
+
+func IsEventType(eventType string) bool {
+	_, ok := templates[eventType]
+	return ok
+}
+
+// DefaultEnabled reports whether an event is delivered on a channel the user
+// has stored no preference for.
+func DefaultEnabled(eventType string) bool {
+	return !optInEvents[eventType]
+}
+
+type Notifier struct {
+	notificationRepo *repositories.NotificationRepository
+	userRepo         *repositories.UserRepository
+	channels         []Channel
+}
+
+func NewNotifier(notificationRepo *repositories.NotificationRepository, userRepo *repositories.UserRepository, channels ...Channel) *Notifier {
+	return &Notifier{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		channels:         channels,
+	}
+}
+
+func (n *Notifier) Channels() []string {
+	names := make([]string, len(n.channels))
+	for i, channel := range n.channels {
+		names[i] = channel.Name()
+	}
+	return names
+}
+
+func (n *Notifier) HasChannel(name string) bool {
+	for _, channel := range n.channels {
+		if channel.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Emit renders the event's template and delivers it on every channel the user
+// has not opted out of. Channels without a stored preference are enabled,
+// except for opt-in events. time.Time values in the event data, including
+// those of []map[string]any items, are written in the user's time zone.
+func (n *Notifier) Emit(ctx context.Context, event Event) error {
+	tmpl, ok := templates[event.Type]
+	if !ok {
+		return fmt.Errorf("unknown notification event %q", event.Type)
+	}
+	data, err := n.localTimes(ctx, event.UserID, event.Data)
+	if err != nil {
+		return err
+	}
+	title, err := render(tmpl.title, data)
+	if err != nil {
+		return err
+	}
+	body, err := render(tmpl.body, data)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, channel := range n.channels {
+		enabled, err := n.enabled(event.UserID, event.Type, channel.Name())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !enabled {
+			continue
+		}
+		notification := &models.Notification{
+			ID:        uuid.New().String(),
+			UserID:    event.UserID,
+			EventType: event.Type,
+			Channel:   channel.Name(),
+			Title:     title,
+			Body:      body,
+		}
+		if err := channel.Deliver(ctx, notification, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) localTimes(ctx context.Context, userID string, data map[string]any) (map[string]any, error) {
+	var location *time.Location
+	local := make(map[string]any, len(data))
+	for key, value := range data {
+		switch value := value.(type) {
+		case time.Time:
+			if location == nil {
+				user, err := n.userRepo.GetByID(ctx, userID)
+				if err != nil {
+					return nil, err
+				}
+				location = user.Location()
+			}
+			local[key] = value.In(location).Format(timeLayout)
+		case []map[string]any:
+			items := make([]map[string]any, len(value))
+			for i, item := range value {
+				localItem, err := n.localTimes(ctx, userID, item)
+				if err != nil {
+					return nil, err
+				}
+				items[i] = localItem
+			}
+			local[key] = items
+		default:
+			local[key] = value
+		}
+	}
+	return local, nil
+}
+
+func (n *Notifier) enabled(userID, eventType, channel string) (bool, error) {
+	preference, err := n.notificationRepo.GetPreference(userID, eventType, channel)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return DefaultEnabled(eventType), nil
+		}
+		return false, err
+	}
+	return preference.Enabled, nil
+}
+
+func render(tmpl *template.Template, data map[string]any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type InAppChannel struct {
+	notificationRepo *repositories.NotificationRepository
+}
+
+func NewInAppChannel(notificationRepo *repositories.NotificationRepository) *InAppChannel {
+	return &InAppChannel{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (c *InAppChannel) Name() string {
+	return ChannelInApp
+}
+
+func (c *InAppChannel) Deliver(ctx context.Context, notification *models.Notification, data map[string]any) error {
+	return c.notificationRepo.Create(notification)
+}
+
+// emailTemplates maps events to the email template that replaces the generic
+// notification email.
+var emailTemplates = map[string]string{
+	EventDueSoon:     mailer.TemplateDueNotice,
+	EventOverdue:     mailer.TemplateOverdueNotice,
+	EventLoanReceipt: mailer.TemplateLoanReceipt,
+}
+
+// EmailChannel emails notifications to the user's address.
+type EmailChannel struct {
+	userRepo *repositories.UserRepository
+	mailer   mailer.Mailer
+}
+
+func NewEmailChannel(userRepo *repositories.UserRepository, m mailer.Mailer) *EmailChannel {
+	return &EmailChannel{
+		userRepo: userRepo,
+		mailer:   m,
+	}
+}
+
+func (c *EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (c *EmailChannel) Deliver(ctx context.Context, notification *models.Notification, data map[string]any) error {
+	user, err := c.userRepo.GetByID(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	emailData := make(map[string]any, len(data)+3)
+	for key, value := range data {
+		emailData[key] = value
+	}
+	emailData["name"] = user.FirstName
+	emailData["subject"] = notification.Title
+	emailData["body"] = notification.Body
+	name, ok := emailTemplates[notification.EventType]
+	if !ok {
+		name = mailer.TemplateNotification
+	}
+	msg, err := mailer.Render(name, emailData)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+	return c.mailer.Send(ctx, msg)
+}
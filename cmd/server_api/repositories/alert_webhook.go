@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AlertWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertWebhookRepository(db *gorm.DB) *AlertWebhookRepository {
+	return &AlertWebhookRepository{
+		db: db,
+	}
+}
+
+func (r *AlertWebhookRepository) Create(ctx context.Context, webhook *models.AlertWebhook) error {
+	now := time.Now().UTC()
+	webhook.CreatedDate = now
+	webhook.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (r *AlertWebhookRepository) GetByID(ctx context.Context, id string) (*models.AlertWebhook, error) {
+	var webhook models.AlertWebhook
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND deleted_date IS NULL", id).
+		First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *AlertWebhookRepository) GetAll(ctx context.Context) ([]models.AlertWebhook, error) {
+	var webhooks []models.AlertWebhook
+	err := r.db.WithContext(ctx).
+		Where("deleted_date IS NULL").
+		Order("name ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *AlertWebhookRepository) GetEnabled(ctx context.Context) ([]models.AlertWebhook, error) {
+	var webhooks []models.AlertWebhook
+	err := r.db.WithContext(ctx).
+		Where("enabled = ? AND deleted_date IS NULL", true).
+		Order("name ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *AlertWebhookRepository) NameExists(ctx context.Context, name, excludeID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.AlertWebhook{}).
+		Where("name = ? AND id <> ? AND deleted_date IS NULL", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *AlertWebhookRepository) Update(ctx context.Context, webhook *models.AlertWebhook) error {
+	webhook.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(webhook).Error
+}
+
+// RecordDelivery stores the outcome of a post to the webhook. A nil cause
+// records a successful delivery at deliveredAt and clears the last error.
+func (r *AlertWebhookRepository) RecordDelivery(ctx context.Context, id string, deliveredAt time.Time, cause error) error {
+	updates := map[string]any{
+		"last_delivered_at": deliveredAt,
+		"last_error":        nil,
+	}
+	if cause != nil {
+		updates = map[string]any{
+			"last_error": cause.Error(),
+		}
+	}
+	return r.db.WithContext(ctx).Model(&models.AlertWebhook{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(updates).Error
+}
+
+func (r *AlertWebhookRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.AlertWebhook{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows audit log reads. Empty fields and nil times match
+// everything.
+type AuditLogFilter struct {
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ActorActivity totals one actor's audited mutations.
+type ActorActivity struct {
+	ActorID        string
+	ActorEmail     string
+	Actions        int
+	BooksCataloged int
+}
+
+// AuditLogRepository only appends and reads; audit rows are never changed.
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	entry.CreatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *AuditLogRepository) GetAll(ctx context.Context, filter AuditLogFilter, limit, offset int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	err := r.scope(ctx, filter).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date DESC, id DESC").
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *AuditLogRepository) Count(ctx context.Context, filter AuditLogFilter) (int64, error) {
+	var count int64
+	err := r.scope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// GetActivityByActor totals the entries created in [from, to) per actor, most
+// active first. An actor who changed e-mail is listed under one of them.
+func (r *AuditLogRepository) GetActivityByActor(ctx context.Context, from, to time.Time) ([]ActorActivity, error) {
+	var activity []ActorActivity
+	err := r.db.WithContext(ctx).Model(&models.AuditLog{}).
+		Select("actor_id, MAX(actor_email) AS actor_email, COUNT(*) AS actions, "+
+			"SUM(CASE WHEN entity_type = ? AND action = ? THEN 1 ELSE 0 END) AS books_cataloged",
+			"book", models.AuditActionCreate).
+		Where("created_date >= ? AND created_date < ?", from, to).
+		Group("actor_id").
+		Order("actions DESC, actor_id ASC").
+		Scan(&activity).Error
+	return activity, err
+}
+
+func (r *AuditLogRepository) scope(ctx context.Context, filter AuditLogFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_date < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/migrations"
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// BackupTables lists the tables a backup holds, each after the tables it
+// references, so restoring them in order satisfies the foreign keys.
+var BackupTables = []string{
+	"membership_plans",
+	"branches",
+	"users",
+	"books",
+	"book_holdings",
+	"book_files",
+	"book_covers",
+	"digital_loans",
+	"loan_reminders",
+	"library_events",
+	"event_rsvps",
+	"resources",
+	"reservations",
+	"devices",
+	"saved_searches",
+	"cash_drawer_sessions",
+	"cash_drawer_payments",
+}
+
+var backupModels = map[string]any{
+	"membership_plans":     &models.MembershipPlan{},
+	"branches":             &models.Branch{},
+	"users":                &models.User{},
+	"books":                &models.Book{},
+	"book_holdings":        &models.BookHolding{},
+	"book_files":           &models.BookFile{},
+	"book_covers":          &models.BookCover{},
+	"digital_loans":        &models.DigitalLoan{},
+	"loan_reminders":       &models.LoanReminder{},
+	"library_events":       &models.LibraryEvent{},
+	"event_rsvps":          &models.EventRSVP{},
+	"resources":            &models.Resource{},
+	"reservations":         &models.Reservation{},
+	"devices":              &models.Device{},
+	"saved_searches":       &models.SavedSearch{},
+	"cash_drawer_sessions": &models.CashDrawerSession{},
+	"cash_drawer_payments": &models.CashDrawerPayment{},
+}
+
+// BackupRow is one row of a backup table, keyed by column name.
+type BackupRow map[string]json.RawMessage
+
+// BackupRepository reads and writes whole tables for logical backups. Rows
+// are keyed by column name, so a backup stays readable without the models.
+type BackupRepository struct {
+	db *gorm.DB
+}
+
+func NewBackupRepository(db *gorm.DB) *BackupRepository {
+	return &BackupRepository{
+		db: db,
+	}
+}
+
+// SchemaVersion returns the latest migration applied to the database, which
+// a backup records so it is only restored into a schema that has its columns.
+func (r *BackupRepository) SchemaVersion(ctx context.Context) (int64, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return 0, err
+	}
+	return migrations.Version(ctx, sqlDB, r.db.Dialector.Name())
+}
+
+// EachRow calls fn for every batch of rows in table, soft-deleted ones
+// included.
+func (r *BackupRepository) EachRow(ctx context.Context, table string, batchSize int, fn func([]map[string]any) error) error {
+	tableSchema, err := r.schema(table)
+	if err != nil {
+		return err
+	}
+	batch := reflect.New(reflect.SliceOf(tableSchema.ModelType))
+	return r.db.WithContext(ctx).
+		Table(table).
+		Order("id").
+		FindInBatches(batch.Interface(), batchSize, func(tx *gorm.DB, _ int) error {
+			records := batch.Elem()
+			rows := make([]map[string]any, records.Len())
+			for i := range rows {
+				rows[i] = make(map[string]any, len(tableSchema.Fields))
+				for _, field := range tableSchema.Fields {
+					if field.DBName == "" {
+						continue
+					}
+					rows[i][field.DBName] = records.Index(i).FieldByIndex(field.StructField.Index).Interface()
+				}
+			}
+			return fn(rows)
+		}).Error
+}
+
+// Restore upserts every row next returns, until io.EOF, in one transaction.
+// Rows in the backup overwrite the rows with the same ID; rows created since
+// are kept. Columns the backup lacks, because they were added after it was
+// taken, are left alone on existing rows and empty on inserted ones. It
+// returns the number of rows restored per table.
+func (r *BackupRepository) Restore(ctx context.Context, batchSize int, next func() (string, BackupRow, error)) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var (
+			table       string
+			tableSchema *schema.Schema
+			columns     []string
+			batch       reflect.Value
+		)
+		flush := func() error {
+			if table == "" || batch.Len() == 0 {
+				return nil
+			}
+			err := tx.Table(table).
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "id"}},
+					DoUpdates: clause.AssignmentColumns(columns),
+				}).
+				Create(batch.Addr().Interface()).Error
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", table, err)
+			}
+			counts[table] += batch.Len()
+			batch.SetLen(0)
+			return nil
+		}
+		for {
+			rowTable, row, err := next()
+			if err == io.EOF {
+				return flush()
+			}
+			if err != nil {
+				return err
+			}
+			if rowTable != table {
+				if err := flush(); err != nil {
+					return err
+				}
+				tableSchema, err = r.schema(rowTable)
+				if err != nil {
+					return err
+				}
+				table = rowTable
+				columns = nil
+				for _, field := range tableSchema.Fields {
+					if _, ok := row[field.DBName]; ok && field.DBName != "" && !field.PrimaryKey {
+						columns = append(columns, field.DBName)
+					}
+				}
+				batch = reflect.New(reflect.SliceOf(tableSchema.ModelType)).Elem()
+			}
+			record := reflect.New(tableSchema.ModelType).Elem()
+			for _, field := range tableSchema.Fields {
+				value, ok := row[field.DBName]
+				if !ok || field.DBName == "" {
+					continue
+				}
+				target := record.FieldByIndex(field.StructField.Index).Addr().Interface()
+				if err := json.Unmarshal(value, target); err != nil {
+					return fmt.Errorf("restore %s.%s: %w", table, field.DBName, err)
+				}
+			}
+			batch.Set(reflect.Append(batch, record))
+			if batch.Len() >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *BackupRepository) schema(table string) (*schema.Schema, error) {
+	model, ok := backupModels[table]
+	if !ok {
+		return nil, fmt.Errorf("table %q is not part of backups", table)
+	}
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
@@ -2,12 +2,72 @@ package repositories
 
 import (
 	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	// ErrBookHasHoldings is returned when a book's quantity is set directly
+	// but it is managed through branch holdings.
+	ErrBookHasHoldings = errors.New("book quantity is managed by holdings")
+	// ErrVersionConflict is returned when a book or user changed after the
+	// caller loaded it, so saving would overwrite someone else's edit.
+	ErrVersionConflict = errors.New("record was changed by another request")
+)
+
+// BookFilter narrows book listings. Empty fields match everything; a zero
+// MinRating includes unrated books. Title and Author match substrings, ISBN
+// ignores hyphens and spaces, and Keyword matches the same fields as
+// SearchBooks. AddedSince matches books created at or after it and
+// AddedBefore those created before it.
+type BookFilter struct {
+	Status      string
+	Genre       string
+	Author      string
+	Title       string
+	ISBN        string
+	Keyword     string
+	MinRating   float64
+	AddedSince  time.Time
+	AddedBefore time.Time
+}
+
+//go:generate mockgen -source=book.go -destination=mocks/book.go -package=mocks
+
+// BookRepo is the book data access used by the API handlers. BookRepository
+// implements it; mocks.MockBookRepo stands in for it in handler tests.
+type BookRepo interface {
+	Create(ctx context.Context, book *models.Book) error
+	CreateBatch(ctx context.Context, books []models.Book, batchSize int) (int64, error)
+	GetByID(ctx context.Context, id string) (*models.Book, error)
+	GetByIDs(ctx context.Context, ids []string) ([]models.Book, error)
+	GetAll(ctx context.Context, limit, offset int) ([]models.Book, error)
+	GetFiltered(ctx context.Context, filter BookFilter, limit, offset int) ([]models.Book, error)
+	CountFiltered(ctx context.Context, filter BookFilter) (int64, error)
+	GetByGenre(ctx context.Context, genre string, limit, offset int) ([]models.Book, error)
+	SearchByTitle(ctx context.Context, title string, minRating float64, limit, offset int) ([]models.Book, error)
+	SearchBooks(ctx context.Context, query string, minRating float64, limit, offset int) ([]models.Book, error)
+	GetAvailable(ctx context.Context, limit, offset int) ([]models.Book, error)
+	GetLowStock(ctx context.Context, threshold int) ([]models.Book, error)
+	Update(ctx context.Context, book *models.Book) error
+	Delete(ctx context.Context, id string) error
+	Count(ctx context.Context) (int64, error)
+	CountByStatus(ctx context.Context, status string) (int64, error)
+	CountAvailable(ctx context.Context) (int64, error)
+	ISBNExists(ctx context.Context, isbn string) (bool, error)
+	UpdateQuantity(ctx context.Context, id string, quantity, availableQuantity int) error
+	AtBranch(branchID string) BookRepo
+	AvailableAtBranch(branchID string) BookRepo
+	GetHoldings(ctx context.Context, bookID string) ([]models.BookHolding, error)
+	SetHolding(ctx context.Context, holding *models.BookHolding) error
+	DeleteHolding(ctx context.Context, bookID, branchID string) error
+}
+
 type BookRepository struct {
 	db *gorm.DB
 }
@@ -18,35 +78,101 @@ func NewBookRepository(db *gorm.DB) *BookRepository {
 	}
 }
 
-func (r *BookRepository) Create(book *models.Book) error {
+func (r *BookRepository) Create(ctx context.Context, book *models.Book) error {
 	now := time.Now().UTC()
 	book.CreatedDate = now
 	book.UpdatedDate = now
-	return r.db.Create(book).Error
+	book.Version = 1
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(book).Error; err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "book", book.ID, EventBookCreated, book)
+	})
 }
 
-func (r *BookRepository) GetByID(id string) (*models.Book, error) {
+// CreateBatch inserts books batchSize rows per statement in one transaction.
+// A book whose ISBN is already taken, by a live or deleted book or by an
+// earlier book in the same call, is skipped rather than failing the batch.
+// It returns how many books were inserted; only those get a book.created
+// event.
+func (r *BookRepository) CreateBatch(ctx context.Context, books []models.Book, batchSize int) (int64, error) {
+	if len(books) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UTC()
+	for i := range books {
+		books[i].CreatedDate = now
+		books[i].UpdatedDate = now
+		books[i].Version = 1
+	}
+	var created int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&books, batchSize).Error
+		if err != nil {
+			return err
+		}
+		inserted := make(map[string]bool, len(books))
+		for start := 0; start < len(books); start += batchSize {
+			end := min(start+batchSize, len(books))
+			ids := make([]string, 0, end-start)
+			for _, book := range books[start:end] {
+				ids = append(ids, book.ID)
+			}
+			// IDs are generated by the caller, so any of them found now was
+			// inserted by this call.
+			var found []string
+			if err := tx.Model(&models.Book{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+				return err
+			}
+			for _, id := range found {
+				inserted[id] = true
+			}
+		}
+		events := make([]*models.OutboxEvent, 0, len(inserted))
+		for i := range books {
+			if !inserted[books[i].ID] {
+				continue
+			}
+			event, err := newOutboxEvent("book", books[i].ID, EventBookCreated, &books[i])
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		created = int64(len(events))
+		if len(events) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(events, batchSize).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+func (r *BookRepository) GetByID(ctx context.Context, id string) (*models.Book, error) {
 	var book models.Book
-	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&book).Error
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&book).Error
 	if err != nil {
 		return nil, err
 	}
 	return &book, nil
 }
 
-func (r *BookRepository) GetAll(limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Book, error) {
 	var books []models.Book
-	err := r.db.Where("deleted_date IS NULL").
-		Limit(limit).
-		Offset(offset).
-		Order("created_date DESC").
-		Find(&books).Error
+	if len(ids) == 0 {
+		return books, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ? AND deleted_date IS NULL", ids).Find(&books).Error
 	return books, err
 }
 
-func (r *BookRepository) GetByStatus(status string, limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) GetAll(ctx context.Context, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
-	err := r.db.Where("status = ? AND deleted_date IS NULL", status).
+	err := r.db.WithContext(ctx).Where("deleted_date IS NULL").
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -54,19 +180,69 @@ func (r *BookRepository) GetByStatus(status string, limit, offset int) ([]models
 	return books, err
 }
 
-func (r *BookRepository) GetByGenre(genre string, limit, offset int) ([]models.Book, error) {
+// GetFiltered lists books matching every field set in filter, newest first,
+// or best rated first when filter.MinRating is set.
+func (r *BookRepository) GetFiltered(ctx context.Context, filter BookFilter, limit, offset int) ([]models.Book, error) {
+	order := "created_date DESC"
+	if filter.MinRating > 0 {
+		order = "rating_average DESC, created_date DESC"
+	}
 	var books []models.Book
-	err := r.db.Where("genre = ? AND deleted_date IS NULL", genre).
+	err := r.filterScope(ctx, filter).
 		Limit(limit).
 		Offset(offset).
-		Order("created_date DESC").
+		Order(order).
 		Find(&books).Error
 	return books, err
 }
 
-func (r *BookRepository) GetByAuthor(author string, limit, offset int) ([]models.Book, error) {
+// CountFiltered counts the books GetFiltered pages through.
+func (r *BookRepository) CountFiltered(ctx context.Context, filter BookFilter) (int64, error) {
+	var count int64
+	err := r.filterScope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *BookRepository) filterScope(ctx context.Context, filter BookFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Book{}).Where("deleted_date IS NULL")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Genre != "" {
+		query = query.Where("genre = ?", filter.Genre)
+	}
+	if filter.Author != "" {
+		query = query.Where("LOWER(author) LIKE LOWER(?)", "%"+filter.Author+"%")
+	}
+	if filter.Title != "" {
+		query = query.Where("LOWER(title) LIKE LOWER(?)", "%"+filter.Title+"%")
+	}
+	if filter.ISBN != "" {
+		isbn := strings.NewReplacer("-", "", " ", "").Replace(filter.ISBN)
+		query = query.Where("REPLACE(REPLACE(isbn, '-', ''), ' ', '') = ?", isbn)
+	}
+	if filter.Keyword != "" {
+		searchTerm := "%" + strings.ToLower(filter.Keyword) + "%"
+		query = query.Where(
+			"(LOWER(title) LIKE ? OR LOWER(author) LIKE ? OR LOWER(genre) LIKE ? OR isbn LIKE ?)",
+			searchTerm, searchTerm, searchTerm, "%"+filter.Keyword+"%",
+		)
+	}
+	if filter.MinRating > 0 {
+		query = query.Where("rating_average >= ? AND rating_count > 0", filter.MinRating)
+	}
+	if !filter.AddedSince.IsZero() {
+		query = query.Where("created_date >= ?", filter.AddedSince)
+	}
+	if !filter.AddedBefore.IsZero() {
+		query = query.Where("created_date < ?", filter.AddedBefore)
+	}
+	return query
+}
+
+func (r *BookRepository) GetByGenre(ctx context.Context, genre string, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
-	err := r.db.Where("LOWER(author) LIKE LOWER(?) AND deleted_date IS NULL", "%"+author+"%").
+	err := r.db.WithContext(ctx).Where("genre = ? AND deleted_date IS NULL", genre).
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -74,9 +250,9 @@ func (r *BookRepository) GetByAuthor(author string, limit, offset int) ([]models
 	return books, err
 }
 
-func (r *BookRepository) SearchByTitle(title string, limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) SearchByTitle(ctx context.Context, title string, minRating float64, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
-	err := r.db.Where("LOWER(title) LIKE LOWER(?) AND deleted_date IS NULL", "%"+title+"%").
+	err := r.db.WithContext(ctx).Where("LOWER(title) LIKE LOWER(?) AND rating_average >= ? AND deleted_date IS NULL", "%"+title+"%", minRating).
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -84,12 +260,12 @@ func (r *BookRepository) SearchByTitle(title string, limit, offset int) ([]model
 	return books, err
 }
 
-func (r *BookRepository) SearchBooks(query string, limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) SearchBooks(ctx context.Context, query string, minRating float64, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
 	searchTerm := "%" + strings.ToLower(query) + "%"
-	err := r.db.Where(
-		"(LOWER(title) LIKE ? OR LOWER(author) LIKE ? OR LOWER(genre) LIKE ? OR isbn LIKE ?) AND deleted_date IS NULL",
-		searchTerm, searchTerm, searchTerm, "%"+query+"%",
+	err := r.db.WithContext(ctx).Where(
+		"(LOWER(title) LIKE ? OR LOWER(author) LIKE ? OR LOWER(genre) LIKE ? OR isbn LIKE ?) AND rating_average >= ? AND deleted_date IS NULL",
+		searchTerm, searchTerm, searchTerm, "%"+query+"%", minRating,
 	).
 		Limit(limit).
 		Offset(offset).
@@ -98,9 +274,9 @@ func (r *BookRepository) SearchBooks(query string, limit, offset int) ([]models.
 	return books, err
 }
 
-func (r *BookRepository) GetAvailable(limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) GetAvailable(ctx context.Context, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
-	err := r.db.Where("available_quantity > 0 AND status = 'active' AND deleted_date IS NULL").
+	err := r.db.WithContext(ctx).Where("available_quantity > 0 AND status = ? AND deleted_date IS NULL", models.BookStatusActive).
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -108,54 +284,287 @@ func (r *BookRepository) GetAvailable(limit, offset int) ([]models.Book, error)
 	return books, err
 }
 
-func (r *BookRepository) Update(book *models.Book) error {
+// GetLowStock returns the books that have copies but no more than threshold
+// of them available, fewest available first. Books still on order are not
+// low on stock.
+func (r *BookRepository) GetLowStock(ctx context.Context, threshold int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.WithContext(ctx).
+		Where("available_quantity <= ? AND quantity > 0 AND status <> ? AND deleted_date IS NULL", threshold, models.BookStatusOnOrder).
+		Order("available_quantity ASC, title ASC").
+		Find(&books).Error
+	return books, err
+}
+
+// Update saves the book if it is still at the version it was loaded at and
+// bumps the version; otherwise it returns ErrVersionConflict. Ratings are
+// maintained by reviews and are not written. Quantities can only change for
+// a book without holdings; otherwise it returns ErrBookHasHoldings, as
+// UpdateQuantity does.
+func (r *BookRepository) Update(ctx context.Context, book *models.Book) error {
 	book.UpdatedDate = time.Now().UTC()
-	return r.db.Save(book).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored, err := lockBook(tx, book.ID)
+		if err != nil {
+			return err
+		}
+		if stored.Quantity != book.Quantity || stored.AvailableQuantity != book.AvailableQuantity {
+			holdings, err := countHoldings(tx, book.ID)
+			if err != nil {
+				return err
+			}
+			if holdings > 0 {
+				return ErrBookHasHoldings
+			}
+		}
+		if err := saveVersioned(tx, book, book.ID, &book.Version, "rating_average", "rating_count"); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "book", book.ID, EventBookUpdated, book)
+	})
 }
 
-func (r *BookRepository) Delete(id string) error {
+func (r *BookRepository) Delete(ctx context.Context, id string) error {
 	now := time.Now().UTC()
-	return r.db.Model(&models.Book{}).
-		Where("id = ? AND deleted_date IS NULL", id).
-		Update("deleted_date", now).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Book{}).
+			Where("id = ? AND deleted_date IS NULL", id).
+			Update("deleted_date", now).Error
+		if err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "book", id, EventBookDeleted, map[string]any{
+			"id":           id,
+			"deleted_date": now,
+		})
+	})
 }
 
-func (r *BookRepository) Count() (int64, error) {
+func (r *BookRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Book{}).Where("deleted_date IS NULL").Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Book{}).Where("deleted_date IS NULL").Count(&count).Error
 	return count, err
 }
 
-func (r *BookRepository) CountByStatus(status string) (int64, error) {
+func (r *BookRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Book{}).
+	err := r.db.WithContext(ctx).Model(&models.Book{}).
 		Where("status = ? AND deleted_date IS NULL", status).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *BookRepository) CountAvailable() (int64, error) {
+func (r *BookRepository) CountAvailable(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Book{}).
-		Where("available_quantity > 0 AND status = 'active' AND deleted_date IS NULL").
+	err := r.db.WithContext(ctx).Model(&models.Book{}).
+		Where("available_quantity > 0 AND status = ? AND deleted_date IS NULL", models.BookStatusActive).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *BookRepository) ISBNExists(isbn string) (bool, error) {
+func (r *BookRepository) ISBNExists(ctx context.Context, isbn string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Book{}).
+	err := r.db.WithContext(ctx).Model(&models.Book{}).
 		Where("isbn = ? AND deleted_date IS NULL", isbn).
 		Count(&count).Error
 	return count > 0, err
 }
 
-func (r *BookRepository) UpdateQuantity(id string, quantity, availableQuantity int) error {
-	return r.db.Model(&models.Book{}).
-		Where("id = ? AND deleted_date IS NULL", id).
-		Updates(map[string]any{
+// UpdateQuantity sets the quantities of a book without holdings. It returns
+// ErrBookHasHoldings if the book gained holdings since the caller checked.
+func (r *BookRepository) UpdateQuantity(ctx context.Context, id string, quantity, availableQuantity int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		book, err := lockBook(tx, id)
+		if err != nil {
+			return err
+		}
+		if book.DeletedDate != nil {
+			return gorm.ErrRecordNotFound
+		}
+		holdings, err := countHoldings(tx, id)
+		if err != nil {
+			return err
+		}
+		if holdings > 0 {
+			return ErrBookHasHoldings
+		}
+		err = tx.Model(&models.Book{}).
+			Where("id = ? AND deleted_date IS NULL", id).
+			Updates(map[string]any{
+				"quantity":           quantity,
+				"available_quantity": availableQuantity,
+				"version":            gorm.Expr("version + 1"),
+				"updated_date":       time.Now().UTC(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "book", id, EventBookQuantityUpdated, map[string]any{
+			"id":                 id,
 			"quantity":           quantity,
 			"available_quantity": availableQuantity,
-			"updated_date":       time.Now().UTC(),
+		})
+	})
+}
+
+// AtBranch returns a read-only view of the repository whose queries only
+// match books with holdings at the branch.
+func (r *BookRepository) AtBranch(branchID string) BookRepo {
+	return r.withHolding("book_holdings.branch_id = ?", branchID)
+}
+
+// AvailableAtBranch is like AtBranch but only matches books with copies
+// available at the branch.
+func (r *BookRepository) AvailableAtBranch(branchID string) BookRepo {
+	return r.withHolding("book_holdings.branch_id = ? AND book_holdings.available_quantity > 0", branchID)
+}
+
+func (r *BookRepository) withHolding(condition string, args ...any) *BookRepository {
+	return &BookRepository{
+		db: r.db.Where(
+			"EXISTS (SELECT 1 FROM book_holdings WHERE book_holdings.book_id = books.id AND book_holdings.deleted_date IS NULL AND "+condition+")",
+			args...,
+		).Session(&gorm.Session{}),
+	}
+}
+
+func (r *BookRepository) GetHoldings(ctx context.Context, bookID string) ([]models.BookHolding, error) {
+	var holdings []models.BookHolding
+	err := r.db.WithContext(ctx).Where("book_id = ? AND deleted_date IS NULL", bookID).
+		Order("created_date ASC").
+		Find(&holdings).Error
+	return holdings, err
+}
+
+// SetHolding creates or replaces the book's holding at a branch. The book's
+// quantity and available_quantity become the sum over its holdings.
+func (r *BookRepository) SetHolding(ctx context.Context, holding *models.BookHolding) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockBook(tx, holding.BookID); err != nil {
+			return err
+		}
+		var existing models.BookHolding
+		err := tx.Where("book_id = ? AND branch_id = ? AND deleted_date IS NULL", holding.BookID, holding.BranchID).
+			First(&existing).Error
+		switch {
+		case err == nil:
+			holding.ID = existing.ID
+			holding.CreatedDate = existing.CreatedDate
+			holding.UpdatedDate = now
+			err = tx.Save(holding).Error
+		case err == gorm.ErrRecordNotFound:
+			holding.CreatedDate = now
+			holding.UpdatedDate = now
+			err = tx.Create(holding).Error
+		}
+		if err != nil {
+			return err
+		}
+		return syncHoldingTotals(tx, holding.BookID, now)
+	})
+}
+
+func (r *BookRepository) DeleteHolding(ctx context.Context, bookID, branchID string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockBook(tx, bookID); err != nil {
+			return err
+		}
+		result := tx.Model(&models.BookHolding{}).
+			Where("book_id = ? AND branch_id = ? AND deleted_date IS NULL", bookID, branchID).
+			Update("deleted_date", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return syncHoldingTotals(tx, bookID, now)
+	})
+}
+
+// saveVersioned writes every column of model except id, created_date and
+// omit, provided the row is live and still at *version, and increments
+// *version.
+func saveVersioned(tx *gorm.DB, model any, id string, version *int, omit ...string) error {
+	loaded := *version
+	*version = loaded + 1
+	result := tx.Model(model).
+		Where("id = ? AND version = ? AND deleted_date IS NULL", id, loaded).
+		Select("*").
+		Omit(append([]string{"id", "created_date"}, omit...)...).
+		Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = loaded
+	}
+	return result.Error
+}
+
+// lockBook locks the book's row, deleted or not, until tx ends. Every
+// transaction that changes a book's quantities or holdings takes this lock
+// first, so concurrent changes cannot recompute the totals from stale sums.
+// SQLite has no row locks but serializes writers anyway.
+func lockBook(tx *gorm.DB, id string) (*models.Book, error) {
+	var book models.Book
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&book).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// countHoldings counts the book's live holdings.
+func countHoldings(tx *gorm.DB, bookID string) (int64, error) {
+	var holdings int64
+	err := tx.Model(&models.BookHolding{}).
+		Where("book_id = ? AND deleted_date IS NULL", bookID).
+		Count(&holdings).Error
+	return holdings, err
+}
+
+// syncHoldingTotals must run after lockBook in the same transaction.
+func syncHoldingTotals(tx *gorm.DB, bookID string, now time.Time) error {
+	var totals struct {
+		Quantity          int
+		AvailableQuantity int
+	}
+	err := tx.Model(&models.BookHolding{}).
+		Select("COALESCE(SUM(quantity), 0) AS quantity, COALESCE(SUM(available_quantity), 0) AS available_quantity").
+		Where("book_id = ? AND deleted_date IS NULL", bookID).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+	// Copies in transit between branches are still owned, just not on a shelf.
+	var inTransit int
+	err = tx.Model(&models.BookTransfer{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("book_id = ? AND status = ? AND deleted_date IS NULL", bookID, models.TransferStatusInTransit).
+		Scan(&inTransit).Error
+	if err != nil {
+		return err
+	}
+	totals.Quantity += inTransit
+	err = tx.Model(&models.Book{}).
+		Where("id = ? AND deleted_date IS NULL", bookID).
+		Updates(map[string]any{
+			"quantity":           totals.Quantity,
+			"available_quantity": totals.AvailableQuantity,
+			"version":            gorm.Expr("version + 1"),
+			"updated_date":       now,
 		}).Error
-}
\ No newline at end of file
+	if err != nil {
+		return err
+	}
+	return addOutboxEvent(tx, "book", bookID, EventBookQuantityUpdated, map[string]any{
+		"id":                 bookID,
+		"quantity":           totals.Quantity,
+		"available_quantity": totals.AvailableQuantity,
+	})
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookCoverRepository struct {
+	db *gorm.DB
+}
+
+func NewBookCoverRepository(db *gorm.DB) *BookCoverRepository {
+	return &BookCoverRepository{
+		db: db,
+	}
+}
+
+func (r *BookCoverRepository) GetByBook(ctx context.Context, bookID string) (*models.BookCover, error) {
+	var cover models.BookCover
+	err := r.db.WithContext(ctx).Where("book_id = ?", bookID).First(&cover).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cover, nil
+}
+
+// Save stores cover as the book's cover. When it replaces a cover it returns
+// the replaced cover, whose object the caller deletes.
+func (r *BookCoverRepository) Save(ctx context.Context, cover *models.BookCover) (*models.BookCover, error) {
+	now := time.Now().UTC()
+	var replaced *models.BookCover
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.BookCover
+		err := tx.Where("book_id = ?", cover.BookID).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			cover.CreatedDate = now
+			cover.UpdatedDate = now
+			return tx.Create(cover).Error
+		}
+		if err != nil {
+			return err
+		}
+		replaced = &existing
+		cover.ID = existing.ID
+		cover.CreatedDate = existing.CreatedDate
+		cover.UpdatedDate = now
+		return tx.Save(cover).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrEnrichmentReviewed is returned when an enrichment was applied or
+// rejected since the caller loaded it.
+var ErrEnrichmentReviewed = errors.New("enrichment already reviewed")
+
+type BookEnrichmentRepository struct {
+	db *gorm.DB
+}
+
+func NewBookEnrichmentRepository(db *gorm.DB) *BookEnrichmentRepository {
+	return &BookEnrichmentRepository{
+		db: db,
+	}
+}
+
+func (r *BookEnrichmentRepository) Create(ctx context.Context, enrichment *models.BookEnrichment) error {
+	now := time.Now().UTC()
+	enrichment.CreatedDate = now
+	enrichment.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(enrichment).Error
+}
+
+func (r *BookEnrichmentRepository) GetByID(ctx context.Context, id string) (*models.BookEnrichment, error) {
+	var enrichment models.BookEnrichment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&enrichment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrichment, nil
+}
+
+// GetAll lists enrichments, oldest first, optionally only those in status.
+func (r *BookEnrichmentRepository) GetAll(ctx context.Context, status string, limit, offset int) ([]models.BookEnrichment, error) {
+	var enrichments []models.BookEnrichment
+	err := r.statusScope(ctx, status).
+		Order("created_date ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&enrichments).Error
+	return enrichments, err
+}
+
+func (r *BookEnrichmentRepository) Count(ctx context.Context, status string) (int64, error) {
+	var count int64
+	err := r.statusScope(ctx, status).Count(&count).Error
+	return count, err
+}
+
+func (r *BookEnrichmentRepository) statusScope(ctx context.Context, status string) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.BookEnrichment{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return query
+}
+
+// GetCandidates lists books with an ISBN that lack a description, page count
+// or cover, oldest first. Books with a pending or rejected enrichment are
+// skipped, as are books a lookup found nothing for after noMatchSince.
+func (r *BookEnrichmentRepository) GetCandidates(ctx context.Context, noMatchSince time.Time, limit int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.WithContext(ctx).
+		Where("deleted_date IS NULL AND isbn IS NOT NULL AND isbn <> ''").
+		Where("(description IS NULL OR description = '' OR pages IS NULL OR cover_url IS NULL OR cover_url = '')").
+		Where(
+			"NOT EXISTS (SELECT 1 FROM book_enrichments e WHERE e.book_id = books.id AND (e.status IN ? OR (e.status = ? AND e.created_date > ?)))",
+			[]string{models.EnrichmentStatusPending, models.EnrichmentStatusRejected},
+			models.EnrichmentStatusNoMatch,
+			noMatchSince,
+		).
+		Order("created_date ASC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+// Apply saves book, with the enrichment's values copied in by the caller,
+// and marks the enrichment applied, in one transaction.
+func (r *BookEnrichmentRepository) Apply(ctx context.Context, enrichment *models.BookEnrichment, book *models.Book, reviewerID string) error {
+	now := time.Now().UTC()
+	book.UpdatedDate = now
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := markReviewed(tx, enrichment, models.EnrichmentStatusApplied, reviewerID, now); err != nil {
+			return err
+		}
+		if err := saveVersioned(tx, book, book.ID, &book.Version, "rating_average", "rating_count"); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "book", book.ID, EventBookUpdated, book)
+	})
+}
+
+func (r *BookEnrichmentRepository) Reject(ctx context.Context, enrichment *models.BookEnrichment, reviewerID string) error {
+	return markReviewed(r.db.WithContext(ctx), enrichment, models.EnrichmentStatusRejected, reviewerID, time.Now().UTC())
+}
+
+func markReviewed(tx *gorm.DB, enrichment *models.BookEnrichment, status, reviewerID string, now time.Time) error {
+	result := tx.Model(&models.BookEnrichment{}).
+		Where("id = ? AND status = ?", enrichment.ID, models.EnrichmentStatusPending).
+		Updates(map[string]any{
+			"status":        status,
+			"reviewed_by":   reviewerID,
+			"reviewed_date": now,
+			"updated_date":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEnrichmentReviewed
+	}
+	enrichment.Status = status
+	enrichment.ReviewedBy = &reviewerID
+	enrichment.ReviewedDate = &now
+	enrichment.UpdatedDate = now
+	return nil
+}
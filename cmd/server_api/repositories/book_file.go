@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BookFileRepository struct {
+	db *gorm.DB
+}
+
+func NewBookFileRepository(db *gorm.DB) *BookFileRepository {
+	return &BookFileRepository{
+		db: db,
+	}
+}
+
+// GetByBook returns the book's files in format order.
+func (r *BookFileRepository) GetByBook(ctx context.Context, bookID string) ([]models.BookFile, error) {
+	var files []models.BookFile
+	err := r.db.WithContext(ctx).
+		Where("book_id = ?", bookID).
+		Order("format ASC").
+		Find(&files).Error
+	return files, err
+}
+
+func (r *BookFileRepository) Get(ctx context.Context, bookID, format string) (*models.BookFile, error) {
+	var file models.BookFile
+	err := r.db.WithContext(ctx).
+		Where("book_id = ? AND format = ?", bookID, format).
+		First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Save stores file as the book's file in its format. When it replaces a file
+// it returns the replaced file, whose object the caller deletes.
+func (r *BookFileRepository) Save(ctx context.Context, file *models.BookFile) (*models.BookFile, error) {
+	now := time.Now().UTC()
+	var replaced *models.BookFile
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.BookFile
+		err := tx.Where("book_id = ? AND format = ?", file.BookID, file.Format).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			file.CreatedDate = now
+			file.UpdatedDate = now
+			return tx.Create(file).Error
+		}
+		if err != nil {
+			return err
+		}
+		replaced = &existing
+		file.ID = existing.ID
+		file.CreatedDate = existing.CreatedDate
+		file.UpdatedDate = now
+		return tx.Save(file).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+func (r *BookFileRepository) Delete(ctx context.Context, file *models.BookFile) error {
+	return r.db.WithContext(ctx).Delete(&models.BookFile{}, "id = ?", file.ID).Error
+}
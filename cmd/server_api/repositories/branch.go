@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BranchRepository struct {
+	db *gorm.DB
+}
+
+func NewBranchRepository(db *gorm.DB) *BranchRepository {
+	return &BranchRepository{
+		db: db,
+	}
+}
+
+func (r *BranchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	now := time.Now().UTC()
+	branch.CreatedDate = now
+	branch.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(branch).Error
+}
+
+func (r *BranchRepository) GetByID(ctx context.Context, id string) (*models.Branch, error) {
+	var branch models.Branch
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&branch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *BranchRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Branch, error) {
+	var branches []models.Branch
+	if len(ids) == 0 {
+		return branches, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ? AND deleted_date IS NULL", ids).Find(&branches).Error
+	return branches, err
+}
+
+func (r *BranchRepository) GetAll(ctx context.Context) ([]models.Branch, error) {
+	var branches []models.Branch
+	err := r.db.WithContext(ctx).Where("deleted_date IS NULL").
+		Order("name ASC").
+		Find(&branches).Error
+	return branches, err
+}
+
+func (r *BranchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	branch.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(branch).Error
+}
+
+func (r *BranchRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.Branch{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
+
+func (r *BranchRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Branch{}).
+		Where("code = ? AND deleted_date IS NULL", code).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// InUse reports whether any user, holding, open transfer, resource,
+// unrevoked device or open cash drawer still references the branch.
+func (r *BranchRepository) InUse(ctx context.Context, id string) (bool, error) {
+	var users int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("branch_id = ? AND deleted_date IS NULL", id).
+		Count(&users).Error
+	if err != nil || users > 0 {
+		return users > 0, err
+	}
+	var holdings int64
+	err = r.db.WithContext(ctx).Model(&models.BookHolding{}).
+		Where("branch_id = ? AND deleted_date IS NULL", id).
+		Count(&holdings).Error
+	if err != nil || holdings > 0 {
+		return holdings > 0, err
+	}
+	var transfers int64
+	err = r.db.WithContext(ctx).Model(&models.BookTransfer{}).
+		Where("(from_branch_id = ? OR to_branch_id = ?) AND status IN ? AND deleted_date IS NULL", id, id, []string{models.TransferStatusRequested, models.TransferStatusInTransit}).
+		Count(&transfers).Error
+	if err != nil || transfers > 0 {
+		return transfers > 0, err
+	}
+	var resources int64
+	err = r.db.WithContext(ctx).Model(&models.Resource{}).
+		Where("branch_id = ? AND deleted_date IS NULL", id).
+		Count(&resources).Error
+	if err != nil || resources > 0 {
+		return resources > 0, err
+	}
+	var devices int64
+	err = r.db.WithContext(ctx).Model(&models.Device{}).
+		Where("branch_id = ? AND revoked_date IS NULL", id).
+		Count(&devices).Error
+	if err != nil || devices > 0 {
+		return devices > 0, err
+	}
+	var drawers int64
+	err = r.db.WithContext(ctx).Model(&models.CashDrawerSession{}).
+		Where("branch_id = ? AND closed_at IS NULL", id).
+		Count(&drawers).Error
+	return drawers > 0, err
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedRepository(db *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{
+		db: db,
+	}
+}
+
+// Create stores a member's feed. It returns gorm.ErrDuplicatedKey if the
+// member already has one.
+func (r *CalendarFeedRepository) Create(ctx context.Context, feed *models.CalendarFeed) error {
+	now := time.Now().UTC()
+	feed.CreatedDate = now
+	feed.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(feed).Error
+}
+
+func (r *CalendarFeedRepository) GetByUser(ctx context.Context, userID string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.WithContext(ctx).Where("user_id = ? AND deleted_date IS NULL", userID).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *CalendarFeedRepository) GetByToken(ctx context.Context, token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.db.WithContext(ctx).Where("token = ? AND deleted_date IS NULL", token).First(&feed).Error
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// DeleteByUser revokes the member's feed, so its URL stops working.
+func (r *CalendarFeedRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.CalendarFeed{}).
+		Where("user_id = ? AND deleted_date IS NULL", userID).
+		Updates(map[string]any{
+			"deleted_date": now,
+			"updated_date": now,
+		})
+	return result.RowsAffected, result.Error
+}
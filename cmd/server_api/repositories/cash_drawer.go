@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCashDrawerOpen is returned when the branch already has an open
+	// cash drawer session.
+	ErrCashDrawerOpen = errors.New("branch already has an open cash drawer")
+	// ErrCashDrawerClosed is returned when a session was closed before the
+	// change.
+	ErrCashDrawerClosed = errors.New("cash drawer session is closed")
+)
+
+// CashDrawerTotal sums the payments of one method in a session.
+type CashDrawerTotal struct {
+	SessionID string  `gorm:"column:session_id"`
+	Method    string  `gorm:"column:method"`
+	Amount    float64 `gorm:"column:amount"`
+	Payments  int64   `gorm:"column:payments"`
+}
+
+// CashDrawerFilter narrows a session listing. Status is "open" or "closed";
+// ClosedFrom and ClosedTo bound the close time, [ClosedFrom, ClosedTo), and
+// match closed sessions only. Empty fields match everything.
+type CashDrawerFilter struct {
+	BranchID   string
+	Status     string
+	ClosedFrom time.Time
+	ClosedTo   time.Time
+}
+
+type CashDrawerRepository struct {
+	db *gorm.DB
+}
+
+func NewCashDrawerRepository(db *gorm.DB) *CashDrawerRepository {
+	return &CashDrawerRepository{
+		db: db,
+	}
+}
+
+// Open starts a session. It returns ErrCashDrawerOpen while the branch has
+// another one open.
+func (r *CashDrawerRepository) Open(ctx context.Context, session *models.CashDrawerSession) error {
+	now := time.Now().UTC()
+	session.OpenedAt = now
+	session.CreatedDate = now
+	session.UpdatedDate = now
+	err := r.db.WithContext(ctx).Create(session).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrCashDrawerOpen
+	}
+	return err
+}
+
+func (r *CashDrawerRepository) GetByID(ctx context.Context, id string) (*models.CashDrawerSession, error) {
+	var session models.CashDrawerSession
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetAll lists sessions, most recently opened first.
+func (r *CashDrawerRepository) GetAll(ctx context.Context, filter CashDrawerFilter, limit, offset int) ([]models.CashDrawerSession, error) {
+	var sessions []models.CashDrawerSession
+	err := r.scope(ctx, filter).
+		Order("opened_at DESC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *CashDrawerRepository) Count(ctx context.Context, filter CashDrawerFilter) (int64, error) {
+	var count int64
+	err := r.scope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+// GetClosed returns every session matching filter, closed in time order, for
+// reconciliation.
+func (r *CashDrawerRepository) GetClosed(ctx context.Context, filter CashDrawerFilter) ([]models.CashDrawerSession, error) {
+	filter.Status = "closed"
+	var sessions []models.CashDrawerSession
+	err := r.scope(ctx, filter).
+		Order("closed_at ASC, id ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *CashDrawerRepository) scope(ctx context.Context, filter CashDrawerFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.CashDrawerSession{})
+	if filter.BranchID != "" {
+		query = query.Where("branch_id = ?", filter.BranchID)
+	}
+	switch filter.Status {
+	case "open":
+		query = query.Where("closed_at IS NULL")
+	case "closed":
+		query = query.Where("closed_at IS NOT NULL")
+	}
+	if !filter.ClosedFrom.IsZero() {
+		query = query.Where("closed_at >= ?", filter.ClosedFrom)
+	}
+	if !filter.ClosedTo.IsZero() {
+		query = query.Where("closed_at < ?", filter.ClosedTo)
+	}
+	return query
+}
+
+// GetPayments returns the session's payments in the order they were taken.
+func (r *CashDrawerRepository) GetPayments(ctx context.Context, sessionID string) ([]models.CashDrawerPayment, error) {
+	var payments []models.CashDrawerPayment
+	err := r.db.WithContext(ctx).
+		Where("session_id = ?", sessionID).
+		Order("created_date ASC, id ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// Totals sums the payments of each session by method.
+func (r *CashDrawerRepository) Totals(ctx context.Context, sessionIDs []string) ([]CashDrawerTotal, error) {
+	var totals []CashDrawerTotal
+	if len(sessionIDs) == 0 {
+		return totals, nil
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.CashDrawerPayment{}).
+		Select("session_id, method, ROUND(SUM(amount), 2) AS amount, COUNT(*) AS payments").
+		Where("session_id IN ?", sessionIDs).
+		Group("session_id, method").
+		Find(&totals).Error
+	return totals, err
+}
+
+// AddPayment records a payment in an open session. The session is locked,
+// so no payment lands in a drawer that is being closed.
+func (r *CashDrawerRepository) AddPayment(ctx context.Context, payment *models.CashDrawerPayment) error {
+	payment.CreatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOpenSession(tx, payment.SessionID); err != nil {
+			return err
+		}
+		return tx.Create(payment).Error
+	})
+}
+
+// Close ends an open session with the cash counted in the drawer and fixes
+// the cash expected in it: the opening float plus the cash payments taken.
+func (r *CashDrawerRepository) Close(ctx context.Context, session *models.CashDrawerSession, userID string, counted float64, note *string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOpenSession(tx, session.ID); err != nil {
+			return err
+		}
+		var cash float64
+		err := tx.Model(&models.CashDrawerPayment{}).
+			Select("COALESCE(ROUND(SUM(amount), 2), 0)").
+			Where("session_id = ? AND method = ?", session.ID, models.PaymentMethodCash).
+			Scan(&cash).Error
+		if err != nil {
+			return err
+		}
+		expected := math.Round((session.OpeningFloat+cash)*100) / 100
+		err = tx.Model(&models.CashDrawerSession{}).
+			Where("id = ?", session.ID).
+			Updates(map[string]any{
+				"expected_cash": expected,
+				"counted_total": counted,
+				"note":          note,
+				"closed_by":     userID,
+				"closed_at":     now,
+				"updated_date":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		session.ExpectedCash = &expected
+		session.CountedTotal = &counted
+		session.Note = note
+		session.ClosedBy = &userID
+		session.ClosedAt = &now
+		session.UpdatedDate = now
+		return nil
+	})
+}
+
+// lockOpenSession locks the session, returning ErrCashDrawerClosed if it has
+// been closed.
+func lockOpenSession(tx *gorm.DB, id string) error {
+	var ids []string
+	err := tx.Model(&models.CashDrawerSession{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND closed_at IS NULL", id).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return ErrCashDrawerClosed
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CatalogTotals counts the books of a catalog and their copies.
+type CatalogTotals struct {
+	Books  int `gorm:"column:books"`
+	Copies int `gorm:"column:copies"`
+}
+
+// CatalogQuantityChange is a book whose quantity changed since a snapshot,
+// with its current title, author and ISBN.
+type CatalogQuantityChange struct {
+	BookID         string  `gorm:"column:book_id"`
+	Title          string  `gorm:"column:title"`
+	Author         string  `gorm:"column:author"`
+	ISBN           *string `gorm:"column:isbn"`
+	QuantityBefore int     `gorm:"column:quantity_before"`
+	QuantityAfter  int     `gorm:"column:quantity_after"`
+}
+
+type CatalogSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewCatalogSnapshotRepository(db *gorm.DB) *CatalogSnapshotRepository {
+	return &CatalogSnapshotRepository{
+		db: db,
+	}
+}
+
+// Create records every live book in a new snapshot and sets its totals.
+func (r *CatalogSnapshotRepository) Create(ctx context.Context, snapshot *models.CatalogSnapshot) error {
+	snapshot.CreatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(snapshot).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(
+			"INSERT INTO catalog_snapshot_books (snapshot_id, book_id, title, author, isbn, quantity) "+
+				"SELECT ?, id, title, author, isbn, quantity FROM books WHERE deleted_date IS NULL",
+			snapshot.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+		var totals CatalogTotals
+		err = tx.Model(&models.CatalogSnapshotBook{}).
+			Select("COUNT(*) AS books, COALESCE(SUM(quantity), 0) AS copies").
+			Where("snapshot_id = ?", snapshot.ID).
+			Scan(&totals).Error
+		if err != nil {
+			return err
+		}
+		snapshot.Books = totals.Books
+		snapshot.Copies = totals.Copies
+		return tx.Model(&models.CatalogSnapshot{}).
+			Where("id = ?", snapshot.ID).
+			Updates(map[string]any{
+				"books":  totals.Books,
+				"copies": totals.Copies,
+			}).Error
+	})
+}
+
+func (r *CatalogSnapshotRepository) GetByID(ctx context.Context, id string) (*models.CatalogSnapshot, error) {
+	var snapshot models.CatalogSnapshot
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// GetAll lists snapshots, newest first.
+func (r *CatalogSnapshotRepository) GetAll(ctx context.Context, limit, offset int) ([]models.CatalogSnapshot, error) {
+	var snapshots []models.CatalogSnapshot
+	err := r.db.WithContext(ctx).
+		Order("created_date DESC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *CatalogSnapshotRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CatalogSnapshot{}).Count(&count).Error
+	return count, err
+}
+
+// Delete removes the snapshot and the books it recorded.
+func (r *CatalogSnapshotRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("snapshot_id = ?", id).Delete(&models.CatalogSnapshotBook{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.CatalogSnapshot{}).Error
+	})
+}
+
+// CurrentTotals counts the live books and their copies.
+func (r *CatalogSnapshotRepository) CurrentTotals(ctx context.Context) (CatalogTotals, error) {
+	var totals CatalogTotals
+	err := r.db.WithContext(ctx).
+		Model(&models.Book{}).
+		Select("COUNT(*) AS books, COALESCE(SUM(quantity), 0) AS copies").
+		Where("deleted_date IS NULL").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetAdded returns the live books the snapshot did not record, by title.
+// They are returned as snapshot books with their current quantity.
+func (r *CatalogSnapshotRepository) GetAdded(ctx context.Context, snapshotID string, limit int) ([]models.CatalogSnapshotBook, error) {
+	var books []models.CatalogSnapshotBook
+	err := r.addedScope(ctx, snapshotID).
+		Select("id AS book_id, title, author, isbn, quantity").
+		Order("title ASC, id ASC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+func (r *CatalogSnapshotRepository) CountAdded(ctx context.Context, snapshotID string) (int64, error) {
+	var count int64
+	err := r.addedScope(ctx, snapshotID).Count(&count).Error
+	return count, err
+}
+
+func (r *CatalogSnapshotRepository) addedScope(ctx context.Context, snapshotID string) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	return db.Model(&models.Book{}).
+		Where("deleted_date IS NULL AND id NOT IN (?)",
+			db.Model(&models.CatalogSnapshotBook{}).Select("book_id").Where("snapshot_id = ?", snapshotID))
+}
+
+// GetRemoved returns the books the snapshot recorded that are no longer
+// live, deleted or purged since, as the snapshot recorded them.
+func (r *CatalogSnapshotRepository) GetRemoved(ctx context.Context, snapshotID string, limit int) ([]models.CatalogSnapshotBook, error) {
+	var books []models.CatalogSnapshotBook
+	err := r.removedScope(ctx, snapshotID).
+		Order("title ASC, book_id ASC").
+		Limit(limit).
+		Find(&books).Error
+	return books, err
+}
+
+func (r *CatalogSnapshotRepository) CountRemoved(ctx context.Context, snapshotID string) (int64, error) {
+	var count int64
+	err := r.removedScope(ctx, snapshotID).Count(&count).Error
+	return count, err
+}
+
+func (r *CatalogSnapshotRepository) removedScope(ctx context.Context, snapshotID string) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	return db.Model(&models.CatalogSnapshotBook{}).
+		Where("snapshot_id = ? AND book_id NOT IN (?)",
+			snapshotID, db.Model(&models.Book{}).Select("id").Where("deleted_date IS NULL"))
+}
+
+// GetQuantityChanges returns the live books whose quantity differs from the
+// snapshot's, by title.
+func (r *CatalogSnapshotRepository) GetQuantityChanges(ctx context.Context, snapshotID string, limit int) ([]CatalogQuantityChange, error) {
+	var changes []CatalogQuantityChange
+	err := r.changedScope(ctx, snapshotID).
+		Select("b.id AS book_id, b.title, b.author, b.isbn, s.quantity AS quantity_before, b.quantity AS quantity_after").
+		Order("b.title ASC, b.id ASC").
+		Limit(limit).
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *CatalogSnapshotRepository) CountQuantityChanges(ctx context.Context, snapshotID string) (int64, error) {
+	var count int64
+	err := r.changedScope(ctx, snapshotID).Count(&count).Error
+	return count, err
+}
+
+func (r *CatalogSnapshotRepository) changedScope(ctx context.Context, snapshotID string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("catalog_snapshot_books AS s").
+		Joins("JOIN books b ON b.id = s.book_id AND b.deleted_date IS NULL").
+		Where("s.snapshot_id = ? AND b.quantity <> s.quantity", snapshotID)
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"book-management-system/pkg/circuitbreaker"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// breakerReleaseKey holds the release func of a statement that is the
+// breaker's probe, for the after callback.
+const breakerReleaseKey = "circuitbreaker:release"
+
+// RegisterCircuitBreaker guards every GORM statement with the breaker. While
+// it is open statements fail with circuitbreaker.ErrOpen without touching the
+// connection pool. Only outage errors count as failures; not-found and
+// constraint errors mean the database is up.
+func RegisterCircuitBreaker(db *gorm.DB, breaker *circuitbreaker.Breaker) error {
+	before := func(tx *gorm.DB) {
+		release, err := breaker.Allow(tx.Statement.Context)
+		if err != nil {
+			tx.AddError(err)
+			return
+		}
+		if release != nil {
+			tx.InstanceSet(breakerReleaseKey, release)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		if release, ok := tx.InstanceGet(breakerReleaseKey); ok {
+			defer release.(func())()
+		}
+		switch {
+		case errors.Is(tx.Error, circuitbreaker.ErrOpen), errors.Is(tx.Error, context.Canceled):
+		case isOutage(tx.Error):
+			breaker.Failure()
+		default:
+			breaker.Success()
+		}
+	}
+	callbacks := db.Callback()
+	registrations := []func() error{
+		func() error { return callbacks.Create().Before("*").Register("circuitbreaker:before_create", before) },
+		func() error { return callbacks.Create().After("*").Register("circuitbreaker:after_create", after) },
+		func() error { return callbacks.Query().Before("*").Register("circuitbreaker:before_query", before) },
+		func() error { return callbacks.Query().After("*").Register("circuitbreaker:after_query", after) },
+		func() error { return callbacks.Update().Before("*").Register("circuitbreaker:before_update", before) },
+		func() error { return callbacks.Update().After("*").Register("circuitbreaker:after_update", after) },
+		func() error { return callbacks.Delete().Before("*").Register("circuitbreaker:before_delete", before) },
+		func() error { return callbacks.Delete().After("*").Register("circuitbreaker:after_delete", after) },
+		func() error { return callbacks.Row().Before("*").Register("circuitbreaker:before_row", before) },
+		func() error { return callbacks.Row().After("*").Register("circuitbreaker:after_row", after) },
+		func() error { return callbacks.Raw().Before("*").Register("circuitbreaker:before_raw", before) },
+		func() error { return callbacks.Raw().After("*").Register("circuitbreaker:after_raw", after) },
+	}
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isOutage reports errors that mean the database cannot be reached: broken
+// or refused connections, network errors other than timeouts, and
+// PostgreSQL connection exception (08), too many connections (53300) and
+// shutdown (57P0x) states. Timeouts, including statement_timeout, are left
+// out: a slow query says nothing about whether the database is up, and
+// counting them would let a few slow reports take down the whole API.
+func isOutage(err error) bool {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") ||
+			strings.HasPrefix(pgErr.Code, "57P0") ||
+			pgErr.Code == "53300"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !netErr.Timeout()
+}
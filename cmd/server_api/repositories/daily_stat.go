@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DailyStatRepository struct {
+	db *gorm.DB
+}
+
+func NewDailyStatRepository(db *gorm.DB) *DailyStatRepository {
+	return &DailyStatRepository{
+		db: db,
+	}
+}
+
+// Compute totals the catalog and members as they stand now. New titles and
+// members are the ones created on day, a UTC midnight.
+func (r *DailyStatRepository) Compute(ctx context.Context, day time.Time) (*models.DailyStat, error) {
+	next := day.AddDate(0, 0, 1)
+	var books struct {
+		Titles          int
+		Copies          int
+		AvailableCopies int
+	}
+	err := r.db.WithContext(ctx).Model(&models.Book{}).
+		Select("COUNT(*) AS titles, COALESCE(SUM(quantity), 0) AS copies, COALESCE(SUM(available_quantity), 0) AS available_copies").
+		Where("deleted_date IS NULL").
+		Scan(&books).Error
+	if err != nil {
+		return nil, err
+	}
+	var newTitles int64
+	err = r.db.WithContext(ctx).Model(&models.Book{}).
+		Where("created_date >= ? AND created_date < ? AND deleted_date IS NULL", day, next).
+		Count(&newTitles).Error
+	if err != nil {
+		return nil, err
+	}
+	var members int64
+	err = r.db.WithContext(ctx).Model(&models.User{}).
+		Where("role = 'member' AND deleted_date IS NULL").
+		Count(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	var newMembers int64
+	err = r.db.WithContext(ctx).Model(&models.User{}).
+		Where("role = 'member' AND created_date >= ? AND created_date < ? AND deleted_date IS NULL", day, next).
+		Count(&newMembers).Error
+	if err != nil {
+		return nil, err
+	}
+	return &models.DailyStat{
+		StatDate:        day,
+		Titles:          books.Titles,
+		Copies:          books.Copies,
+		AvailableCopies: books.AvailableCopies,
+		NewTitles:       int(newTitles),
+		Members:         int(members),
+		NewMembers:      int(newMembers),
+	}, nil
+}
+
+// Upsert stores the day's row, replacing the totals if it already exists.
+func (r *DailyStatRepository) Upsert(ctx context.Context, stat *models.DailyStat) error {
+	now := time.Now().UTC()
+	stat.CreatedDate = now
+	stat.UpdatedDate = now
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "stat_date"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"titles",
+			"copies",
+			"available_copies",
+			"new_titles",
+			"members",
+			"new_members",
+			"updated_date",
+		}),
+	}).Create(stat).Error
+}
+
+func (r *DailyStatRepository) GetRange(ctx context.Context, from, to time.Time) ([]models.DailyStat, error) {
+	var stats []models.DailyStat
+	err := r.db.WithContext(ctx).
+		Where("stat_date >= ? AND stat_date <= ?", from, to).
+		Order("stat_date ASC").
+		Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDeviceRevoked is returned when revoking a device that was already
+// revoked.
+var ErrDeviceRevoked = errors.New("device already revoked")
+
+// DeviceFilter narrows device listings. Zero fields don't filter.
+type DeviceFilter struct {
+	BranchID string
+	// Revoked keeps only revoked devices when true and only active ones
+	// when false.
+	Revoked *bool
+}
+
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{
+		db: db,
+	}
+}
+
+func (r *DeviceRepository) Create(ctx context.Context, device *models.Device) error {
+	now := time.Now().UTC()
+	device.CreatedDate = now
+	device.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(device).Error
+}
+
+func (r *DeviceRepository) GetByID(ctx context.Context, id string) (*models.Device, error) {
+	var device models.Device
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&device).Error
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *DeviceRepository) GetFiltered(ctx context.Context, filter DeviceFilter, limit, offset int) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.scope(ctx, filter).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date DESC").
+		Find(&devices).Error
+	return devices, err
+}
+
+func (r *DeviceRepository) CountFiltered(ctx context.Context, filter DeviceFilter) (int64, error) {
+	var count int64
+	err := r.scope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *DeviceRepository) scope(ctx context.Context, filter DeviceFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Device{})
+	if filter.BranchID != "" {
+		query = query.Where("branch_id = ?", filter.BranchID)
+	}
+	if filter.Revoked != nil {
+		if *filter.Revoked {
+			query = query.Where("revoked_date IS NOT NULL")
+		} else {
+			query = query.Where("revoked_date IS NULL")
+		}
+	}
+	return query
+}
+
+// MarkSeen records that the device used its token at now. It writes at most
+// once a minute per device, since kiosks call on every search.
+func (r *DeviceRepository) MarkSeen(ctx context.Context, id string, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Device{}).
+		Where("id = ? AND (last_seen_date IS NULL OR last_seen_date < ?)", id, now.Add(-time.Minute)).
+		Update("last_seen_date", now).Error
+}
+
+// Revoke stops the device's token from being accepted.
+func (r *DeviceRepository) Revoke(ctx context.Context, device *models.Device, revokedBy string) error {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.Device{}).
+		Where("id = ? AND revoked_date IS NULL", device.ID).
+		Updates(map[string]any{
+			"revoked_date": now,
+			"revoked_by":   revokedBy,
+			"updated_date": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceRevoked
+	}
+	device.RevokedDate = &now
+	device.RevokedBy = &revokedBy
+	device.UpdatedDate = now
+	return nil
+}
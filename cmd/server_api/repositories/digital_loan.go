@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNoDigitalCopies is returned when every digital copy of a book is
+	// on loan.
+	ErrNoDigitalCopies = errors.New("no digital copies available")
+	// ErrDigitalLoanExists is returned when the member already has the
+	// book on loan.
+	ErrDigitalLoanExists = errors.New("book already on loan to member")
+	// ErrLoanLimitReached is returned when the member has as many loans as
+	// their membership plan allows.
+	ErrLoanLimitReached = errors.New("loan limit reached")
+	// ErrReferenceOnly is returned when the book's loan policy keeps it
+	// from being lent.
+	ErrReferenceOnly = errors.New("book is reference only")
+	// ErrDigitalLoanEnded is returned when a loan was returned or fell due
+	// before the change.
+	ErrDigitalLoanEnded = errors.New("digital loan has ended")
+)
+
+type DigitalLoanRepository struct {
+	db *gorm.DB
+}
+
+func NewDigitalLoanRepository(db *gorm.DB) *DigitalLoanRepository {
+	return &DigitalLoanRepository{
+		db: db,
+	}
+}
+
+func (r *DigitalLoanRepository) GetByID(ctx context.Context, id string) (*models.DigitalLoan, error) {
+	var loan models.DigitalLoan
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&loan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// GetActiveByUser returns the member's loans active at now, due soonest
+// first.
+func (r *DigitalLoanRepository) GetActiveByUser(ctx context.Context, userID string, now time.Time) ([]models.DigitalLoan, error) {
+	var loans []models.DigitalLoan
+	err := r.active(r.db.WithContext(ctx), now).
+		Where("user_id = ?", userID).
+		Order("due_date ASC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// CountActiveByBook counts the book's digital copies on loan at now.
+func (r *DigitalLoanRepository) CountActiveByBook(ctx context.Context, bookID string, now time.Time) (int64, error) {
+	var count int64
+	err := r.active(r.db.WithContext(ctx), now).
+		Model(&models.DigitalLoan{}).
+		Where("book_id = ?", bookID).
+		Count(&count).Error
+	return count, err
+}
+
+// HasBorrowed reports whether the member has ever had the book on loan,
+// whether or not the loan has ended.
+func (r *DigitalLoanRepository) HasBorrowed(ctx context.Context, userID, bookID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.DigitalLoan{}).
+		Where("user_id = ? AND book_id = ? AND deleted_date IS NULL", userID, bookID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create lends the book to the member under their plan unless the book is
+// reference only, all of its digital copies are on loan, the member already
+// has it, or the member is at the plan's loan limit. The loan falls due
+// after the plan's loan period as capped by the book's policy. The member
+// is locked while their loans are counted, so two checkouts of different
+// books cannot both pass the limit. The book is locked while the copies are
+// counted, so two members cannot take the last copy at once, and its policy
+// is read under the same lock.
+func (r *DigitalLoanRepository) Create(ctx context.Context, loan *models.DigitalLoan, plan *models.MembershipPlan) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", loan.UserID).
+			First(&user).Error
+		if err != nil {
+			return err
+		}
+		book, err := lockBook(tx, loan.BookID)
+		if err != nil {
+			return err
+		}
+		if book.DeletedDate != nil {
+			return gorm.ErrRecordNotFound
+		}
+		if book.LoanPolicy == models.BookLoanPolicyReferenceOnly {
+			return ErrReferenceOnly
+		}
+		var onLoan, borrowed, userLoans int64
+		if err := r.active(tx, now).Model(&models.DigitalLoan{}).Where("book_id = ?", loan.BookID).Count(&onLoan).Error; err != nil {
+			return err
+		}
+		if err := r.active(tx, now).Model(&models.DigitalLoan{}).Where("book_id = ? AND user_id = ?", loan.BookID, loan.UserID).Count(&borrowed).Error; err != nil {
+			return err
+		}
+		if borrowed > 0 {
+			return ErrDigitalLoanExists
+		}
+		if onLoan >= int64(book.DigitalLoanLimit) {
+			return ErrNoDigitalCopies
+		}
+		if err := r.active(tx, now).Model(&models.DigitalLoan{}).Where("user_id = ?", loan.UserID).Count(&userLoans).Error; err != nil {
+			return err
+		}
+		if userLoans >= int64(plan.MaxLoans) {
+			return ErrLoanLimitReached
+		}
+		loan.DueDate = now.AddDate(0, 0, book.LoanDays(plan.LoanPeriodDays))
+		loan.CreatedDate = now
+		loan.UpdatedDate = now
+		return tx.Create(loan).Error
+	})
+}
+
+// Return ends an active loan early.
+func (r *DigitalLoanRepository) Return(ctx context.Context, loan *models.DigitalLoan) error {
+	now := time.Now().UTC()
+	result := r.active(r.db.WithContext(ctx), now).
+		Model(&models.DigitalLoan{}).
+		Where("id = ?", loan.ID).
+		Updates(map[string]any{
+			"returned_date": now,
+			"updated_date":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDigitalLoanEnded
+	}
+	loan.ReturnedDate = &now
+	loan.UpdatedDate = now
+	return nil
+}
+
+// GetEndedByUser returns the member's loans that were returned or fell due
+// before now, most recently ended first.
+func (r *DigitalLoanRepository) GetEndedByUser(ctx context.Context, userID string, now time.Time) ([]models.DigitalLoan, error) {
+	var loans []models.DigitalLoan
+	err := r.ended(r.db.WithContext(ctx), now).
+		Where("user_id = ?", userID).
+		Order("COALESCE(returned_date, due_date) DESC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// GetUnreminded returns loans not returned that fall due after dueAfter and
+// no later than dueBefore and have no reminder of eventType yet, due soonest
+// first.
+func (r *DigitalLoanRepository) GetUnreminded(ctx context.Context, eventType string, dueAfter, dueBefore time.Time, limit int) ([]models.DigitalLoan, error) {
+	var loans []models.DigitalLoan
+	err := r.db.WithContext(ctx).
+		Where("returned_date IS NULL AND deleted_date IS NULL AND due_date > ? AND due_date <= ?", dueAfter, dueBefore).
+		Where("NOT EXISTS (?)", r.db.Model(&models.LoanReminder{}).
+			Select("1").
+			Where("loan_reminders.loan_id = digital_loans.id AND loan_reminders.event_type = ?", eventType)).
+		Order("due_date ASC, id ASC").
+		Limit(limit).
+		Find(&loans).Error
+	return loans, err
+}
+
+// DeleteEndedByUser deletes the member's ended loans, clearing their
+// reading history.
+func (r *DigitalLoanRepository) DeleteEndedByUser(ctx context.Context, userID string, now time.Time) (int64, error) {
+	result := r.ended(r.db.WithContext(ctx), now).
+		Model(&models.DigitalLoan{}).
+		Where("user_id = ?", userID).
+		Update("deleted_date", now)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteEndedWithoutHistory deletes the ended loans of every member who
+// turned reading history off.
+func (r *DigitalLoanRepository) DeleteEndedWithoutHistory(ctx context.Context, now time.Time) (int64, error) {
+	result := r.ended(r.db.WithContext(ctx), now).
+		Model(&models.DigitalLoan{}).
+		Where("user_id IN (?)", r.db.Model(&models.User{}).Select("id").Where("reading_history_enabled = ?", false)).
+		Update("deleted_date", now)
+	return result.RowsAffected, result.Error
+}
+
+func (r *DigitalLoanRepository) active(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("returned_date IS NULL AND due_date > ? AND deleted_date IS NULL", now)
+}
+
+func (r *DigitalLoanRepository) ended(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("(returned_date IS NOT NULL OR due_date <= ?) AND deleted_date IS NULL", now)
+}
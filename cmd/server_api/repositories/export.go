@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DigitalLoanExport is a digital loan with the title of its book and the
+// email of its borrower.
+type DigitalLoanExport struct {
+	models.DigitalLoan
+	BookTitle string `gorm:"column:book_title"`
+	UserEmail string `gorm:"column:user_email"`
+}
+
+// DigitalLoanExportFilter narrows a loan export. A zero From or To leaves
+// that end open; BranchID matches loans of users at the branch.
+type DigitalLoanExportFilter struct {
+	BranchID string
+	From     time.Time
+	To       time.Time
+}
+
+// PaymentExport is a desk payment with the branch of its cash drawer and
+// the name of the paying member, when known.
+type PaymentExport struct {
+	models.CashDrawerPayment
+	BranchID      string  `gorm:"column:branch_id"`
+	UserFirstName *string `gorm:"column:user_first_name"`
+	UserLastName  *string `gorm:"column:user_last_name"`
+}
+
+// PaymentExportFilter narrows a payment export. A zero From or To leaves
+// that end open; BranchID matches payments taken at the branch's drawer.
+type PaymentExportFilter struct {
+	BranchID string
+	From     time.Time
+	To       time.Time
+}
+
+// ExportRepository reads whole tables for CSV exports with a keyset cursor on
+// id, so each batch is a short indexed read however far into the table the
+// export has got, and no export holds more than one batch in memory.
+type ExportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) *ExportRepository {
+	return &ExportRepository{
+		db: db,
+	}
+}
+
+// EachBook calls fn for every batch of live books matching filter, limited
+// to books held at branchID when it is set.
+func (r *ExportRepository) EachBook(ctx context.Context, filter BookFilter, branchID string, batchSize int, fn func([]models.Book) error) error {
+	books := NewBookRepository(r.db)
+	if branchID != "" {
+		books = books.withHolding("book_holdings.branch_id = ?", branchID)
+	}
+	return eachBatch(books.filterScope(ctx, filter), "books.id", batchSize, func(book *models.Book) string {
+		return book.ID
+	}, fn)
+}
+
+// EachDigitalLoan calls fn for every batch of digital loans created in
+// [filter.From, filter.To).
+func (r *ExportRepository) EachDigitalLoan(ctx context.Context, filter DigitalLoanExportFilter, batchSize int, fn func([]DigitalLoanExport) error) error {
+	query := r.db.WithContext(ctx).
+		Table("digital_loans").
+		Select("digital_loans.*, books.title AS book_title, users.email AS user_email").
+		Joins("LEFT JOIN books ON books.id = digital_loans.book_id").
+		Joins("LEFT JOIN users ON users.id = digital_loans.user_id").
+		Where("digital_loans.deleted_date IS NULL")
+	if filter.BranchID != "" {
+		query = query.Where("users.branch_id = ?", filter.BranchID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("digital_loans.created_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("digital_loans.created_date < ?", filter.To)
+	}
+	return eachBatch(query, "digital_loans.id", batchSize, func(loan *DigitalLoanExport) string {
+		return loan.ID
+	}, fn)
+}
+
+// EachPayment calls fn for every batch of desk payments taken in
+// [filter.From, filter.To).
+func (r *ExportRepository) EachPayment(ctx context.Context, filter PaymentExportFilter, batchSize int, fn func([]PaymentExport) error) error {
+	query := r.db.WithContext(ctx).
+		Table("cash_drawer_payments").
+		Select("cash_drawer_payments.*, cash_drawer_sessions.branch_id, users.first_name AS user_first_name, users.last_name AS user_last_name").
+		Joins("JOIN cash_drawer_sessions ON cash_drawer_sessions.id = cash_drawer_payments.session_id").
+		Joins("LEFT JOIN users ON users.id = cash_drawer_payments.user_id")
+	if filter.BranchID != "" {
+		query = query.Where("cash_drawer_sessions.branch_id = ?", filter.BranchID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("cash_drawer_payments.created_date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("cash_drawer_payments.created_date < ?", filter.To)
+	}
+	return eachBatch(query, "cash_drawer_payments.id", batchSize, func(payment *PaymentExport) string {
+		return payment.ID
+	}, fn)
+}
+
+// EachMember calls fn for every batch of live members, limited to branchID
+// when it is set. Password hashes are not read.
+func (r *ExportRepository) EachMember(ctx context.Context, branchID string, batchSize int, fn func([]models.User) error) error {
+	query := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Select("id, email, first_name, last_name, card_number, membership_plan_id, branch_id, status, last_login_at, created_date").
+		Where("role = 'member' AND deleted_date IS NULL")
+	if branchID != "" {
+		query = query.Where("branch_id = ?", branchID)
+	}
+	return eachBatch(query, "id", batchSize, func(user *models.User) string {
+		return user.ID
+	}, fn)
+}
+
+// eachBatch pages through query in idColumn order, resuming each batch after
+// the last id of the previous one rather than at an offset.
+func eachBatch[T any](query *gorm.DB, idColumn string, batchSize int, id func(*T) string, fn func([]T) error) error {
+	query = query.Session(&gorm.Session{})
+	afterID := ""
+	for {
+		var batch []T
+		err := query.Where(idColumn+" > ?", afterID).
+			Order(idColumn).
+			Limit(batchSize).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		afterID = id(&batch[len(batch)-1])
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db: db,
+	}
+}
+
+// Create claims the key. It fails on the unique index when another request
+// holds the same key, so concurrent retries cannot both run the handler.
+func (r *IdempotencyKeyRepository) Create(key *models.IdempotencyKey) error {
+	now := time.Now().UTC()
+	key.CreatedDate = now
+	key.UpdatedDate = now
+	return r.db.Create(key).Error
+}
+
+func (r *IdempotencyKeyRepository) Get(userID, method, path, idempotencyKey string) (*models.IdempotencyKey, error) {
+	var key models.IdempotencyKey
+	err := r.db.Where(
+		"user_id = ? AND method = ? AND path = ? AND idempotency_key = ? AND deleted_date IS NULL",
+		userID, method, path, idempotencyKey,
+	).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Complete stores the response. Pass either the body or, for responses
+// rebuilt on replay, the ID of the created resource.
+func (r *IdempotencyKeyRepository) Complete(id string, statusCode int, contentType, body, resourceID string) error {
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(map[string]any{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"resource_id":   resourceID,
+			"updated_date":  time.Now().UTC(),
+		}).Error
+}
+
+// Release frees the key so the request can be retried, used when the
+// original request failed or its record expired.
+func (r *IdempotencyKeyRepository) Release(id string) error {
+	now := time.Now().UTC()
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
+
+// PurgeExpired hard-deletes expired and released records. They are a cache,
+// not history, so nothing is kept once they stop being replayable.
+func (r *IdempotencyKeyRepository) PurgeExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ? OR deleted_date IS NOT NULL", now).
+		Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRSVPClosed is returned when an event does not take RSVPs or has
+	// already started.
+	ErrRSVPClosed = errors.New("event is not taking rsvps")
+	// ErrRSVPExists is returned when the member has already RSVPed.
+	ErrRSVPExists = errors.New("member already rsvped")
+	// ErrEventFull is returned when an event has as many RSVPs as its
+	// capacity.
+	ErrEventFull = errors.New("event is full")
+)
+
+// LibraryEventFilter narrows event listings. Zero fields don't filter.
+type LibraryEventFilter struct {
+	Kind     string
+	BranchID string
+	// HiddenBranchIDs leaves out events at these branches, for the local
+	// branches the caller may not see.
+	HiddenBranchIDs []string
+	// CurrentAt keeps only the events that have not ended and the
+	// announcements showing at that time, soonest first. Without it events
+	// are listed latest first.
+	CurrentAt time.Time
+}
+
+type LibraryEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLibraryEventRepository(db *gorm.DB) *LibraryEventRepository {
+	return &LibraryEventRepository{
+		db: db,
+	}
+}
+
+func (r *LibraryEventRepository) Create(ctx context.Context, event *models.LibraryEvent) error {
+	now := time.Now().UTC()
+	event.CreatedDate = now
+	event.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *LibraryEventRepository) GetByID(ctx context.Context, id string) (*models.LibraryEvent, error) {
+	var event models.LibraryEvent
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&event).Error
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *LibraryEventRepository) GetFiltered(ctx context.Context, filter LibraryEventFilter, limit, offset int) ([]models.LibraryEvent, error) {
+	var events []models.LibraryEvent
+	order := "starts_at DESC"
+	if !filter.CurrentAt.IsZero() {
+		order = "starts_at ASC"
+	}
+	err := r.scope(ctx, filter).
+		Limit(limit).
+		Offset(offset).
+		Order(order).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *LibraryEventRepository) CountFiltered(ctx context.Context, filter LibraryEventFilter) (int64, error) {
+	var count int64
+	err := r.scope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *LibraryEventRepository) scope(ctx context.Context, filter LibraryEventFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.LibraryEvent{}).Where("deleted_date IS NULL")
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.BranchID != "" {
+		query = query.Where("branch_id = ?", filter.BranchID)
+	}
+	if len(filter.HiddenBranchIDs) > 0 {
+		query = query.Where("(branch_id IS NULL OR branch_id NOT IN ?)", filter.HiddenBranchIDs)
+	}
+	if !filter.CurrentAt.IsZero() {
+		query = query.Where(
+			"((kind = ? AND COALESCE(ends_at, starts_at) > ?) OR (kind = ? AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)))",
+			models.LibraryEventKindEvent, filter.CurrentAt,
+			models.LibraryEventKindAnnouncement, filter.CurrentAt, filter.CurrentAt,
+		)
+	}
+	return query
+}
+
+func (r *LibraryEventRepository) Update(ctx context.Context, event *models.LibraryEvent) error {
+	event.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+func (r *LibraryEventRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.LibraryEvent{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
+
+// CountRSVPs returns the number of RSVPs per event, leaving out events
+// without any.
+func (r *LibraryEventRepository) CountRSVPs(ctx context.Context, eventIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(eventIDs))
+	if len(eventIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		EventID string
+		Count   int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.EventRSVP{}).
+		Select("event_id, COUNT(*) AS count").
+		Where("event_id IN ?", eventIDs).
+		Group("event_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.EventID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetRSVPs returns an event's RSVPs, earliest first.
+func (r *LibraryEventRepository) GetRSVPs(ctx context.Context, eventID string) ([]models.EventRSVP, error) {
+	var rsvps []models.EventRSVP
+	err := r.db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Order("created_date ASC").
+		Find(&rsvps).Error
+	return rsvps, err
+}
+
+// GetRSVPedByUser returns the events the member has RSVPed to that have not
+// ended at now, soonest first.
+func (r *LibraryEventRepository) GetRSVPedByUser(ctx context.Context, userID string, now time.Time) ([]models.LibraryEvent, error) {
+	var events []models.LibraryEvent
+	err := r.db.WithContext(ctx).
+		Where("deleted_date IS NULL AND COALESCE(ends_at, starts_at) > ?", now).
+		Where("id IN (?)", r.db.Model(&models.EventRSVP{}).Select("event_id").Where("user_id = ?", userID)).
+		Order("starts_at ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// CreateRSVP adds the member to an event that takes RSVPs and has not
+// started yet, unless it is full. The event is locked while its RSVPs are
+// counted, so two members cannot take the last place at once.
+func (r *LibraryEventRepository) CreateRSVP(ctx context.Context, rsvp *models.EventRSVP) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event models.LibraryEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_date IS NULL", rsvp.EventID).
+			First(&event).Error
+		if err != nil {
+			return err
+		}
+		if event.Kind != models.LibraryEventKindEvent || !event.RSVPEnabled || !now.Before(event.StartsAt) {
+			return ErrRSVPClosed
+		}
+		var existing, total int64
+		if err := tx.Model(&models.EventRSVP{}).Where("event_id = ? AND user_id = ?", rsvp.EventID, rsvp.UserID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrRSVPExists
+		}
+		if event.Capacity != nil {
+			if err := tx.Model(&models.EventRSVP{}).Where("event_id = ?", rsvp.EventID).Count(&total).Error; err != nil {
+				return err
+			}
+			if total >= int64(*event.Capacity) {
+				return ErrEventFull
+			}
+		}
+		rsvp.CreatedDate = now
+		return tx.Create(rsvp).Error
+	})
+}
+
+// DeleteRSVP cancels the member's RSVP, returning gorm.ErrRecordNotFound if
+// there is none.
+func (r *LibraryEventRepository) DeleteRSVP(ctx context.Context, eventID, userID string) error {
+	result := r.db.WithContext(ctx).
+		Where("event_id = ? AND user_id = ?", eventID, userID).
+		Delete(&models.EventRSVP{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
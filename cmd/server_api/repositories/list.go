@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ListRepository struct {
+	db *gorm.DB
+}
+
+func NewListRepository(db *gorm.DB) *ListRepository {
+	return &ListRepository{
+		db: db,
+	}
+}
+
+func (r *ListRepository) Create(list *models.ReadingList) error {
+	now := time.Now().UTC()
+	list.CreatedDate = now
+	list.UpdatedDate = now
+	return r.db.Create(list).Error
+}
+
+func (r *ListRepository) GetByID(id string) (*models.ReadingList, error) {
+	var list models.ReadingList
+	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *ListRepository) GetByShareToken(token string) (*models.ReadingList, error) {
+	var list models.ReadingList
+	err := r.db.Where("share_token = ? AND deleted_date IS NULL", token).First(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *ListRepository) GetByUserID(userID string) ([]models.ReadingList, error) {
+	var lists []models.ReadingList
+	err := r.db.Where("user_id = ? AND deleted_date IS NULL", userID).
+		Order("created_date ASC").
+		Find(&lists).Error
+	return lists, err
+}
+
+func (r *ListRepository) Update(list *models.ReadingList) error {
+	list.UpdatedDate = time.Now().UTC()
+	return r.db.Save(list).Error
+}
+
+func (r *ListRepository) Delete(id string) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ReadingListItem{}).
+			Where("list_id = ? AND deleted_date IS NULL", id).
+			Update("deleted_date", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.ReadingList{}).
+			Where("id = ? AND deleted_date IS NULL", id).
+			Update("deleted_date", now).Error
+	})
+}
+
+func (r *ListRepository) GetItems(listID string) ([]models.ReadingListItem, error) {
+	var items []models.ReadingListItem
+	err := r.db.Where("list_id = ? AND deleted_date IS NULL", listID).
+		Order("position ASC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *ListRepository) ItemExists(listID, bookID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ReadingListItem{}).
+		Where("list_id = ? AND book_id = ? AND deleted_date IS NULL", listID, bookID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ListRepository) AddItem(item *models.ReadingListItem) error {
+	now := time.Now().UTC()
+	item.CreatedDate = now
+	item.UpdatedDate = now
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxPosition int
+		err := tx.Model(&models.ReadingListItem{}).
+			Where("list_id = ? AND deleted_date IS NULL", item.ListID).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error
+		if err != nil {
+			return err
+		}
+		item.Position = maxPosition + 1
+		return tx.Create(item).Error
+	})
+}
+
+func (r *ListRepository) RemoveItem(listID, bookID string) (int64, error) {
+	result := r.db.Model(&models.ReadingListItem{}).
+		Where("list_id = ? AND book_id = ? AND deleted_date IS NULL", listID, bookID).
+		Update("deleted_date", time.Now().UTC())
+	return result.RowsAffected, result.Error
+}
+
+func (r *ListRepository) Reorder(listID string, bookIDs []string) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, bookID := range bookIDs {
+			err := tx.Model(&models.ReadingListItem{}).
+				Where("list_id = ? AND book_id = ? AND deleted_date IS NULL", listID, bookID).
+				Updates(map[string]any{
+					"position":     i + 1,
+					"updated_date": now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
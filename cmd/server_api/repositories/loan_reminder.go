@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrLoanReminderSent is returned when the loan's reminder was already
+// recorded, by an earlier run or by another instance.
+var ErrLoanReminderSent = errors.New("loan reminder already sent")
+
+type LoanReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewLoanReminderRepository(db *gorm.DB) *LoanReminderRepository {
+	return &LoanReminderRepository{
+		db: db,
+	}
+}
+
+// Create records a reminder before it is sent. The unique index on loan and
+// event type lets only one caller record it.
+func (r *LoanReminderRepository) Create(ctx context.Context, reminder *models.LoanReminder) error {
+	now := time.Now().UTC()
+	reminder.CreatedDate = now
+	reminder.UpdatedDate = now
+	err := r.db.WithContext(ctx).Create(reminder).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrLoanReminderSent
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) *LoginEventRepository {
+	return &LoginEventRepository{
+		db: db,
+	}
+}
+
+func (r *LoginEventRepository) Create(event *models.LoginEvent) error {
+	now := time.Now().UTC()
+	event.CreatedDate = now
+	event.UpdatedDate = now
+	return r.db.Create(event).Error
+}
+
+func (r *LoginEventRepository) GetByUserID(userID string, limit int) ([]models.LoginEvent, error) {
+	var events []models.LoginEvent
+	err := r.db.Where("user_id = ? AND deleted_date IS NULL", userID).
+		Limit(limit).
+		Order("created_date DESC").
+		Find(&events).Error
+	return events, err
+}
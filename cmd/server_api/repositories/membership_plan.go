@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MembershipPlanRepository struct {
+	db *gorm.DB
+}
+
+func NewMembershipPlanRepository(db *gorm.DB) *MembershipPlanRepository {
+	return &MembershipPlanRepository{
+		db: db,
+	}
+}
+
+func (r *MembershipPlanRepository) Create(plan *models.MembershipPlan) error {
+	now := time.Now().UTC()
+	plan.CreatedDate = now
+	plan.UpdatedDate = now
+	return r.db.Create(plan).Error
+}
+
+func (r *MembershipPlanRepository) GetByID(id string) (*models.MembershipPlan, error) {
+	var plan models.MembershipPlan
+	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *MembershipPlanRepository) GetByCode(code string) (*models.MembershipPlan, error) {
+	var plan models.MembershipPlan
+	err := r.db.Where("code = ? AND deleted_date IS NULL", code).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *MembershipPlanRepository) GetAll() ([]models.MembershipPlan, error) {
+	var plans []models.MembershipPlan
+	err := r.db.Where("deleted_date IS NULL").
+		Order("name ASC").
+		Find(&plans).Error
+	return plans, err
+}
+
+func (r *MembershipPlanRepository) Update(plan *models.MembershipPlan) error {
+	plan.UpdatedDate = time.Now().UTC()
+	return r.db.Save(plan).Error
+}
+
+func (r *MembershipPlanRepository) Delete(id string) error {
+	now := time.Now().UTC()
+	return r.db.Model(&models.MembershipPlan{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
+
+func (r *MembershipPlanRepository) CodeExists(code string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.MembershipPlan{}).
+		Where("code = ? AND deleted_date IS NULL", code).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,395 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: book.go
+//
+// Generated by this command:
+//
+//	mockgen -source=book.go -destination=mocks/book.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	models "book-management-system/cmd/server_api/models"
+	repositories "book-management-system/cmd/server_api/repositories"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBookRepo is a mock of BookRepo interface.
+type MockBookRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockBookRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockBookRepoMockRecorder is the mock recorder for MockBookRepo.
+type MockBookRepoMockRecorder struct {
+	mock *MockBookRepo
+}
+
+// NewMockBookRepo creates a new mock instance.
+func NewMockBookRepo(ctrl *gomock.Controller) *MockBookRepo {
+	mock := &MockBookRepo{ctrl: ctrl}
+	mock.recorder = &MockBookRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBookRepo) EXPECT() *MockBookRepoMockRecorder {
+	return m.recorder
+}
+
+// AtBranch mocks base method.
+func (m *MockBookRepo) AtBranch(branchID string) repositories.BookRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AtBranch", branchID)
+	ret0, _ := ret[0].(repositories.BookRepo)
+	return ret0
+}
+
+// AtBranch indicates an expected call of AtBranch.
+func (mr *MockBookRepoMockRecorder) AtBranch(branchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AtBranch", reflect.TypeOf((*MockBookRepo)(nil).AtBranch), branchID)
+}
+
+// AvailableAtBranch mocks base method.
+func (m *MockBookRepo) AvailableAtBranch(branchID string) repositories.BookRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailableAtBranch", branchID)
+	ret0, _ := ret[0].(repositories.BookRepo)
+	return ret0
+}
+
+// AvailableAtBranch indicates an expected call of AvailableAtBranch.
+func (mr *MockBookRepoMockRecorder) AvailableAtBranch(branchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailableAtBranch", reflect.TypeOf((*MockBookRepo)(nil).AvailableAtBranch), branchID)
+}
+
+// Count mocks base method.
+func (m *MockBookRepo) Count(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockBookRepoMockRecorder) Count(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockBookRepo)(nil).Count), ctx)
+}
+
+// CountAvailable mocks base method.
+func (m *MockBookRepo) CountAvailable(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAvailable", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAvailable indicates an expected call of CountAvailable.
+func (mr *MockBookRepoMockRecorder) CountAvailable(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAvailable", reflect.TypeOf((*MockBookRepo)(nil).CountAvailable), ctx)
+}
+
+// CountByStatus mocks base method.
+func (m *MockBookRepo) CountByStatus(ctx context.Context, status string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByStatus", ctx, status)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByStatus indicates an expected call of CountByStatus.
+func (mr *MockBookRepoMockRecorder) CountByStatus(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByStatus", reflect.TypeOf((*MockBookRepo)(nil).CountByStatus), ctx, status)
+}
+
+// CountFiltered mocks base method.
+func (m *MockBookRepo) CountFiltered(ctx context.Context, filter repositories.BookFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFiltered", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFiltered indicates an expected call of CountFiltered.
+func (mr *MockBookRepoMockRecorder) CountFiltered(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFiltered", reflect.TypeOf((*MockBookRepo)(nil).CountFiltered), ctx, filter)
+}
+
+// Create mocks base method.
+func (m *MockBookRepo) Create(ctx context.Context, book *models.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, book)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockBookRepoMockRecorder) Create(ctx, book any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockBookRepo)(nil).Create), ctx, book)
+}
+
+// CreateBatch mocks base method.
+func (m *MockBookRepo) CreateBatch(ctx context.Context, books []models.Book, batchSize int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, books, batchSize)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockBookRepoMockRecorder) CreateBatch(ctx, books, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockBookRepo)(nil).CreateBatch), ctx, books, batchSize)
+}
+
+// Delete mocks base method.
+func (m *MockBookRepo) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBookRepoMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBookRepo)(nil).Delete), ctx, id)
+}
+
+// DeleteHolding mocks base method.
+func (m *MockBookRepo) DeleteHolding(ctx context.Context, bookID, branchID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHolding", ctx, bookID, branchID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHolding indicates an expected call of DeleteHolding.
+func (mr *MockBookRepoMockRecorder) DeleteHolding(ctx, bookID, branchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHolding", reflect.TypeOf((*MockBookRepo)(nil).DeleteHolding), ctx, bookID, branchID)
+}
+
+// GetAll mocks base method.
+func (m *MockBookRepo) GetAll(ctx context.Context, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockBookRepoMockRecorder) GetAll(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockBookRepo)(nil).GetAll), ctx, limit, offset)
+}
+
+// GetAvailable mocks base method.
+func (m *MockBookRepo) GetAvailable(ctx context.Context, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAvailable", ctx, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAvailable indicates an expected call of GetAvailable.
+func (mr *MockBookRepoMockRecorder) GetAvailable(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailable", reflect.TypeOf((*MockBookRepo)(nil).GetAvailable), ctx, limit, offset)
+}
+
+// GetByGenre mocks base method.
+func (m *MockBookRepo) GetByGenre(ctx context.Context, genre string, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByGenre", ctx, genre, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByGenre indicates an expected call of GetByGenre.
+func (mr *MockBookRepoMockRecorder) GetByGenre(ctx, genre, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByGenre", reflect.TypeOf((*MockBookRepo)(nil).GetByGenre), ctx, genre, limit, offset)
+}
+
+// GetByID mocks base method.
+func (m *MockBookRepo) GetByID(ctx context.Context, id string) (*models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockBookRepoMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockBookRepo)(nil).GetByID), ctx, id)
+}
+
+// GetByIDs mocks base method.
+func (m *MockBookRepo) GetByIDs(ctx context.Context, ids []string) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockBookRepoMockRecorder) GetByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockBookRepo)(nil).GetByIDs), ctx, ids)
+}
+
+// GetFiltered mocks base method.
+func (m *MockBookRepo) GetFiltered(ctx context.Context, filter repositories.BookFilter, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFiltered", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFiltered indicates an expected call of GetFiltered.
+func (mr *MockBookRepoMockRecorder) GetFiltered(ctx, filter, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFiltered", reflect.TypeOf((*MockBookRepo)(nil).GetFiltered), ctx, filter, limit, offset)
+}
+
+// GetHoldings mocks base method.
+func (m *MockBookRepo) GetHoldings(ctx context.Context, bookID string) ([]models.BookHolding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldings", ctx, bookID)
+	ret0, _ := ret[0].([]models.BookHolding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldings indicates an expected call of GetHoldings.
+func (mr *MockBookRepoMockRecorder) GetHoldings(ctx, bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldings", reflect.TypeOf((*MockBookRepo)(nil).GetHoldings), ctx, bookID)
+}
+
+// GetLowStock mocks base method.
+func (m *MockBookRepo) GetLowStock(ctx context.Context, threshold int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLowStock", ctx, threshold)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLowStock indicates an expected call of GetLowStock.
+func (mr *MockBookRepoMockRecorder) GetLowStock(ctx, threshold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLowStock", reflect.TypeOf((*MockBookRepo)(nil).GetLowStock), ctx, threshold)
+}
+
+// ISBNExists mocks base method.
+func (m *MockBookRepo) ISBNExists(ctx context.Context, isbn string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ISBNExists", ctx, isbn)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ISBNExists indicates an expected call of ISBNExists.
+func (mr *MockBookRepoMockRecorder) ISBNExists(ctx, isbn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ISBNExists", reflect.TypeOf((*MockBookRepo)(nil).ISBNExists), ctx, isbn)
+}
+
+// SearchBooks mocks base method.
+func (m *MockBookRepo) SearchBooks(ctx context.Context, query string, minRating float64, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchBooks", ctx, query, minRating, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchBooks indicates an expected call of SearchBooks.
+func (mr *MockBookRepoMockRecorder) SearchBooks(ctx, query, minRating, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBooks", reflect.TypeOf((*MockBookRepo)(nil).SearchBooks), ctx, query, minRating, limit, offset)
+}
+
+// SearchByTitle mocks base method.
+func (m *MockBookRepo) SearchByTitle(ctx context.Context, title string, minRating float64, limit, offset int) ([]models.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchByTitle", ctx, title, minRating, limit, offset)
+	ret0, _ := ret[0].([]models.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchByTitle indicates an expected call of SearchByTitle.
+func (mr *MockBookRepoMockRecorder) SearchByTitle(ctx, title, minRating, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByTitle", reflect.TypeOf((*MockBookRepo)(nil).SearchByTitle), ctx, title, minRating, limit, offset)
+}
+
+// SetHolding mocks base method.
+func (m *MockBookRepo) SetHolding(ctx context.Context, holding *models.BookHolding) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHolding", ctx, holding)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHolding indicates an expected call of SetHolding.
+func (mr *MockBookRepoMockRecorder) SetHolding(ctx, holding any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHolding", reflect.TypeOf((*MockBookRepo)(nil).SetHolding), ctx, holding)
+}
+
+// Update mocks base method.
+func (m *MockBookRepo) Update(ctx context.Context, book *models.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, book)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockBookRepoMockRecorder) Update(ctx, book any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockBookRepo)(nil).Update), ctx, book)
+}
+
+// UpdateQuantity mocks base method.
+func (m *MockBookRepo) UpdateQuantity(ctx context.Context, id string, quantity, availableQuantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuantity", ctx, id, quantity, availableQuantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateQuantity indicates an expected call of UpdateQuantity.
+func (mr *MockBookRepoMockRecorder) UpdateQuantity(ctx, id, quantity, availableQuantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuantity", reflect.TypeOf((*MockBookRepo)(nil).UpdateQuantity), ctx, id, quantity, availableQuantity)
+}
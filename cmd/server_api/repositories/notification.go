@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	now := time.Now().UTC()
+	notification.CreatedDate = now
+	notification.UpdatedDate = now
+	return r.db.Create(notification).Error
+}
+
+func (r *NotificationRepository) GetByUserID(userID string, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.userScope(userID, unreadOnly).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date DESC").
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *NotificationRepository) CountByUserID(userID string, unreadOnly bool) (int64, error) {
+	var count int64
+	err := r.userScope(userID, unreadOnly).Count(&count).Error
+	return count, err
+}
+
+func (r *NotificationRepository) userScope(userID string, unreadOnly bool) *gorm.DB {
+	query := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND deleted_date IS NULL", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	return query
+}
+
+func (r *NotificationRepository) MarkRead(id, userID string) (int64, error) {
+	now := time.Now().UTC()
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND deleted_date IS NULL", id, userID).
+		Updates(map[string]any{
+			"read_at":      gorm.Expr("COALESCE(read_at, ?)", now),
+			"updated_date": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepository) MarkAllRead(userID string) (int64, error) {
+	now := time.Now().UTC()
+	result := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND deleted_date IS NULL", userID).
+		Updates(map[string]any{
+			"read_at":      now,
+			"updated_date": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepository) GetPreferences(userID string) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ? AND deleted_date IS NULL", userID).
+		Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *NotificationRepository) GetPreference(userID, eventType, channel string) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	err := r.db.Where("user_id = ? AND event_type = ? AND channel = ? AND deleted_date IS NULL", userID, eventType, channel).
+		First(&preference).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *NotificationRepository) SavePreference(preference *models.NotificationPreference) error {
+	now := time.Now().UTC()
+	if preference.CreatedDate.IsZero() {
+		preference.CreatedDate = now
+	}
+	preference.UpdatedDate = now
+	return r.db.Save(preference).Error
+}
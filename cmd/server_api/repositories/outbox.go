@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	EventBookCreated         = "book.created"
+	EventBookUpdated         = "book.updated"
+	EventBookDeleted         = "book.deleted"
+	EventBookRestored        = "book.restored"
+	EventBookQuantityUpdated = "book.quantity_updated"
+	EventReviewCreated       = "review.created"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+func (r *OutboxRepository) GetUnpublished(limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.Where("published_at IS NULL AND deleted_date IS NULL").
+		Limit(limit).
+		Order("created_date ASC").
+		Find(&events).Error
+	return events, err
+}
+
+func (r *OutboxRepository) MarkPublished(id string) error {
+	now := time.Now().UTC()
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(map[string]any{
+			"published_at": now,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   nil,
+			"updated_date": now,
+		}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(id string, cause error) error {
+	return r.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(map[string]any{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   cause.Error(),
+			"updated_date": time.Now().UTC(),
+		}).Error
+}
+
+// addOutboxEvent must be called with the transaction that performs the data
+// change so the event is committed or rolled back together with it.
+func addOutboxEvent(tx *gorm.DB, aggregateType, aggregateID, eventType string, payload any) error {
+	event, err := newOutboxEvent(aggregateType, aggregateID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(event).Error
+}
+
+func newOutboxEvent(aggregateType, aggregateID, eventType string, payload any) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &models.OutboxEvent{
+		ID:            uuid.New().String(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(data),
+		CreatedDate:   now,
+		UpdatedDate:   now,
+	}, nil
+}
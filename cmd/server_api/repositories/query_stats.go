@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"book-management-system/pkg/querystats"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "querystats:start"
+
+// RegisterQueryStats times every GORM statement and records it by its SQL
+// text, which holds placeholders rather than bound values.
+func RegisterQueryStats(db *gorm.DB, recorder *querystats.Recorder) error {
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(queryStartKey, time.Now())
+	}
+	after := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		failed := tx.Error != nil && tx.Error != gorm.ErrRecordNotFound
+		recorder.Record(tx.Statement.SQL.String(), time.Since(start), failed)
+	}
+	cb := db.Callback()
+	registrations := []func() error{
+		func() error { return cb.Create().Before("gorm:create").Register("querystats:before_create", before) },
+		func() error { return cb.Create().After("gorm:create").Register("querystats:after_create", after) },
+		func() error { return cb.Query().Before("gorm:query").Register("querystats:before_query", before) },
+		func() error { return cb.Query().After("gorm:query").Register("querystats:after_query", after) },
+		func() error { return cb.Update().Before("gorm:update").Register("querystats:before_update", before) },
+		func() error { return cb.Update().After("gorm:update").Register("querystats:after_update", after) },
+		func() error { return cb.Delete().Before("gorm:delete").Register("querystats:before_delete", before) },
+		func() error { return cb.Delete().After("gorm:delete").Register("querystats:after_delete", after) },
+		func() error { return cb.Row().Before("gorm:row").Register("querystats:before_row", before) },
+		func() error { return cb.Row().After("gorm:row").Register("querystats:after_row", after) },
+		func() error { return cb.Raw().Before("gorm:raw").Register("querystats:before_raw", before) },
+		func() error { return cb.Raw().After("gorm:raw").Register("querystats:after_raw", after) },
+	}
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
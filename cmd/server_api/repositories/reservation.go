@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrReservationConflict is returned when the resource is already
+	// reserved for part of the requested time.
+	ErrReservationConflict = errors.New("resource already reserved for that time")
+	// ErrReservationLimitReached is returned when the member has as many
+	// reservations as their membership plan allows.
+	ErrReservationLimitReached = errors.New("reservation limit reached")
+	// ErrReservationEnded is returned when a reservation was cancelled or
+	// ended before the change.
+	ErrReservationEnded = errors.New("reservation has ended")
+)
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{
+		db: db,
+	}
+}
+
+func (r *ReservationRepository) GetByID(ctx context.Context, id string) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&reservation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetActiveByUser returns the member's reservations that have not ended at
+// now, soonest first.
+func (r *ReservationRepository) GetActiveByUser(ctx context.Context, userID string, now time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := r.active(r.db.WithContext(ctx), now).
+		Where("user_id = ?", userID).
+		Order("starts_at ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// GetByResource returns the resource's reservations overlapping from to to,
+// earliest first. Cancelled ones are left out.
+func (r *ReservationRepository) GetByResource(ctx context.Context, resourceID string, from, to time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	err := r.overlapping(r.db.WithContext(ctx), resourceID, from, to).
+		Order("starts_at ASC").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+// HasUpcoming reports whether the resource has reservations that have not
+// ended at now.
+func (r *ReservationRepository) HasUpcoming(ctx context.Context, resourceID string, now time.Time) (bool, error) {
+	var count int64
+	err := r.active(r.db.WithContext(ctx), now).
+		Model(&models.Reservation{}).
+		Where("resource_id = ?", resourceID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create reserves the resource unless another reservation overlaps it or
+// the member already has maxReservations that have not ended. The resource
+// is locked while overlaps are checked, so two members cannot reserve the
+// same time at once.
+func (r *ReservationRepository) Create(ctx context.Context, reservation *models.Reservation, maxReservations int) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var resource models.Resource
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_date IS NULL", reservation.ResourceID).
+			First(&resource).Error
+		if err != nil {
+			return err
+		}
+		var conflicts, userReservations int64
+		if err := r.overlapping(tx, reservation.ResourceID, reservation.StartsAt, reservation.EndsAt).Model(&models.Reservation{}).Count(&conflicts).Error; err != nil {
+			return err
+		}
+		if conflicts > 0 {
+			return ErrReservationConflict
+		}
+		if err := r.active(tx, now).Model(&models.Reservation{}).Where("user_id = ?", reservation.UserID).Count(&userReservations).Error; err != nil {
+			return err
+		}
+		if userReservations >= int64(maxReservations) {
+			return ErrReservationLimitReached
+		}
+		reservation.CreatedDate = now
+		reservation.UpdatedDate = now
+		return tx.Create(reservation).Error
+	})
+}
+
+// Cancel releases a reservation that has not ended.
+func (r *ReservationRepository) Cancel(ctx context.Context, reservation *models.Reservation, cancelledBy string) error {
+	now := time.Now().UTC()
+	result := r.active(r.db.WithContext(ctx), now).
+		Model(&models.Reservation{}).
+		Where("id = ?", reservation.ID).
+		Updates(map[string]any{
+			"cancelled_date": now,
+			"cancelled_by":   cancelledBy,
+			"updated_date":   now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReservationEnded
+	}
+	reservation.CancelledDate = &now
+	reservation.CancelledBy = &cancelledBy
+	reservation.UpdatedDate = now
+	return nil
+}
+
+func (r *ReservationRepository) active(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("cancelled_date IS NULL AND ends_at > ?", now)
+}
+
+func (r *ReservationRepository) overlapping(db *gorm.DB, resourceID string, from, to time.Time) *gorm.DB {
+	return db.Where("resource_id = ? AND cancelled_date IS NULL AND starts_at < ? AND ends_at > ?", resourceID, to, from)
+}
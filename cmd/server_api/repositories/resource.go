@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ResourceFilter narrows resource listings. Zero fields don't filter.
+type ResourceFilter struct {
+	Kind     string
+	BranchID string
+	// HiddenBranchIDs leaves out resources at these branches, for the local
+	// branches the caller may not see.
+	HiddenBranchIDs []string
+}
+
+type ResourceRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceRepository(db *gorm.DB) *ResourceRepository {
+	return &ResourceRepository{
+		db: db,
+	}
+}
+
+func (r *ResourceRepository) Create(ctx context.Context, resource *models.Resource) error {
+	now := time.Now().UTC()
+	resource.CreatedDate = now
+	resource.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(resource).Error
+}
+
+func (r *ResourceRepository) GetByID(ctx context.Context, id string) (*models.Resource, error) {
+	var resource models.Resource
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&resource).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+func (r *ResourceRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Resource, error) {
+	var resources []models.Resource
+	if len(ids) == 0 {
+		return resources, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&resources).Error
+	return resources, err
+}
+
+func (r *ResourceRepository) GetFiltered(ctx context.Context, filter ResourceFilter, limit, offset int) ([]models.Resource, error) {
+	var resources []models.Resource
+	err := r.scope(ctx, filter).
+		Limit(limit).
+		Offset(offset).
+		Order("name ASC").
+		Find(&resources).Error
+	return resources, err
+}
+
+func (r *ResourceRepository) CountFiltered(ctx context.Context, filter ResourceFilter) (int64, error) {
+	var count int64
+	err := r.scope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *ResourceRepository) scope(ctx context.Context, filter ResourceFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Resource{}).Where("deleted_date IS NULL")
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.BranchID != "" {
+		query = query.Where("branch_id = ?", filter.BranchID)
+	}
+	if len(filter.HiddenBranchIDs) > 0 {
+		query = query.Where("branch_id NOT IN ?", filter.HiddenBranchIDs)
+	}
+	return query
+}
+
+func (r *ResourceRepository) Update(ctx context.Context, resource *models.Resource) error {
+	resource.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(resource).Error
+}
+
+func (r *ResourceRepository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.Resource{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
@@ -0,0 +1,312 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPurgeBlocked is returned when a soft-deleted record is still referenced
+// by history the library keeps, such as transfers or loans.
+var ErrPurgeBlocked = errors.New("record is still referenced")
+
+// tableColumn is a column of another table that refers to a purged record.
+type tableColumn struct {
+	table  string
+	column string
+}
+
+// purgeSpec describes how a soft-deleted record is removed for good.
+type purgeSpec struct {
+	model any
+	// blockers refer to history that is kept, so any row in them stops
+	// the purge.
+	blockers []tableColumn
+	// cleared are optional references, set to NULL.
+	cleared []tableColumn
+	// dependents delete the rows the record owns, children first. Each
+	// takes the record's id.
+	dependents []string
+}
+
+var bookPurge = purgeSpec{
+	model: &models.Book{},
+	blockers: []tableColumn{
+		{"book_transfers", "book_id"},
+		{"digital_loans", "book_id"},
+		{"book_files", "book_id"},
+		{"book_covers", "book_id"},
+	},
+	cleared: []tableColumn{
+		{"suggestions", "book_id"},
+	},
+	dependents: []string{
+		"DELETE FROM book_holdings WHERE book_id = ?",
+		"DELETE FROM reading_list_items WHERE book_id = ?",
+		"DELETE FROM reviews WHERE book_id = ?",
+		"DELETE FROM book_enrichments WHERE book_id = ?",
+	},
+}
+
+var userPurge = purgeSpec{
+	model: &models.User{},
+	blockers: []tableColumn{
+		{"digital_loans", "user_id"},
+		{"reservations", "user_id"},
+		{"book_transfers", "requested_by"},
+		{"devices", "created_by"},
+		{"user_notes", "author_id"},
+		{"suggestions", "user_id"},
+		{"cash_drawer_sessions", "opened_by"},
+		{"cash_drawer_sessions", "closed_by"},
+		{"cash_drawer_payments", "user_id"},
+		{"cash_drawer_payments", "recorded_by"},
+	},
+	cleared: []tableColumn{
+		{"book_transfers", "shipped_by"},
+		{"book_transfers", "received_by"},
+		{"book_transfers", "cancelled_by"},
+		{"book_files", "uploaded_by"},
+		{"book_enrichments", "reviewed_by"},
+		{"library_events", "created_by"},
+		{"reservations", "cancelled_by"},
+		{"devices", "revoked_by"},
+		{"suggestions", "reviewed_by"},
+		{"catalog_snapshots", "taken_by"},
+	},
+	dependents: []string{
+		"DELETE FROM reading_list_items WHERE list_id IN (SELECT id FROM reading_lists WHERE user_id = ?)",
+		"DELETE FROM reading_lists WHERE user_id = ?",
+		"DELETE FROM reviews WHERE user_id = ?",
+		"DELETE FROM event_rsvps WHERE user_id = ?",
+		"DELETE FROM notifications WHERE user_id = ?",
+		"DELETE FROM notification_preferences WHERE user_id = ?",
+		"DELETE FROM saved_searches WHERE user_id = ?",
+		"DELETE FROM saved_reports WHERE user_id = ?",
+		"DELETE FROM user_notes WHERE user_id = ?",
+		"DELETE FROM login_events WHERE user_id = ?",
+		"DELETE FROM idempotency_keys WHERE user_id = ?",
+		"DELETE FROM calendar_feeds WHERE user_id = ?",
+	},
+}
+
+// RetentionRepository lists, restores and purges soft-deleted books and
+// users. Audit log entries are never purged and keep the actor's id and
+// email.
+type RetentionRepository struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) *RetentionRepository {
+	return &RetentionRepository{
+		db: db,
+	}
+}
+
+// GetDeletedBooks returns soft-deleted books, longest deleted first.
+func (r *RetentionRepository) GetDeletedBooks(ctx context.Context, limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.WithContext(ctx).Where("deleted_date IS NOT NULL").
+		Order("deleted_date ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&books).Error
+	return books, err
+}
+
+func (r *RetentionRepository) CountDeletedBooks(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Book{}).Where("deleted_date IS NOT NULL").Count(&count).Error
+	return count, err
+}
+
+// GetDeletedUsers returns soft-deleted users, longest deleted first.
+func (r *RetentionRepository) GetDeletedUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Where("deleted_date IS NOT NULL").
+		Order("deleted_date ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *RetentionRepository) CountDeletedUsers(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("deleted_date IS NOT NULL").Count(&count).Error
+	return count, err
+}
+
+func (r *RetentionRepository) GetDeletedBook(ctx context.Context, id string) (*models.Book, error) {
+	var book models.Book
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NOT NULL", id).First(&book).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *RetentionRepository) GetDeletedUser(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NOT NULL", id).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RestoreBook undeletes a book and publishes book.restored with its data.
+func (r *RetentionRepository) RestoreBook(ctx context.Context, book *models.Book) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Book{}).
+			Where("id = ? AND deleted_date IS NOT NULL", book.ID).
+			Updates(map[string]any{
+				"deleted_date": nil,
+				"version":      gorm.Expr("version + 1"),
+				"updated_date": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		book.DeletedDate = nil
+		book.Version++
+		book.UpdatedDate = now
+		return addOutboxEvent(tx, "book", book.ID, EventBookRestored, book)
+	})
+}
+
+// RestoreUser undeletes a user. It returns gorm.ErrDuplicatedKey if a live
+// user has taken the email since.
+func (r *RetentionRepository) RestoreUser(ctx context.Context, user *models.User) error {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND deleted_date IS NOT NULL", user.ID).
+		Updates(map[string]any{
+			"deleted_date": nil,
+			"version":      gorm.Expr("version + 1"),
+			"updated_date": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	user.DeletedDate = nil
+	user.Version++
+	user.UpdatedDate = now
+	return nil
+}
+
+// ExpiredBookIDs returns up to limit ids of books deleted before cutoff,
+// in id order after afterID.
+func (r *RetentionRepository) ExpiredBookIDs(ctx context.Context, cutoff time.Time, afterID string, limit int) ([]string, error) {
+	return r.expiredIDs(ctx, &models.Book{}, cutoff, afterID, limit)
+}
+
+// ExpiredUserIDs returns up to limit ids of users deleted before cutoff,
+// in id order after afterID.
+func (r *RetentionRepository) ExpiredUserIDs(ctx context.Context, cutoff time.Time, afterID string, limit int) ([]string, error) {
+	return r.expiredIDs(ctx, &models.User{}, cutoff, afterID, limit)
+}
+
+func (r *RetentionRepository) expiredIDs(ctx context.Context, model any, cutoff time.Time, afterID string, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(model).
+		Where("deleted_date IS NOT NULL AND deleted_date < ? AND id > ?", cutoff, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// PurgeBook permanently removes a soft-deleted book with its holdings,
+// reading list entries, reviews and pending enrichments. It returns
+// ErrPurgeBlocked while transfers, digital loans or ebook files refer to
+// the book. With dryRun it only checks.
+func (r *RetentionRepository) PurgeBook(ctx context.Context, id string, dryRun bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkPurge(tx, &bookPurge, id); err != nil || dryRun {
+			return err
+		}
+		return purgeRecord(tx, &bookPurge, id)
+	})
+}
+
+// PurgeUser permanently removes a soft-deleted user with everything the
+// account owns, and recomputes the ratings of the books they reviewed. It
+// returns ErrPurgeBlocked while digital loans, reservations, transfers they
+// requested, devices they registered, notes they wrote, their suggestions,
+// desk payments they made or took, or cash drawer sessions they opened or
+// closed remain. With dryRun it only checks.
+func (r *RetentionRepository) PurgeUser(ctx context.Context, id string, dryRun bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkPurge(tx, &userPurge, id); err != nil || dryRun {
+			return err
+		}
+		var bookIDs []string
+		err := tx.Model(&models.Review{}).
+			Where("user_id = ? AND deleted_date IS NULL", id).
+			Pluck("book_id", &bookIDs).Error
+		if err != nil {
+			return err
+		}
+		if err := purgeRecord(tx, &userPurge, id); err != nil {
+			return err
+		}
+		for _, bookID := range bookIDs {
+			if err := refreshBookRating(tx, bookID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkPurge locks the soft-deleted record and returns ErrPurgeBlocked if
+// kept history still refers to it.
+func checkPurge(tx *gorm.DB, spec *purgeSpec, id string) error {
+	var ids []string
+	err := tx.Model(spec.model).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND deleted_date IS NOT NULL", id).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	var count int64
+	for _, ref := range spec.blockers {
+		if err := tx.Table(ref.table).Where(ref.column+" = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPurgeBlocked
+		}
+	}
+	return nil
+}
+
+func purgeRecord(tx *gorm.DB, spec *purgeSpec, id string) error {
+	for _, ref := range spec.cleared {
+		if err := tx.Table(ref.table).Where(ref.column+" = ?", id).Update(ref.column, nil).Error; err != nil {
+			return err
+		}
+	}
+	for _, statement := range spec.dependents {
+		if err := tx.Exec(statement, id).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("id = ?", id).Delete(spec.model).Error
+}
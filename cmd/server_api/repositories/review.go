@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{
+		db: db,
+	}
+}
+
+func (r *ReviewRepository) Create(review *models.Review) error {
+	now := time.Now().UTC()
+	review.CreatedDate = now
+	review.UpdatedDate = now
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if err := refreshBookRating(tx, review.BookID); err != nil {
+			return err
+		}
+		return addOutboxEvent(tx, "review", review.ID, EventReviewCreated, review)
+	})
+}
+
+func (r *ReviewRepository) GetByBookID(bookID string, limit, offset int) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.Where("book_id = ? AND deleted_date IS NULL", bookID).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date DESC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *ReviewRepository) CountByBookID(bookID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).
+		Where("book_id = ? AND deleted_date IS NULL", bookID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *ReviewRepository) ExistsForUser(bookID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).
+		Where("book_id = ? AND user_id = ? AND deleted_date IS NULL", bookID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func refreshBookRating(tx *gorm.DB, bookID string) error {
+	return tx.Exec(
+		`UPDATE books SET rating_count = stats.review_count, rating_average = stats.average
+		FROM (
+			SELECT COUNT(*) AS review_count, COALESCE(ROUND(AVG(rating), 2), 0) AS average
+			FROM reviews
+			WHERE book_id = ? AND deleted_date IS NULL
+		) AS stats
+		WHERE books.id = ?`,
+		bookID,
+		bookID,
+	).Error
+}
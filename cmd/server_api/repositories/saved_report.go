@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SavedReportRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedReportRepository(db *gorm.DB) *SavedReportRepository {
+	return &SavedReportRepository{
+		db: db,
+	}
+}
+
+func (r *SavedReportRepository) Create(ctx context.Context, report *models.SavedReport) error {
+	now := time.Now().UTC()
+	report.CreatedDate = now
+	report.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// GetByID only finds reports owned by userID.
+func (r *SavedReportRepository) GetByID(ctx context.Context, userID, id string) (*models.SavedReport, error) {
+	var report models.SavedReport
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND deleted_date IS NULL", id, userID).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *SavedReportRepository) GetByUserID(ctx context.Context, userID string) ([]models.SavedReport, error) {
+	var reports []models.SavedReport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND deleted_date IS NULL", userID).
+		Order("name ASC").
+		Find(&reports).Error
+	return reports, err
+}
+
+// GetDue returns the scheduled reports whose next run is at or before now.
+func (r *SavedReportRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.SavedReport, error) {
+	var reports []models.SavedReport
+	err := r.db.WithContext(ctx).
+		Where("next_run_at <= ? AND deleted_date IS NULL", now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r *SavedReportRepository) NameExists(ctx context.Context, userID, name, excludeID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SavedReport{}).
+		Where("user_id = ? AND name = ? AND id <> ? AND deleted_date IS NULL", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *SavedReportRepository) Update(ctx context.Context, report *models.SavedReport) error {
+	report.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(report).Error
+}
+
+// RecordRun stores a run's outcome. nextRunAt is nil for unscheduled reports.
+func (r *SavedReportRepository) RecordRun(ctx context.Context, id string, runAt time.Time, status int, result string, nextRunAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.SavedReport{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(map[string]any{
+			"last_run_at": runAt,
+			"last_status": status,
+			"last_result": result,
+			"next_run_at": nextRunAt,
+		}).Error
+}
+
+func (r *SavedReportRepository) Delete(ctx context.Context, userID, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.SavedReport{}).
+		Where("id = ? AND user_id = ? AND deleted_date IS NULL", id, userID).
+		Update("deleted_date", now).Error
+}
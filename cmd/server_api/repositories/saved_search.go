@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SavedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		db: db,
+	}
+}
+
+// SavedSearchFilter returns the book filter a saved search runs.
+func SavedSearchFilter(search *models.SavedSearch) BookFilter {
+	var filter BookFilter
+	if search.Query != nil {
+		filter.Keyword = *search.Query
+	}
+	if search.Title != nil {
+		filter.Title = *search.Title
+	}
+	if search.Author != nil {
+		filter.Author = *search.Author
+	}
+	if search.Genre != nil {
+		filter.Genre = *search.Genre
+	}
+	return filter
+}
+
+func (r *SavedSearchRepository) Create(ctx context.Context, search *models.SavedSearch) error {
+	now := time.Now().UTC()
+	search.CreatedDate = now
+	search.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(search).Error
+}
+
+// GetByID only finds searches owned by userID.
+func (r *SavedSearchRepository) GetByID(ctx context.Context, userID, id string) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND deleted_date IS NULL", id, userID).
+		First(&search).Error
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *SavedSearchRepository) GetByUserID(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND deleted_date IS NULL", userID).
+		Order("name ASC").
+		Find(&searches).Error
+	return searches, err
+}
+
+func (r *SavedSearchRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SavedSearch{}).
+		Where("user_id = ? AND deleted_date IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetDue returns the searches last checked before before, least recently
+// checked first.
+func (r *SavedSearchRepository) GetDue(ctx context.Context, before time.Time, limit int) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("last_checked_at < ? AND deleted_date IS NULL", before).
+		Order("last_checked_at ASC").
+		Limit(limit).
+		Find(&searches).Error
+	return searches, err
+}
+
+func (r *SavedSearchRepository) NameExists(ctx context.Context, userID, name, excludeID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SavedSearch{}).
+		Where("user_id = ? AND name = ? AND id <> ? AND deleted_date IS NULL", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *SavedSearchRepository) Update(ctx context.Context, search *models.SavedSearch) error {
+	search.UpdatedDate = time.Now().UTC()
+	return r.db.WithContext(ctx).Save(search).Error
+}
+
+// RecordCheck moves the search's watermark to checkedAt. matchedAt is nil
+// when no new books matched.
+func (r *SavedSearchRepository) RecordCheck(ctx context.Context, id string, checkedAt time.Time, matchedAt *time.Time) error {
+	updates := map[string]any{
+		"last_checked_at": checkedAt,
+	}
+	if matchedAt != nil {
+		updates["last_matched_at"] = *matchedAt
+	}
+	return r.db.WithContext(ctx).Model(&models.SavedSearch{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Updates(updates).Error
+}
+
+func (r *SavedSearchRepository) Delete(ctx context.Context, userID, id string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Model(&models.SavedSearch{}).
+		Where("id = ? AND user_id = ? AND deleted_date IS NULL", id, userID).
+		Update("deleted_date", now).Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AuthorMatch is an author of live books and how many of them the catalog
+// has.
+type AuthorMatch struct {
+	Name  string `gorm:"column:author"`
+	Books int    `gorm:"column:books"`
+}
+
+// SearchRepository holds the lookups of global search that no entity
+// repository covers.
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+// SearchAuthors returns the authors whose name contains query, those with
+// the most books first.
+func (r *SearchRepository) SearchAuthors(ctx context.Context, query string, limit int) ([]AuthorMatch, error) {
+	var authors []AuthorMatch
+	err := r.authorScope(ctx, query).
+		Select("author, COUNT(*) AS books").
+		Group("author").
+		Order("books DESC, author ASC").
+		Limit(limit).
+		Find(&authors).Error
+	return authors, err
+}
+
+// CountAuthors counts the distinct authors SearchAuthors matches.
+func (r *SearchRepository) CountAuthors(ctx context.Context, query string) (int64, error) {
+	var count int64
+	err := r.authorScope(ctx, query).Distinct("author").Count(&count).Error
+	return count, err
+}
+
+func (r *SearchRepository) authorScope(ctx context.Context, query string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("books").
+		Where("LOWER(author) LIKE ? AND deleted_date IS NULL", "%"+strings.ToLower(query)+"%")
+}
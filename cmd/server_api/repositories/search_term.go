@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SearchTermRepository struct {
+	db *gorm.DB
+}
+
+// SearchTermTotal is a query's searches summed over a date range. Days
+// counts the days it was searched for, or, from GetTopZeroResults, the days
+// it found nothing.
+type SearchTermTotal struct {
+	Query       string
+	Searches    int
+	ZeroResults int
+	Days        int
+}
+
+// SearchTermSummary sums every query over a date range. Terms counts the
+// distinct queries.
+type SearchTermSummary struct {
+	Searches    int64
+	ZeroResults int64
+	Terms       int64
+}
+
+func NewSearchTermRepository(db *gorm.DB) *SearchTermRepository {
+	return &SearchTermRepository{
+		db: db,
+	}
+}
+
+// Record counts one search for query on the current UTC day, and one
+// zero-result search when zeroResult is set.
+func (r *SearchTermRepository) Record(ctx context.Context, query string, zeroResult bool) error {
+	now := time.Now().UTC()
+	zeroResults := 0
+	if zeroResult {
+		zeroResults = 1
+	}
+	term := &models.SearchTerm{
+		ID:             uuid.New().String(),
+		Query:          query,
+		SearchDate:     time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Searches:       1,
+		ZeroResults:    zeroResults,
+		LastSearchedAt: now,
+		CreatedDate:    now,
+		UpdatedDate:    now,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "query"},
+			{Name: "search_date"},
+		},
+		DoUpdates: clause.Assignments(map[string]any{
+			"searches":         gorm.Expr("search_terms.searches + 1"),
+			"zero_results":     gorm.Expr("search_terms.zero_results + ?", zeroResults),
+			"last_searched_at": now,
+			"updated_date":     now,
+		}),
+	}).Create(term).Error
+}
+
+// GetTop returns the queries searched for between the UTC days from and to,
+// inclusive, most searched first.
+func (r *SearchTermRepository) GetTop(ctx context.Context, from, to time.Time, limit int) ([]SearchTermTotal, error) {
+	var totals []SearchTermTotal
+	err := r.rangeScope(ctx, from, to).
+		Select("query, SUM(searches) AS searches, SUM(zero_results) AS zero_results, COUNT(*) AS days").
+		Group("query").
+		Order("searches DESC, query ASC").
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetTopZeroResults returns the queries that found nothing at least
+// minZeroResults times between the UTC days from and to, inclusive, those
+// that found nothing most often first.
+func (r *SearchTermRepository) GetTopZeroResults(ctx context.Context, from, to time.Time, minZeroResults, limit int) ([]SearchTermTotal, error) {
+	var totals []SearchTermTotal
+	err := r.rangeScope(ctx, from, to).
+		Select("query, SUM(searches) AS searches, SUM(zero_results) AS zero_results, COUNT(*) AS days").
+		Where("zero_results > 0").
+		Group("query").
+		Having("SUM(zero_results) >= ?", minZeroResults).
+		Order("zero_results DESC, query ASC").
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// Summarize sums the searches between the UTC days from and to, inclusive.
+func (r *SearchTermRepository) Summarize(ctx context.Context, from, to time.Time) (SearchTermSummary, error) {
+	var summary SearchTermSummary
+	err := r.rangeScope(ctx, from, to).
+		Select("COALESCE(SUM(searches), 0) AS searches, COALESCE(SUM(zero_results), 0) AS zero_results, COUNT(DISTINCT query) AS terms").
+		Scan(&summary).Error
+	return summary, err
+}
+
+func (r *SearchTermRepository) rangeScope(ctx context.Context, from, to time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&models.SearchTerm{}).
+		Where("search_date >= ? AND search_date <= ?", from, to)
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SuggestionRepository struct {
+	db *gorm.DB
+}
+
+func NewSuggestionRepository(db *gorm.DB) *SuggestionRepository {
+	return &SuggestionRepository{
+		db: db,
+	}
+}
+
+func (r *SuggestionRepository) Create(suggestion *models.Suggestion) error {
+	now := time.Now().UTC()
+	suggestion.CreatedDate = now
+	suggestion.UpdatedDate = now
+	return r.db.Create(suggestion).Error
+}
+
+func (r *SuggestionRepository) GetByID(id string) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&suggestion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+func (r *SuggestionRepository) GetByUserID(userID string) ([]models.Suggestion, error) {
+	var suggestions []models.Suggestion
+	err := r.db.Where("user_id = ? AND deleted_date IS NULL", userID).
+		Order("created_date DESC").
+		Find(&suggestions).Error
+	return suggestions, err
+}
+
+func (r *SuggestionRepository) GetAll(status string, limit, offset int) ([]models.Suggestion, error) {
+	var suggestions []models.Suggestion
+	err := r.statusScope(status).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date ASC").
+		Find(&suggestions).Error
+	return suggestions, err
+}
+
+func (r *SuggestionRepository) Count(status string) (int64, error) {
+	var count int64
+	err := r.statusScope(status).Count(&count).Error
+	return count, err
+}
+
+func (r *SuggestionRepository) statusScope(status string) *gorm.DB {
+	query := r.db.Model(&models.Suggestion{}).Where("deleted_date IS NULL")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return query
+}
+
+func (r *SuggestionRepository) CountMerged(ids []string) (map[string]int64, error) {
+	var rows []struct {
+		MergedIntoID string
+		Count        int64
+	}
+	err := r.db.Model(&models.Suggestion{}).
+		Select("merged_into_id, COUNT(*) AS count").
+		Where("merged_into_id IN ? AND deleted_date IS NULL", ids).
+		Group("merged_into_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.MergedIntoID] = row.Count
+	}
+	return counts, nil
+}
+
+// Accept creates the on-order book and resolves the suggestion together with
+// every suggestion merged into it.
+func (r *SuggestionRepository) Accept(suggestion *models.Suggestion, book *models.Book, reviewerID string) error {
+	now := time.Now().UTC()
+	book.CreatedDate = now
+	book.UpdatedDate = now
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(book).Error; err != nil {
+			return err
+		}
+		if err := addOutboxEvent(tx, "book", book.ID, EventBookCreated, book); err != nil {
+			return err
+		}
+		err := r.resolve(tx, suggestion, map[string]any{
+			"status":      models.SuggestionStatusAccepted,
+			"book_id":     book.ID,
+			"reviewed_by": reviewerID,
+			"reviewed_at": now,
+		})
+		if err != nil {
+			return err
+		}
+		suggestion.Status = models.SuggestionStatusAccepted
+		suggestion.BookID = &book.ID
+		suggestion.ReviewedBy = &reviewerID
+		suggestion.ReviewedAt = &now
+		return nil
+	})
+}
+
+func (r *SuggestionRepository) Reject(suggestion *models.Suggestion, reason, reviewerID string) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := r.resolve(tx, suggestion, map[string]any{
+			"status":           models.SuggestionStatusRejected,
+			"rejection_reason": reason,
+			"reviewed_by":      reviewerID,
+			"reviewed_at":      now,
+		})
+		if err != nil {
+			return err
+		}
+		suggestion.Status = models.SuggestionStatusRejected
+		suggestion.RejectionReason = &reason
+		suggestion.ReviewedBy = &reviewerID
+		suggestion.ReviewedAt = &now
+		return nil
+	})
+}
+
+func (r *SuggestionRepository) resolve(tx *gorm.DB, suggestion *models.Suggestion, updates map[string]any) error {
+	updates["updated_date"] = time.Now().UTC()
+	err := tx.Model(&models.Suggestion{}).
+		Where("id = ? AND deleted_date IS NULL", suggestion.ID).
+		Updates(updates).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Suggestion{}).
+		Where("merged_into_id = ? AND deleted_date IS NULL", suggestion.ID).
+		Updates(updates).Error
+}
+
+// Merge folds a duplicate into target, re-pointing anything already merged
+// into the duplicate so merges never chain.
+func (r *SuggestionRepository) Merge(duplicate, target *models.Suggestion, reviewerID string) error {
+	now := time.Now().UTC()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Suggestion{}).
+			Where("merged_into_id = ? AND deleted_date IS NULL", duplicate.ID).
+			Updates(map[string]any{
+				"merged_into_id": target.ID,
+				"updated_date":   now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Suggestion{}).
+			Where("id = ? AND deleted_date IS NULL", duplicate.ID).
+			Updates(map[string]any{
+				"status":         models.SuggestionStatusMerged,
+				"merged_into_id": target.ID,
+				"reviewed_by":    reviewerID,
+				"reviewed_at":    now,
+				"updated_date":   now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		duplicate.Status = models.SuggestionStatusMerged
+		duplicate.MergedIntoID = &target.ID
+		duplicate.ReviewedBy = &reviewerID
+		duplicate.ReviewedAt = &now
+		duplicate.UpdatedDate = now
+		return nil
+	})
+}
+
+func (r *SuggestionRepository) FindPendingDuplicate(title, author string, isbn *string) (*models.Suggestion, error) {
+	var suggestion models.Suggestion
+	query := r.db.Where("status = ? AND deleted_date IS NULL", models.SuggestionStatusPending)
+	if isbn != nil {
+		query = query.Where("(isbn = ? OR (LOWER(title) = LOWER(?) AND LOWER(author) = LOWER(?)))", *isbn, title, author)
+	} else {
+		query = query.Where("LOWER(title) = LOWER(?) AND LOWER(author) = LOWER(?)", title, author)
+	}
+	err := query.Order("created_date ASC").First(&suggestion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTransferStateChanged is returned when a transfer is no longer in the
+	// status the caller loaded it in, e.g. two staff members shipping it at
+	// once.
+	ErrTransferStateChanged = errors.New("transfer status changed")
+	// ErrInsufficientCopies is returned when the source branch no longer has
+	// enough available copies to ship.
+	ErrInsufficientCopies = errors.New("not enough available copies")
+)
+
+type TransferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) *TransferRepository {
+	return &TransferRepository{
+		db: db,
+	}
+}
+
+func (r *TransferRepository) Create(ctx context.Context, transfer *models.BookTransfer) error {
+	now := time.Now().UTC()
+	transfer.CreatedDate = now
+	transfer.UpdatedDate = now
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+func (r *TransferRepository) GetByID(ctx context.Context, id string) (*models.BookTransfer, error) {
+	var transfer models.BookTransfer
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetAll lists transfers, newest first. branchID matches either end of the
+// transfer; empty filters match everything.
+func (r *TransferRepository) GetAll(ctx context.Context, status, bookID, branchID string, limit, offset int) ([]models.BookTransfer, error) {
+	var transfers []models.BookTransfer
+	err := r.scope(ctx, status, bookID, branchID).
+		Limit(limit).
+		Offset(offset).
+		Order("created_date DESC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *TransferRepository) Count(ctx context.Context, status, bookID, branchID string) (int64, error) {
+	var count int64
+	err := r.scope(ctx, status, bookID, branchID).Count(&count).Error
+	return count, err
+}
+
+func (r *TransferRepository) scope(ctx context.Context, status, bookID, branchID string) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.BookTransfer{}).Where("deleted_date IS NULL")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if bookID != "" {
+		query = query.Where("book_id = ?", bookID)
+	}
+	if branchID != "" {
+		query = query.Where("(from_branch_id = ? OR to_branch_id = ?)", branchID, branchID)
+	}
+	return query
+}
+
+// GetOpenByBook returns the book's requested and in-transit transfers.
+func (r *TransferRepository) GetOpenByBook(ctx context.Context, bookID string) ([]models.BookTransfer, error) {
+	var transfers []models.BookTransfer
+	err := r.db.WithContext(ctx).
+		Where("book_id = ? AND status IN ? AND deleted_date IS NULL", bookID, []string{models.TransferStatusRequested, models.TransferStatusInTransit}).
+		Order("created_date ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// Ship takes the copies out of the source holding and marks the transfer in
+// transit.
+func (r *TransferRepository) Ship(ctx context.Context, transfer *models.BookTransfer, userID string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockBook(tx, transfer.BookID); err != nil {
+			return err
+		}
+		err := r.advance(tx, transfer, models.TransferStatusRequested, map[string]any{
+			"status":       models.TransferStatusInTransit,
+			"shipped_by":   userID,
+			"shipped_at":   now,
+			"updated_date": now,
+		})
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.BookHolding{}).
+			Where("book_id = ? AND branch_id = ? AND available_quantity >= ? AND deleted_date IS NULL", transfer.BookID, transfer.FromBranchID, transfer.Quantity).
+			Updates(map[string]any{
+				"quantity":           gorm.Expr("quantity - ?", transfer.Quantity),
+				"available_quantity": gorm.Expr("available_quantity - ?", transfer.Quantity),
+				"updated_date":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientCopies
+		}
+		transfer.Status = models.TransferStatusInTransit
+		transfer.ShippedBy = &userID
+		transfer.ShippedAt = &now
+		transfer.UpdatedDate = now
+		return syncHoldingTotals(tx, transfer.BookID, now)
+	})
+}
+
+// Receive adds the copies to the destination holding, creating it if the
+// branch did not hold the book yet, and completes the transfer.
+func (r *TransferRepository) Receive(ctx context.Context, transfer *models.BookTransfer, userID string) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockBook(tx, transfer.BookID); err != nil {
+			return err
+		}
+		err := r.advance(tx, transfer, models.TransferStatusInTransit, map[string]any{
+			"status":       models.TransferStatusReceived,
+			"received_by":  userID,
+			"received_at":  now,
+			"updated_date": now,
+		})
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.BookHolding{}).
+			Where("book_id = ? AND branch_id = ? AND deleted_date IS NULL", transfer.BookID, transfer.ToBranchID).
+			Updates(map[string]any{
+				"quantity":           gorm.Expr("quantity + ?", transfer.Quantity),
+				"available_quantity": gorm.Expr("available_quantity + ?", transfer.Quantity),
+				"updated_date":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			err = tx.Create(&models.BookHolding{
+				ID:                uuid.New().String(),
+				BookID:            transfer.BookID,
+				BranchID:          transfer.ToBranchID,
+				Quantity:          transfer.Quantity,
+				AvailableQuantity: transfer.Quantity,
+				CreatedDate:       now,
+				UpdatedDate:       now,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		transfer.Status = models.TransferStatusReceived
+		transfer.ReceivedBy = &userID
+		transfer.ReceivedAt = &now
+		transfer.UpdatedDate = now
+		return syncHoldingTotals(tx, transfer.BookID, now)
+	})
+}
+
+// Cancel withdraws a transfer that has not shipped yet. No copies have moved,
+// so holdings are untouched.
+func (r *TransferRepository) Cancel(ctx context.Context, transfer *models.BookTransfer, userID string) error {
+	now := time.Now().UTC()
+	err := r.advance(r.db.WithContext(ctx), transfer, models.TransferStatusRequested, map[string]any{
+		"status":       models.TransferStatusCancelled,
+		"cancelled_by": userID,
+		"cancelled_at": now,
+		"updated_date": now,
+	})
+	if err != nil {
+		return err
+	}
+	transfer.Status = models.TransferStatusCancelled
+	transfer.CancelledBy = &userID
+	transfer.CancelledAt = &now
+	transfer.UpdatedDate = now
+	return nil
+}
+
+// advance moves the transfer out of status from, failing if another request
+// got there first.
+func (r *TransferRepository) advance(tx *gorm.DB, transfer *models.BookTransfer, from string, updates map[string]any) error {
+	result := tx.Model(&models.BookTransfer{}).
+		Where("id = ? AND status = ? AND deleted_date IS NULL", transfer.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTransferStateChanged
+	}
+	return nil
+}
@@ -2,11 +2,56 @@ package repositories
 
 import (
 	"book-management-system/cmd/server_api/models"
+	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrEmailExists is returned by Create when a live user already has the
+// email. Other unique violations are returned as gorm.ErrDuplicatedKey.
+var ErrEmailExists = errors.New("email already registered")
+
+// UserFilter narrows user listings. Empty fields match everything.
+type UserFilter struct {
+	Role   string
+	Status string
+}
+
+//go:generate mockgen -source=user.go -destination=mocks/user.go -package=mocks
+
+// UserRepo is the user data access used by the API handlers. UserRepository
+// implements it; mocks.MockUserRepo stands in for it in handler tests.
+type UserRepo interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []string) ([]models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByCardNumber(ctx context.Context, cardNumber string) (*models.User, error)
+	GetAll(ctx context.Context, limit, offset int) ([]models.User, error)
+	GetFiltered(ctx context.Context, filter UserFilter, limit, offset int) ([]models.User, error)
+	CountFiltered(ctx context.Context, filter UserFilter) (int64, error)
+	SearchUsers(ctx context.Context, query string, limit, offset int) ([]models.User, error)
+	CountSearch(ctx context.Context, query string) (int64, error)
+	GetInactiveSince(ctx context.Context, cutoff time.Time, limit, offset int) ([]models.User, error)
+	CountInactiveSince(ctx context.Context, cutoff time.Time) (int64, error)
+	GetInactiveMembersSince(ctx context.Context, cutoff time.Time, excludeFlagged bool) ([]models.User, error)
+	GetMemberActivity(ctx context.Context) ([]models.User, error)
+	FlagInactive(ctx context.Context, ids []string, at time.Time) (int64, error)
+	DeactivateUsers(ctx context.Context, ids []string) (int64, error)
+	UpdateLastLogin(ctx context.Context, id string, at time.Time) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id string) error
+	Count(ctx context.Context) (int64, error)
+	CountByRole(ctx context.Context, role string) (int64, error)
+	CountByMembershipPlan(ctx context.Context, planID string) (int64, error)
+	EmailExists(ctx context.Context, email string) (bool, error)
+	CardNumberExists(ctx context.Context, cardNumber string) (bool, error)
+	InBranch(branchID string) UserRepo
+}
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -17,34 +62,63 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	}
 }
 
-func (r *UserRepository) Create(user *models.User) error {
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	now := time.Now().UTC()
 	user.CreatedDate = now
 	user.UpdatedDate = now
-	return r.db.Create(user).Error
+	user.Version = 1
+	if user.Timezone == "" {
+		user.Timezone = models.DefaultTimezone
+	}
+	err := r.db.WithContext(ctx).Create(user).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// The violated index is not reported, so look at the email.
+		if taken, lookupErr := r.EmailExists(ctx, user.Email); lookupErr == nil && taken {
+			return ErrEmailExists
+		}
+	}
+	return err
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_date IS NULL", id).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ? AND deleted_date IS NULL", ids).Find(&users).Error
+	return users, err
 }
 
-func (r *UserRepository) GetByID(id string) (*models.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&user).Error
+	err := r.db.WithContext(ctx).Where("LOWER(email) = LOWER(?) AND deleted_date IS NULL", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+func (r *UserRepository) GetByCardNumber(ctx context.Context, cardNumber string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("email = ? AND deleted_date IS NULL", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("card_number = ? AND deleted_date IS NULL", cardNumber).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *UserRepository) GetAll(limit, offset int) ([]models.User, error) {
+func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Where("deleted_date IS NULL").
+	err := r.db.WithContext(ctx).Where("deleted_date IS NULL").
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -52,9 +126,10 @@ func (r *UserRepository) GetAll(limit, offset int) ([]models.User, error) {
 	return users, err
 }
 
-func (r *UserRepository) GetByRole(role string, limit, offset int) ([]models.User, error) {
+// GetFiltered lists users matching every field set in filter, newest first.
+func (r *UserRepository) GetFiltered(ctx context.Context, filter UserFilter, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Where("role = ? AND deleted_date IS NULL", role).
+	err := r.filterScope(ctx, filter).
 		Limit(limit).
 		Offset(offset).
 		Order("created_date DESC").
@@ -62,46 +137,179 @@ func (r *UserRepository) GetByRole(role string, limit, offset int) ([]models.Use
 	return users, err
 }
 
-func (r *UserRepository) GetByStatus(status string, limit, offset int) ([]models.User, error) {
+// CountFiltered counts the users GetFiltered pages through.
+func (r *UserRepository) CountFiltered(ctx context.Context, filter UserFilter) (int64, error) {
+	var count int64
+	err := r.filterScope(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) filterScope(ctx context.Context, filter UserFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.User{}).Where("deleted_date IS NULL")
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+func (r *UserRepository) SearchUsers(ctx context.Context, query string, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Where("status = ? AND deleted_date IS NULL", status).
+	err := r.searchScope(ctx, query).
 		Limit(limit).
 		Offset(offset).
-		Order("created_date DESC").
+		Order("last_name ASC, first_name ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) CountSearch(ctx context.Context, query string) (int64, error) {
+	var count int64
+	err := r.searchScope(ctx, query).Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) searchScope(ctx context.Context, query string) *gorm.DB {
+	searchTerm := "%" + strings.ToLower(query) + "%"
+	return r.db.WithContext(ctx).Where(
+		"(LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(first_name || ' ' || last_name) LIKE ? OR LOWER(email) LIKE ? OR card_number LIKE ?) AND deleted_date IS NULL",
+		searchTerm, searchTerm, searchTerm, searchTerm, "%"+query+"%",
+	)
+}
+
+func (r *UserRepository) GetInactiveSince(ctx context.Context, cutoff time.Time, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := r.inactiveScope(ctx, cutoff).
+		Limit(limit).
+		Offset(offset).
+		Order("last_login_at ASC NULLS FIRST, created_date ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) CountInactiveSince(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	err := r.inactiveScope(ctx, cutoff).Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) inactiveScope(ctx context.Context, cutoff time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).Where(
+		"COALESCE(last_login_at, created_date) < ? AND deleted_date IS NULL",
+		cutoff,
+	)
+}
+
+func (r *UserRepository) GetInactiveMembersSince(ctx context.Context, cutoff time.Time, excludeFlagged bool) ([]models.User, error) {
+	var users []models.User
+	query := r.inactiveScope(ctx, cutoff).Where("role = 'member' AND status = 'active'")
+	if excludeFlagged {
+		query = query.Where("flagged_inactive_at IS NULL")
+	}
+	err := query.Order("created_date ASC").Find(&users).Error
+	return users, err
+}
+
+// GetMemberActivity loads the status, registration date and last login of
+// every member, for engagement reporting.
+func (r *UserRepository) GetMemberActivity(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Select("id, status, last_login_at, created_date").
+		Where("role = 'member' AND deleted_date IS NULL").
+		Order("created_date ASC").
 		Find(&users).Error
 	return users, err
 }
 
-func (r *UserRepository) Update(user *models.User) error {
+func (r *UserRepository) FlagInactive(ctx context.Context, ids []string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id IN ? AND deleted_date IS NULL", ids).
+		UpdateColumn("flagged_inactive_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *UserRepository) DeactivateUsers(ctx context.Context, ids []string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id IN ? AND deleted_date IS NULL", ids).
+		Updates(map[string]any{
+			"status":       "inactive",
+			"version":      gorm.Expr("version + 1"),
+			"updated_date": time.Now().UTC(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		UpdateColumns(map[string]any{
+			"last_login_at":       at,
+			"flagged_inactive_at": nil,
+		}).Error
+}
+
+// Update saves the user if it is still at the version it was loaded at and
+// bumps the version; otherwise it returns ErrVersionConflict. Login tracking
+// columns are maintained by sign-ins and the inactivity job and are not
+// written.
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	user.UpdatedDate = time.Now().UTC()
-	return r.db.Save(user).Error
+	return saveVersioned(r.db.WithContext(ctx), user, user.ID, &user.Version, "last_login_at", "flagged_inactive_at")
 }
 
-func (r *UserRepository) Delete(id string) error {
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	now := time.Now().UTC()
-	return r.db.Model(&models.User{}).
+	return r.db.WithContext(ctx).Model(&models.User{}).
 		Where("id = ? AND deleted_date IS NULL", id).
 		Update("deleted_date", now).Error
 }
 
-func (r *UserRepository) Count() (int64, error) {
+func (r *UserRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("deleted_date IS NULL").Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("deleted_date IS NULL").Count(&count).Error
 	return count, err
 }
 
-func (r *UserRepository) CountByRole(role string) (int64, error) {
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).
+	err := r.db.WithContext(ctx).Model(&models.User{}).
 		Where("role = ? AND deleted_date IS NULL", role).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *UserRepository) EmailExists(email string) (bool, error) {
+func (r *UserRepository) CountByMembershipPlan(ctx context.Context, planID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("membership_plan_id = ? AND deleted_date IS NULL", planID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).
-		Where("email = ? AND deleted_date IS NULL", email).
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("LOWER(email) = LOWER(?) AND deleted_date IS NULL", email).
 		Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
+
+func (r *UserRepository) CardNumberExists(ctx context.Context, cardNumber string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("card_number = ?", cardNumber).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// InBranch returns a view of the repository whose queries only match users
+// whose home branch is branchID. Use it for reads; writes go through the
+// unscoped repository.
+func (r *UserRepository) InBranch(branchID string) UserRepo {
+	return &UserRepository{
+		db: r.db.Where("users.branch_id = ?", branchID).Session(&gorm.Session{}),
+	}
+}
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserMergeCounts is how many records of each kind a merge moves, or would
+// move, from the duplicate account.
+type UserMergeCounts struct {
+	DigitalLoans  int64
+	Reservations  int64
+	EventRSVPs    int64
+	ReadingLists  int64
+	Reviews       int64
+	Suggestions   int64
+	SavedSearches int64
+	Notes         int64
+	Payments      int64
+}
+
+// UserMergeRepository consolidates a duplicate member account onto another.
+// Records that would clash with one the surviving account already has stay
+// with the duplicate: reviews of the same book, RSVPs to the same event,
+// active digital loans of the same book and saved searches with the same
+// name. Notifications, notification preferences and login history are not
+// moved.
+type UserMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewUserMergeRepository(db *gorm.DB) *UserMergeRepository {
+	return &UserMergeRepository{
+		db: db,
+	}
+}
+
+// userMergeTable selects the duplicate's movable rows of one table.
+type userMergeTable struct {
+	count *int64
+	scope func(db *gorm.DB) *gorm.DB
+}
+
+// Preview counts what Merge would move from fromID onto intoID.
+func (r *UserMergeRepository) Preview(ctx context.Context, fromID, intoID string) (*UserMergeCounts, error) {
+	counts := &UserMergeCounts{}
+	db := r.db.WithContext(ctx)
+	for _, table := range userMergeTables(counts, fromID, intoID, time.Now().UTC()) {
+		if err := table.scope(db).Count(table.count).Error; err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// Merge moves fromID's records onto intoID and deactivates fromID in one
+// transaction.
+func (r *UserMergeRepository) Merge(ctx context.Context, fromID, intoID string) (*UserMergeCounts, error) {
+	counts := &UserMergeCounts{}
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range userMergeTables(counts, fromID, intoID, now) {
+			result := table.scope(tx).UpdateColumn("user_id", intoID)
+			if result.Error != nil {
+				return result.Error
+			}
+			*table.count = result.RowsAffected
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND deleted_date IS NULL", fromID).
+			Updates(map[string]any{
+				"status":       "inactive",
+				"version":      gorm.Expr("version + 1"),
+				"updated_date": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func userMergeTables(counts *UserMergeCounts, fromID, intoID string, now time.Time) []userMergeTable {
+	return []userMergeTable{
+		{&counts.DigitalLoans, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.DigitalLoan{}).
+				Where("user_id = ?", fromID).
+				Where("returned_date IS NOT NULL OR due_date <= ? OR book_id NOT IN (?)", now,
+					db.Model(&models.DigitalLoan{}).Select("book_id").
+						Where("user_id = ? AND returned_date IS NULL AND due_date > ? AND deleted_date IS NULL", intoID, now))
+		}},
+		{&counts.Reservations, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Reservation{}).Where("user_id = ?", fromID)
+		}},
+		{&counts.EventRSVPs, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.EventRSVP{}).
+				Where("user_id = ?", fromID).
+				Where("event_id NOT IN (?)", db.Model(&models.EventRSVP{}).Select("event_id").Where("user_id = ?", intoID))
+		}},
+		{&counts.ReadingLists, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.ReadingList{}).Where("user_id = ?", fromID)
+		}},
+		{&counts.Reviews, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Review{}).
+				Where("user_id = ?", fromID).
+				Where("deleted_date IS NOT NULL OR book_id NOT IN (?)",
+					db.Model(&models.Review{}).Select("book_id").Where("user_id = ? AND deleted_date IS NULL", intoID))
+		}},
+		{&counts.Suggestions, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.Suggestion{}).Where("user_id = ?", fromID)
+		}},
+		{&counts.SavedSearches, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.SavedSearch{}).
+				Where("user_id = ?", fromID).
+				Where("deleted_date IS NOT NULL OR name NOT IN (?)",
+					db.Model(&models.SavedSearch{}).Select("name").Where("user_id = ? AND deleted_date IS NULL", intoID))
+		}},
+		{&counts.Notes, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.UserNote{}).Where("user_id = ?", fromID)
+		}},
+		{&counts.Payments, func(db *gorm.DB) *gorm.DB {
+			return db.Model(&models.CashDrawerPayment{}).Where("user_id = ?", fromID)
+		}},
+	}
+}
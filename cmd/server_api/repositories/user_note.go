@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewUserNoteRepository(db *gorm.DB) *UserNoteRepository {
+	return &UserNoteRepository{
+		db: db,
+	}
+}
+
+func (r *UserNoteRepository) Create(note *models.UserNote) error {
+	now := time.Now().UTC()
+	note.CreatedDate = now
+	note.UpdatedDate = now
+	return r.db.Create(note).Error
+}
+
+func (r *UserNoteRepository) GetByID(id string) (*models.UserNote, error) {
+	var note models.UserNote
+	err := r.db.Where("id = ? AND deleted_date IS NULL", id).First(&note).Error
+	if err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *UserNoteRepository) GetByUserID(userID string) ([]models.UserNote, error) {
+	var notes []models.UserNote
+	err := r.db.Where("user_id = ? AND deleted_date IS NULL", userID).
+		Order("created_date DESC").
+		Find(&notes).Error
+	return notes, err
+}
+
+func (r *UserNoteRepository) Delete(id string) error {
+	now := time.Now().UTC()
+	return r.db.Model(&models.UserNote{}).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Update("deleted_date", now).Error
+}
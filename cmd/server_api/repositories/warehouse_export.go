@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"book-management-system/cmd/server_api/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WarehouseExportRepository reads tables in batches for the warehouse export,
+// so a full dump never holds a whole table in memory.
+type WarehouseExportRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseExportRepository(db *gorm.DB) *WarehouseExportRepository {
+	return &WarehouseExportRepository{
+		db: db,
+	}
+}
+
+// EachBook calls fn for every batch of books, soft-deleted ones included so
+// the warehouse can see removals.
+func (r *WarehouseExportRepository) EachBook(ctx context.Context, batchSize int, fn func([]models.Book) error) error {
+	var books []models.Book
+	return r.db.WithContext(ctx).
+		Order("id").
+		FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(books)
+		}).Error
+}
+
+// EachOutboxEvent calls fn for every batch of outbox events created in
+// [from, to).
+func (r *WarehouseExportRepository) EachOutboxEvent(ctx context.Context, from, to time.Time, batchSize int, fn func([]models.OutboxEvent) error) error {
+	var events []models.OutboxEvent
+	return r.db.WithContext(ctx).
+		Where("created_date >= ? AND created_date < ? AND deleted_date IS NULL", from, to).
+		Order("id").
+		FindInBatches(&events, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(events)
+		}).Error
+}
+
+// EachLoginEvent calls fn for every batch of login events created in
+// [from, to).
+func (r *WarehouseExportRepository) EachLoginEvent(ctx context.Context, from, to time.Time, batchSize int, fn func([]models.LoginEvent) error) error {
+	var events []models.LoginEvent
+	return r.db.WithContext(ctx).
+		Where("created_date >= ? AND created_date < ? AND deleted_date IS NULL", from, to).
+		Order("id").
+		FindInBatches(&events, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(events)
+		}).Error
+}
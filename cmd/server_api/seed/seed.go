@@ -0,0 +1,160 @@
+package seed
+
+import (
+	"book-management-system/cmd/server_api/librarycard"
+	"book-management-system/cmd/server_api/models"
+	"book-management-system/cmd/server_api/repositories"
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Demo credentials are fixed so local and demo environments can log in
+// straight after seeding. Never seed a production database.
+const (
+	AdminPassword  = "admin12345"
+	MemberPassword = "member12345"
+)
+
+const bookBatchSize = 100
+
+type seedUser struct {
+	Email     string
+	FirstName string
+	LastName  string
+	Role      string
+	PlanCode  string
+	Password  string
+}
+
+type seedBook struct {
+	Title           string
+	Author          string
+	ISBN            string
+	Publisher       string
+	PublicationYear int
+	Genre           string
+	Pages           int
+	Quantity        int
+	Location        string
+}
+
+var users = []seedUser{
+	{Email: "admin@bookms.local", FirstName: "Ada", LastName: "Admin", Role: "admin", PlanCode: "premium", Password: AdminPassword},
+	{Email: "alice@bookms.local", FirstName: "Alice", LastName: "Reader", Role: "member", PlanCode: "basic", Password: MemberPassword},
+	{Email: "bob@bookms.local", FirstName: "Bob", LastName: "Borrower", Role: "member", PlanCode: "premium", Password: MemberPassword},
+	{Email: "carol@bookms.local", FirstName: "Carol", LastName: "Student", Role: "member", PlanCode: "student", Password: MemberPassword},
+}
+
+var books = []seedBook{
+	{Title: "The Go Programming Language", Author: "Alan A. A. Donovan", ISBN: "9780134190440", Publisher: "Addison-Wesley", PublicationYear: 2015, Genre: "Technology", Pages: 380, Quantity: 3, Location: "A1-01"},
+	{Title: "Designing Data-Intensive Applications", Author: "Martin Kleppmann", ISBN: "9781449373320", Publisher: "O'Reilly Media", PublicationYear: 2017, Genre: "Technology", Pages: 616, Quantity: 2, Location: "A1-02"},
+	{Title: "Pride and Prejudice", Author: "Jane Austen", ISBN: "9780141439518", Publisher: "Penguin Classics", PublicationYear: 1813, Genre: "Fiction", Pages: 480, Quantity: 4, Location: "B2-01"},
+	{Title: "Nineteen Eighty-Four", Author: "George Orwell", ISBN: "9780451524935", Publisher: "Signet Classics", PublicationYear: 1949, Genre: "Fiction", Pages: 328, Quantity: 5, Location: "B2-02"},
+	{Title: "A Brief History of Time", Author: "Stephen Hawking", ISBN: "9780553380163", Publisher: "Bantam", PublicationYear: 1988, Genre: "Science", Pages: 212, Quantity: 2, Location: "C3-01"},
+	{Title: "Sapiens", Author: "Yuval Noah Harari", ISBN: "9780062316097", Publisher: "Harper", PublicationYear: 2015, Genre: "History", Pages: 464, Quantity: 3, Location: "C3-02"},
+}
+
+type Report struct {
+	UsersCreated int
+	UsersSkipped int
+	BooksCreated int
+	BooksSkipped int
+}
+
+type Seeder struct {
+	userRepo *repositories.UserRepository
+	planRepo *repositories.MembershipPlanRepository
+	bookRepo *repositories.BookRepository
+}
+
+func NewSeeder(userRepo *repositories.UserRepository, planRepo *repositories.MembershipPlanRepository, bookRepo *repositories.BookRepository) *Seeder {
+	return &Seeder{
+		userRepo: userRepo,
+		planRepo: planRepo,
+		bookRepo: bookRepo,
+	}
+}
+
+// Run creates any seed users and books that are missing, matching users by
+// email and books by ISBN, so it is safe to run repeatedly.
+func (s *Seeder) Run(ctx context.Context) (*Report, error) {
+	report := &Report{}
+	for _, u := range users {
+		created, err := s.seedUser(ctx, u)
+		if err != nil {
+			return report, err
+		}
+		if created {
+			report.UsersCreated++
+		} else {
+			report.UsersSkipped++
+		}
+	}
+	batch := make([]models.Book, 0, len(books))
+	for _, b := range books {
+		batch = append(batch, newBook(b))
+	}
+	created, err := s.bookRepo.CreateBatch(ctx, batch, bookBatchSize)
+	if err != nil {
+		return report, err
+	}
+	report.BooksCreated = int(created)
+	report.BooksSkipped = len(books) - int(created)
+	return report, nil
+}
+
+func (s *Seeder) seedUser(ctx context.Context, u seedUser) (bool, error) {
+	exists, err := s.userRepo.EmailExists(ctx, u.Email)
+	if err != nil || exists {
+		return false, err
+	}
+	plan, err := s.planRepo.GetByCode(u.PlanCode)
+	if err != nil {
+		return false, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, err
+	}
+	cardNumber, err := librarycard.Issue(ctx, s.userRepo.CardNumberExists)
+	if err != nil {
+		return false, err
+	}
+	user := &models.User{
+		ID:                    uuid.New().String(),
+		Email:                 u.Email,
+		PasswordHash:          string(hashedPassword),
+		FirstName:             u.FirstName,
+		LastName:              u.LastName,
+		Role:                  u.Role,
+		Status:                "active",
+		MembershipPlanID:      plan.ID,
+		CardNumber:            cardNumber,
+		ReadingHistoryEnabled: true,
+	}
+	return true, s.userRepo.Create(ctx, user)
+}
+
+// newBook builds the seed book. Books whose ISBN already exists are skipped
+// by CreateBatch.
+func newBook(b seedBook) models.Book {
+	return models.Book{
+		ID:                uuid.New().String(),
+		Title:             b.Title,
+		Author:            b.Author,
+		ISBN:              &b.ISBN,
+		Publisher:         &b.Publisher,
+		PublicationYear:   &b.PublicationYear,
+		Genre:             &b.Genre,
+		Pages:             &b.Pages,
+		Language:          "English",
+		Quantity:          b.Quantity,
+		AvailableQuantity: b.Quantity,
+		Location:          &b.Location,
+		Status:            models.BookStatusActive,
+		DigitalLoanLimit:  models.DefaultDigitalLoanLimit,
+		LoanPolicy:        models.BookLoanPolicyStandard,
+	}
+}
@@ -1,50 +0,0 @@
--- Book Management System Database Schema
--- PostgreSQL initialization script
-
--- Create users table
-CREATE TABLE users (
-    id VARCHAR(100) PRIMARY KEY,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password_hash VARCHAR(255) NOT NULL,
-    first_name VARCHAR(100) NOT NULL,
-    last_name VARCHAR(100) NOT NULL,
-    role VARCHAR(20) NOT NULL,
-    status VARCHAR(20) NOT NULL,
-    created_date timestamptz NOT NULL,
-    updated_date timestamptz NOT NULL,
-    deleted_date timestamptz
-);
-
--- Create indexes for users table
-CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_users_role ON users(role);
-CREATE INDEX idx_users_status ON users(status);
-
--- Create books table
-CREATE TABLE books (
-    id VARCHAR(100) PRIMARY KEY,
-    title VARCHAR(255) NOT NULL,
-    author VARCHAR(255) NOT NULL,
-    isbn VARCHAR(20) UNIQUE,
-    publisher VARCHAR(255),
-    publication_year INTEGER,
-    genre VARCHAR(100),
-    description TEXT,
-    pages INTEGER,
-    language VARCHAR(50) NOT NULL,
-    price DECIMAL(10,2),
-    quantity INTEGER NOT NULL,
-    available_quantity INTEGER NOT NULL,
-    location VARCHAR(100),
-    status VARCHAR(20) NOT NULL,
-    created_date timestamptz NOT NULL,
-    updated_date timestamptz NOT NULL,
-    deleted_date timestamptz
-);
-
--- Create indexes for books table
-CREATE INDEX idx_books_title ON books(title);
-CREATE INDEX idx_books_author ON books(author);
-CREATE UNIQUE INDEX idx_books_isbn ON books(isbn) WHERE isbn IS NOT NULL;
-CREATE INDEX idx_books_genre ON books(genre);
-CREATE INDEX idx_books_status ON books(status);
\ No newline at end of file
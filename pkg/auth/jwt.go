@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,98 +14,228 @@ type User interface {
 	GetRole() string
 }
 
+// ErrDeviceToken is returned when a device token is presented where a user
+// token is required. Device tokens are only accepted by RequireDevice.
+var ErrDeviceToken = errors.New("device token not accepted here")
+
+// ErrNotDeviceToken is returned when a user token is presented where a
+// device token is required.
+var ErrNotDeviceToken = errors.New("not a device token")
+
+// Claims are the claims of an access token. Device tokens carry DeviceID and
+// a space-separated Scope instead of a user.
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	DeviceID string `json:"device_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasScope reports whether a device token was issued for scope.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 type JWT struct {
 	secret             string
 	expiryHours        int
 	refreshExpiryHours int
+	issuer             string
+	audience           string
+}
+
+// Option configures a JWT.
+type Option func(*JWT)
+
+// WithIssuer sets the iss claim of issued tokens and rejects tokens from any
+// other issuer.
+func WithIssuer(issuer string) Option {
+	return func(j *JWT) {
+		j.issuer = issuer
+	}
 }
 
+// WithAudience sets the aud claim of issued tokens and rejects tokens not
+// meant for that audience.
+func WithAudience(audience string) Option {
+	return func(j *JWT) {
+		j.audience = audience
+	}
+}
+
+// TokenType is the OAuth 2.0 token type of the access tokens JWT issues.
+const TokenType = "Bearer"
+
+// TokenPair carries the expiry written into each token, so clients can
+// schedule a refresh before the access token runs out.
 type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
-func NewJWT(secret string, expiryHours, refreshExpiryHours int) *JWT {
-	return &JWT{
+func NewJWT(secret string, expiryHours, refreshExpiryHours int, opts ...Option) *JWT {
+	j := &JWT{
 		secret:             secret,
 		expiryHours:        expiryHours,
 		refreshExpiryHours: refreshExpiryHours,
 	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
 }
 
 func (j *JWT) GenerateTokenPair(user User) (*TokenPair, error) {
-	accessToken, err := j.GenerateAccessToken(user)
+	now := time.Now()
+	accessExpiry := jwt.NewNumericDate(now.Add(time.Hour * time.Duration(j.expiryHours)))
+	refreshExpiry := jwt.NewNumericDate(now.Add(time.Hour * time.Duration(j.refreshExpiryHours)))
+	accessToken, err := j.signAccessToken(user, now, accessExpiry)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := j.GenerateRefreshToken(user)
+	refreshToken, err := j.signRefreshToken(user, now, refreshExpiry)
 	if err != nil {
 		return nil, err
 	}
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        TokenType,
+		ExpiresAt:        accessExpiry.Time,
+		RefreshExpiresAt: refreshExpiry.Time,
 	}, nil
 }
 
 func (j *JWT) GenerateAccessToken(user User) (string, error) {
+	now := time.Now()
+	return j.signAccessToken(user, now, jwt.NewNumericDate(now.Add(time.Hour*time.Duration(j.expiryHours))))
+}
+
+func (j *JWT) GenerateRefreshToken(user User) (string, error) {
+	now := time.Now()
+	return j.signRefreshToken(user, now, jwt.NewNumericDate(now.Add(time.Hour*time.Duration(j.refreshExpiryHours))))
+}
+
+func (j *JWT) signAccessToken(user User, now time.Time, expiresAt *jwt.NumericDate) (string, error) {
 	claims := &Claims{
-		UserID: user.GetID(),
-		Email:  user.GetEmail(),
-		Role:   user.GetRole(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(j.expiryHours))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Subject:   user.GetID(),
-		},
+		UserID:           user.GetID(),
+		Email:            user.GetEmail(),
+		Role:             user.GetRole(),
+		RegisteredClaims: j.registeredClaims(user.GetID(), now, expiresAt),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secret))
 }
 
-func (j *JWT) GenerateRefreshToken(user User) (string, error) {
-	claims := &jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(j.refreshExpiryHours))),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		NotBefore: jwt.NewNumericDate(time.Now()),
-		Subject:   user.GetID(),
+func (j *JWT) signRefreshToken(user User, now time.Time, expiresAt *jwt.NumericDate) (string, error) {
+	claims := j.registeredClaims(user.GetID(), now, expiresAt)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateDeviceToken signs a long-lived token for an unattended device,
+// limited to scopes. It names no user, so it cannot be refreshed or used on
+// routes behind RequireAuth.
+func (j *JWT) GenerateDeviceToken(deviceID string, scopes []string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		DeviceID:         deviceID,
+		Scope:            strings.Join(scopes, " "),
+		RegisteredClaims: j.registeredClaims("device:"+deviceID, now, jwt.NewNumericDate(expiresAt)),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secret))
 }
 
+func (j *JWT) registeredClaims(subject string, now time.Time, expiresAt *jwt.NumericDate) jwt.RegisteredClaims {
+	claims := jwt.RegisteredClaims{
+		Issuer:    j.issuer,
+		ExpiresAt: expiresAt,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Subject:   subject,
+	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
+	}
+	return claims
+}
+
+// parserOptions pin the signing method, so a token whose header names
+// another algorithm, such as none or RS256 with the secret as its public
+// key, is rejected before its signature is checked.
+func (j *JWT) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if j.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		opts = append(opts, jwt.WithAudience(j.audience))
+	}
+	return opts
+}
+
+func (j *JWT) keyFunc(token *jwt.Token) (any, error) {
+	return []byte(j.secret), nil
+}
+
+// ValidateToken validates a user's access token. Device tokens are rejected
+// with ErrDeviceToken.
 func (j *JWT) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		return []byte(j.secret), nil
-	})
+	claims, err := j.parse(tokenString)
 	if err != nil {
 		return nil, err
 	}
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, jwt.ErrInvalidKey
+	if claims.DeviceID != "" {
+		return nil, ErrDeviceToken
+	}
+	return claims, nil
+}
+
+// ValidateDeviceToken validates a token issued by GenerateDeviceToken.
+func (j *JWT) ValidateDeviceToken(tokenString string) (*Claims, error) {
+	claims, err := j.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.DeviceID == "" {
+		return nil, ErrNotDeviceToken
 	}
 	return claims, nil
 }
 
 func (j *JWT) ValidateRefreshToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
-		return []byte(j.secret), nil
-	})
+	claims, err := j.parse(tokenString)
 	if err != nil {
 		return "", err
 	}
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok || !token.Valid {
-		return "", jwt.ErrInvalidKey
+	if claims.DeviceID != "" {
+		return "", ErrDeviceToken
 	}
 	return claims.Subject, nil
+}
+
+func (j *JWT) parse(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keyFunc, j.parserOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrInvalidKey
+	}
+	return claims, nil
 }
\ No newline at end of file
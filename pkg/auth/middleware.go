@@ -1,24 +1,80 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
 const (
-	UserContextKey = "user"
+	UserContextKey   = "user"
+	DeviceContextKey = "device"
 )
 
+// Error codes the middleware rejects requests with. They match the API's
+// error_code values.
+const (
+	ErrCodeAuthenticationRequired  = "AUTHENTICATION_REQUIRED"
+	ErrCodeInvalidToken            = "INVALID_TOKEN"
+	ErrCodeTokenExpired            = "TOKEN_EXPIRED"
+	ErrCodeInsufficientPermissions = "INSUFFICIENT_PERMISSIONS"
+	ErrCodeDeviceRevoked           = "DEVICE_REVOKED"
+)
+
+// Error is why the middleware rejected a request. It comes wrapped in the
+// *echo.HTTPError the middleware returns, so the server's HTTP error handler
+// renders the body and the request logger still sees the status.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func reject(status int, code, message string) error {
+	return &echo.HTTPError{
+		Code:    status,
+		Message: message,
+		Internal: &Error{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// DeviceCheck reports whether a device's tokens are still accepted, so a
+// device can be revoked before its token expires.
+type DeviceCheck func(c echo.Context, deviceID string) (bool, error)
+
+// MiddlewareOption configures a Middleware.
+type MiddlewareOption func(*Middleware)
+
+// WithDeviceCheck makes RequireDevice reject tokens of devices check turns
+// down. Without it every unexpired device token is accepted.
+func WithDeviceCheck(check DeviceCheck) MiddlewareOption {
+	return func(m *Middleware) {
+		m.deviceCheck = check
+	}
+}
+
 type Middleware struct {
-	jwt *JWT
+	jwt         *JWT
+	deviceCheck DeviceCheck
 }
 
-func NewMiddleware(jwt *JWT) *Middleware {
-	return &Middleware{
+func NewMiddleware(jwt *JWT, opts ...MiddlewareOption) *Middleware {
+	m := &Middleware{
 		jwt: jwt,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 func (m *Middleware) RequireAuth() echo.MiddlewareFunc {
@@ -26,15 +82,14 @@ func (m *Middleware) RequireAuth() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			token := m.extractToken(c)
 			if token == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"message": "Authorization header is required",
-				})
+				return reject(http.StatusUnauthorized, ErrCodeAuthenticationRequired, "Authorization header is required")
 			}
 			claims, err := m.jwt.ValidateToken(token)
+			if errors.Is(err, ErrDeviceToken) {
+				return reject(http.StatusForbidden, ErrCodeInsufficientPermissions, "Device tokens cannot be used here")
+			}
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"message": "Invalid or expired token",
-				})
+				return rejectToken(err)
 			}
 			c.Set(UserContextKey, claims)
 			return next(c)
@@ -42,19 +97,57 @@ func (m *Middleware) RequireAuth() echo.MiddlewareFunc {
 	}
 }
 
+// RequireDevice accepts only device tokens issued for scope, for the routes
+// unattended kiosks use.
+func (m *Middleware) RequireDevice(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := m.extractToken(c)
+			if token == "" {
+				return reject(http.StatusUnauthorized, ErrCodeAuthenticationRequired, "Authorization header is required")
+			}
+			claims, err := m.jwt.ValidateDeviceToken(token)
+			if errors.Is(err, ErrNotDeviceToken) {
+				return reject(http.StatusForbidden, ErrCodeInsufficientPermissions, "Only device tokens can be used here")
+			}
+			if err != nil {
+				return rejectToken(err)
+			}
+			if !claims.HasScope(scope) {
+				return reject(http.StatusForbidden, ErrCodeInsufficientPermissions, "Device token lacks the required scope")
+			}
+			if m.deviceCheck != nil {
+				ok, err := m.deviceCheck(c, claims.DeviceID)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return reject(http.StatusUnauthorized, ErrCodeDeviceRevoked, "Device has been revoked")
+				}
+			}
+			c.Set(DeviceContextKey, claims)
+			return next(c)
+		}
+	}
+}
+
+func rejectToken(err error) error {
+	errorCode := ErrCodeInvalidToken
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		errorCode = ErrCodeTokenExpired
+	}
+	return reject(http.StatusUnauthorized, errorCode, "Invalid or expired token")
+}
+
 func (m *Middleware) RequireRole(role string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			user := m.GetUserFromContext(c)
 			if user == nil {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"message": "Authentication required",
-				})
+				return reject(http.StatusUnauthorized, ErrCodeAuthenticationRequired, "Authentication required")
 			}
 			if user.Role != role {
-				return c.JSON(http.StatusForbidden, map[string]string{
-					"message": "Insufficient permissions",
-				})
+				return reject(http.StatusForbidden, ErrCodeInsufficientPermissions, "Insufficient permissions")
 			}
 			return next(c)
 		}
@@ -65,6 +158,20 @@ func (m *Middleware) RequireAdmin() echo.MiddlewareFunc {
 	return m.RequireRole("admin")
 }
 
+// UserID returns the user ID from a valid bearer token without rejecting the
+// request, for middleware that only needs to tell users apart.
+func (m *Middleware) UserID(c echo.Context) string {
+	token := m.extractToken(c)
+	if token == "" {
+		return ""
+	}
+	claims, err := m.jwt.ValidateToken(token)
+	if err != nil {
+		return ""
+	}
+	return claims.UserID
+}
+
 func (m *Middleware) extractToken(c echo.Context) string {
 	authHeader := c.Request().Header.Get("Authorization")
 	if authHeader == "" {
@@ -83,4 +190,13 @@ func (m *Middleware) GetUserFromContext(c echo.Context) *Claims {
 		return nil
 	}
 	return user
+}
+
+// GetDeviceFromContext returns the claims RequireDevice accepted, or nil.
+func (m *Middleware) GetDeviceFromContext(c echo.Context) *Claims {
+	device, ok := c.Get(DeviceContextKey).(*Claims)
+	if !ok {
+		return nil
+	}
+	return device
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+package bodylimit
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware rejects requests whose declared Content-Length exceeds maxBytes
+// and caps the body reader for chunked uploads, where reads past the limit
+// fail with *http.MaxBytesError.
+func Middleware(maxBytes int64) echo.MiddlewareFunc {
+	return MiddlewareWithSkipper(maxBytes, nil)
+}
+
+// MiddlewareWithSkipper is Middleware but leaves the requests skipper returns
+// true for alone, so routes taking larger uploads can apply their own limit.
+// A nil skipper skips nothing.
+func MiddlewareWithSkipper(maxBytes int64, skipper func(echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper != nil && skipper(c) {
+				return next(c)
+			}
+			req := c.Request()
+			if req.ContentLength > maxBytes {
+				return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+					"message":    "Request body too large",
+					"error_code": "PAYLOAD_TOO_LARGE",
+				})
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBytes)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package bookmeta looks books up by ISBN in public catalogs, Google Books
+// and Open Library, for the metadata a library's own records are missing.
+package bookmeta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	SourceGoogleBooks = "google_books"
+	SourceOpenLibrary = "open_library"
+
+	lookupTimeout = 10 * time.Second
+	// maxResponseBytes bounds how much of a catalog response is read.
+	maxResponseBytes = 1 << 20
+)
+
+// ErrNotFound is returned when the catalog has no book with the ISBN.
+var ErrNotFound = errors.New("book not found")
+
+// Metadata is what a catalog knows about a book. Zero fields are unknown.
+type Metadata struct {
+	Description string
+	Pages       int
+	CoverURL    string
+}
+
+// Provider is implemented by GoogleBooks and OpenLibrary.
+type Provider interface {
+	// Source names the catalog, e.g. SourceGoogleBooks.
+	Source() string
+	Lookup(ctx context.Context, isbn string) (*Metadata, error)
+}
+
+// getJSON decodes the JSON response to a GET of rawURL into v. A 404 is
+// ErrNotFound.
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL can carry an API key, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("catalog responded %s", resp.Status)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// httpsURL upgrades http image links, which catalogs still return, so they
+// don't cause mixed content on https pages.
+func httpsURL(rawURL string) string {
+	if strings.HasPrefix(rawURL, "http://") {
+		return "https://" + strings.TrimPrefix(rawURL, "http://")
+	}
+	return rawURL
+}
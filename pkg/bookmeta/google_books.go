@@ -0,0 +1,67 @@
+package bookmeta
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const googleBooksEndpoint = "https://www.googleapis.com/books/v1/volumes"
+
+// GoogleBooks looks books up in the Google Books API. Without an API key
+// requests share Google's anonymous quota.
+type GoogleBooks struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+type googleVolumes struct {
+	Items []struct {
+		VolumeInfo struct {
+			Description string `json:"description"`
+			PageCount   int    `json:"pageCount"`
+			ImageLinks  struct {
+				Thumbnail string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func NewGoogleBooks(apiKey string) *GoogleBooks {
+	return &GoogleBooks{
+		endpoint: googleBooksEndpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{},
+	}
+}
+
+func (g *GoogleBooks) Source() string {
+	return SourceGoogleBooks
+}
+
+func (g *GoogleBooks) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	query := url.Values{}
+	query.Set("q", "isbn:"+isbn)
+	if g.apiKey != "" {
+		query.Set("key", g.apiKey)
+	}
+	var volumes googleVolumes
+	if err := getJSON(ctx, g.client, g.endpoint+"?"+query.Encode(), &volumes); err != nil {
+		return nil, err
+	}
+	if len(volumes.Items) == 0 {
+		return nil, ErrNotFound
+	}
+	info := volumes.Items[0].VolumeInfo
+	metadata := &Metadata{
+		Description: strings.TrimSpace(info.Description),
+		Pages:       info.PageCount,
+	}
+	if info.ImageLinks.Thumbnail != "" {
+		// The curled page edge is a decoration Google adds to thumbnails.
+		metadata.CoverURL = httpsURL(strings.ReplaceAll(info.ImageLinks.Thumbnail, "&edge=curl", ""))
+	}
+	return metadata, nil
+}
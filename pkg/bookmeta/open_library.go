@@ -0,0 +1,107 @@
+package bookmeta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	openLibraryEndpoint = "https://openlibrary.org"
+	openLibraryCovers   = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+)
+
+// OpenLibrary looks books up in the Open Library API, which needs no key.
+type OpenLibrary struct {
+	endpoint string
+	client   *http.Client
+}
+
+type openLibraryEdition struct {
+	NumberOfPages int                    `json:"number_of_pages"`
+	Covers        []int                  `json:"covers"`
+	Description   openLibraryDescription `json:"description"`
+	Works         []struct {
+		Key string `json:"key"`
+	} `json:"works"`
+}
+
+type openLibraryWork struct {
+	Covers      []int                  `json:"covers"`
+	Description openLibraryDescription `json:"description"`
+}
+
+// openLibraryDescription is either a plain string or a typed text object,
+// {"type": "/type/text", "value": "..."}.
+type openLibraryDescription string
+
+func (d *openLibraryDescription) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*d = openLibraryDescription(text)
+		return nil
+	}
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+	*d = openLibraryDescription(typed.Value)
+	return nil
+}
+
+func NewOpenLibrary() *OpenLibrary {
+	return &OpenLibrary{
+		endpoint: openLibraryEndpoint,
+		client:   &http.Client{},
+	}
+}
+
+func (o *OpenLibrary) Source() string {
+	return SourceOpenLibrary
+}
+
+// Lookup reads the edition, falling back to its work for the description
+// and cover, which Open Library often records only once per work.
+func (o *OpenLibrary) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	var edition openLibraryEdition
+	err := getJSON(ctx, o.client, o.endpoint+"/isbn/"+url.PathEscape(isbn)+".json", &edition)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &Metadata{
+		Description: strings.TrimSpace(string(edition.Description)),
+		Pages:       edition.NumberOfPages,
+		CoverURL:    openLibraryCover(edition.Covers),
+	}
+	if (metadata.Description == "" || metadata.CoverURL == "") && len(edition.Works) > 0 {
+		var work openLibraryWork
+		err := getJSON(ctx, o.client, o.endpoint+edition.Works[0].Key+".json", &work)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if metadata.Description == "" {
+			metadata.Description = strings.TrimSpace(string(work.Description))
+		}
+		if metadata.CoverURL == "" {
+			metadata.CoverURL = openLibraryCover(work.Covers)
+		}
+	}
+	return metadata, nil
+}
+
+// openLibraryCover links the first real cover. Open Library uses -1 for a
+// removed cover.
+func openLibraryCover(ids []int) string {
+	for _, id := range ids {
+		if id > 0 {
+			return fmt.Sprintf(openLibraryCovers, id)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Breaker opens after threshold consecutive failures and rejects calls for the
+// cooldown. It then goes half-open and lets a single probe through, rejecting
+// everything else until the probe reports: its success closes the breaker,
+// its failure opens it again.
+type Breaker struct {
+	mu            sync.Mutex
+	threshold     int
+	cooldown      time.Duration
+	state         State
+	failures      int
+	openedAt      time.Time
+	probing       bool
+	probeSeq      uint64
+	opensTotal    int64
+	rejectedTotal int64
+}
+
+// probeKey marks the context of a request admitted as a breaker's probe.
+type probeKey struct {
+	breaker *Breaker
+}
+
+type Snapshot struct {
+	State               State      `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAfterSeconds   int        `json:"retry_after_seconds,omitempty"`
+	OpensTotal          int64      `json:"opens_total"`
+	RejectedTotal       int64      `json:"rejected_total"`
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+	}
+}
+
+// Allow returns ErrOpen while the breaker is open and the cooldown has not
+// elapsed, and while it is half-open with a probe in flight. Otherwise, when
+// half-open, the caller becomes the probe and gets a release func, which it
+// must call once done in case it reported neither Success nor Failure, so a
+// probe that never reaches the dependency does not hold the breaker half-open.
+// Calls made with a probe's context, as marked by Middleware, are part of
+// that probe and are let through.
+func (b *Breaker) Allow(ctx context.Context) (release func(), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			b.rejectedTotal++
+			return nil, ErrOpen
+		}
+		b.state = StateHalfOpen
+	case StateHalfOpen:
+		if ctx.Value(probeKey{b}) != nil {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	if b.probing {
+		b.rejectedTotal++
+		return nil, ErrOpen
+	}
+	b.probing = true
+	b.probeSeq++
+	seq := b.probeSeq
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.probing && b.probeSeq == seq {
+			b.probing = false
+		}
+	}, nil
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	if b.state == StateHalfOpen {
+		b.state = StateClosed
+	}
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.opensTotal++
+	}
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	snapshot := Snapshot{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		OpensTotal:          b.opensTotal,
+		RejectedTotal:       b.rejectedTotal,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt.UTC()
+		snapshot.OpenedAt = &openedAt
+	}
+	if b.state == StateOpen {
+		if remaining := b.cooldown - time.Since(b.openedAt); remaining > 0 {
+			snapshot.RetryAfterSeconds = int(math.Ceil(remaining.Seconds()))
+		}
+	}
+	return snapshot
+}
+
+// Middleware answers 503 with Retry-After while the breaker is open, so
+// requests fail fast instead of waiting on a dependency that is down. A
+// request admitted as the probe carries it in its context, so its own calls
+// through the breaker are not turned away as a second probe.
+func (b *Breaker) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			release, err := b.Allow(c.Request().Context())
+			if err != nil {
+				retryAfter := b.Snapshot().RetryAfterSeconds
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				// The server's error handler writes the response envelope.
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "Service temporarily unavailable, please retry later")
+			}
+			if release != nil {
+				defer release()
+				ctx := context.WithValue(c.Request().Context(), probeKey{b}, true)
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	}
+}
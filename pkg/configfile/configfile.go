@@ -0,0 +1,69 @@
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads a flat YAML or TOML file whose keys are the environment variable
+// names without the prefix (db_host, server_port, ...) and exports each value
+// as PREFIX_KEY unless that variable is already set. Running envconfig
+// afterwards therefore resolves environment, then file, then defaults.
+func Load(path, prefix string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unsupported config file type %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	for key, value := range values {
+		name := prefix + "_" + strings.ToUpper(key)
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		str, err := stringify(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if err := os.Setenv(name, str); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringify(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			str, err := stringify(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = str
+		}
+		return strings.Join(parts, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("nested tables are not supported")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,183 @@
+// Package cql parses the subset of the Contextual Query Language (CQL 1.2)
+// that SRU clients send for simple catalog searches: search clauses joined by
+// AND. OR, NOT, PROX, parentheses, modifiers, prefix assignments and sortBy
+// are reported as unsupported rather than misread.
+package cql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ServerChoice is the index of a clause given as a bare term.
+const ServerChoice = "cql.serverchoice"
+
+var (
+	// ErrSyntax is returned for queries that are not valid CQL.
+	ErrSyntax = errors.New("cql: syntax error")
+	// ErrUnsupported is returned for valid CQL outside the supported subset.
+	ErrUnsupported = errors.New("cql: unsupported query feature")
+)
+
+// Clause is one search clause. Index and Relation are lower case; a bare
+// term has index ServerChoice and relation "=".
+type Clause struct {
+	Index    string
+	Relation string
+	Term     string
+}
+
+type token struct {
+	text   string
+	quoted bool
+	symbol bool
+}
+
+var namedRelations = map[string]bool{
+	"adj":      true,
+	"all":      true,
+	"any":      true,
+	"encloses": true,
+	"exact":    true,
+	"within":   true,
+}
+
+// Parse splits query into the clauses it ANDs together.
+func Parse(query string) ([]Clause, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty query", ErrSyntax)
+	}
+	var clauses []Clause
+	i := 0
+	for {
+		clause, next, err := parseClause(tokens, i)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+		if next == len(tokens) {
+			return clauses, nil
+		}
+		tok := tokens[next]
+		word := strings.ToLower(tok.text)
+		switch {
+		case tok.quoted || tok.symbol:
+			if tok.text == "/" {
+				return nil, fmt.Errorf("%w: modifiers", ErrUnsupported)
+			}
+			return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, tok.text)
+		case word == "and":
+			i = next + 1
+			if i == len(tokens) {
+				return nil, fmt.Errorf("%w: query ends after AND", ErrSyntax)
+			}
+		case word == "or" || word == "not" || word == "prox":
+			return nil, fmt.Errorf("%w: boolean operator %s", ErrUnsupported, strings.ToUpper(word))
+		case word == "sortby":
+			return nil, fmt.Errorf("%w: sortBy", ErrUnsupported)
+		default:
+			return nil, fmt.Errorf("%w: expected boolean operator, found %q", ErrSyntax, tok.text)
+		}
+	}
+}
+
+func parseClause(tokens []token, i int) (Clause, int, error) {
+	tok := tokens[i]
+	if tok.symbol {
+		switch tok.text {
+		case "(":
+			return Clause{}, 0, fmt.Errorf("%w: parentheses", ErrUnsupported)
+		case ">":
+			return Clause{}, 0, fmt.Errorf("%w: prefix assignment", ErrUnsupported)
+		}
+		return Clause{}, 0, fmt.Errorf("%w: unexpected %q", ErrSyntax, tok.text)
+	}
+	if tok.quoted || i+1 == len(tokens) || !isRelation(tokens[i+1]) {
+		return Clause{
+			Index:    ServerChoice,
+			Relation: "=",
+			Term:     tok.text,
+		}, i + 1, nil
+	}
+	relation := strings.ToLower(tokens[i+1].text)
+	if i+2 == len(tokens) {
+		return Clause{}, 0, fmt.Errorf("%w: missing term after %s", ErrSyntax, relation)
+	}
+	term := tokens[i+2]
+	if term.symbol {
+		if term.text == "/" {
+			return Clause{}, 0, fmt.Errorf("%w: modifiers", ErrUnsupported)
+		}
+		return Clause{}, 0, fmt.Errorf("%w: unexpected %q", ErrSyntax, term.text)
+	}
+	return Clause{
+		Index:    strings.ToLower(tok.text),
+		Relation: relation,
+		Term:     term.text,
+	}, i + 3, nil
+}
+
+func isRelation(tok token) bool {
+	if tok.quoted {
+		return false
+	}
+	if tok.symbol {
+		switch tok.text {
+		case "=", "==", "<>", "<", ">", "<=", ">=":
+			return true
+		}
+		return false
+	}
+	return namedRelations[strings.ToLower(tok.text)]
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+		case r == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quoted term", ErrSyntax)
+			}
+			i++
+			tokens = append(tokens, token{text: b.String(), quoted: true})
+		case r == '(' || r == ')' || r == '/':
+			tokens = append(tokens, token{text: string(r), symbol: true})
+			i++
+		case r == '=' || r == '<' || r == '>':
+			text := string(r)
+			if i+1 < len(runes) {
+				switch text + string(runes[i+1]) {
+				case "==", "<>", "<=", ">=":
+					text += string(runes[i+1])
+				}
+			}
+			tokens = append(tokens, token{text: text, symbol: true})
+			i += len(text)
+		default:
+			start := i
+			for i < len(runes) && !strings.ContainsRune(" \t\n\r\"()/=<>", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i])})
+		}
+	}
+	return tokens, nil
+}
@@ -0,0 +1,133 @@
+// Package goodreads reads the library export Goodreads offers under My Books
+// > Import and export.
+package goodreads
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Goodreads' built-in exclusive shelves. Every book is on exactly one.
+const (
+	ShelfRead             = "read"
+	ShelfCurrentlyReading = "currently-reading"
+	ShelfToRead           = "to-read"
+)
+
+// ErrNotExport is returned when the CSV lacks the columns of a Goodreads
+// library export.
+var ErrNotExport = errors.New("not a Goodreads library export")
+
+// Entry is one book from the export.
+type Entry struct {
+	// Line is the entry's line number in the file, for reporting.
+	Line   int
+	Title  string
+	Author string
+	ISBN   string
+	ISBN13 string
+	// Shelves holds the exclusive shelf first, then any custom shelves.
+	Shelves []string
+}
+
+var requiredColumns = []string{
+	"Title",
+	"Author",
+	"ISBN",
+	"ISBN13",
+	"Exclusive Shelf",
+	"Bookshelves",
+}
+
+// Parse reads every entry from an export. Columns are found by header name,
+// so exports with extra or reordered columns still parse.
+func Parse(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: file is empty", ErrNotExport)
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrNotExport, name)
+		}
+	}
+
+	var entries []Entry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		entry := Entry{
+			Line:   line,
+			Title:  field("Title"),
+			Author: field("Author"),
+			ISBN:   unwrapISBN(field("ISBN")),
+			ISBN13: unwrapISBN(field("ISBN13")),
+		}
+		seen := make(map[string]bool)
+		shelves := append([]string{field("Exclusive Shelf")}, strings.Split(field("Bookshelves"), ",")...)
+		for _, shelf := range shelves {
+			shelf = strings.TrimSpace(shelf)
+			if shelf == "" || seen[shelf] {
+				continue
+			}
+			seen[shelf] = true
+			entry.Shelves = append(entry.Shelves, shelf)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// unwrapISBN strips the ="..." Goodreads wraps ISBNs in so spreadsheets keep
+// leading zeros.
+func unwrapISBN(value string) string {
+	value = strings.TrimPrefix(value, "=")
+	return strings.Trim(value, `"`)
+}
+
+// ISBN13 returns isbn as an ISBN-13, converting an ISBN-10, or "" when isbn
+// is neither.
+func ISBN13(isbn string) string {
+	isbn = strings.NewReplacer("-", "", " ", "").Replace(isbn)
+	switch len(isbn) {
+	case 13:
+		return isbn
+	case 10:
+		digits := "978" + isbn[:9]
+		sum := 0
+		for i, r := range digits {
+			if r < '0' || r > '9' {
+				return ""
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(r-'0') * weight
+		}
+		return digits + string(rune('0'+(10-sum%10)%10))
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package httpcompress
+
+import (
+	"book-management-system/pkg/negotiate"
+	"compress/gzip"
+	"io"
+	"net/http"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// Middleware compresses responses with brotli or gzip, whichever the client's
+// Accept-Encoding ranks higher (brotli on a tie).
+func Middleware(skipper middleware.Skipper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if skipper != nil && skipper(c) {
+				return next(c)
+			}
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			acceptEncoding := c.Request().Header.Get(echo.HeaderAcceptEncoding)
+			if acceptEncoding == "" || c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+			encoding := negotiate.Preferred(
+				acceptEncoding,
+				encodingBrotli,
+				encodingGzip,
+			)
+			if encoding == "" {
+				return next(c)
+			}
+			writer := &compressWriter{
+				ResponseWriter: res.Writer,
+				encoding:       encoding,
+			}
+			res.Writer = writer
+			defer func() {
+				writer.close()
+				res.Writer = writer.ResponseWriter
+			}()
+			return next(c)
+		}
+	}
+}
+
+// compressWriter only starts an encoder once a status with a body is written,
+// so 204 and 304 responses go out bare.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding      string
+	encoder       io.WriteCloser
+	headerWritten bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.headerWritten {
+		return
+	}
+	w.headerWritten = true
+	if code != http.StatusNoContent && code != http.StatusNotModified {
+		header := w.Header()
+		header.Set(echo.HeaderContentEncoding, w.encoding)
+		header.Del(echo.HeaderContentLength)
+		if w.encoding == encodingBrotli {
+			w.encoder = brotli.NewWriter(w.ResponseWriter)
+		} else {
+			w.encoder = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.headerWritten {
+		if w.Header().Get(echo.HeaderContentType) == "" {
+			w.Header().Set(echo.HeaderContentType, http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.encoder == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.encoder.Write(b)
+}
+
+func (w *compressWriter) Flush() {
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) close() {
+	if w.encoder != nil {
+		w.encoder.Close()
+	}
+}
@@ -0,0 +1,173 @@
+// Package i18n translates user-facing messages using JSON catalogs keyed by
+// the English source text, in the style of gettext message IDs. Keys may
+// contain %s, %d or %v verbs; a message produced by fmt.Sprintf with that
+// format is matched and its arguments are carried into the translation, which
+// may reorder them with explicit indexes such as %[2]s.
+package i18n
+
+import (
+	"book-management-system/pkg/negotiate"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[dsv]`)
+
+type template struct {
+	pattern *regexp.Regexp
+	format  string
+}
+
+type Catalog struct {
+	defaultLanguage string
+	languages       []string
+	messages        map[string]map[string]string
+	templates       map[string][]template
+}
+
+// Load reads every <language>.json file at the root of fsys. Each file is a
+// flat object mapping English messages to their translation.
+func Load(fsys fs.FS, defaultLanguage string) (*Catalog, error) {
+	catalog := &Catalog{
+		defaultLanguage: strings.ToLower(defaultLanguage),
+		messages:        make(map[string]map[string]string),
+		templates:       make(map[string][]template),
+	}
+	if err := catalog.Merge(fsys); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Merge adds the catalogs in fsys, overriding entries already loaded. It lets
+// deployments reword messages or add languages without rebuilding.
+func (c *Catalog) Merge(fsys fs.FS) error {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var entries map[string]string
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return fmt.Errorf("i18n: parsing %s: %w", name, err)
+		}
+		language := strings.ToLower(strings.TrimSuffix(path.Base(name), ".json"))
+		if _, ok := c.messages[language]; !ok {
+			c.messages[language] = make(map[string]string)
+			c.languages = append(c.languages, language)
+		}
+		for key, value := range entries {
+			c.messages[language][key] = value
+		}
+		c.templates[language] = compileTemplates(c.messages[language])
+	}
+	sort.Strings(c.languages)
+	return nil
+}
+
+func compileTemplates(messages map[string]string) []template {
+	var templates []template
+	for key, value := range messages {
+		if !verbPattern.MatchString(key) {
+			continue
+		}
+		parts := verbPattern.Split(key, -1)
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		templates = append(templates, template{
+			pattern: regexp.MustCompile("^" + strings.Join(parts, "(.*?)") + "$"),
+			format:  verbPattern.ReplaceAllString(value, "%${1}s"),
+		})
+	}
+	// Longer keys are more specific, so "must be at least %s characters"
+	// wins over "must be at least %s".
+	sort.Slice(templates, func(i, j int) bool {
+		return len(templates[i].pattern.String()) > len(templates[j].pattern.String())
+	})
+	return templates
+}
+
+// Languages lists the loaded catalogs.
+func (c *Catalog) Languages() []string {
+	return c.languages
+}
+
+// DefaultLanguage is used when the client accepts none of the catalogs.
+func (c *Catalog) DefaultLanguage() string {
+	return c.defaultLanguage
+}
+
+// Negotiate picks the catalog an Accept-Language header prefers. A regional
+// tag such as th-TH also accepts the base language th.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	if strings.TrimSpace(acceptLanguage) == "" {
+		return c.defaultLanguage
+	}
+	offers := make([]string, 0, len(c.languages)+1)
+	offers = append(offers, c.defaultLanguage)
+	for _, language := range c.languages {
+		if language != c.defaultLanguage {
+			offers = append(offers, language)
+		}
+	}
+	if language := negotiate.Preferred(withBaseLanguages(acceptLanguage), offers...); language != "" {
+		return language
+	}
+	return c.defaultLanguage
+}
+
+func withBaseLanguages(header string) string {
+	parts := strings.Split(header, ",")
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		tag, _, _ := strings.Cut(part, ";")
+		seen[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	for _, part := range parts {
+		tag, params, _ := strings.Cut(part, ";")
+		base, _, ok := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !ok || seen[base] {
+			continue
+		}
+		seen[base] = true
+		if params != "" {
+			base += ";" + params
+		}
+		header += "," + base
+	}
+	return header
+}
+
+// Translate returns message in language, or message unchanged when the
+// catalog has no entry for it.
+func (c *Catalog) Translate(language, message string) string {
+	messages, ok := c.messages[language]
+	if !ok || message == "" {
+		return message
+	}
+	if translated, ok := messages[message]; ok {
+		return translated
+	}
+	for _, t := range c.templates[language] {
+		match := t.pattern.FindStringSubmatch(message)
+		if match == nil {
+			continue
+		}
+		args := make([]any, len(match)-1)
+		for i, arg := range match[1:] {
+			args[i] = arg
+		}
+		return fmt.Sprintf(t.format, args...)
+	}
+	return message
+}
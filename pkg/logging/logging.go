@@ -0,0 +1,194 @@
+// Package logging builds the process slog handler with a level that can be
+// changed while the server runs, both globally and per module. A module is
+// the "module" attribute attached with Module; records without one use the
+// global level.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const ModuleKey = "module"
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Levels holds the global level and per-module overrides shared by every
+// logger derived from the handler.
+type Levels struct {
+	mu      sync.RWMutex
+	level   slog.Level
+	modules map[string]slog.Level
+}
+
+func NewLevels(level slog.Level, modules map[string]slog.Level) *Levels {
+	overrides := make(map[string]slog.Level, len(modules))
+	for module, moduleLevel := range modules {
+		overrides[module] = moduleLevel
+	}
+	return &Levels{
+		level:   level,
+		modules: overrides,
+	}
+}
+
+// Level returns the minimum level logged for module, falling back to the
+// global level when the module has no override.
+func (l *Levels) Level(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.modules[module]; ok {
+		return level
+	}
+	return l.level
+}
+
+func (l *Levels) Global() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.level
+}
+
+func (l *Levels) SetGlobal(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+func (l *Levels) SetModule(module string, level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.modules[module] = level
+}
+
+// ResetModule drops the module's override so it follows the global level.
+func (l *Levels) ResetModule(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.modules, module)
+}
+
+func (l *Levels) Modules() map[string]slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	modules := make(map[string]slog.Level, len(l.modules))
+	for module, level := range l.modules {
+		modules[module] = level
+	}
+	return modules
+}
+
+// ParseLevel accepts debug, info, warn or error in any case, with an optional
+// offset such as warn+2.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// ParseModuleLevels parses a comma-separated list of module=level pairs, for
+// example "gorm=warn,jobs=debug".
+func ParseModuleLevels(s string) (map[string]slog.Level, error) {
+	modules := make(map[string]slog.Level)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, value, ok := strings.Cut(pair, "=")
+		module = strings.TrimSpace(module)
+		if !ok || module == "" {
+			return nil, fmt.Errorf("invalid module level %q, expected module=level", pair)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module, err)
+		}
+		modules[module] = level
+	}
+	return modules, nil
+}
+
+// FormatModuleLevels is the inverse of ParseModuleLevels, sorted by module.
+func FormatModuleLevels(modules map[string]slog.Level) string {
+	pairs := make([]string, 0, len(modules))
+	for module, level := range modules {
+		pairs = append(pairs, module+"="+strings.ToLower(level.String()))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// NewHandler writes records to w as text or JSON, filtered by levels.
+func NewHandler(w io.Writer, format string, levels *Levels) (slog.Handler, error) {
+	// The inner handler accepts everything; Handler.Enabled does the
+	// filtering so level changes apply without rebuilding it.
+	opts := &slog.HandlerOptions{
+		Level: slog.Level(-1 << 10),
+	}
+	var next slog.Handler
+	switch format {
+	case FormatText:
+		next = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		next = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected text or json", format)
+	}
+	return &Handler{
+		next:   next,
+		levels: levels,
+	}, nil
+}
+
+type Handler struct {
+	next   slog.Handler
+	levels *Levels
+	module string
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.module)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	return h.next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, attr := range attrs {
+		if attr.Key == ModuleKey {
+			module = attr.Value.String()
+		}
+	}
+	return &Handler{
+		next:   h.next.WithAttrs(attrs),
+		levels: h.levels,
+		module: module,
+	}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{
+		next:   h.next.WithGroup(name),
+		levels: h.levels,
+		module: h.module,
+	}
+}
+
+// Module returns the default logger tagged with module, so its records follow
+// that module's level. Call it after slog.SetDefault.
+func Module(name string) *slog.Logger {
+	return slog.Default().With(ModuleKey, name)
+}
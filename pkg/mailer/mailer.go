@@ -0,0 +1,86 @@
+// Package mailer sends email through SMTP, Amazon SES or SendGrid, and renders
+// the application's email templates.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+)
+
+const (
+	DriverOff      = "off"
+	DriverLog      = "log"
+	DriverSMTP     = "smtp"
+	DriverSES      = "ses"
+	DriverSendGrid = "sendgrid"
+)
+
+// sendTimeout bounds one delivery attempt, including the SMTP dialogue.
+const sendTimeout = 30 * time.Second
+
+// Mailer is implemented by SMTP, SES, SendGrid and Log.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is an email to To. At least one of Text and HTML must be set; when
+// both are, clients pick the alternative they display best.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+func (m Message) validate() error {
+	if len(m.To) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+	for _, to := range m.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("recipient %q: %w", to, err)
+		}
+	}
+	if m.Text == "" && m.HTML == "" {
+		return fmt.Errorf("message has no body")
+	}
+	return nil
+}
+
+// Log writes messages to the log instead of sending them, for local
+// development.
+type Log struct {
+	logger *slog.Logger
+}
+
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{
+		logger: logger,
+	}
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	l.logger.InfoContext(ctx, "Email not sent, MAILER_DRIVER is log",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"text", msg.Text,
+	)
+	return nil
+}
+
+func parseFrom(from string) (*mail.Address, error) {
+	if from == "" {
+		return nil, fmt.Errorf("from address is required")
+	}
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("from address %q: %w", from, err)
+	}
+	return address, nil
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+const sendGridSendURL = "https://api.sendgrid.com/v3/mail/send"
+
+type SendGridConfig struct {
+	APIKey string
+	From   string
+}
+
+// SendGrid sends mail with the SendGrid v3 Mail Send API. The From address
+// must be a verified sender.
+type SendGrid struct {
+	apiKey string
+	from   *mail.Address
+	client *http.Client
+}
+
+func NewSendGrid(cfg SendGridConfig) (*SendGrid, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("sendgrid: API key is required")
+	}
+	from, err := parseFrom(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("sendgrid: %w", err)
+	}
+	return &SendGrid{
+		apiKey: cfg.APIKey,
+		from:   from,
+		client: &http.Client{
+			Timeout: sendTimeout,
+		},
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+func (s *SendGrid) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	var personalization sendGridPersonalization
+	for _, recipient := range msg.To {
+		address, _ := mail.ParseAddress(recipient)
+		personalization.To = append(personalization.To, sendGridAddress{
+			Email: address.Address,
+			Name:  address.Name,
+		})
+	}
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{personalization},
+		From:             sendGridAddress{Email: s.from.Address, Name: s.from.Name},
+		Subject:          msg.Subject,
+	}
+	// SendGrid requires text/plain before text/html.
+	if msg.Text != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridSendURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("sendgrid: mail send returned %s: %s", res.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
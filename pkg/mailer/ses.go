@@ -0,0 +1,132 @@
+package mailer
+
+import (
+	"book-management-system/pkg/sigv4"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const sesSendPath = "/v2/email/outbound-emails"
+
+type SESConfig struct {
+	// Endpoint is the scheme and host of the API. Empty means AWS,
+	// https://email.<region>.amazonaws.com.
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	From            string
+}
+
+// SES sends mail with the Amazon SES v2 SendEmail API. The From address, or
+// its domain, must be a verified SES identity.
+type SES struct {
+	endpoint *url.URL
+	from     *mail.Address
+	signer   sigv4.Signer
+	client   *http.Client
+}
+
+func NewSES(cfg SESConfig) (*SES, error) {
+	if cfg.Region == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("ses: region and credentials are required")
+	}
+	from, err := parseFrom(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("ses: %w", err)
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://email." + cfg.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("ses: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("ses: endpoint %q must include a scheme and host", endpoint)
+	}
+	return &SES{
+		endpoint: u,
+		from:     from,
+		signer: sigv4.Signer{
+			Region:          cfg.Region,
+			Service:         "ses",
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+		},
+		client: &http.Client{
+			Timeout: sendTimeout,
+		},
+	}, nil
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesSendEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				HTML *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+func (s *SES) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return fmt.Errorf("ses: %w", err)
+	}
+	var payload sesSendEmailRequest
+	payload.FromEmailAddress = s.from.Address
+	if s.from.Name != "" {
+		payload.FromEmailAddress = s.from.String()
+	}
+	payload.Destination.ToAddresses = msg.To
+	payload.Content.Simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	if msg.Text != "" {
+		payload.Content.Simple.Body.Text = &sesContent{Data: msg.Text, Charset: "UTF-8"}
+	}
+	if msg.HTML != "" {
+		payload.Content.Simple.Body.HTML = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ses: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint.String()+sesSendPath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ses: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.signer.Sign(req, sesSendPath, body, time.Now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ses: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("ses: SendEmail returned %s: %s", res.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+const (
+	TemplateVerification  = "verification"
+	TemplatePasswordReset = "password_reset"
+	TemplateDueNotice     = "due_notice"
+	TemplateOverdueNotice = "overdue_notice"
+	TemplateNotification  = "notification"
+	TemplateLoanReceipt   = "loan_receipt"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// emailTemplate is parsed from templates/<name>.txt, which defines the
+// "subject" and holds the plain text body, and templates/<name>.html, which
+// holds the HTML body inside the shared layout.
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = map[string]emailTemplate{}
+
+func init() {
+	for _, name := range []string{
+		TemplateVerification,
+		TemplatePasswordReset,
+		TemplateDueNotice,
+		TemplateOverdueNotice,
+		TemplateNotification,
+		TemplateLoanReceipt,
+	} {
+		templates[name] = emailTemplate{
+			text: texttemplate.Must(texttemplate.New(name+".txt").
+				Option("missingkey=error").
+				ParseFS(templateFiles, "templates/"+name+".txt")),
+			html: htmltemplate.Must(htmltemplate.New("layout.html").
+				Option("missingkey=error").
+				ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html")),
+		}
+	}
+}
+
+// Render builds the subject and bodies of the named template; the caller sets
+// To. The data each template reads:
+//   - verification and password_reset: name, url and expires_at
+//   - due_notice and overdue_notice: name, title and due_date
+//   - notification: name, subject and body
+//   - loan_receipt: name, subject, action ("checkout" or "return"), title,
+//     due_date for a checkout or returned_date for a return, and loans, the
+//     member's loans after it, each with title and due_date
+func Render(name string, data map[string]any) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
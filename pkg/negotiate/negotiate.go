@@ -0,0 +1,71 @@
+package negotiate
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Preferred returns the offer the Accept-style header ranks highest, or ""
+// when none is acceptable. An empty header accepts the first offer, and ties
+// go to the earlier offer, so callers list their default first.
+func Preferred(header string, offers ...string) string {
+	if strings.TrimSpace(header) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+	qualities := parse(header)
+	best := ""
+	bestQ := 0.0
+	for _, offer := range offers {
+		q := quality(qualities, offer)
+		if q > bestQ {
+			best = offer
+			bestQ = q
+		}
+	}
+	return best
+}
+
+func parse(header string) map[string]float64 {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(fields[0]))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, raw, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		qualities[value] = q
+	}
+	return qualities
+}
+
+func quality(qualities map[string]float64, offer string) float64 {
+	offer = strings.ToLower(offer)
+	if q, ok := qualities[offer]; ok {
+		return q
+	}
+	if mediaType, _, ok := strings.Cut(offer, "/"); ok {
+		if q, ok := qualities[mediaType+"/*"]; ok {
+			return q
+		}
+		if q, ok := qualities["*/*"]; ok {
+			return q
+		}
+		return 0
+	}
+	if q, ok := qualities["*"]; ok {
+		return q
+	}
+	return 0
+}
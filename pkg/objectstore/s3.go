@@ -0,0 +1,268 @@
+// Package objectstore stores objects in S3 or an S3-compatible store such as
+// MinIO, signing requests with AWS Signature Version 4.
+package objectstore
+
+import (
+	"book-management-system/pkg/sigv4"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const s3Timeout = 5 * time.Minute
+
+// ErrNotFound is returned by Get for a key that does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Store is implemented by S3.
+type Store interface {
+	Put(ctx context.Context, key, contentType, contentEncoding string, body []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, key string) error
+	PresignGet(key string, query url.Values, expires time.Duration) (string, error)
+}
+
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+type S3Config struct {
+	// Endpoint is the scheme and host of the store, e.g. http://minio:9000.
+	// Empty means AWS, https://s3.<region>.amazonaws.com.
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses objects as <endpoint>/<bucket>/<key> instead of
+	// <bucket>.<host>/<key>. MinIO usually needs it.
+	PathStyle bool
+}
+
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	signer   sigv4.Signer
+	client   *http.Client
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Bucket == "" || cfg.Region == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3: bucket, region and credentials are required")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("s3: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("s3: endpoint %q must include a scheme and host", endpoint)
+	}
+	return &S3{
+		cfg:      cfg,
+		endpoint: u,
+		signer: sigv4.Signer{
+			Region:          cfg.Region,
+			Service:         "s3",
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+		},
+		client: &http.Client{
+			Timeout: s3Timeout,
+		},
+	}, nil
+}
+
+// Put stores body under key, replacing any existing object. contentEncoding
+// may be empty.
+func (s *S3) Put(ctx context.Context, key, contentType, contentEncoding string, body []byte) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		header.Set("Content-Encoding", contentEncoding)
+	}
+	res, err := s.do(ctx, http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return expectStatus(res, http.MethodPut, key, http.StatusOK)
+}
+
+// Get returns the object stored under key as stored, without undoing its
+// Content-Encoding. The caller closes it.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	// Asking for identity stops the client from transparently gunzipping
+	// objects stored with Content-Encoding: gzip.
+	header := http.Header{}
+	header.Set("Accept-Encoding", "identity")
+	res, err := s.do(ctx, http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, fmt.Errorf("s3: %s: %w", key, ErrNotFound)
+	}
+	if err := expectStatus(res, http.MethodGet, key, http.StatusOK); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// List returns every object whose key starts with prefix, in key order.
+func (s *S3) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{
+			"list-type": {"2"},
+			"prefix":    {prefix},
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		res, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = expectStatus(res, http.MethodGet, "?prefix="+prefix, http.StatusOK)
+		if err == nil {
+			err = xml.NewDecoder(res.Body).Decode(&page)
+		}
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3: list %s: %w", prefix, err)
+		}
+		for _, content := range page.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				LastModified: content.LastModified,
+			})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Delete removes the object stored under key. Deleting a missing key
+// succeeds.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return expectStatus(res, http.MethodDelete, key, http.StatusNoContent, http.StatusOK)
+}
+
+// PresignGet returns a URL that downloads the object stored under key
+// without credentials until expires has passed. query may set S3's response
+// header overrides, such as response-content-disposition.
+func (s *S3) PresignGet(key string, query url.Values, expires time.Duration) (string, error) {
+	host, path := s.location(key)
+	// Parsing keeps the escaped path as the raw path, so the URL is
+	// written out exactly as it was signed.
+	target, err := url.Parse(s.endpoint.Scheme + "://" + host + path + "?" + query.Encode())
+	if err != nil {
+		return "", fmt.Errorf("s3: %w", err)
+	}
+	return s.signer.Presign(http.MethodGet, target, path, expires, time.Now()), nil
+}
+
+// location returns the host and escaped path that address key, or the
+// bucket itself when key is empty.
+func (s *S3) location(key string) (host, path string) {
+	if s.cfg.PathStyle {
+		path = "/" + escapePath(s.cfg.Bucket)
+		if key != "" {
+			path += "/" + escapePath(key)
+		}
+		return s.endpoint.Host, path
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host, "/" + escapePath(key)
+}
+
+// do sends a signed request for key, or for the bucket itself when key is
+// empty.
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	host, path := s.location(key)
+	target := s.endpoint.Scheme + "://" + host + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("s3: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.signer.Sign(req, path, body, time.Now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3: %w", err)
+	}
+	return res, nil
+}
+
+// expectStatus turns any status but the expected ones into an error carrying
+// the start of the store's error document.
+func expectStatus(res *http.Response, method, key string, statuses ...int) error {
+	for _, status := range statuses {
+		if res.StatusCode == status {
+			return nil
+		}
+	}
+	detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3: %s %s returned %s: %s", method, key, res.Status, strings.TrimSpace(string(detail)))
+}
+
+// escapePath percent-encodes every byte of key outside the unreserved set,
+// keeping the slashes between segments, as Signature Version 4 requires.
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,442 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: bookms/v1/loan.proto
+
+package bookmsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Loan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BookId        string                 `protobuf:"bytes,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DueDate       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	ReturnedDate  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=returned_date,json=returnedDate,proto3" json:"returned_date,omitempty"`
+	CreatedDate   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_date,json=createdDate,proto3" json:"created_date,omitempty"`
+	UpdatedDate   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_date,json=updatedDate,proto3" json:"updated_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Loan) Reset() {
+	*x = Loan{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Loan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Loan) ProtoMessage() {}
+
+func (x *Loan) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Loan.ProtoReflect.Descriptor instead.
+func (*Loan) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Loan) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Loan) GetBookId() string {
+	if x != nil {
+		return x.BookId
+	}
+	return ""
+}
+
+func (x *Loan) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Loan) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+func (x *Loan) GetReturnedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReturnedDate
+	}
+	return nil
+}
+
+func (x *Loan) GetCreatedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedDate
+	}
+	return nil
+}
+
+func (x *Loan) GetUpdatedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedDate
+	}
+	return nil
+}
+
+type GetLoanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoanRequest) Reset() {
+	*x = GetLoanRequest{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoanRequest) ProtoMessage() {}
+
+func (x *GetLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoanRequest.ProtoReflect.Descriptor instead.
+func (*GetLoanRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetLoanRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListActiveLoansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveLoansRequest) Reset() {
+	*x = ListActiveLoansRequest{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveLoansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveLoansRequest) ProtoMessage() {}
+
+func (x *ListActiveLoansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveLoansRequest.ProtoReflect.Descriptor instead.
+func (*ListActiveLoansRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListActiveLoansRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListActiveLoansResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Loans         []*Loan                `protobuf:"bytes,1,rep,name=loans,proto3" json:"loans,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveLoansResponse) Reset() {
+	*x = ListActiveLoansResponse{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveLoansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveLoansResponse) ProtoMessage() {}
+
+func (x *ListActiveLoansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveLoansResponse.ProtoReflect.Descriptor instead.
+func (*ListActiveLoansResponse) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListActiveLoansResponse) GetLoans() []*Loan {
+	if x != nil {
+		return x.Loans
+	}
+	return nil
+}
+
+type CreateLoanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	BookId        string                 `protobuf:"bytes,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLoanRequest) Reset() {
+	*x = CreateLoanRequest{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLoanRequest) ProtoMessage() {}
+
+func (x *CreateLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLoanRequest.ProtoReflect.Descriptor instead.
+func (*CreateLoanRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateLoanRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateLoanRequest) GetBookId() string {
+	if x != nil {
+		return x.BookId
+	}
+	return ""
+}
+
+type ReturnLoanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnLoanRequest) Reset() {
+	*x = ReturnLoanRequest{}
+	mi := &file_bookms_v1_loan_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnLoanRequest) ProtoMessage() {}
+
+func (x *ReturnLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_loan_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnLoanRequest.ProtoReflect.Descriptor instead.
+func (*ReturnLoanRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_loan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReturnLoanRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_bookms_v1_loan_proto protoreflect.FileDescriptor
+
+const file_bookms_v1_loan_proto_rawDesc = "" +
+	"\n" +
+	"\x14bookms/v1/loan.proto\x12\tbookms.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbe\x02\n" +
+	"\x04Loan\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\tR\x06bookId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x125\n" +
+	"\bdue_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\x12?\n" +
+	"\rreturned_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\freturnedDate\x12=\n" +
+	"\fcreated_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedDate\x12=\n" +
+	"\fupdated_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vupdatedDate\" \n" +
+	"\x0eGetLoanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x16ListActiveLoansRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"@\n" +
+	"\x17ListActiveLoansResponse\x12%\n" +
+	"\x05loans\x18\x01 \x03(\v2\x0f.bookms.v1.LoanR\x05loans\"E\n" +
+	"\x11CreateLoanRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\abook_id\x18\x02 \x01(\tR\x06bookId\"#\n" +
+	"\x11ReturnLoanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\x98\x02\n" +
+	"\vLoanService\x125\n" +
+	"\aGetLoan\x12\x19.bookms.v1.GetLoanRequest\x1a\x0f.bookms.v1.Loan\x12X\n" +
+	"\x0fListActiveLoans\x12!.bookms.v1.ListActiveLoansRequest\x1a\".bookms.v1.ListActiveLoansResponse\x12;\n" +
+	"\n" +
+	"CreateLoan\x12\x1c.bookms.v1.CreateLoanRequest\x1a\x0f.bookms.v1.Loan\x12;\n" +
+	"\n" +
+	"ReturnLoan\x12\x1c.bookms.v1.ReturnLoanRequest\x1a\x0f.bookms.v1.LoanB2Z0book-management-system/pkg/pb/bookms/v1;bookmsv1b\x06proto3"
+
+var (
+	file_bookms_v1_loan_proto_rawDescOnce sync.Once
+	file_bookms_v1_loan_proto_rawDescData []byte
+)
+
+func file_bookms_v1_loan_proto_rawDescGZIP() []byte {
+	file_bookms_v1_loan_proto_rawDescOnce.Do(func() {
+		file_bookms_v1_loan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bookms_v1_loan_proto_rawDesc), len(file_bookms_v1_loan_proto_rawDesc)))
+	})
+	return file_bookms_v1_loan_proto_rawDescData
+}
+
+var file_bookms_v1_loan_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_bookms_v1_loan_proto_goTypes = []any{
+	(*Loan)(nil),                    // 0: bookms.v1.Loan
+	(*GetLoanRequest)(nil),          // 1: bookms.v1.GetLoanRequest
+	(*ListActiveLoansRequest)(nil),  // 2: bookms.v1.ListActiveLoansRequest
+	(*ListActiveLoansResponse)(nil), // 3: bookms.v1.ListActiveLoansResponse
+	(*CreateLoanRequest)(nil),       // 4: bookms.v1.CreateLoanRequest
+	(*ReturnLoanRequest)(nil),       // 5: bookms.v1.ReturnLoanRequest
+	(*timestamppb.Timestamp)(nil),   // 6: google.protobuf.Timestamp
+}
+var file_bookms_v1_loan_proto_depIdxs = []int32{
+	6, // 0: bookms.v1.Loan.due_date:type_name -> google.protobuf.Timestamp
+	6, // 1: bookms.v1.Loan.returned_date:type_name -> google.protobuf.Timestamp
+	6, // 2: bookms.v1.Loan.created_date:type_name -> google.protobuf.Timestamp
+	6, // 3: bookms.v1.Loan.updated_date:type_name -> google.protobuf.Timestamp
+	0, // 4: bookms.v1.ListActiveLoansResponse.loans:type_name -> bookms.v1.Loan
+	1, // 5: bookms.v1.LoanService.GetLoan:input_type -> bookms.v1.GetLoanRequest
+	2, // 6: bookms.v1.LoanService.ListActiveLoans:input_type -> bookms.v1.ListActiveLoansRequest
+	4, // 7: bookms.v1.LoanService.CreateLoan:input_type -> bookms.v1.CreateLoanRequest
+	5, // 8: bookms.v1.LoanService.ReturnLoan:input_type -> bookms.v1.ReturnLoanRequest
+	0, // 9: bookms.v1.LoanService.GetLoan:output_type -> bookms.v1.Loan
+	3, // 10: bookms.v1.LoanService.ListActiveLoans:output_type -> bookms.v1.ListActiveLoansResponse
+	0, // 11: bookms.v1.LoanService.CreateLoan:output_type -> bookms.v1.Loan
+	0, // 12: bookms.v1.LoanService.ReturnLoan:output_type -> bookms.v1.Loan
+	9, // [9:13] is the sub-list for method output_type
+	5, // [5:9] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_bookms_v1_loan_proto_init() }
+func file_bookms_v1_loan_proto_init() {
+	if File_bookms_v1_loan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bookms_v1_loan_proto_rawDesc), len(file_bookms_v1_loan_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bookms_v1_loan_proto_goTypes,
+		DependencyIndexes: file_bookms_v1_loan_proto_depIdxs,
+		MessageInfos:      file_bookms_v1_loan_proto_msgTypes,
+	}.Build()
+	File_bookms_v1_loan_proto = out.File
+	file_bookms_v1_loan_proto_goTypes = nil
+	file_bookms_v1_loan_proto_depIdxs = nil
+}
@@ -0,0 +1,322 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: bookms/v1/user.proto
+
+package bookmsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type User struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email            string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	FirstName        string                 `protobuf:"bytes,3,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName         string                 `protobuf:"bytes,4,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	Role             string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	Status           string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	MembershipPlanId string                 `protobuf:"bytes,7,opt,name=membership_plan_id,json=membershipPlanId,proto3" json:"membership_plan_id,omitempty"`
+	CardNumber       string                 `protobuf:"bytes,8,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	LastLoginAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_login_at,json=lastLoginAt,proto3" json:"last_login_at,omitempty"`
+	CreatedDate      *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_date,json=createdDate,proto3" json:"created_date,omitempty"`
+	UpdatedDate      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_date,json=updatedDate,proto3" json:"updated_date,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_bookms_v1_user_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_user_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_user_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *User) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *User) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *User) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *User) GetMembershipPlanId() string {
+	if x != nil {
+		return x.MembershipPlanId
+	}
+	return ""
+}
+
+func (x *User) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *User) GetLastLoginAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastLoginAt
+	}
+	return nil
+}
+
+func (x *User) GetCreatedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedDate
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedDate
+	}
+	return nil
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_bookms_v1_user_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_user_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_user_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetUserByCardNumberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CardNumber    string                 `protobuf:"bytes,1,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserByCardNumberRequest) Reset() {
+	*x = GetUserByCardNumberRequest{}
+	mi := &file_bookms_v1_user_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserByCardNumberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserByCardNumberRequest) ProtoMessage() {}
+
+func (x *GetUserByCardNumberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_bookms_v1_user_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserByCardNumberRequest.ProtoReflect.Descriptor instead.
+func (*GetUserByCardNumberRequest) Descriptor() ([]byte, []int) {
+	return file_bookms_v1_user_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetUserByCardNumberRequest) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+var File_bookms_v1_user_proto protoreflect.FileDescriptor
+
+const file_bookms_v1_user_proto_rawDesc = "" +
+	"\n" +
+	"\x14bookms/v1/user.proto\x12\tbookms.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa1\x03\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x03 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x04 \x01(\tR\blastName\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12,\n" +
+	"\x12membership_plan_id\x18\a \x01(\tR\x10membershipPlanId\x12\x1f\n" +
+	"\vcard_number\x18\b \x01(\tR\n" +
+	"cardNumber\x12>\n" +
+	"\rlast_login_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vlastLoginAt\x12=\n" +
+	"\fcreated_date\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedDate\x12=\n" +
+	"\fupdated_date\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vupdatedDate\" \n" +
+	"\x0eGetUserRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
+	"\x1aGetUserByCardNumberRequest\x12\x1f\n" +
+	"\vcard_number\x18\x01 \x01(\tR\n" +
+	"cardNumber2\x93\x01\n" +
+	"\vUserService\x125\n" +
+	"\aGetUser\x12\x19.bookms.v1.GetUserRequest\x1a\x0f.bookms.v1.User\x12M\n" +
+	"\x13GetUserByCardNumber\x12%.bookms.v1.GetUserByCardNumberRequest\x1a\x0f.bookms.v1.UserB2Z0book-management-system/pkg/pb/bookms/v1;bookmsv1b\x06proto3"
+
+var (
+	file_bookms_v1_user_proto_rawDescOnce sync.Once
+	file_bookms_v1_user_proto_rawDescData []byte
+)
+
+func file_bookms_v1_user_proto_rawDescGZIP() []byte {
+	file_bookms_v1_user_proto_rawDescOnce.Do(func() {
+		file_bookms_v1_user_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_bookms_v1_user_proto_rawDesc), len(file_bookms_v1_user_proto_rawDesc)))
+	})
+	return file_bookms_v1_user_proto_rawDescData
+}
+
+var file_bookms_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_bookms_v1_user_proto_goTypes = []any{
+	(*User)(nil),                       // 0: bookms.v1.User
+	(*GetUserRequest)(nil),             // 1: bookms.v1.GetUserRequest
+	(*GetUserByCardNumberRequest)(nil), // 2: bookms.v1.GetUserByCardNumberRequest
+	(*timestamppb.Timestamp)(nil),      // 3: google.protobuf.Timestamp
+}
+var file_bookms_v1_user_proto_depIdxs = []int32{
+	3, // 0: bookms.v1.User.last_login_at:type_name -> google.protobuf.Timestamp
+	3, // 1: bookms.v1.User.created_date:type_name -> google.protobuf.Timestamp
+	3, // 2: bookms.v1.User.updated_date:type_name -> google.protobuf.Timestamp
+	1, // 3: bookms.v1.UserService.GetUser:input_type -> bookms.v1.GetUserRequest
+	2, // 4: bookms.v1.UserService.GetUserByCardNumber:input_type -> bookms.v1.GetUserByCardNumberRequest
+	0, // 5: bookms.v1.UserService.GetUser:output_type -> bookms.v1.User
+	0, // 6: bookms.v1.UserService.GetUserByCardNumber:output_type -> bookms.v1.User
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_bookms_v1_user_proto_init() }
+func file_bookms_v1_user_proto_init() {
+	if File_bookms_v1_user_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_bookms_v1_user_proto_rawDesc), len(file_bookms_v1_user_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_bookms_v1_user_proto_goTypes,
+		DependencyIndexes: file_bookms_v1_user_proto_depIdxs,
+		MessageInfos:      file_bookms_v1_user_proto_msgTypes,
+	}.Build()
+	File_bookms_v1_user_proto = out.File
+	file_bookms_v1_user_proto_goTypes = nil
+	file_bookms_v1_user_proto_depIdxs = nil
+}
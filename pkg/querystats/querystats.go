@@ -0,0 +1,161 @@
+// Package querystats aggregates SQL statement timings in memory so the
+// slowest queries since startup can be inspected without a database
+// extension such as pg_stat_statements.
+package querystats
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SortMax   = "max"
+	SortMean  = "mean"
+	SortTotal = "total"
+)
+
+var (
+	placeholderList = regexp.MustCompile(`\(\s*(?:\?|\$\d+)(?:\s*,\s*(?:\?|\$\d+))+\s*\)`)
+	placeholder     = regexp.MustCompile(`\$\d+`)
+	limitOffset     = regexp.MustCompile(`(?i)\b(LIMIT|OFFSET) \d+`)
+	whitespace      = regexp.MustCompile(`\s+`)
+)
+
+type Stat struct {
+	Query     string
+	Count     int64
+	SlowCount int64
+	Errors    int64
+	Total     time.Duration
+	Max       time.Duration
+	LastSeen  time.Time
+}
+
+func (s Stat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Recorder keeps one Stat per normalized statement. Once maxStatements
+// distinct statements are tracked, new ones are counted as dropped instead of
+// growing memory without bound.
+type Recorder struct {
+	mu            sync.Mutex
+	slowThreshold time.Duration
+	maxStatements int
+	stats         map[string]*Stat
+	dropped       int64
+	since         time.Time
+}
+
+func NewRecorder(slowThreshold time.Duration, maxStatements int) *Recorder {
+	return &Recorder{
+		slowThreshold: slowThreshold,
+		maxStatements: maxStatements,
+		stats:         make(map[string]*Stat),
+		since:         time.Now().UTC(),
+	}
+}
+
+// Normalize collapses placeholder lists such as IN ($1,$2,$3) to (?),
+// numbered placeholders and inlined LIMIT/OFFSET values to ?, so the same
+// statement with different arguments, page sizes or dialect shares one entry.
+func Normalize(query string) string {
+	query = placeholderList.ReplaceAllString(query, "(?)")
+	query = placeholder.ReplaceAllString(query, "?")
+	query = limitOffset.ReplaceAllString(query, "$1 ?")
+	return strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+}
+
+func (r *Recorder) Record(query string, elapsed time.Duration, failed bool) {
+	if query == "" {
+		return
+	}
+	query = Normalize(query)
+	now := time.Now().UTC()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stat, ok := r.stats[query]
+	if !ok {
+		if len(r.stats) >= r.maxStatements {
+			r.dropped++
+			return
+		}
+		stat = &Stat{
+			Query: query,
+		}
+		r.stats[query] = stat
+	}
+	stat.Count++
+	stat.Total += elapsed
+	if elapsed > stat.Max {
+		stat.Max = elapsed
+	}
+	if r.slowThreshold > 0 && elapsed >= r.slowThreshold {
+		stat.SlowCount++
+	}
+	if failed {
+		stat.Errors++
+	}
+	stat.LastSeen = now
+}
+
+type Snapshot struct {
+	Queries       []Stat
+	Tracked       int
+	Dropped       int64
+	Since         time.Time
+	SlowThreshold time.Duration
+}
+
+// Top returns the limit slowest statements ordered by sortBy, which is one of
+// SortMax, SortMean or SortTotal.
+func (r *Recorder) Top(limit int, sortBy string) Snapshot {
+	r.mu.Lock()
+	stats := make([]Stat, 0, len(r.stats))
+	for _, stat := range r.stats {
+		stats = append(stats, *stat)
+	}
+	snapshot := Snapshot{
+		Tracked:       len(r.stats),
+		Dropped:       r.dropped,
+		Since:         r.since,
+		SlowThreshold: r.slowThreshold,
+	}
+	r.mu.Unlock()
+
+	key := func(s Stat) time.Duration {
+		switch sortBy {
+		case SortMean:
+			return s.Mean()
+		case SortTotal:
+			return s.Total
+		}
+		return s.Max
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if ki, kj := key(stats[i]), key(stats[j]); ki != kj {
+			return ki > kj
+		}
+		return stats[i].Query < stats[j].Query
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	snapshot.Queries = stats
+	return snapshot
+}
+
+// Reset forgets every statement and restarts the observation window.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = make(map[string]*Stat)
+	r.dropped = 0
+	r.since = time.Now().UTC()
+}
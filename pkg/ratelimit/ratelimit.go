@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+const visitorExpiry = 3 * time.Minute
+
+// PerIP limits each client IP to perMinute requests, refilled evenly.
+func PerIP(perMinute int) echo.MiddlewareFunc {
+	return newLimiter(perMinute, func(c echo.Context) (string, error) {
+		return "ip:" + c.RealIP(), nil
+	})
+}
+
+// PerUser limits each authenticated user to perMinute requests and falls back
+// to the client IP for anonymous requests.
+func PerUser(perMinute int, userID func(c echo.Context) string) echo.MiddlewareFunc {
+	return newLimiter(perMinute, func(c echo.Context) (string, error) {
+		if id := userID(c); id != "" {
+			return "user:" + id, nil
+		}
+		return "ip:" + c.RealIP(), nil
+	})
+}
+
+func newLimiter(perMinute int, extractor middleware.Extractor) echo.MiddlewareFunc {
+	retryAfter := strconv.Itoa(int(math.Ceil(60 / float64(perMinute))))
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(
+			middleware.RateLimiterMemoryStoreConfig{
+				Rate:      rate.Limit(float64(perMinute) / 60),
+				Burst:     perMinute,
+				ExpiresIn: visitorExpiry,
+			},
+		),
+		IdentifierExtractor: extractor,
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			c.Response().Header().Set("Retry-After", retryAfter)
+			// The server's error handler writes the response envelope.
+			return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const vaultTimeout = 10 * time.Second
+
+// LoadFiles resolves PREFIX_NAME_FILE for each name, the Docker and Kubernetes
+// secrets convention, into PREFIX_NAME unless PREFIX_NAME is already set.
+func LoadFiles(prefix string, names ...string) error {
+	for _, name := range names {
+		key := prefix + "_" + name
+		path := os.Getenv(key + "_FILE")
+		if path == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE: %w", key, err)
+		}
+		if err := os.Setenv(key, strings.TrimRight(string(data), "\r\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadVault reads the secret at path (e.g. secret/data/bookms for KV v2 or
+// secret/bookms for KV v1) and exports its lower-case keys as PREFIX_NAME for
+// each name that is not already set.
+func LoadVault(ctx context.Context, addr, token, path, prefix string, names ...string) error {
+	if path == "" {
+		return fmt.Errorf("vault: %s_VAULT_SECRET_PATH is required", prefix)
+	}
+	ctx, cancel := context.WithTimeout(ctx, vaultTimeout)
+	defer cancel()
+	url := strings.TrimRight(addr, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault: GET %s returned %s", path, res.Status)
+	}
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return fmt.Errorf("vault: %w", err)
+	}
+	values := body.Data
+	if nested, ok := values["data"].(map[string]any); ok {
+		if _, v2 := values["metadata"]; v2 {
+			values = nested
+		}
+	}
+	for _, name := range names {
+		key := prefix + "_" + name
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		value, ok := values[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		if err := os.Setenv(key, fmt.Sprint(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package sigv4 signs HTTP requests with AWS Signature Version 4, for the
+// AWS APIs called without the SDK.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateLayout    = "20060102T150405Z"
+	amzDayLayout     = "20060102"
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+type Signer struct {
+	Region          string
+	Service         string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Sign adds the Signature Version 4 headers to req. path is the already
+// escaped request path, which is signed as is. The host, date and payload
+// hash are signed, and Content-Type and Content-Encoding when set, along
+// with the query string.
+func (s Signer) Sign(req *http.Request, path string, body []byte, now time.Time) {
+	now = now.UTC()
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format(amzDateLayout)
+	day := now.Format(amzDayLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	for _, name := range []string{"content-encoding", "content-type"} {
+		if value := req.Header.Get(name); value != "" {
+			headers = append(headers, name)
+			values[name] = value
+		}
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(day)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm,
+		s.AccessKeyID,
+		scope,
+		signedHeaders,
+		s.signature(day, amzDate, scope, canonicalRequest),
+	))
+}
+
+// Presign returns target with a query string signature that lets whoever
+// holds the URL make one kind of request, method, until expires has passed.
+// path is the already escaped path of target. Only the host is signed, so
+// the holder may send any headers, and the payload is left unsigned. S3
+// accepts expiry times of up to seven days.
+func (s Signer) Presign(method string, target *url.URL, path string, expires time.Duration, now time.Time) string {
+	now = now.UTC()
+	amzDate := now.Format(amzDateLayout)
+	day := now.Format(amzDayLayout)
+	scope := s.scope(day)
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", signingAlgorithm)
+	query.Set("X-Amz-Credential", s.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery(query),
+		"host:" + target.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(day, amzDate, scope, canonicalRequest))
+
+	signed := *target
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+func (s Signer) scope(day string) string {
+	return day + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+func (s Signer) signature(day, amzDate, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery sorts the parameters by name and value and encodes them
+// strictly, as the signature requires whatever encoding the URL used.
+func canonicalQuery(query url.Values) string {
+	encoded := make(map[string][]string, len(query))
+	names := make([]string, 0, len(query))
+	for name, values := range query {
+		name = escape(name)
+		for _, value := range values {
+			encoded[name] = append(encoded[name], escape(value))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var params []string
+	for _, name := range names {
+		sort.Strings(encoded[name])
+		for _, value := range encoded[name] {
+			params = append(params, name+"="+value)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// escape percent-encodes every byte outside the unreserved set.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}